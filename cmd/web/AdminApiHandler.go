@@ -0,0 +1,437 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/gorilla/websocket"
+	"github.com/onlysumitg/GoMockAPI/internal/iwebsocket"
+	"github.com/onlysumitg/GoMockAPI/internal/models"
+)
+
+// ------------------------------------------------------
+//
+//	JSON api to drive the mock server from tests
+//
+// ------------------------------------------------------
+func (app *application) AdminAPIHandlers(router *chi.Mux) {
+	router.Route("/mockadmin", func(r chi.Router) {
+		r.Use(app.RequireAdminToken)
+
+		r.Get("/clock", app.adminClockGet)
+		r.Post("/clock", app.adminClockSet)
+		r.Delete("/clock", app.adminClockReset)
+
+		r.Get("/scenarios", app.adminScenarioList)
+		r.Post("/scenarios/reset", app.adminScenarioResetAll)
+		r.Get("/scenarios/{id}", app.adminScenarioGet)
+		r.Put("/scenarios/{id}/state", app.adminScenarioSetState)
+		r.Post("/scenarios/{id}/reset", app.adminScenarioReset)
+
+		r.Get("/resources/{endpointid}", app.adminResourceList)
+		r.Post("/resources/{endpointid}/reset", app.adminResourceReset)
+
+		r.Post("/sequences/reset", app.adminSequenceReset)
+
+		r.Get("/store", app.adminStoreList)
+		r.Delete("/store", app.adminStoreClear)
+		r.Put("/store/{key}", app.adminStoreSet)
+		r.Delete("/store/{key}", app.adminStoreDelete)
+
+		r.Get("/webhooks", app.adminWebhookList)
+		r.Delete("/webhooks", app.adminWebhookClear)
+
+		r.Get("/ws/{id}/clients", app.adminWsClients)
+		r.Post("/ws/{id}/push", app.adminWsPush)
+		r.Post("/ws/{id}/close", app.adminWsClose)
+	})
+
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+type clockRequest struct {
+	Now     string `json:"now"`     // RFC3339
+	Advance string `json:"advance"` // 1h30m
+	Frozen  bool   `json:"frozen"`
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (app *application) readJSON(r *http.Request, dst any) error {
+	decoder := json.NewDecoder(r.Body)
+	err := decoder.Decode(dst)
+	if err == io.EOF {
+		return nil
+	}
+	return err
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (app *application) adminClockGet(w http.ResponseWriter, r *http.Request) {
+	app.writeJSON(w, http.StatusOK, models.Clock.Status(), nil)
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (app *application) adminClockSet(w http.ResponseWriter, r *http.Request) {
+	var request clockRequest
+
+	err := app.readJSON(r, &request)
+	if err != nil {
+		app.errorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if strings.TrimSpace(request.Now) == "" && strings.TrimSpace(request.Advance) == "" {
+		app.errorResponse(w, r, http.StatusBadRequest, "now or advance is required")
+		return
+	}
+
+	if strings.TrimSpace(request.Now) != "" {
+		now, err := time.Parse(time.RFC3339, strings.TrimSpace(request.Now))
+		if err != nil {
+			app.errorResponse(w, r, http.StatusBadRequest, "now must be in RFC3339 format: 2006-01-02T15:04:05Z")
+			return
+		}
+		models.Clock.Set(now, request.Frozen)
+	}
+
+	if strings.TrimSpace(request.Advance) != "" {
+		d, err := time.ParseDuration(strings.TrimSpace(request.Advance))
+		if err != nil {
+			app.errorResponse(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+		models.Clock.Advance(d)
+	}
+
+	app.writeJSON(w, http.StatusOK, models.Clock.Status(), nil)
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (app *application) adminClockReset(w http.ResponseWriter, r *http.Request) {
+	models.Clock.Reset()
+	app.writeJSON(w, http.StatusOK, models.Clock.Status(), nil)
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (app *application) adminScenarioList(w http.ResponseWriter, r *http.Request) {
+	collectionid := r.URL.Query().Get("cid")
+	if collectionid != "" {
+		app.writeJSON(w, http.StatusOK, app.scenarios.ListByCollectionID(collectionid), nil)
+		return
+	}
+	app.writeJSON(w, http.StatusOK, app.scenarios.List(), nil)
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (app *application) adminScenarioGet(w http.ResponseWriter, r *http.Request) {
+	scenario, err := app.scenarios.Get(chi.URLParam(r, "id"))
+	if err != nil {
+		app.errorResponse(w, r, http.StatusNotFound, err.Error())
+		return
+	}
+	app.writeJSON(w, http.StatusOK, scenario, nil)
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (app *application) adminScenarioSetState(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		State string `json:"state"`
+	}
+
+	err := app.readJSON(r, &request)
+	if err != nil {
+		app.errorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if strings.TrimSpace(request.State) == "" {
+		app.errorResponse(w, r, http.StatusBadRequest, "state is required")
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	err = app.scenarios.SetState(id, request.State)
+	if err != nil {
+		app.errorResponse(w, r, http.StatusNotFound, err.Error())
+		return
+	}
+
+	app.adminScenarioGet(w, r)
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (app *application) adminScenarioReset(w http.ResponseWriter, r *http.Request) {
+	err := app.scenarios.Reset(chi.URLParam(r, "id"))
+	if err != nil {
+		app.errorResponse(w, r, http.StatusNotFound, err.Error())
+		return
+	}
+
+	app.adminScenarioGet(w, r)
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (app *application) adminScenarioResetAll(w http.ResponseWriter, r *http.Request) {
+	collectionid := r.URL.Query().Get("cid")
+	app.scenarios.ResetAll(collectionid)
+	app.adminScenarioList(w, r)
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (app *application) adminResourceEndPoint(w http.ResponseWriter, r *http.Request) (*models.EndPoint, bool) {
+	endpoint, err := app.endpoints.Get(chi.URLParam(r, "endpointid"))
+	if err != nil {
+		app.errorResponse(w, r, http.StatusNotFound, err.Error())
+		return nil, false
+	}
+
+	if !endpoint.ResourceMode {
+		app.errorResponse(w, r, http.StatusBadRequest, "not a resource endpoint")
+		return nil, false
+	}
+
+	return endpoint, true
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (app *application) adminResourceList(w http.ResponseWriter, r *http.Request) {
+	endpoint, ok := app.adminResourceEndPoint(w, r)
+	if !ok {
+		return
+	}
+
+	items, err := app.resources.List(endpoint)
+	if err != nil {
+		app.errorResponse(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	app.writeJSON(w, http.StatusOK, items, nil)
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (app *application) adminResourceReset(w http.ResponseWriter, r *http.Request) {
+	endpoint, ok := app.adminResourceEndPoint(w, r)
+	if !ok {
+		return
+	}
+
+	err := app.resources.Reset(endpoint)
+	if err != nil {
+		app.errorResponse(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	app.adminResourceList(w, r)
+}
+
+// ------------------------------------------------------
+// ?cid=<collection id>, blank is the default V1 collection
+// ------------------------------------------------------
+func (app *application) adminStoreList(w http.ResponseWriter, r *http.Request) {
+	app.writeJSON(w, http.StatusOK, app.store.List(r.URL.Query().Get("cid")), nil)
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (app *application) adminStoreClear(w http.ResponseWriter, r *http.Request) {
+	err := app.store.ClearCollectionData(r.URL.Query().Get("cid"))
+	if err != nil {
+		app.errorResponse(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+	app.adminStoreList(w, r)
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (app *application) adminStoreSet(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Value any    `json:"value"`
+		TTL   string `json:"ttl"` // 30m
+	}
+
+	err := app.readJSON(r, &request)
+	if err != nil {
+		app.errorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ttl := time.Duration(0)
+	if strings.TrimSpace(request.TTL) != "" {
+		ttl, err = time.ParseDuration(strings.TrimSpace(request.TTL))
+		if err != nil {
+			app.errorResponse(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	err = app.store.Set(r.URL.Query().Get("cid"), chi.URLParam(r, "key"), request.Value, ttl)
+	if err != nil {
+		app.errorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	app.adminStoreList(w, r)
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (app *application) adminStoreDelete(w http.ResponseWriter, r *http.Request) {
+	err := app.store.Delete(r.URL.Query().Get("cid"), chi.URLParam(r, "key"))
+	if err != nil {
+		app.errorResponse(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+	app.adminStoreList(w, r)
+}
+
+// ------------------------------------------------------
+// ?endpointid=<id>, blank resets every endpoint
+// ------------------------------------------------------
+func (app *application) adminSequenceReset(w http.ResponseWriter, r *http.Request) {
+	models.ResetResponseSequence(r.URL.Query().Get("endpointid"))
+	app.writeJSON(w, http.StatusOK, map[string]string{"status": "reset"}, nil)
+}
+
+// ------------------------------------------------------
+// ?callid=<correlation id>&endpointid=<id>, both optional
+// ------------------------------------------------------
+func (app *application) adminWebhookList(w http.ResponseWriter, r *http.Request) {
+	app.writeJSON(w, http.StatusOK, app.webhookLogs.List(r.URL.Query().Get("callid"), r.URL.Query().Get("endpointid")), nil)
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (app *application) adminWebhookClear(w http.ResponseWriter, r *http.Request) {
+	err := app.webhookLogs.Clear()
+	if err != nil {
+		app.errorResponse(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+	app.adminWebhookList(w, r)
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (app *application) adminWsEndPoint(w http.ResponseWriter, r *http.Request) (*models.WsEndPoint, bool) {
+	wsEndPoint, err := app.wsEndPoints.Get(chi.URLParam(r, "id"))
+	if err != nil {
+		app.errorResponse(w, r, http.StatusNotFound, err.Error())
+		return nil, false
+	}
+	return wsEndPoint, true
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (app *application) adminWsClients(w http.ResponseWriter, r *http.Request) {
+	wsEndPoint, ok := app.adminWsEndPoint(w, r)
+	if !ok {
+		return
+	}
+	app.writeJSON(w, http.StatusOK, iwebsocket.MockClients.List(wsEndPoint.ID), nil)
+}
+
+// ------------------------------------------------------
+// a string message is sent as is, any other JSON value as JSON
+// ------------------------------------------------------
+func (app *application) adminWsPush(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Message json.RawMessage `json:"message"`
+	}
+
+	err := app.readJSON(r, &request)
+	if err != nil {
+		app.errorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if len(request.Message) == 0 {
+		app.errorResponse(w, r, http.StatusBadRequest, "message is required")
+		return
+	}
+
+	message := string(request.Message)
+	var text string
+	if json.Unmarshal(request.Message, &text) == nil {
+		message = text
+	}
+
+	wsEndPoint, ok := app.adminWsEndPoint(w, r)
+	if !ok {
+		return
+	}
+
+	sent := iwebsocket.MockClients.Push(wsEndPoint.ID, message)
+	app.writeJSON(w, http.StatusOK, map[string]int{"sent": sent}, nil)
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (app *application) adminWsClose(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Code   int    `json:"code"`
+		Reason string `json:"reason"`
+	}
+
+	err := app.readJSON(r, &request)
+	if err != nil {
+		app.errorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if request.Code == 0 {
+		request.Code = websocket.CloseNormalClosure
+	}
+
+	if !models.IsValidCloseCode(request.Code) {
+		app.errorResponse(w, r, http.StatusBadRequest, "invalid close code")
+		return
+	}
+
+	wsEndPoint, ok := app.adminWsEndPoint(w, r)
+	if !ok {
+		return
+	}
+
+	closed := iwebsocket.MockClients.CloseAll(wsEndPoint.ID, request.Code, request.Reason)
+	app.writeJSON(w, http.StatusOK, map[string]int{"closed": closed}, nil)
+}
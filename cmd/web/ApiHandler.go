@@ -1,6 +1,7 @@
 package main
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -31,6 +32,7 @@ func (app *application) APIHandlers(router *chi.Mux) {
 		r.Patch("/", app.POST)
 
 		r.Delete("/", app.GET)
+		r.Head("/", app.GET)
 
 		r.Get("/*", app.GET)
 		r.Post("/*", app.POST)
@@ -38,6 +40,7 @@ func (app *application) APIHandlers(router *chi.Mux) {
 		r.Patch("/*", app.POST)
 
 		r.Delete("/*", app.GET)
+		r.Head("/*", app.GET)
 	})
 
 	router.Route("/apilogs", func(r chi.Router) {
@@ -88,6 +91,8 @@ func (app *application) apilogs(w http.ResponseWriter, r *http.Request) {
 func (app *application) InjectClientInfo(r *http.Request, requesyBodyFlatMap map[string]xmlutils.ValueDatatype) {
 	requesyBodyFlatMap["*CLIENT_IP"] = xmlutils.ValueDatatype{r.RemoteAddr, "STRING"}
 
+	models.InjectRequestVars(r, requesyBodyFlatMap)
+
 }
 
 // ------------------------------------------------------
@@ -159,11 +164,25 @@ func (app *application) POST(w http.ResponseWriter, r *http.Request) {
 
 	collection, endpointName, pathParams := app.GetPathParameters(r)
 
+	// keep the body so it can be read again later (resources, actual url)
+	rawBody, err := io.ReadAll(r.Body)
+	if err != nil {
+		app.errorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	r.Body = io.NopCloser(bytes.NewReader(rawBody))
+
 	endPoint, err := app.GetEndPoint(collection, endpointName, strings.ToLower(r.Method))
 
 	if err != nil {
-		app.errorResponse(w, r, 404, err.Error())
-		return
+		// SOAP operations are called on the url of their service
+		soapEndPoint, soapErr := app.GetSoapEndPoint(collection, endpointName, models.SoapAction(r), rawBody)
+		if soapErr != nil {
+			app.errorResponse(w, r, 404, err.Error())
+			return
+		}
+		endPoint = soapEndPoint
+		endpointName = soapEndPoint.Name
 	}
 
 	requestBodyMap := make(map[string]any)
@@ -174,7 +193,7 @@ func (app *application) POST(w http.ResponseWriter, r *http.Request) {
 	queryParams, _ := httputils.QueryParamToMap(fmt.Sprint(r.URL))
 
 	switch endPoint.SampleRequestType {
-	case "JSON":
+	case "JSON", models.GrpcType:
 		decoder := json.NewDecoder(r.Body)
 		err := decoder.Decode(&requestBodyMap)
 		switch {
@@ -200,6 +219,33 @@ func (app *application) POST(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+	case models.SoapType:
+		envelope, err := models.ParseSoapEnvelope(string(rawBody))
+		if err != nil {
+			app.errorResponse(w, r, http.StatusBadRequest, fmt.Sprintf("Invalid SOAP body: %s", err.Error()))
+			return
+		}
+		requestBodyFlatMap, _, err = xmlutils.XmlToLocalFlatMapAndPlaceholder(string(rawBody))
+		if err != nil {
+			app.errorResponse(w, r, http.StatusBadRequest, fmt.Sprintf("Invalid SOAP body: %s", err.Error()))
+			return
+		}
+		requestBodyFlatMap[models.SoapOperationKey] = xmlutils.ValueDatatype{envelope.Operation, "STRING"}
+
+	case models.GraphqlType:
+		request, err := models.ParseGraphqlRequest(rawBody)
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write(models.GraphqlErrorResponse(err))
+			return
+		}
+		_ = json.Unmarshal(rawBody, &requestBodyMap)
+		requestBodyFlatMap = jsonutils.JsonToFlatMapFromMap(requestBodyMap)
+		for key, value := range models.GraphqlRequestValues(request) {
+			requestBodyFlatMap[key] = xmlutils.ValueDatatype{value, "STRING"}
+		}
+
 	}
 
 	// add path parms
@@ -218,6 +264,8 @@ func (app *application) POST(w http.ResponseWriter, r *http.Request) {
 		requestBodyFlatMap[k] = xmlutils.ValueDatatype{v, "STRING"}
 	}
 
+	r.Body = io.NopCloser(bytes.NewReader(rawBody))
+
 	app.ProcessAPICall(w, r, collection, endpointName, pathParams, requestBodyFlatMap)
 
 }
@@ -267,12 +315,20 @@ func (app *application) ProcessAPICall(w http.ResponseWriter, r *http.Request, c
 		ResponseMessage:          "",
 		Log:                      make([]string, 0),
 		DB:                       app.LogDB,
+		DataDB:                   app.DB,
 		HttpRequest:              r,
 		PathParams:               pathParams,
 		CurrentEndPoint:          endPoint,
 		AdditionalResponseValues: make(map[string]any),
 	}
 
+	recording, recordConditions := app.isRecording(endPoint)
+	if recording {
+		apiCall.LogInfo("Recording: calling ActualEndPoint")
+		apiCall.UseActualURL = true
+		apiCall.Recording = true
+	}
+
 	//apiCall.ResponseString = html.UnescapeString(endPoint.ResponsePlaceholder) //string(jsonByte)
 	apiCall.CopyResponseString(endPoint)
 
@@ -284,7 +340,33 @@ func (app *application) ProcessAPICall(w http.ResponseWriter, r *http.Request, c
 	//app.writeJSON(w, apiCall.ResponseCode, apiCall.Response, apiCall.GetHttpHeader())
 	//app.writeJSON(w, apiCall.ResponseCode, apiCall.Response, apiCall.GetHttpHeader())
 
-	app.writeJSONorXML(apiCall.FinalResponseType, w, apiCall.StatusCode, apiCall.FinalResponseString, apiCall.GetHttpHeader())
+	fault := apiCall.GetFault()
+
+	// slow network: chunked and throttled body
+	writer := w
+	streamSettings := endPoint.GetResponseByID(apiCall.ResponseID).StreamSettings
+	if !apiCall.UsingAcutalUrlResponse && streamSettings.IsStreamed() {
+		total := 0
+		if !models.IsBinaryResponseType(apiCall.FinalResponseType) {
+			total = len(apiCall.FinalResponseString) + 1
+		}
+		apiCall.LogInfo(fmt.Sprintf("Streaming response %+v", streamSettings))
+		writer = newThrottledWriter(w, r, streamSettings, total)
+	}
+
+	if apiCall.UsingAcutalUrlResponse {
+		app.writeActualResponse(w, apiCall)
+	} else if fault != models.FaultNone && app.writeFault(w, r, apiCall, fault) {
+		// fault sent instead of the response
+	} else if models.IsBinaryResponseType(apiCall.FinalResponseType) {
+		app.writeBinaryResponse(writer, r, apiCall)
+	} else if strings.EqualFold(apiCall.FinalResponseType, models.ResponseTypeSSE) {
+		app.writeEventStream(writer, r, apiCall)
+	} else {
+		app.writeJSONorXML(apiCall.GetContentType(), writer, apiCall.StatusCode, apiCall.FinalResponseString, apiCall.GetHttpHeader())
+	}
+
+	app.scheduleWebhooks(apiCall)
 
 	go func() {
 
@@ -306,6 +388,14 @@ func (app *application) ProcessAPICall(w http.ResponseWriter, r *http.Request, c
 			//app.endpoints.UpdateRequestParamFromApiCall(nil, endPoint, requestJson)
 			app.invalidateEndPointCache()
 		}
+
+		app.endPointSaveMutex.Lock()
+		defer app.endPointSaveMutex.Unlock()
+
+		if recording && apiCall.UsingAcutalUrlResponse {
+			app.recordResponse(endPoint, apiCall, recordConditions)
+		}
+
 		orignalEndPoint, err := app.endpoints.Get(endPoint.ID)
 
 		if err != nil {
@@ -323,12 +413,6 @@ func (app *application) ProcessAPICall(w http.ResponseWriter, r *http.Request, c
 			},
 		)
 
-		// if apiCall.UsingAcutalUrlResponse && endPoint.AutoUpdateResponse {
-		// 	// fetch again to avoid any previous updates
-		// 	orignalEndPoint.SampleResponse = apiCall.ActualCallResult.Body
-		// 	app.invalidateEndPointCache()
-		// }
-
 		app.endpoints.Save(orignalEndPoint, "")
 
 	}()
@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/onlysumitg/GoMockAPI/internal/models"
@@ -64,6 +65,9 @@ func (app *application) collectionsAdd(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if r.Method == http.MethodPost {
+		// unchecked boxes are not posted
+		collection.Record = false
+		collection.RecordConditions = false
 
 		err := app.decodePostForm(r, &collection)
 		if err != nil {
@@ -72,6 +76,7 @@ func (app *application) collectionsAdd(w http.ResponseWriter, r *http.Request) {
 		}
 
 		collection.Name = stringutils.RemoveSpecialChars(stringutils.RemoveMultipleSpaces(collection.Name))
+		collection.RandomSeed = strings.TrimSpace(collection.RandomSeed)
 
 		collection.CheckField(validator.NotBlank(collection.Name), "name", "This field cannot be blank")
 		collection.CheckField(validator.CanNotBe(collection.Name, "V1"), "name", "Can not use reserved name V1.")
@@ -147,6 +152,10 @@ func (app *application) collectionsDeleteConfirm(w http.ResponseWriter, r *http.
 		for _, ep := range endpoints {
 			app.endpoints.Delete(ep.ID)
 		}
+		app.scenarios.ClearCollectionData(id)
+		app.wsEndPoints.ClearCollectionData(id)
+		app.resources.ClearCollectionData(id)
+		app.store.ClearCollectionData(id)
 	}
 	app.invalidateEndPointCache()
 	app.sessionManager.Put(r.Context(), "flash", "Deleted sucessfully")
@@ -51,6 +51,7 @@ func (app *application) EndPointHandlers(router *chi.Mux) {
 		g1.Post("/addowners/{endpointid}", app.ownerList)
 
 		g1.Get("/copy/{endpointid}", app.makeCopy)
+		g1.Post("/resetresource/{endpointid}", app.EndPointResetResource)
 
 		// g1.Get("/addowners/{endpointid}", app.ownerAdd)
 		// g1.Post("/addowners/{endpointid}", app.ownerAdd)
@@ -139,6 +140,29 @@ func (app *application) EndPointView(w http.ResponseWriter, r *http.Request) {
 
 }
 
+// ------------------------------------------------------
+// resource endpoint: drop stored records, next call seeds from sample
+// ------------------------------------------------------
+func (app *application) EndPointResetResource(w http.ResponseWriter, r *http.Request) {
+	endpointID := chi.URLParam(r, "endpointid")
+
+	endpoint, err := app.endpoints.Get(endpointID)
+	if err != nil {
+		app.notFound(w, err)
+		return
+	}
+
+	err = app.resources.Reset(endpoint)
+	if err != nil {
+		app.sessionManager.Put(r.Context(), "error", fmt.Sprintf("reset failed:: %s", err.Error()))
+		app.goBack(w, r, http.StatusSeeOther)
+		return
+	}
+
+	app.sessionManager.Put(r.Context(), "flash", "Resource data reset")
+	app.goBack(w, r, http.StatusSeeOther)
+}
+
 // ------------------------------------------------------
 // EndPoint details
 // ------------------------------------------------------
@@ -377,6 +401,7 @@ func (app *application) EndPointAddPost(w http.ResponseWriter, r *http.Request)
 	}
 
 	app.invalidateEndPointCache()
+	models.ResetResponseSequence(id)
 
 	app.sessionManager.Put(r.Context(), "flash", fmt.Sprintf("EndPoint %s saved sucessfully", endpoint.Name))
 
@@ -6,10 +6,14 @@ import (
 	"strings"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/onlysumitg/GoMockAPI/internal/models"
 	"github.com/onlysumitg/GoMockAPI/internal/validator"
 	"github.com/onlysumitg/GoMockAPI/utils/httputils"
 )
 
+// uploads bigger than this go to temp files while parsing
+const maxResponseFileMemory = 32 << 20
+
 // ------------------------------------------------------
 //
 // ------------------------------------------------------
@@ -105,6 +109,7 @@ func (app *application) ResponseDeleteConfirm(w http.ResponseWriter, r *http.Req
 	}
 
 	objectid := r.PostForm.Get("objectid")
+	models.DeleteResponseFile(endpoint.GetResponseByID(objectid))
 	endpoint.RemoveResponse(objectid)
 	_, err = app.endpoints.Save(endpoint, "")
 	if err != nil {
@@ -161,6 +166,19 @@ func (app *application) ResponseUpdate(w http.ResponseWriter, r *http.Request) {
 	response := endpoint.GetResponseByID(paramid)
 
 	if r.Method == http.MethodPost {
+		response.UseTemplate = false // unchecked boxes are not posted
+		response.EventLoop = false
+		response.Cookies = nil // all cookie rows are posted
+		response.Webhooks = nil
+
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+			err := r.ParseMultipartForm(maxResponseFileMemory)
+			if err != nil {
+				app.clientError(w, http.StatusBadRequest, err)
+				return
+			}
+		}
+
 		err := app.decodePostForm(r, &response)
 		if err != nil {
 			app.clientError(w, http.StatusBadRequest, err)
@@ -179,28 +197,133 @@ func (app *application) ResponseUpdate(w http.ResponseWriter, r *http.Request) {
 
 		response.CheckField(!endpoint.IsDuplicateResponseWithNameDefault(response.HttpCode, response), "name", "Name 'DEFAULT' can be used only once.")
 
-		// valid json/xml : response
+		response.CheckField(response.SequenceOrder >= 0, "sequenceorder", "Can not be negative")
+		response.CheckField(response.Weight >= 0, "weight", "Can not be negative")
+		response.CheckField(response.StreamSettings.IsValid(), "streamsettings", "Can not be negative")
+
 		response.CheckField(validator.MustBeFromList(response.ResponseHeaderType, "JSON", "XML"), "headertype", "Valid values are JSON or XML")
-		if response.ResponseHeaderType == "JSON" {
-			response.CheckField(validator.MustBeJSON(response.ResponseHeader), "header", "Must be a valid JSON")
+		response.CheckField(validator.MustBeFromList(response.ResponseType, models.ResponseTypeList...), "responsetype", "Please select a valid value")
+		response.CheckField(validator.MustBeFromList(strings.ToLower(response.ContentDisposition), "", "inline", "attachment"), "contentdisposition", "Valid values are inline or attachment")
+
+		response.Webhooks = response.Webhooks.Clean()
+		err = response.Webhooks.Validate()
+		if err != nil {
+			response.CheckField(false, "webhooks", err.Error())
 		}
 
-		if response.ResponseHeaderType == "XML" {
-			response.CheckField(validator.MustBeXML(response.ResponseHeader), "header", "Must be a valid XML")
+		response.CleanCookies()
+		cookieNames := make(map[string]bool)
+		for _, c := range response.Cookies {
+			err := c.Validate()
+			if err != nil {
+				response.CheckField(false, "cookies", err.Error())
+			}
+			response.CheckField(!cookieNames[c.Name], "cookies", fmt.Sprintf("Cookie %s is used more than once", c.Name))
+			cookieNames[c.Name] = true
 		}
-		// valid json/xml : header
-		response.CheckField(validator.MustBeFromList(response.ResponseType, "JSON", "XML"), "responsetype", "Valid values are JSON or XML")
-		if response.ResponseType == "JSON" {
-			response.CheckField(validator.MustBeJSON(response.Response), "response", "Must be a valid JSON")
+
+		response.Fault = strings.ToUpper(strings.TrimSpace(response.Fault))
+		response.CheckField(models.IsValidFault(response.Fault), "fault", "Please select a valid value")
+
+		response.ContentType = strings.TrimSpace(response.ContentType)
+		err = models.ValidateContentType(response.ContentType)
+		if err != nil {
+			response.CheckField(false, "contenttype", fmt.Sprintf("Must be a valid content type: %s", err.Error()))
+		}
+
+		uploadedFile, uploadedFileHeader, fileErr := r.FormFile("responsefile")
+		if fileErr == nil {
+			defer uploadedFile.Close()
+		}
+
+		switch {
+		case response.ResponseType == models.ResponseTypeFile:
+			response.CheckField(fileErr == nil || response.StoredFile != "", "responsefile", "Please upload a file")
+			response.CheckField(fileErr == nil || fileErr == http.ErrMissingFile, "responsefile", "Error reading the file")
+
+		case response.ResponseType == models.ResponseTypeBase64:
+			err := models.ValidateBase64Response(response.Response)
+			if err != nil {
+				response.CheckField(false, "response", fmt.Sprintf("Must be valid base64: %s", err.Error()))
+			}
+
+		case response.UseTemplate:
+			// templates are checked for syntax, the output is only known at call time
+			_, err := models.ParseResponseTemplate("header", response.ResponseHeader)
+			if err != nil {
+				response.CheckField(false, "header", fmt.Sprintf("Template error: %s", err.Error()))
+			}
+			_, err = models.ParseResponseTemplate("response", response.Response)
+			if err != nil {
+				response.CheckField(false, "response", fmt.Sprintf("Template error: %s", err.Error()))
+			}
+		default:
+			// valid json/xml : header
+			if response.ResponseHeaderType == "JSON" {
+				response.CheckField(validator.MustBeJSON(response.ResponseHeader), "header", "Must be a valid JSON")
+			}
+
+			if response.ResponseHeaderType == "XML" {
+				response.CheckField(validator.MustBeXML(response.ResponseHeader), "header", "Must be a valid XML")
+			}
+
+			// valid json/xml : response
+			if response.ResponseType == "JSON" {
+				response.CheckField(validator.MustBeJSON(response.Response), "response", "Must be a valid JSON")
+			}
+
+			if response.ResponseType == "XML" {
+				response.CheckField(validator.MustBeXML(response.Response), "response", "Must be a valid XML")
+			}
+
+			if response.ResponseType == "JSON" && endpoint.IsGrpc() {
+				err := endpoint.ValidateGrpcResponse(response.Response)
+				if err != nil {
+					response.CheckField(false, "response", fmt.Sprintf("Must be the JSON of the response message: %s", err.Error()))
+				}
+			}
+
+			if response.ResponseType == models.GraphqlType {
+				response.CheckField(validator.MustBeJSON(response.Response), "response", "Must be a valid JSON with the mock values by type and field")
+			}
+
+			if response.ResponseType == models.SoapType {
+				response.CheckField(validator.MustBeXML(response.Response), "response", "Must be a valid XML, an envelope or the body of one")
+			}
+
+			if response.ResponseType == models.ResponseTypeSSE {
+				_, err := models.ParseEventScript(response.Response)
+				if err != nil {
+					response.CheckField(false, "response", fmt.Sprintf("Invalid event script: %s", err.Error()))
+				}
+			}
+
+			if response.ResponseType == models.ResponseTypeForm {
+				err := models.ValidateFormResponse(response.Response)
+				if err != nil {
+					response.CheckField(false, "response", fmt.Sprintf("Must be form encoded like a=1&b=2: %s", err.Error()))
+				}
+			}
+		}
+
+		if response.Valid() && response.ResponseType == models.ResponseTypeFile && fileErr == nil {
+			if response.ID == "" {
+				endpoint.SetResponse(response) // gives the response its id
+			}
+			err := models.SaveResponseFile(endpoint.ID, response, uploadedFileHeader.Filename, uploadedFile)
+			if err != nil {
+				response.CheckField(false, "responsefile", fmt.Sprintf("Error saving the file: %s", err.Error()))
+			}
 		}
 
-		if response.ResponseType == "XML" {
-			response.CheckField(validator.MustBeXML(response.Response), "response", "Must be a valid XML")
+		if response.Valid() && response.ResponseType != models.ResponseTypeFile {
+			models.DeleteResponseFile(response)
 		}
 
 		if response.Valid() {
 			endpoint.SetResponse(response)
 			app.endpoints.Save(endpoint, "")
+			models.ResetResponseSequence(endpoint.ID)
 			http.Redirect(w, r, fmt.Sprintf("/epr/%s", endpointID), http.StatusSeeOther)
 			return
 
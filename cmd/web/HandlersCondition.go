@@ -213,7 +213,7 @@ func (app *application) ConditionUpdatePost(w http.ResponseWriter, r *http.Reque
 
 	condition.CheckField(validator.NotBlank(condition.Compareto), "compareto", "This field cannot be blank")
 
-	condition.CheckField(validator.MustBeOfType(condition.Compareto, requestParam.DefaultDatatype), "compareto", fmt.Sprintf("Make sure value is compatible with %s", requestParam.DefaultDatatype))
+	condition.CheckField(models.IsStoreReference(condition.Compareto) || validator.MustBeOfType(condition.Compareto, requestParam.DefaultDatatype), "compareto", fmt.Sprintf("Make sure value is compatible with %s", requestParam.DefaultDatatype))
 
 	condition.Name = fmt.Sprintf("%s %s %s", condition.VariableName, condition.Operator, condition.Compareto)
 	//condition.CheckField(validator.NotBlank(condition.Name), "name", "This field cannot be blank")
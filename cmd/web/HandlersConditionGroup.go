@@ -163,12 +163,20 @@ func (app *application) ConditionGroupUpdate(w http.ResponseWriter, r *http.Requ
 	paramKeys := make([]string, 0)
 	requestParams := app.requestParams.ListById(endpoint.ID)
 
+	requestKeys := make([]string, 0)
 	for _, requestParam := range requestParams {
 		paramKeys = append(paramKeys, fmt.Sprintf("REQUEST[%s]: %s", requestParam.DefaultDatatype, requestParam.Key))
+		requestKeys = append(requestKeys, requestParam.Key)
 	}
+	paramKeys = append(paramKeys, models.RequestSubtreeKeys(requestKeys)...)
 
 	randomFuncKeys := models.GetRandonFunctiolist()
 	paramKeys = append(paramKeys, randomFuncKeys...)
+	paramKeys = append(paramKeys, models.TimeValueExamples...)
+
+	for _, entry := range app.store.List(endpoint.CollectionID) {
+		paramKeys = append(paramKeys, fmt.Sprintf("%s:%s", models.StoreValuePrefix, entry.Key))
+	}
 
 	data := app.newTemplateData(r)
 
@@ -195,6 +203,7 @@ func (app *application) ConditionGroupUpdate(w http.ResponseWriter, r *http.Requ
 	}
 
 	data.EndPoint = endpoint
+	data.Scenarios = app.scenarios.ListAvailable(endpoint.CollectionID)
 
 	app.render(w, r, http.StatusOK, "condition_group_add.tmpl", data)
 
@@ -231,6 +240,19 @@ func (app *application) ConditionGroupUpdatePost(w http.ResponseWriter, r *http.
 
 	conditionGroup.CheckField(validator.NotBlank(conditionGroup.Name), "name", "This field cannot be blank")
 	conditionGroup.CheckField(!app.conditionGroup.DuplicateName(&conditionGroup, *endpoint), "name", "Duplicate Name")
+	conditionGroup.ValidateTimeWindow()
+	conditionGroup.ValidateStoreWrites()
+	conditionGroup.ValidateWebhooks()
+
+	conditionGroup.RequiredState = models.NormalizeScenarioState(conditionGroup.RequiredState)
+	conditionGroup.NewState = models.NormalizeScenarioState(conditionGroup.NewState)
+	if conditionGroup.ScenarioID != "" {
+		scenario, err := app.scenarios.Get(conditionGroup.ScenarioID)
+		conditionGroup.CheckField(err == nil && (scenario.CollectionID == "" || strings.EqualFold(scenario.CollectionID, endpoint.CollectionID)), "scenarioid", "Please select a valid scenario")
+	} else {
+		conditionGroup.CheckField(conditionGroup.RequiredState == "", "requiredstate", "Please select a scenario")
+		conditionGroup.CheckField(conditionGroup.NewState == "", "newstate", "Please select a scenario")
+	}
 
 	invalidMappedParams := false
 	for _, mappedParam := range conditionGroup.ConditionGroupParameters {
@@ -240,7 +262,7 @@ func (app *application) ConditionGroupUpdatePost(w http.ResponseWriter, r *http.
 		}
 
 		if strings.TrimSpace(mappedParam.AssgineValue) != "" {
-			mappedParam.CheckField(validator.MustBeOfType(mappedParam.AssgineValue, mappedParam.ResponseVariableDatatype), "assignvalue", fmt.Sprintf("Make sure value is compatible with %s", mappedParam.ResponseVariableDatatype))
+			mappedParam.CheckField(models.IsStoreReference(mappedParam.AssgineValue) || validator.MustBeOfType(mappedParam.AssgineValue, mappedParam.ResponseVariableDatatype), "assignvalue", fmt.Sprintf("Make sure value is compatible with %s", mappedParam.ResponseVariableDatatype))
 			if !mappedParam.Valid() {
 				invalidMappedParams = true
 			}
@@ -255,6 +277,7 @@ func (app *application) ConditionGroupUpdatePost(w http.ResponseWriter, r *http.
 		data.Form = conditionGroup
 		data.EndPoint = endpoint
 		data.Conditions = app.condition.ListById(endpoint.ID)
+		data.Scenarios = app.scenarios.ListAvailable(endpoint.CollectionID)
 
 		app.sessionManager.Put(r.Context(), "error", "Please fix error(s) and resubmit")
 
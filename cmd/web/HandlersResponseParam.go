@@ -81,12 +81,20 @@ func (app *application) ResponseParamUpdate(w http.ResponseWriter, r *http.Reque
 	requestParams := app.requestParams.ListById(endpoint.ID)
 	paramKeys := make([]string, 0)
 
+	requestKeys := make([]string, 0)
 	for _, requestParam := range requestParams {
 		paramKeys = append(paramKeys, fmt.Sprintf("REQUEST[%s]: %s", requestParam.DefaultDatatype, requestParam.Key))
+		requestKeys = append(requestKeys, requestParam.Key)
 	}
+	paramKeys = append(paramKeys, models.RequestSubtreeKeys(requestKeys)...)
 
 	randomFuncKeys := models.GetRandonFunctiolist()
 	paramKeys = append(paramKeys, randomFuncKeys...)
+	paramKeys = append(paramKeys, models.TimeValueExamples...)
+
+	for _, entry := range app.store.List(endpoint.CollectionID) {
+		paramKeys = append(paramKeys, fmt.Sprintf("%s:%s", models.StoreValuePrefix, entry.Key))
+	}
 
 	paramid := chi.URLParam(r, "paramid")
 	data := app.newTemplateData(r)
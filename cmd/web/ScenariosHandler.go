@@ -0,0 +1,190 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/onlysumitg/GoMockAPI/internal/models"
+	"github.com/onlysumitg/GoMockAPI/internal/validator"
+)
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (app *application) ScenarioHandlers(router *chi.Mux) {
+	router.Route("/scenarios", func(r chi.Router) {
+		r.Use(app.RequireAuthentication)
+
+		// CSRF
+		r.Use(noSurf)
+		r.Get("/", app.scenarioList)
+		r.Get("/add", app.scenarioAdd)
+		r.Post("/add", app.scenarioAdd)
+
+		r.Get("/edit/{id}", app.scenarioAdd)
+		r.Post("/edit/{id}", app.scenarioAdd)
+
+		r.Post("/reset", app.scenarioReset)
+
+		r.Get("/delete/{id}", app.scenarioDelete)
+		r.Post("/delete", app.scenarioDeleteConfirm)
+
+	})
+
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (app *application) scenarioList(w http.ResponseWriter, r *http.Request) {
+
+	data := app.newTemplateData(r)
+	data.Collections = app.collectionsModel.List()
+
+	collectionid := r.URL.Query().Get("cid")
+	if collectionid != "" {
+		collection, err := app.collectionsModel.Get(collectionid)
+		if err == nil {
+			data.Collection = collection
+			data.Scenarios = app.scenarios.ListByCollectionID(collectionid)
+		}
+	}
+
+	if data.Collection == nil {
+		data.Scenarios = app.scenarios.List()
+	}
+
+	app.render(w, r, http.StatusOK, "scenario_list.tmpl", data)
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (app *application) scenarioAdd(w http.ResponseWriter, r *http.Request) {
+
+	scenario := &models.Scenario{}
+
+	id := chi.URLParam(r, "id")
+	if id != "" {
+		s, err := app.scenarios.Get(id)
+		if err == nil {
+			scenario = s
+		}
+	}
+
+	if r.Method == http.MethodPost {
+
+		err := app.decodePostForm(r, scenario)
+		if err != nil {
+			app.clientError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		scenario.CheckField(validator.NotBlank(scenario.Name), "name", "This field cannot be blank")
+		scenario.CheckField(!app.scenarios.DuplicateName(scenario), "name", "Duplicate Name")
+
+		if scenario.CollectionID != "" {
+			_, err := app.collectionsModel.Get(scenario.CollectionID)
+			scenario.CheckField(err == nil, "collectionid", "Please select a valid collection")
+		}
+
+		if scenario.Valid() {
+			err = app.scenarios.Save(scenario)
+			if err != nil {
+				app.serverError500(w, r, err)
+				return
+			}
+
+			app.sessionManager.Put(r.Context(), "flash", "Saved sucessfully")
+
+			http.Redirect(w, r, "/scenarios", http.StatusSeeOther)
+			return
+		}
+
+	}
+
+	data := app.newTemplateData(r)
+	data.Form = scenario
+	data.Collections = app.collectionsModel.List()
+
+	app.render(w, r, http.StatusOK, "scenario_add.tmpl", data)
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (app *application) scenarioReset(w http.ResponseWriter, r *http.Request) {
+
+	err := r.ParseForm()
+	if err != nil {
+		app.sessionManager.Put(r.Context(), "error", fmt.Sprintf("001 Error processing form %s", err.Error()))
+		app.goBack(w, r, http.StatusSeeOther)
+		return
+	}
+
+	id := r.PostForm.Get("id")
+	if id == "" {
+		app.scenarios.ResetAll(r.PostForm.Get("collectionid"))
+		app.sessionManager.Put(r.Context(), "flash", "All scenarios reset")
+		app.goBack(w, r, http.StatusSeeOther)
+		return
+	}
+
+	err = app.scenarios.Reset(id)
+	if err != nil {
+		app.sessionManager.Put(r.Context(), "error", fmt.Sprintf("reset failed:: %s", err.Error()))
+		app.goBack(w, r, http.StatusSeeOther)
+		return
+	}
+
+	app.sessionManager.Put(r.Context(), "flash", "Scenario reset")
+	app.goBack(w, r, http.StatusSeeOther)
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (app *application) scenarioDelete(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	scenario, err := app.scenarios.Get(id)
+	if err != nil {
+		app.clientError(w, http.StatusNotFound, err)
+		return
+	}
+
+	data := app.newTemplateData(r)
+	data.Scenario = scenario
+
+	app.render(w, r, http.StatusOK, "scenario_delete.tmpl", data)
+
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (app *application) scenarioDeleteConfirm(w http.ResponseWriter, r *http.Request) {
+
+	err := r.ParseForm()
+	if err != nil {
+		app.sessionManager.Put(r.Context(), "error", fmt.Sprintf("001 Error processing form %s", err.Error()))
+		app.goBack(w, r, http.StatusSeeOther)
+		return
+	}
+
+	id := r.PostForm.Get("id")
+
+	err = app.scenarios.Delete(id)
+	if err != nil {
+		app.sessionManager.Put(r.Context(), "error", fmt.Sprintf("delete failed:: %s", err.Error()))
+		app.goBack(w, r, http.StatusSeeOther)
+		return
+	}
+
+	app.invalidateEndPointCache()
+	app.sessionManager.Put(r.Context(), "flash", "Deleted sucessfully")
+
+	http.Redirect(w, r, "/scenarios", http.StatusSeeOther)
+
+}
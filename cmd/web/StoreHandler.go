@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (app *application) StoreHandlers(router *chi.Mux) {
+	router.Route("/store", func(r chi.Router) {
+		r.Use(app.RequireAuthentication)
+
+		// CSRF
+		r.Use(noSurf)
+		r.Get("/", app.storeList)
+		r.Post("/delete", app.storeDelete)
+		r.Post("/clear", app.storeClear)
+
+	})
+
+}
+
+// ------------------------------------------------------
+// blank cid is the default V1 collection
+// ------------------------------------------------------
+func (app *application) storeList(w http.ResponseWriter, r *http.Request) {
+
+	data := app.newTemplateData(r)
+	data.Collections = app.collectionsModel.List()
+
+	collectionid := r.URL.Query().Get("cid")
+	if collectionid != "" {
+		collection, err := app.collectionsModel.Get(collectionid)
+		if err != nil {
+			app.notFound(w, err)
+			return
+		}
+		data.Collection = collection
+	}
+
+	data.StoreEntries = app.store.List(collectionid)
+
+	app.render(w, r, http.StatusOK, "store_list.tmpl", data)
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (app *application) storeDelete(w http.ResponseWriter, r *http.Request) {
+
+	err := r.ParseForm()
+	if err != nil {
+		app.sessionManager.Put(r.Context(), "error", fmt.Sprintf("001 Error processing form %s", err.Error()))
+		app.goBack(w, r, http.StatusSeeOther)
+		return
+	}
+
+	collectionid := r.PostForm.Get("collectionid")
+
+	err = app.store.Delete(collectionid, r.PostForm.Get("key"))
+	if err != nil {
+		app.sessionManager.Put(r.Context(), "error", fmt.Sprintf("delete failed:: %s", err.Error()))
+	} else {
+		app.sessionManager.Put(r.Context(), "flash", "Deleted sucessfully")
+	}
+
+	http.Redirect(w, r, fmt.Sprintf("/store?cid=%s", url.QueryEscape(collectionid)), http.StatusSeeOther)
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (app *application) storeClear(w http.ResponseWriter, r *http.Request) {
+
+	err := r.ParseForm()
+	if err != nil {
+		app.sessionManager.Put(r.Context(), "error", fmt.Sprintf("001 Error processing form %s", err.Error()))
+		app.goBack(w, r, http.StatusSeeOther)
+		return
+	}
+
+	collectionid := r.PostForm.Get("collectionid")
+
+	err = app.store.ClearCollectionData(collectionid)
+	if err != nil {
+		app.sessionManager.Put(r.Context(), "error", fmt.Sprintf("clear failed:: %s", err.Error()))
+	} else {
+		app.sessionManager.Put(r.Context(), "flash", "Store cleared")
+	}
+
+	http.Redirect(w, r, fmt.Sprintf("/store?cid=%s", url.QueryEscape(collectionid)), http.StatusSeeOther)
+}
@@ -0,0 +1,316 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/gorilla/websocket"
+	"github.com/onlysumitg/GoMockAPI/internal/iwebsocket"
+	"github.com/onlysumitg/GoMockAPI/internal/models"
+	"github.com/onlysumitg/GoMockAPI/internal/validator"
+)
+
+// mock clients connect from anywhere
+var wsUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	CheckOrigin:     func(r *http.Request) bool { return true },
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (app *application) WsHandlers(router *chi.Mux) {
+	router.Get("/ws/*", app.wsConnect)
+
+	router.Route("/wsendpoints", func(r chi.Router) {
+		r.Use(app.RequireAuthentication)
+
+		// CSRF
+		r.Use(noSurf)
+		r.Get("/", app.wsEndPointList)
+		r.Get("/add", app.wsEndPointAdd)
+		r.Post("/add", app.wsEndPointAdd)
+
+		r.Get("/edit/{id}", app.wsEndPointAdd)
+		r.Post("/edit/{id}", app.wsEndPointAdd)
+
+		r.Get("/delete/{id}", app.wsEndPointDelete)
+		r.Post("/delete", app.wsEndPointDeleteConfirm)
+
+	})
+
+}
+
+// ------------------------------------------------------
+// <collection>/<name>, or <name> for the V1 collection
+// ------------------------------------------------------
+func (app *application) wsEndPointFromPath(path string) (*models.WsEndPoint, error) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+
+	switch len(parts) {
+	case 1:
+		return app.wsEndPoints.GetByName("", parts[0])
+	case 2:
+		if strings.EqualFold(parts[0], "V1") {
+			return app.wsEndPoints.GetByName("", parts[1])
+		}
+		for _, c := range app.collectionsModel.List() {
+			if strings.EqualFold(c.Name, parts[0]) {
+				return app.wsEndPoints.GetByName(c.ID, parts[1])
+			}
+		}
+	}
+
+	return nil, models.ErrNotFound
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (app *application) wsConnect(w http.ResponseWriter, r *http.Request) {
+	wsEndPoint, err := app.wsEndPointFromPath(chi.URLParam(r, "*"))
+	if err != nil {
+		app.notFound(w, errors.New("websocket endpoint not found"))
+		return
+	}
+
+	// the upgrader answers failed handshakes
+	conn, err := wsUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+
+	client := iwebsocket.NewMockClient(conn)
+	iwebsocket.MockClients.Add(wsEndPoint.ID, client)
+
+	done := make(chan struct{})
+	defer func() {
+		close(done)
+		iwebsocket.MockClients.Remove(wsEndPoint.ID, client)
+		conn.Close()
+	}()
+
+	for _, message := range models.SplitWsMessages(wsEndPoint.OnConnect) {
+		client.Send(message)
+	}
+
+	if wsEndPoint.PushInterval > 0 {
+		go wsPush(client, wsEndPoint, done)
+	}
+
+	if wsEndPoint.CloseAfter > 0 {
+		go wsCloseAfter(client, wsEndPoint, done)
+	}
+
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		// close frame sent: only wait for the answer of the client
+		if client.Closing() {
+			continue
+		}
+
+		flatMap := models.WsMessageFlatMap(string(message))
+		rule := wsEndPoint.MatchRule(flatMap)
+		if rule == nil {
+			continue
+		}
+
+		// replies keep the order of the messages
+		if rule.Delay > 0 {
+			time.Sleep(time.Duration(rule.Delay) * time.Millisecond)
+		}
+
+		reply := models.RenderWsReply(rule.Reply, flatMap)
+		if strings.TrimSpace(reply) != "" {
+			client.Send(reply)
+		}
+
+		if rule.CloseCode != 0 {
+			client.Close(rule.CloseCode, "")
+		}
+	}
+}
+
+// ------------------------------------------------------
+// push messages are sent in turn
+// ------------------------------------------------------
+func wsPush(client *iwebsocket.MockClient, wsEndPoint *models.WsEndPoint, done chan struct{}) {
+	messages := models.SplitWsMessages(wsEndPoint.PushMessage)
+	if len(messages) == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(time.Duration(wsEndPoint.PushInterval) * time.Millisecond)
+	defer ticker.Stop()
+
+	for i := 0; ; i++ {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			client.Send(messages[i%len(messages)])
+		}
+	}
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func wsCloseAfter(client *iwebsocket.MockClient, wsEndPoint *models.WsEndPoint, done chan struct{}) {
+	timer := time.NewTimer(time.Duration(wsEndPoint.CloseAfter) * time.Millisecond)
+	defer timer.Stop()
+
+	select {
+	case <-done:
+	case <-timer.C:
+		code := wsEndPoint.CloseCode
+		if code == 0 {
+			code = websocket.CloseNormalClosure
+		}
+		client.Close(code, wsEndPoint.CloseReason)
+	}
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (app *application) wsEndPointList(w http.ResponseWriter, r *http.Request) {
+
+	data := app.newTemplateData(r)
+	data.Collections = app.collectionsModel.List()
+
+	collectionid := r.URL.Query().Get("cid")
+	if collectionid != "" {
+		collection, err := app.collectionsModel.Get(collectionid)
+		if err == nil {
+			data.Collection = collection
+			data.WsEndPoints = app.wsEndPoints.ListByCollectionID(collectionid)
+		}
+	}
+
+	if data.Collection == nil {
+		data.WsEndPoints = app.wsEndPoints.List()
+	}
+
+	app.render(w, r, http.StatusOK, "wsendpoint_list.tmpl", data)
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (app *application) wsEndPointAdd(w http.ResponseWriter, r *http.Request) {
+
+	wsEndPoint := &models.WsEndPoint{}
+
+	id := chi.URLParam(r, "id")
+	if id != "" {
+		e, err := app.wsEndPoints.Get(id)
+		if err == nil {
+			wsEndPoint = e
+		}
+	}
+
+	if r.Method == http.MethodPost {
+		wsEndPoint.Rules = nil // all rule rows are posted
+
+		err := app.decodePostForm(r, wsEndPoint)
+		if err != nil {
+			app.clientError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		wsEndPoint.CheckField(validator.NotBlank(wsEndPoint.Name), "name", "This field cannot be blank")
+		wsEndPoint.CheckField(!app.wsEndPoints.DuplicateName(wsEndPoint), "name", "Duplicate Name")
+
+		if wsEndPoint.CollectionID != "" {
+			_, err := app.collectionsModel.Get(wsEndPoint.CollectionID)
+			wsEndPoint.CheckField(err == nil, "collectionid", "Please select a valid collection")
+		}
+
+		wsEndPoint.CheckField(wsEndPoint.PushInterval >= 0, "pushinterval", "Can not be negative")
+		wsEndPoint.CheckField(wsEndPoint.CloseAfter >= 0, "closeafter", "Can not be negative")
+		wsEndPoint.CheckField(wsEndPoint.CloseCode == 0 || models.IsValidCloseCode(wsEndPoint.CloseCode), "closecode", "Valid codes are 1000-1003, 1007-1014 and 3000-4999")
+
+		wsEndPoint.CleanRules()
+		err = wsEndPoint.ValidateRules()
+		if err != nil {
+			wsEndPoint.CheckField(false, "rules", err.Error())
+		}
+
+		if wsEndPoint.Valid() {
+			err = app.wsEndPoints.Save(wsEndPoint)
+			if err != nil {
+				app.serverError500(w, r, err)
+				return
+			}
+
+			app.sessionManager.Put(r.Context(), "flash", "Saved sucessfully")
+
+			http.Redirect(w, r, "/wsendpoints", http.StatusSeeOther)
+			return
+		}
+
+	}
+
+	data := app.newTemplateData(r)
+	data.Form = wsEndPoint
+	data.Collections = app.collectionsModel.List()
+
+	app.render(w, r, http.StatusOK, "wsendpoint_add.tmpl", data)
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (app *application) wsEndPointDelete(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	wsEndPoint, err := app.wsEndPoints.Get(id)
+	if err != nil {
+		app.clientError(w, http.StatusNotFound, err)
+		return
+	}
+
+	data := app.newTemplateData(r)
+	data.WsEndPoint = wsEndPoint
+
+	app.render(w, r, http.StatusOK, "wsendpoint_delete.tmpl", data)
+
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (app *application) wsEndPointDeleteConfirm(w http.ResponseWriter, r *http.Request) {
+
+	err := r.ParseForm()
+	if err != nil {
+		app.sessionManager.Put(r.Context(), "error", fmt.Sprintf("001 Error processing form %s", err.Error()))
+		app.goBack(w, r, http.StatusSeeOther)
+		return
+	}
+
+	id := r.PostForm.Get("id")
+
+	err = app.wsEndPoints.Delete(id)
+	if err != nil {
+		app.sessionManager.Put(r.Context(), "error", fmt.Sprintf("delete failed:: %s", err.Error()))
+		app.goBack(w, r, http.StatusSeeOther)
+		return
+	}
+
+	iwebsocket.MockClients.CloseAll(id, websocket.CloseGoingAway, "endpoint deleted")
+	app.sessionManager.Put(r.Context(), "flash", "Deleted sucessfully")
+
+	http.Redirect(w, r, "/wsendpoints", http.StatusSeeOther)
+
+}
@@ -62,3 +62,70 @@ func (app *application) GetEndPoint(collection, endpointname, httpmethod string)
 	return endPoint, nil
 
 }
+
+// ------------------------------------------------------
+// SOAP operations share the url of their service and are told apart by
+// the SOAPAction header, or by the first element in the body
+// ------------------------------------------------------
+func (app *application) GetSoapEndPoint(collection, service string, action string, body []byte) (*models.EndPoint, error) {
+	element := ""
+	envelope, err := models.ParseSoapEnvelope(string(body))
+	if err == nil {
+		element = envelope.Operation
+	}
+
+	app.endPointMutex.Lock()
+	defer app.endPointMutex.Unlock()
+
+	if app.endPointCache == nil || app.invalidEndPointCache {
+		app.endPointCache = app.endpoints.BuildEndPointCache(app.maxAllowedEndPoints)
+		app.invalidEndPointCache = false
+	}
+
+	var byElement *models.EndPoint
+	for _, endPoint := range app.endPointCache {
+		if !endPoint.IsSoapOperationOf(collection, service) {
+			continue
+		}
+		if action != "" && strings.EqualFold(endPoint.SoapAction, action) {
+			return endPoint, nil
+		}
+		if byElement == nil && element != "" && strings.EqualFold(endPoint.SoapElement, element) {
+			byElement = endPoint
+		}
+	}
+
+	if byElement == nil {
+		return nil, fmt.Errorf("no SOAP operation of %s %s for action %q element %q", collection, service, action, element)
+	}
+	return byElement, nil
+}
+
+// ------------------------------------------------------
+// endpoint of /service/method, the first collection by name when
+// several mock the same method
+// ------------------------------------------------------
+func (app *application) GetGrpcEndPoint(service string, method string) (*models.EndPoint, error) {
+	app.endPointMutex.Lock()
+	defer app.endPointMutex.Unlock()
+
+	if app.endPointCache == nil || app.invalidEndPointCache {
+		app.endPointCache = app.endpoints.BuildEndPointCache(app.maxAllowedEndPoints)
+		app.invalidEndPointCache = false
+	}
+
+	var found *models.EndPoint
+	for _, endPoint := range app.endPointCache {
+		if !endPoint.IsGrpcMethodOf(service, method) {
+			continue
+		}
+		if found == nil || endPoint.CollectionName < found.CollectionName {
+			found = endPoint
+		}
+	}
+
+	if found == nil {
+		return nil, fmt.Errorf("no mock of /%s/%s", service, method)
+	}
+	return found, nil
+}
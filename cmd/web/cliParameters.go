@@ -16,6 +16,7 @@ import (
 type parameters struct {
 	host           string
 	port           int
+	grpcport       int
 	superuseremail string
 	superuserpwd   string
 
@@ -59,6 +60,7 @@ func (p *parameters) getHttpAddress() (string, string) {
 func (params *parameters) Load() {
 	flag.StringVar(&params.host, "host", "", "Http Host Name")
 	flag.IntVar(&params.port, "port", 4041, "Port")
+	flag.IntVar(&params.grpcport, "grpcport", 0, "Port of the gRPC mocks, 0 to disable")
 
 	flag.StringVar(&params.superuseremail, "superuseremail", "admin2@example.com", "Super User email")
 	flag.StringVar(&params.superuserpwd, "superuserpwd", "adminpass", "Super User password")
@@ -81,6 +83,11 @@ func (params *parameters) Load() {
 		params.port = port
 	}
 
+	grpcPort, err := strconv.Atoi(env.GetEnvVariable("GRPC_PORT", ""))
+	if err == nil {
+		params.grpcport = grpcPort
+	}
+
 	domainEnv := env.GetEnvVariable("DOMAIN", "")
 
 	if domainEnv != "" {
@@ -11,6 +11,7 @@ import (
 	"github.com/alexedwards/scs/v2"
 	"github.com/go-playground/form"
 	"github.com/onlysumitg/GoMockAPI/internal/models"
+	"github.com/onlysumitg/GoMockAPI/internal/worker"
 	mail "github.com/xhit/go-simple-mail/v2"
 	bolt "go.etcd.io/bbolt"
 )
@@ -22,6 +23,9 @@ type application struct {
 	endPointMutex        sync.Mutex
 	invalidEndPointCache bool
 
+	// calls that save their endpoint: recording and call logs
+	endPointSaveMutex sync.Mutex
+
 	errorLog *log.Logger
 	infoLog  *log.Logger
 
@@ -46,9 +50,20 @@ type application struct {
 	condition        *models.ConditionModel
 	conditionGroup   *models.ConditionGroupModel
 	collectionsModel *models.CollectionModel
+	scenarios        *models.ScenarioModel
+	resources        *models.ResourceModel
+	store            *models.StoreModel
+	wsEndPoints      *models.WsEndPointModel
+	webhookLogs      *models.WebhookLogModel
+
+	// runs outbound webhooks
+	worker *worker.Simple
 
 	mainAppServer *http.Server
 
+	// 0 when gRPC is not served
+	grpcPort int
+
 	InProduction bool
 	hostURL      string
 	domain       string
@@ -90,6 +105,11 @@ func baseAppConfig(params parameters, db *bolt.DB, logdb *bolt.DB) *application
 		conditionGroup: &models.ConditionGroupModel{DB: db},
 
 		collectionsModel: &models.CollectionModel{DB: db},
+		scenarios:        &models.ScenarioModel{DB: db},
+		resources:        &models.ResourceModel{DB: db},
+		store:            &models.StoreModel{DB: db},
+		wsEndPoints:      &models.WsEndPointModel{DB: db},
+		webhookLogs:      &models.WebhookLogModel{DB: logdb},
 
 		hostURL: hostUrl,
 
@@ -99,6 +119,7 @@ func baseAppConfig(params parameters, db *bolt.DB, logdb *bolt.DB) *application
 
 		domain:         params.domain,
 		useletsencrypt: params.useletsencrypt,
+		grpcPort:       params.grpcport,
 	}
 
 	if app.testMode {
@@ -107,6 +128,8 @@ func baseAppConfig(params parameters, db *bolt.DB, logdb *bolt.DB) *application
 
 	}
 
+	app.startWebhookWorker()
+
 	//--------------------------------------- Setup template cache ----------------------------
 	templateCache, err := app.newTemplateCache()
 	if err != nil {
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/onlysumitg/GoMockAPI/internal/models"
+)
+
+// -----------------------------------------------------------------
+// take over the connection, nil when the server does not allow it
+// -----------------------------------------------------------------
+func hijackConnection(w http.ResponseWriter, apiCall *models.ApiCall) (net.Conn, *bufio.ReadWriter) {
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		apiCall.LogError("Fault skipped: the connection can not be hijacked (HTTP/2?)")
+		return nil, nil
+	}
+
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		apiCall.LogError(fmt.Sprintf("Fault skipped: %s", err.Error()))
+		return nil, nil
+	}
+	return conn, rw
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func writeRawHead(rw *bufio.ReadWriter, apiCall *models.ApiCall, contentLength int) {
+	fmt.Fprintf(rw, "HTTP/1.1 %d %s\r\n", apiCall.StatusCode, http.StatusText(apiCall.StatusCode))
+
+	header := apiCall.GetHttpHeader()
+	header.Set("Content-Type", apiCall.GetContentType())
+	header.Set("Content-Length", fmt.Sprint(contentLength))
+	header.Set("Connection", "close")
+	header.Write(rw)
+
+	rw.WriteString("\r\n")
+}
+
+// -----------------------------------------------------------------
+// true when the fault took care of the response
+// -----------------------------------------------------------------
+func (app *application) writeFault(w http.ResponseWriter, r *http.Request, apiCall *models.ApiCall, fault string) bool {
+	apiCall.LogInfo(fmt.Sprintf("Simulating fault %s", fault))
+
+	body := apiCall.FinalResponseString + "\n"
+	half := body[:len(body)/2]
+
+	switch fault {
+	case models.FaultHang:
+		select {
+		case <-r.Context().Done():
+			apiCall.LogInfo("Fault HANG: client gave up")
+		case <-time.After(models.MaxFaultHang):
+			apiCall.LogInfo("Fault HANG: released after the max hang time")
+		}
+		if conn, _ := hijackConnection(w, apiCall); conn != nil {
+			conn.Close()
+		}
+		return true
+
+	case models.FaultMalformed:
+		app.writeJSONorXML(apiCall.GetContentType(), w, apiCall.StatusCode, models.MalformedBody(apiCall.FinalResponseString), apiCall.GetHttpHeader())
+		return true
+
+	case models.FaultTruncate:
+		// Content-Length is set from the truncated body
+		app.writeJSONorXML(apiCall.GetContentType(), w, apiCall.StatusCode, half, apiCall.GetHttpHeader())
+		return true
+	}
+
+	conn, rw := hijackConnection(w, apiCall)
+	if conn == nil {
+		return false
+	}
+	defer conn.Close()
+
+	switch fault {
+	case models.FaultClose:
+		// nothing sent
+
+	case models.FaultReset:
+		writeRawHead(rw, apiCall, len(body))
+		rw.WriteString(half)
+		rw.Flush()
+
+		// RST instead of FIN on close
+		if tcpConn, ok := conn.(*net.TCPConn); ok {
+			tcpConn.SetLinger(0)
+		}
+
+	case models.FaultBadLength:
+		writeRawHead(rw, apiCall, len(body)+512)
+		rw.WriteString(body)
+		rw.Flush()
+	}
+
+	return true
+}
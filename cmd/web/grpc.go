@@ -0,0 +1,419 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/onlysumitg/GoMockAPI/internal/models"
+	"github.com/onlysumitg/GoMockAPI/internal/protobuf"
+	"github.com/onlysumitg/GoMockAPI/utils/jsonutils"
+	"github.com/onlysumitg/GoMockAPI/utils/stringutils"
+	"github.com/onlysumitg/GoMockAPI/utils/xmlutils"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (app *application) GrpcHandlers(router *chi.Mux) {
+	router.Route("/grpc", func(r chi.Router) {
+		r.Use(app.RequireAuthentication)
+
+		// CSRF
+		r.Use(noSurf)
+		r.Get("/", app.grpcUploader)
+		r.Post("/upload", app.grpcUpload)
+	})
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (app *application) grpcUploader(w http.ResponseWriter, r *http.Request) {
+	data := app.newTemplateData(r)
+
+	app.render(w, r, http.StatusOK, "grpc_upload.tmpl", data)
+}
+
+// ------------------------------------------------------
+// the files are parsed together so imports between them resolve
+// ------------------------------------------------------
+func (app *application) grpcUpload(w http.ResponseWriter, r *http.Request) {
+	user, err := app.GetUser(r)
+	if err != nil {
+		app.UnauthorizedError(w, r)
+		return
+	}
+
+	err = r.ParseMultipartForm(MAX_UPLOAD_SIZE)
+	if err != nil {
+		app.sessionManager.Put(r.Context(), "error", fmt.Sprintf("Error reading files %s", err.Error()))
+		app.goBack(w, r, http.StatusSeeOther)
+		return
+	}
+
+	fileHeaders := r.MultipartForm.File["files"]
+	if len(fileHeaders) == 0 {
+		app.sessionManager.Put(r.Context(), "error", "Please select the .proto files")
+		app.goBack(w, r, http.StatusSeeOther)
+		return
+	}
+
+	files := make([]string, 0, len(fileHeaders))
+	for _, fileHeader := range fileHeaders {
+		file, err := fileHeader.Open()
+		if err != nil {
+			app.sessionManager.Put(r.Context(), "error", fmt.Sprintf("Error reading file %s", err.Error()))
+			app.goBack(w, r, http.StatusSeeOther)
+			return
+		}
+
+		proto, err := io.ReadAll(file)
+		file.Close()
+		if err != nil {
+			app.sessionManager.Put(r.Context(), "error", fmt.Sprintf("Error reading file %s", err.Error()))
+			app.goBack(w, r, http.StatusSeeOther)
+			return
+		}
+
+		files = append(files, fmt.Sprintf("// file: %s\n%s", fileHeader.Filename, proto))
+	}
+
+	data := app.newTemplateData(r)
+	data.Messages = app.ImportProto(files, user)
+	app.render(w, r, http.StatusOK, "user_message.tmpl", data)
+}
+
+// ------------------------------------------------------
+// a collection for the files with an endpoint for every method. An
+// endpoint keeps the file of its service, the other files are its imports.
+// ------------------------------------------------------
+func (app *application) ImportProto(files []string, currentUser *models.User) []string {
+	messageList := make([]string, 0)
+
+	registry, err := protobuf.Parse(files...)
+	if err != nil {
+		messageList = append(messageList, fmt.Sprintf("Error: %s", err.Error()))
+		return messageList
+	}
+
+	services := registry.ServiceList()
+	if len(services) == 0 {
+		messageList = append(messageList, "Error: no service found")
+		return messageList
+	}
+
+	collectionName := stringutils.RemoveSpecialChars(services[0].Name)
+	for _, c := range app.collectionsModel.List() {
+		if strings.EqualFold(c.Name, collectionName) {
+			collectionName = fmt.Sprintf("%s_%s", collectionName, stringutils.RandomString(6))
+		}
+	}
+
+	collection := &models.Collection{
+		Name: collectionName,
+		Desc: fmt.Sprintf("%s gRPC", services[0].FullName),
+	}
+	messageList = append(messageList, fmt.Sprintf("Info: creating collection %s", collection.Name))
+	app.collectionsModel.Save(collection)
+
+	for _, service := range services {
+		imports := make([]string, 0, len(files)-1)
+		for i, file := range files {
+			if i != service.Source {
+				imports = append(imports, file)
+			}
+		}
+
+		for _, method := range service.Methods {
+			if method.ClientStreaming {
+				messageList = append(messageList, fmt.Sprintf("Error: %s/%s client streaming is not supported", service.FullName, method.Name))
+				continue
+			}
+
+			request, _ := json.MarshalIndent(registry.Sample(method.Input), "", "  ")
+
+			var sample any = registry.Sample(method.Output)
+			if method.ServerStreaming {
+				sample = map[string]any{models.GrpcStreamKey: []any{sample}}
+			}
+			response, _ := json.MarshalIndent(sample, "", "  ")
+
+			ep := &models.EndPoint{
+				Name:                    fmt.Sprintf("%s_%s", service.Name, method.Name),
+				CollectionID:            collection.ID,
+				CollectionName:          collection.Name,
+				Method:                  http.MethodPost,
+				ActualURL:               fmt.Sprintf("http://localhost/%s/%s", service.FullName, method.Name),
+				SampleRequest:           string(request),
+				SampleRequestType:       models.GrpcType,
+				SampleRequestHeader:     "{}",
+				SampleRequestHeaderType: "JSON",
+				GrpcService:             service.FullName,
+				GrpcMethod:              method.Name,
+				ProtoSource:             files[service.Source],
+				ProtoImports:            imports,
+			}
+
+			ep.SetResponse(&models.EndPointResponse{
+				Name:               "DEFAULT",
+				HttpCode:           http.StatusOK,
+				Response:           string(response),
+				ResponseType:       "JSON",
+				ResponseHeader:     "{}",
+				ResponseHeaderType: "JSON",
+			})
+			ep.SetResponse(&models.EndPointResponse{
+				Name:               "ERROR",
+				HttpCode:           http.StatusNotFound,
+				Response:           "{}",
+				ResponseType:       "JSON",
+				ResponseHeader:     fmt.Sprintf(`{"%s": "NOT_FOUND", "%s": "not found"}`, models.GrpcStatusHeader, models.GrpcMessageHeader),
+				ResponseHeaderType: "JSON",
+			})
+
+			messageList = append(messageList, fmt.Sprintf("Info: creating endpoint %s for %s", ep.Name, ep.GrpcPath()))
+
+			ep.Prepare()
+			if !ep.Valid() {
+				for k, v := range ep.Validator.FieldErrors {
+					messageList = append(messageList, fmt.Sprintf("Error: %s %s %s", ep.Name, k, v))
+				}
+				continue
+			}
+
+			_, err := app.endpoints.Save(ep, currentUser.Email)
+			if err != nil {
+				messageList = append(messageList, fmt.Sprintf("Error: %s %s", ep.Name, err.Error()))
+			}
+		}
+	}
+
+	app.invalidateEndPointCache()
+	if app.grpcPort > 0 {
+		messageList = append(messageList, fmt.Sprintf("Info: call the methods on port %d with plaintext HTTP/2", app.grpcPort))
+	} else {
+		messageList = append(messageList, "Info: start GoMockAPI with -grpcport to call the methods")
+	}
+
+	return messageList
+}
+
+// ------------------------------------------------------
+// gRPC over plaintext HTTP/2
+// ------------------------------------------------------
+func (app *application) grpcServer(addr string) *http.Server {
+	return &http.Server{
+		Addr:     addr,
+		Handler:  h2c.NewHandler(http.HandlerFunc(app.grpcCall), &http2.Server{}),
+		ErrorLog: app.errorLog,
+	}
+}
+
+// ------------------------------------------------------
+// keeps the response of the endpoint so it can be sent as messages
+// ------------------------------------------------------
+type grpcRecorder struct {
+	header     http.Header
+	statusCode int
+	body       bytes.Buffer
+}
+
+func (g *grpcRecorder) Header() http.Header {
+	return g.header
+}
+
+func (g *grpcRecorder) Write(b []byte) (int, error) {
+	if g.statusCode == 0 {
+		g.statusCode = http.StatusOK
+	}
+	return g.body.Write(b)
+}
+
+func (g *grpcRecorder) WriteHeader(statusCode int) {
+	if g.statusCode == 0 {
+		g.statusCode = statusCode
+	}
+}
+
+func (g *grpcRecorder) Flush() {}
+
+// ------------------------------------------------------
+// status of the call in the trailers
+// ------------------------------------------------------
+func writeGrpcStatus(w http.ResponseWriter, code int, message string) {
+	w.Header().Set(http.TrailerPrefix+"Grpc-Status", fmt.Sprint(code))
+	if message != "" {
+		w.Header().Set(http.TrailerPrefix+"Grpc-Message", models.EncodeGrpcMessage(message))
+	}
+}
+
+// ------------------------------------------------------
+// error before anything is sent
+// ------------------------------------------------------
+func grpcError(w http.ResponseWriter, code string, message string) {
+	w.Header().Set("Content-Type", "application/grpc")
+	w.WriteHeader(http.StatusOK)
+	writeGrpcStatus(w, models.GrpcCodes[code], message)
+}
+
+// ------------------------------------------------------
+// one length prefixed message
+// ------------------------------------------------------
+func readGrpcMessage(r *http.Request) ([]byte, error) {
+	prefix := make([]byte, 5)
+	_, err := io.ReadFull(r.Body, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("reading the message: %s", err.Error())
+	}
+
+	length := binary.BigEndian.Uint32(prefix[1:])
+	if length > MAX_UPLOAD_SIZE {
+		return nil, fmt.Errorf("message of %d bytes is too big", length)
+	}
+
+	message := make([]byte, length)
+	_, err = io.ReadFull(r.Body, message)
+	if err != nil {
+		return nil, fmt.Errorf("reading the message: %s", err.Error())
+	}
+
+	if prefix[0] == 0 {
+		return message, nil
+	}
+
+	if !strings.EqualFold(r.Header.Get("grpc-encoding"), "gzip") {
+		return nil, fmt.Errorf("compressed message with unsupported encoding %q", r.Header.Get("grpc-encoding"))
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(message))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(io.LimitReader(reader, MAX_UPLOAD_SIZE))
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func writeGrpcMessage(w http.ResponseWriter, message []byte) {
+	prefix := make([]byte, 5)
+	binary.BigEndian.PutUint32(prefix[1:], uint32(len(message)))
+	w.Write(prefix)
+	w.Write(message)
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// ------------------------------------------------------
+// /package.Service/Method: the request message is decoded to JSON and
+// handled like a JSON call of the endpoint, the JSON response is sent
+// back as protobuf
+// ------------------------------------------------------
+func (app *application) grpcCall(w http.ResponseWriter, r *http.Request) {
+	if r.ProtoMajor != 2 || r.Method != http.MethodPost {
+		http.Error(w, "gRPC requires HTTP/2 POST", http.StatusHTTPVersionNotSupported)
+		return
+	}
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc") {
+		http.Error(w, "Content-Type must be application/grpc", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	service, method, found := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if !found {
+		grpcError(w, "UNIMPLEMENTED", fmt.Sprintf("invalid path %s", r.URL.Path))
+		return
+	}
+
+	endPoint, err := app.GetGrpcEndPoint(service, method)
+	if err != nil {
+		grpcError(w, "UNIMPLEMENTED", err.Error())
+		return
+	}
+
+	registry, descriptor, err := endPoint.GrpcMethodDescriptor()
+	if err != nil {
+		grpcError(w, "INTERNAL", err.Error())
+		return
+	}
+	if descriptor.ClientStreaming {
+		grpcError(w, "UNIMPLEMENTED", "client streaming is not supported")
+		return
+	}
+
+	message, err := readGrpcMessage(r)
+	if err != nil {
+		grpcError(w, "INVALID_ARGUMENT", err.Error())
+		return
+	}
+
+	request, err := registry.Unmarshal(descriptor.Input, message)
+	if err != nil {
+		grpcError(w, "INVALID_ARGUMENT", err.Error())
+		return
+	}
+
+	// same flat map as a JSON body
+	requestJson, _ := json.Marshal(request)
+	requestBodyMap := make(map[string]any)
+	json.Unmarshal(requestJson, &requestBodyMap)
+
+	requestBodyFlatMap := jsonutils.JsonToFlatMapFromMap(requestBodyMap)
+	for key, value := range endPoint.GrpcRequestValues() {
+		requestBodyFlatMap[key] = xmlutils.ValueDatatype{value, "STRING"}
+	}
+
+	jsonRequest := r.Clone(r.Context())
+	jsonRequest.Body = io.NopCloser(bytes.NewReader(requestJson))
+	jsonRequest.ContentLength = int64(len(requestJson))
+	jsonRequest.Header.Set("Content-Type", "application/json")
+
+	recorder := &grpcRecorder{header: make(http.Header)}
+	app.ProcessAPICall(recorder, jsonRequest, endPoint.CollectionName, endPoint.Name, nil, requestBodyFlatMap)
+	if recorder.statusCode == 0 {
+		recorder.statusCode = http.StatusOK
+	}
+
+	// response headers are the metadata
+	keys := make([]string, 0, len(recorder.header))
+	for key := range recorder.header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		lower := strings.ToLower(key)
+		if lower == "content-type" || lower == "content-length" || strings.HasPrefix(lower, "grpc-") {
+			continue
+		}
+		for _, value := range recorder.header[key] {
+			w.Header().Add(key, value)
+		}
+	}
+
+	code, statusMessage := models.GrpcStatus(recorder.header, recorder.statusCode)
+
+	// an error can follow the messages of a stream
+	messages, err := models.GrpcResponseMessages(registry, descriptor, recorder.body.String(), code != models.GrpcCodes["OK"])
+	if err != nil {
+		grpcError(w, "INTERNAL", fmt.Sprintf("response is not a JSON %s: %s", descriptor.Output, err.Error()))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/grpc")
+	w.WriteHeader(http.StatusOK)
+	for _, m := range messages {
+		writeGrpcMessage(w, m)
+	}
+	writeGrpcStatus(w, code, statusMessage)
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/onlysumitg/GoMockAPI/internal/models"
+	"github.com/onlysumitg/GoMockAPI/internal/protobuf"
+	"golang.org/x/net/http2"
+)
+
+const testCommonProto = `
+syntax = "proto3";
+package demo.common;
+message Greeting { string message = 1; int32 count = 2; }
+`
+
+const testGreeterProto = `
+syntax = "proto3";
+package demo.v1;
+import "common.proto";
+message HelloRequest { string name = 1; }
+service Greeter {
+  rpc SayHello(HelloRequest) returns (demo.common.Greeting);
+  rpc Countdown(HelloRequest) returns (stream demo.common.Greeting);
+}
+`
+
+// -----------------------------------------------------------------
+// length prefixed messages of a gRPC body
+// -----------------------------------------------------------------
+func grpcFrames(t *testing.T, body []byte) [][]byte {
+	t.Helper()
+
+	frames := make([][]byte, 0)
+	for len(body) > 0 {
+		if len(body) < 5 {
+			t.Fatalf("truncated message prefix %x", body)
+		}
+		length := binary.BigEndian.Uint32(body[1:5])
+		if uint32(len(body)-5) < length {
+			t.Fatalf("truncated message %x", body)
+		}
+		frames = append(frames, body[5:5+length])
+		body = body[5+length:]
+	}
+	return frames
+}
+
+func Test_GrpcCall(t *testing.T) {
+	app := newTestApplication(t)
+
+	messages := app.ImportProto([]string{testCommonProto, testGreeterProto}, &models.User{Email: "test@local"})
+	for _, message := range messages {
+		if strings.HasPrefix(message, "Error") {
+			t.Fatalf("import: %s", message)
+		}
+	}
+
+	// responses with metadata, and a stream that ends with an error
+	for _, endPoint := range app.endpoints.List() {
+		if endPoint.ProtoSource != strings.TrimSpace(testGreeterProto) || !reflect.DeepEqual(endPoint.ProtoImports, []string{strings.TrimSpace(testCommonProto)}) {
+			t.Errorf("%s: expected the service file and its import but got %q %q", endPoint.Name, endPoint.ProtoSource, endPoint.ProtoImports)
+		}
+
+		for _, response := range endPoint.ResponseMap {
+			if response.Name != "DEFAULT" {
+				continue
+			}
+			switch endPoint.GrpcMethod {
+			case "SayHello":
+				response.Response = `{"message": "hello", "count": -1}`
+				response.ResponseHeader = `{"x-trace": "abc"}`
+			case "Countdown":
+				response.Response = `{"*STREAM": [{"count": 2}, {"count": 1}]}`
+				response.ResponseHeader = `{"grpc-status": "NOT_FOUND", "grpc-message": "no more 100%"}`
+			}
+		}
+		if _, err := app.endpoints.Save(endPoint, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	app.invalidateEndPointCache()
+
+	server := httptest.NewServer(app.grpcServer("").Handler)
+	defer server.Close()
+
+	client := &http.Client{Transport: &http2.Transport{
+		AllowHTTP: true,
+		DialTLSContext: func(ctx context.Context, network, addr string, cfg *tls.Config) (net.Conn, error) {
+			return net.Dial(network, addr)
+		},
+	}}
+
+	registry, err := protobuf.Parse(testCommonProto, testGreeterProto)
+	if err != nil {
+		t.Fatal(err)
+	}
+	request, _ := registry.Marshal("demo.v1.HelloRequest", map[string]any{"name": "Ada"})
+	prefix := make([]byte, 5)
+	binary.BigEndian.PutUint32(prefix[1:], uint32(len(request)))
+	message := append(prefix, request...)
+
+	tests := []struct {
+		name          string
+		path          string
+		body          []byte
+		statusX       string
+		statusMessage string
+		metadata      map[string]string
+		messagesX     []string
+	}{
+		{
+			"unary with metadata", "/demo.v1.Greeter/SayHello", message, "0", "",
+			map[string]string{"X-Trace": "abc"},
+			[]string{`{"count":-1,"message":"hello"}`},
+		},
+		{
+			"stream then error", "/demo.v1.Greeter/Countdown", message, "5", "no more 100%25",
+			map[string]string{"X-Trace": ""},
+			[]string{`{"count":2,"message":""}`, `{"count":1,"message":""}`},
+		},
+		{
+			"unknown method", "/demo.v1.Greeter/Nope", message, "12", "no mock of /demo.v1.Greeter/Nope",
+			nil, []string{},
+		},
+		{
+			"invalid message", "/demo.v1.Greeter/SayHello", []byte{0, 0, 0, 0, 2, 0x0a, 0x05}, "3", "",
+			nil, []string{},
+		},
+		{
+			"missing message", "/demo.v1.Greeter/SayHello", []byte{}, "3", "",
+			nil, []string{},
+		},
+	}
+
+	for _, test := range tests {
+		r, _ := http.NewRequest(http.MethodPost, server.URL+test.path, bytes.NewReader(test.body))
+		r.Header.Set("Content-Type", "application/grpc")
+		r.Header.Set("TE", "trailers")
+
+		response, err := client.Do(r)
+		if err != nil {
+			t.Fatalf("%s: %s", test.name, err.Error())
+		}
+		body, _ := io.ReadAll(response.Body)
+		response.Body.Close()
+
+		if response.StatusCode != http.StatusOK || response.Header.Get("Content-Type") != "application/grpc" {
+			t.Errorf("%s: expected 200 application/grpc but got %d %s", test.name, response.StatusCode, response.Header.Get("Content-Type"))
+		}
+
+		// the status is in the trailers, after the messages
+		if response.Trailer.Get("Grpc-Status") != test.statusX {
+			t.Errorf("%s: expected grpc-status %s but got %q %q", test.name, test.statusX, response.Trailer.Get("Grpc-Status"), response.Trailer.Get("Grpc-Message"))
+		}
+		if test.statusMessage != "" && response.Trailer.Get("Grpc-Message") != test.statusMessage {
+			t.Errorf("%s: expected grpc-message %q but got %q", test.name, test.statusMessage, response.Trailer.Get("Grpc-Message"))
+		}
+		if response.Header.Get("Grpc-Status") != "" {
+			t.Errorf("%s: grpc-status must not be a header", test.name)
+		}
+
+		for key, value := range test.metadata {
+			if response.Header.Get(key) != value {
+				t.Errorf("%s: expected metadata %s %q but got %q", test.name, key, value, response.Header.Get(key))
+			}
+		}
+
+		frames := grpcFrames(t, body)
+		if len(frames) != len(test.messagesX) {
+			t.Errorf("%s: expected %d messages but got %d", test.name, len(test.messagesX), len(frames))
+			continue
+		}
+		for i, frame := range frames {
+			decoded, err := registry.Unmarshal("demo.common.Greeting", frame)
+			if err != nil {
+				t.Errorf("%s: unexpected error %s", test.name, err.Error())
+				continue
+			}
+			if result, _ := json.Marshal(decoded); string(result) != test.messagesX[i] {
+				t.Errorf("%s: expected %s but got %s", test.name, test.messagesX[i], result)
+			}
+		}
+	}
+
+	// gRPC needs HTTP/2
+	response, err := http.Post(server.URL+"/demo.v1.Greeter/SayHello", "application/grpc", bytes.NewReader(message))
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusHTTPVersionNotSupported {
+		t.Errorf("HTTP/1.1: expected 505 but got %d", response.StatusCode)
+	}
+}
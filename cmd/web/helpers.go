@@ -4,15 +4,16 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"os/exec"
 	"runtime"
 	"runtime/debug"
-	"strings"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-playground/form/v4"
+	"github.com/onlysumitg/GoMockAPI/internal/models"
 )
 
 // -----------------------------------------------------------------
@@ -86,7 +87,7 @@ func (app *application) writeJSON(w http.ResponseWriter, status int, data interf
 // Define a writeJSON() helper for sending responses. This takes the destination
 // http.ResponseWriter, the HTTP status code to send, the data to encode to JSON, and a
 // header map containing any additional HTTP headers we want to include in the response.
-func (app *application) writeJSONorXML(responseType string, w http.ResponseWriter, status int, data string, headers http.Header) error {
+func (app *application) writeJSONorXML(contentType string, w http.ResponseWriter, status int, data string, headers http.Header) error {
 
 	// Encode the data to JSON, returning the error if there was one.
 	js := []byte(data)
@@ -101,14 +102,46 @@ func (app *application) writeJSONorXML(responseType string, w http.ResponseWrite
 	for key, value := range headers {
 		w.Header()[key] = value
 	}
-	// Add the "Content-Type: application/json" header, then write the status code and
-	// JSON response.
-	w.Header().Set("Content-Type", fmt.Sprintf("application/%s", strings.ToLower(responseType)))
+	// Add the Content-Type header, then write the status code and the response.
+	w.Header().Set("Content-Type", contentType)
 	w.WriteHeader(status)
 	w.Write(js)
 	return nil
 }
 
+// -----------------------------------------------------------------
+// FILE and BASE64 responses: Range and conditional requests are
+// handled by http.ServeContent for 200 responses
+// -----------------------------------------------------------------
+func (app *application) writeBinaryResponse(w http.ResponseWriter, r *http.Request, apiCall *models.ApiCall) {
+	response := apiCall.CurrentEndPoint.GetResponseByID(apiCall.ResponseID)
+
+	content, modTime, err := response.OpenContent()
+	if err != nil {
+		apiCall.LogError(fmt.Sprintf("Error serving %s response: %s", response.ResponseType, err.Error()))
+		app.errorResponse(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer content.Close()
+
+	for key, value := range apiCall.GetHttpHeader() {
+		w.Header()[key] = value
+	}
+
+	w.Header().Set("Content-Type", response.GetContentType())
+	if disposition := response.GetContentDisposition(); disposition != "" {
+		w.Header().Set("Content-Disposition", disposition)
+	}
+
+	if apiCall.StatusCode == http.StatusOK {
+		http.ServeContent(w, r, response.FileName, modTime, content)
+		return
+	}
+
+	w.WriteHeader(apiCall.StatusCode)
+	io.Copy(w, content)
+}
+
 // -----------------------------------------------------------------
 //
 // -----------------------------------------------------------------
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/onlysumitg/GoMockAPI/internal/models"
+)
+
+func Test_WriteBinaryResponse(t *testing.T) {
+	app := newTestApplication(t)
+
+	dir := models.ResponseFilesDir
+	models.ResponseFilesDir = filepath.Join(t.TempDir(), "files")
+	defer func() { models.ResponseFilesDir = dir }()
+
+	digits := base64.StdEncoding.EncodeToString([]byte("0123456789"))
+	endPoints := map[string]*models.EndPointResponse{
+		"digits":   {ID: "1", Name: "DEFAULT", HttpCode: 200, ResponseType: models.ResponseTypeBase64, Response: digits, FileName: "digits.txt", ContentDisposition: "attachment"},
+		"missing":  {ID: "1", Name: "DEFAULT", HttpCode: 404, ResponseType: models.ResponseTypeBase64, Response: digits, ContentType: "text/plain"},
+		"broken":   {ID: "1", Name: "DEFAULT", HttpCode: 200, ResponseType: models.ResponseTypeBase64, Response: "not base64!"},
+		"typed":    {ID: "1", Name: "DEFAULT", HttpCode: 200, ResponseType: models.ResponseTypeFile, FileName: "invoice.pdf", ContentDisposition: "inline"},
+		"uploaded": {ID: "1", Name: "DEFAULT", HttpCode: 200, ResponseType: models.ResponseTypeFile, ContentDisposition: "attachment"},
+	}
+	for name, response := range endPoints {
+		endPoint := &models.EndPoint{Name: name, Method: "GET", ResponseMap: []*models.EndPointResponse{response}}
+		if err := endPoint.ParseUrl(); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := app.endpoints.Save(endPoint, "test@local"); err != nil {
+			t.Fatal(err)
+		}
+		if response.ResponseType == models.ResponseTypeFile {
+			if err := models.SaveResponseFile(endPoint.ID, response, "/uploads/scan 001.pdf", strings.NewReader("%PDF-1.4 "+name)); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := app.endpoints.Save(endPoint, "test@local"); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	app.invalidateEndPointCache()
+
+	server := httptest.NewServer(app.routes())
+	defer server.Close()
+
+	tests := []struct {
+		name         string
+		rangeHeader  string
+		status       int
+		body         string
+		contentType  string
+		disposition  string
+		contentRange string
+	}{
+		{"digits", "", 200, "0123456789", "text/plain; charset=utf-8", "attachment; filename=digits.txt", ""},
+		{"digits", "bytes=2-5", 206, "2345", "text/plain; charset=utf-8", "attachment; filename=digits.txt", "bytes 2-5/10"},
+		{"digits", "bytes=-3", 206, "789", "text/plain; charset=utf-8", "attachment; filename=digits.txt", "bytes 7-9/10"},
+		{"digits", "bytes=20-30", 416, "", "", "", "bytes */10"},
+		// Range is for 200 responses only
+		{"missing", "bytes=2-5", 404, "0123456789", "text/plain", "", ""},
+		{"typed", "", 200, "%PDF-1.4 typed", "application/pdf", "inline; filename=invoice.pdf", ""},
+		{"uploaded", "", 200, "%PDF-1.4 uploaded", "application/pdf", `attachment; filename="scan 001.pdf"`, ""},
+	}
+
+	for _, test := range tests {
+		r, _ := http.NewRequest(http.MethodGet, server.URL+"/api/v1/"+test.name, nil)
+		if test.rangeHeader != "" {
+			r.Header.Set("Range", test.rangeHeader)
+		}
+		response, err := http.DefaultClient.Do(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(response.Body)
+		response.Body.Close()
+
+		name := test.name + " " + test.rangeHeader
+		if response.StatusCode != test.status {
+			t.Errorf("%s: expected status %d but got %d %q", name, test.status, response.StatusCode, body)
+			continue
+		}
+		if test.status == http.StatusRequestedRangeNotSatisfiable {
+			if got := response.Header.Get("Content-Range"); got != test.contentRange {
+				t.Errorf("%s: expected Content-Range %q but got %q", name, test.contentRange, got)
+			}
+			continue
+		}
+		if string(body) != test.body {
+			t.Errorf("%s: expected body %q but got %q", name, test.body, body)
+		}
+		if got := response.Header.Get("Content-Type"); got != test.contentType {
+			t.Errorf("%s: expected Content-Type %q but got %q", name, test.contentType, got)
+		}
+		if got := response.Header.Get("Content-Disposition"); got != test.disposition {
+			t.Errorf("%s: expected Content-Disposition %q but got %q", name, test.disposition, got)
+		}
+		if got := response.Header.Get("Content-Range"); got != test.contentRange {
+			t.Errorf("%s: expected Content-Range %q but got %q", name, test.contentRange, got)
+		}
+	}
+
+	// content that does not decode is a server error
+	response, err := http.Get(server.URL + "/api/v1/broken")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(response.Body)
+	response.Body.Close()
+	if response.StatusCode != http.StatusInternalServerError || !strings.Contains(string(body), "invalid base64 content") {
+		t.Errorf("expected 500 with the decode error but got %d %q", response.StatusCode, body)
+	}
+}
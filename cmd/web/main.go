@@ -94,6 +94,14 @@ func main() {
 
 	go app.clearLogsSchedular(db)
 
+	//--------------------------------------- gRPC mocks ----------------------------
+
+	if params.grpcport > 0 {
+		grpcServer := app.grpcServer(fmt.Sprintf("%s:%d", params.host, params.grpcport))
+		log.Printf("gRPC mocks are live at port %d \n", params.grpcport)
+		go concurrent.RecoverAndRestart(10, "grpc server", func() { log.Println(grpcServer.ListenAndServe()) })
+	}
+
 	//--------------------------------------- Create super user ----------------------------
 
 	go app.CreateSuperUser(params.superuseremail, params.superuserpwd)
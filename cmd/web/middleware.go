@@ -1,10 +1,13 @@
 package main
 
 import (
+	"crypto/subtle"
 	"log"
 	"net/http"
+	"strings"
 
 	"github.com/justinas/nosurf" // New import
+	"github.com/onlysumitg/GoMockAPI/env"
 )
 
 type ContextKey string
@@ -52,3 +55,42 @@ func (app *application) RedirectToHTTPS(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r)
 	})
 }
+
+// ------------------------------------------------------
+// mock admin api needs the ADMIN_API_TOKEN in the X-Admin-Token
+// header, or a logged in user. Without a token set only logged
+// in users can call it.
+// ------------------------------------------------------
+func (app *application) RequireAdminToken(next http.Handler) http.Handler {
+	token := env.GetEnvVariable("ADMIN_API_TOKEN", "")
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sentToken := r.Header.Get("X-Admin-Token")
+		validToken := token != "" && subtle.ConstantTimeCompare([]byte(sentToken), []byte(token)) == 1
+
+		if !validToken && !app.isAuthenticated(r) {
+			app.errorResponse(w, r, http.StatusUnauthorized, "admin token or login required")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ------------------------------------------------------
+// mock calls do not use the session, and the session
+// middleware buffers the whole response, so streamed
+// responses would not stream
+// ------------------------------------------------------
+func (app *application) LoadAndSaveSession(next http.Handler) http.Handler {
+	withSession := app.sessionManager.LoadAndSave(next)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/api/") || strings.HasPrefix(r.URL.Path, "/ws/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		withSession.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func Test_RequireAdminToken(t *testing.T) {
+	tests := []struct {
+		name       string
+		token      string
+		sent       string
+		statusCode int
+	}{
+		{"no token set", "", "", http.StatusUnauthorized},
+		{"no token set, any header", "", "anything", http.StatusUnauthorized},
+		{"token set, not sent", "secret", "", http.StatusUnauthorized},
+		{"token set, wrong", "secret", "nope", http.StatusUnauthorized},
+		{"token set, sent", "secret", "secret", http.StatusOK},
+	}
+
+	for _, test := range tests {
+		t.Setenv("ADMIN_API_TOKEN", test.token)
+
+		app := newTestApplication(t)
+		server := httptest.NewServer(app.routes())
+
+		r, _ := http.NewRequest(http.MethodGet, server.URL+"/mockadmin/clock", nil)
+		if test.sent != "" {
+			r.Header.Set("X-Admin-Token", test.sent)
+		}
+		response, err := http.DefaultClient.Do(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		response.Body.Close()
+		server.Close()
+
+		if response.StatusCode != test.statusCode {
+			t.Errorf("%s: expected %d but got %d", test.name, test.statusCode, response.StatusCode)
+		}
+	}
+}
+
+// browsers can send the token cross origin
+func Test_AdminToken_CorsPreflight(t *testing.T) {
+	app := newTestApplication(t)
+	server := httptest.NewServer(app.routes())
+	defer server.Close()
+
+	r, _ := http.NewRequest(http.MethodOptions, server.URL+"/mockadmin/clock", nil)
+	r.Header.Set("Origin", "http://tests.local")
+	r.Header.Set("Access-Control-Request-Method", http.MethodPost)
+	r.Header.Set("Access-Control-Request-Headers", "X-Admin-Token")
+	response, err := http.DefaultClient.Do(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+
+	if response.Header.Get("Access-Control-Allow-Headers") == "" {
+		t.Errorf("expected X-Admin-Token to be allowed, got headers %v", response.Header)
+	}
+}
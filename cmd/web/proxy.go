@@ -0,0 +1,48 @@
+package main
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/onlysumitg/GoMockAPI/internal/models"
+)
+
+// ------------------------------------------------------
+// the actual url response as received: all header values, status
+// code and body; a streamed body is flushed as it arrives
+// ------------------------------------------------------
+func (app *application) writeActualResponse(w http.ResponseWriter, apiCall *models.ApiCall) {
+	result := apiCall.ActualCallResult
+
+	for key, value := range result.Header {
+		w.Header()[key] = value
+	}
+	w.Header()["CORRELATIONID"] = []string{apiCall.ID}
+	w.WriteHeader(result.StatusCode)
+
+	if result.Stream == nil {
+		io.WriteString(w, apiCall.FinalResponseString)
+		return
+	}
+	defer result.Stream.Close()
+
+	flusher, _ := w.(http.Flusher)
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := result.Stream.Read(buf)
+		if n > 0 {
+			if _, writeErr := w.Write(buf[:n]); writeErr != nil {
+				return
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+		if err != nil {
+			if err != io.EOF {
+				apiCall.LogError("Error streaming response from ActualEndPoint: " + err.Error())
+			}
+			return
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/onlysumitg/GoMockAPI/internal/models"
+)
+
+func Test_ActualUrlCall_ForwardedFor(t *testing.T) {
+	app := newTestApplication(t)
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, r.Header.Get("X-Forwarded-For"))
+	}))
+	defer upstream.Close()
+
+	endPoint := &models.EndPoint{
+		Name:               "FORWARDED",
+		Method:             "GET",
+		ActualURL:          upstream.URL + "/echo",
+		AutoUpdateResponse: true,
+		ResponseMap: []*models.EndPointResponse{
+			{ID: "1", Name: "DEFAULT", HttpCode: 200, Response: "{}"},
+		},
+	}
+	if err := endPoint.ParseUrl(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.endpoints.Save(endPoint, "test@local"); err != nil {
+		t.Fatal(err)
+	}
+	app.invalidateEndPointCache()
+
+	server := httptest.NewServer(app.routes())
+	defer server.Close()
+
+	tests := []struct {
+		name      string
+		forwarded string
+		expected  string
+	}{
+		{"direct", "", "127.0.0.1"},
+		{"behind a proxy", "1.1.1.1", "1.1.1.1, 127.0.0.1"},
+		{"chain", "1.1.1.1, 2.2.2.2", "1.1.1.1, 2.2.2.2, 127.0.0.1"},
+	}
+
+	for _, test := range tests {
+		r, _ := http.NewRequest(http.MethodGet, server.URL+"/api/v1/forwarded", nil)
+		if test.forwarded != "" {
+			r.Header.Set("X-Forwarded-For", test.forwarded)
+		}
+		response, err := http.DefaultClient.Do(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(response.Body)
+		response.Body.Close()
+
+		if string(body) != test.expected {
+			t.Errorf("%s: expected X-Forwarded-For %q but got %d %q", test.name, test.expected, response.StatusCode, body)
+		}
+	}
+}
+
+// a client that goes away ends the call to the actual url
+func Test_ActualUrlCall_ClientGone(t *testing.T) {
+	app := newTestApplication(t)
+
+	canceled := make(chan bool, 1)
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(5 * time.Second):
+			canceled <- false
+		case <-r.Context().Done():
+			canceled <- true
+		}
+	}))
+	defer upstream.Close()
+
+	endPoint := &models.EndPoint{
+		Name:               "SLOW",
+		Method:             "GET",
+		ActualURL:          upstream.URL + "/slow",
+		AutoUpdateResponse: true,
+		ResponseMap: []*models.EndPointResponse{
+			{ID: "1", Name: "DEFAULT", HttpCode: 200, Response: "{}"},
+		},
+	}
+	if err := endPoint.ParseUrl(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.endpoints.Save(endPoint, "test@local"); err != nil {
+		t.Fatal(err)
+	}
+	app.invalidateEndPointCache()
+
+	server := httptest.NewServer(app.routes())
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	r, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/api/v1/slow", nil)
+	if response, err := http.DefaultClient.Do(r); err == nil {
+		response.Body.Close()
+		t.Fatalf("expected the client to give up but got %d", response.StatusCode)
+	}
+
+	select {
+	case gone := <-canceled:
+		if !gone {
+			t.Errorf("expected the actual url call to end with the client")
+		}
+	case <-time.After(2 * time.Second):
+		t.Errorf("actual url call still running after the client went away")
+	}
+}
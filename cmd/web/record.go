@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/onlysumitg/GoMockAPI/internal/models"
+)
+
+// ------------------------------------------------------
+// record mode of the endpoint or its collection, and if
+// condition groups are added for the recorded values
+// ------------------------------------------------------
+func (app *application) isRecording(endPoint *models.EndPoint) (bool, bool) {
+	recording := endPoint.AutoUpdateResponse
+	conditions := false
+
+	// collection of the endpoint cache, no db read per call
+	if collection := endPoint.Collection; collection != nil {
+		recording = recording || collection.Record
+		conditions = collection.RecordConditions
+	}
+	return recording, conditions
+}
+
+// ------------------------------------------------------
+// the actual url response becomes a response of the endpoint, once
+// for every status code and record key value
+// ------------------------------------------------------
+func (app *application) recordResponse(endPoint *models.EndPoint, apiCall *models.ApiCall, withConditions bool) {
+	result := apiCall.ActualCallResult
+	if result == nil || result.Err != nil {
+		return
+	}
+
+	// fresh copy, the cached one can be behind
+	current, err := app.endpoints.Get(endPoint.ID)
+	if err != nil {
+		return
+	}
+
+	value := current.RecordValue(apiCall)
+	name := models.RecordedName(value)
+
+	response := current.RecordedResponse(result.StatusCode, name)
+	if response == nil {
+		response = models.NewRecordedResponse(name, result)
+		current.SetResponse(response)
+
+		_, err = app.endpoints.Save(current, "")
+		if err != nil {
+			app.errorLog.Println("Recording", current.Name, err.Error())
+			return
+		}
+		app.infoLog.Println("Recorded", current.Name, name, result.StatusCode)
+	}
+
+	if withConditions && value != "" {
+		app.recordConditionGroup(current, value, response)
+	}
+
+	app.invalidateEndPointCache()
+}
+
+// ------------------------------------------------------
+// <record key> EQUALS_TO <value> picks the recorded response
+// ------------------------------------------------------
+func (app *application) recordConditionGroup(endPoint *models.EndPoint, value string, response *models.EndPointResponse) {
+	for _, cg := range app.conditionGroup.ListById(endPoint.ID) {
+		if cg.Name == response.Name {
+			return
+		}
+	}
+
+	var requestParam *models.EndPointRequestParam
+	for _, p := range app.requestParams.ListById(endPoint.ID) {
+		if p.Key == endPoint.RecordKey {
+			requestParam = p
+			break
+		}
+	}
+	if requestParam == nil {
+		app.infoLog.Println("Recording", endPoint.Name, "no request param", endPoint.RecordKey, "for the condition")
+		return
+	}
+
+	var condition *models.Condition
+	for _, c := range app.condition.ListById(endPoint.ID) {
+		if c.Variable == requestParam.ID && c.Operator == "EQUALS_TO" && c.Compareto == value {
+			condition = c
+			break
+		}
+	}
+
+	if condition == nil {
+		condition = &models.Condition{
+			EndpointID:        endPoint.ID,
+			Variable:          requestParam.ID,
+			VariableName:      requestParam.Key,
+			Operator:          "EQUALS_TO",
+			Compareto:         value,
+			ComparetoDataType: requestParam.DefaultDatatype,
+			Name:              fmt.Sprintf("%s %s %s", requestParam.Key, "EQUALS_TO", value),
+		}
+		_, err := app.condition.Save(condition)
+		if err != nil {
+			app.errorLog.Println("Recording", endPoint.Name, err.Error())
+			return
+		}
+	}
+
+	conditionGroup := &models.ConditionGroup{
+		EndpointID:   endPoint.ID,
+		Name:         response.Name,
+		ConditionIDs: []string{condition.ID},
+		ResponseID:   response.ID,
+	}
+	_, err := app.conditionGroup.Save(conditionGroup)
+	if err != nil {
+		app.errorLog.Println("Recording", endPoint.Name, err.Error())
+	}
+}
@@ -33,6 +33,7 @@ import (
 	"github.com/go-chi/httprate"
 	"github.com/onlysumitg/GoMockAPI/env"
 	"github.com/onlysumitg/GoMockAPI/ui" // New import
+	"github.com/onlysumitg/GoMockAPI/utils/httputils"
 )
 
 // -----------------------------------------------------------------
@@ -73,10 +74,11 @@ func addHttpRateLimiter(app *application, router *chi.Mux) {
 // -----------------------------------------------------------------
 func addMiddleWares(app *application, router *chi.Mux) {
 
-	router.Use(app.sessionManager.LoadAndSave)
+	router.Use(app.LoadAndSaveSession)
 
 	// A good base middleware stack : inbuilt in chi
 	router.Use(middleware.RequestID)
+	router.Use(httputils.KeepPeerAddr) // X-Forwarded-For of actual url calls needs the peer
 	router.Use(middleware.RealIP)
 	router.Use(middleware.Logger)
 	router.Use(middleware.Recoverer)
@@ -122,8 +124,8 @@ func (app *application) routes() *chi.Mux {
 		AllowedOrigins: []string{"https://*", "http://*"},
 
 		// AllowOriginFunc:  func(r *http.Request, origin string) bool { return true },
-		AllowedMethods: []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders: []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
+		AllowedMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"},
+		AllowedHeaders: []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "X-Mock-Seed", "X-Admin-Token"},
 
 		ExposedHeaders: []string{"Link"},
 
@@ -154,14 +156,20 @@ func (app *application) routes() *chi.Mux {
 
 	app.EndPointResponseHandlers(router)
 
-	//app.WsHandlers(router)
+	app.WsHandlers(router)
 
 	app.UserHandlers(router)
 	app.UsersHandlers(router)
 	// app.RbacHandlers(router)
 
 	app.PostmantHandlers(router)
+	app.WsdlHandlers(router)
+	app.GrpcHandlers(router)
 
 	app.CollectionsHandlers(router)
+	app.ScenarioHandlers(router)
+	app.StoreHandlers(router)
+
+	app.AdminAPIHandlers(router)
 	return router // standard.Then(router)
 }
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/onlysumitg/GoMockAPI/internal/models"
+)
+
+// -----------------------------------------------------------------
+// SSE responses: events are flushed one by one after their delay,
+// until the script ends or the client goes away
+// -----------------------------------------------------------------
+func (app *application) writeEventStream(w http.ResponseWriter, r *http.Request, apiCall *models.ApiCall) {
+	events, err := models.ParseEventScript(apiCall.FinalResponseString)
+	if err != nil {
+		apiCall.LogError(fmt.Sprintf("Invalid event script: %s", err.Error()))
+		app.errorResponse(w, r, http.StatusInternalServerError, fmt.Sprintf("invalid event script: %s", err.Error()))
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		apiCall.LogError("Events can not be flushed, they are sent at the end")
+	}
+	flush := func() {
+		if ok {
+			flusher.Flush()
+		}
+	}
+
+	for key, value := range apiCall.GetHttpHeader() {
+		w.Header()[key] = value
+	}
+	w.Header().Set("Content-Type", apiCall.GetContentType())
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no") // nginx
+	w.WriteHeader(apiCall.StatusCode)
+	flush()
+
+	loop := apiCall.CurrentEndPoint.GetResponseByID(apiCall.ResponseID).EventLoop
+
+	toSend := models.EventsAfter(events, r.Header.Get("Last-Event-ID"))
+	if len(toSend) < len(events) {
+		apiCall.LogInfo(fmt.Sprintf("Resuming after Last-Event-ID %s", r.Header.Get("Last-Event-ID")))
+	}
+
+	sent := 0
+	for sent < models.MaxSSELoopEvents {
+		for _, e := range toSend {
+			if e.Delay > 0 {
+				select {
+				case <-time.After(time.Duration(e.Delay) * time.Millisecond):
+				case <-r.Context().Done():
+					apiCall.LogInfo(fmt.Sprintf("Client closed the event stream after %d events", sent))
+					return
+				}
+			}
+
+			_, err := io.WriteString(w, e.String())
+			if err != nil {
+				apiCall.LogInfo(fmt.Sprintf("Event stream closed after %d events: %s", sent, err.Error()))
+				return
+			}
+			flush()
+			sent++
+		}
+
+		if !loop {
+			break
+		}
+		toSend = events
+	}
+
+	apiCall.LogInfo(fmt.Sprintf("Event stream finished after %d events", sent))
+}
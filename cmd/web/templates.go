@@ -60,6 +60,14 @@ type templateData struct {
 	Collection  *models.Collection
 	Collections []*models.Collection
 
+	Scenario  *models.Scenario
+	Scenarios []*models.Scenario
+
+	WsEndPoint  *models.WsEndPoint
+	WsEndPoints []*models.WsEndPoint
+
+	StoreEntries []*models.StoreEntry
+
 	ComparisonOperators []string
 
 	LogEntries []string
@@ -5,6 +5,9 @@ import (
 	"html/template"
 	"net/http"
 	"time"
+
+	"github.com/onlysumitg/GoMockAPI/internal/iwebsocket"
+	"github.com/onlysumitg/GoMockAPI/internal/models"
 )
 
 // -----------------------------------------------------------------
@@ -73,6 +76,18 @@ func (app *application) getCollectionName(id string) string {
 	return collection.Name
 }
 
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (app *application) getScenarioName(id string) string {
+	scenario, err := app.scenarios.Get(id)
+	if err != nil {
+		return ""
+	}
+
+	return scenario.Name
+}
+
 // -----------------------------------------------------------------
 //
 // -----------------------------------------------------------------
@@ -85,6 +100,13 @@ func (app *application) getUserName(id string) string {
 	return user.Email
 }
 
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (app *application) getWsClientCount(id string) int {
+	return len(iwebsocket.MockClients.List(id))
+}
+
 // -----------------------------------------------------------------
 //
 // -----------------------------------------------------------------
@@ -101,14 +123,17 @@ func (app *application) indexBy1(id int) int {
 
 func (app *application) getFunctionMap() template.FuncMap {
 	var functions = template.FuncMap{
-		"humanDate":      humanDate,
-		"toJson":         toJson,
-		"yesNo":          yesNo,
-		"ispreformatted": IsPreFormatted,
-		"httpCodeText":   httpCodeText,
-		"collectionname": app.getCollectionName,
-		"username":       app.getUserName,
-		"indexby1":       app.indexBy1,
+		"humanDate":        humanDate,
+		"toJson":           toJson,
+		"yesNo":            yesNo,
+		"ispreformatted":   IsPreFormatted,
+		"httpCodeText":     httpCodeText,
+		"collectionname":   app.getCollectionName,
+		"scenarioname":     app.getScenarioName,
+		"wsclientcount":    app.getWsClientCount,
+		"username":         app.getUserName,
+		"indexby1":         app.indexBy1,
+		"randomGenerators": models.RandomGeneratorList,
 	}
 
 	return functions
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// -----------------------------------------------------------------
+// application on bolt files of the test, closed when the test ends
+// -----------------------------------------------------------------
+func newTestApplication(t *testing.T) *application {
+	t.Helper()
+
+	dir := t.TempDir()
+	db, err := bolt.Open(filepath.Join(dir, "test.db"), 0600, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logdb, err := bolt.Open(filepath.Join(dir, "log.db"), 0600, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		db.Close()
+		logdb.Close()
+	})
+
+	return baseAppConfig(parameters{}, db, logdb)
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/onlysumitg/GoMockAPI/internal/models"
+)
+
+// -----------------------------------------------------------------
+// writes the body in chunks, flushed one at a time at the pace of
+// the stream settings
+// -----------------------------------------------------------------
+type throttledWriter struct {
+	http.ResponseWriter
+
+	ctx      context.Context
+	settings models.StreamSettings
+
+	start     time.Time
+	lastChunk time.Time
+	written   int
+	total     int // body size, 0 when not known
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func newThrottledWriter(w http.ResponseWriter, r *http.Request, settings models.StreamSettings, total int) *throttledWriter {
+	return &throttledWriter{
+		ResponseWriter: w,
+		ctx:            r.Context(),
+		settings:       settings,
+		total:          total,
+	}
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (tw *throttledWriter) wait(until time.Time) error {
+	d := time.Until(until)
+	if d <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-tw.ctx.Done():
+		return tw.ctx.Err()
+	}
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (tw *throttledWriter) Flush() {
+	if flusher, ok := tw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// -----------------------------------------------------------------
+// headers go out after the time to first byte
+// -----------------------------------------------------------------
+func (tw *throttledWriter) WriteHeader(statusCode int) {
+	if !tw.start.IsZero() {
+		tw.ResponseWriter.WriteHeader(statusCode)
+		return
+	}
+
+	tw.wait(time.Now().Add(time.Duration(tw.settings.FirstByteDelay) * time.Millisecond))
+
+	// file responses set the length
+	if tw.total == 0 {
+		tw.total, _ = strconv.Atoi(tw.Header().Get("Content-Length"))
+	}
+
+	tw.ResponseWriter.WriteHeader(statusCode)
+	tw.Flush()
+	tw.start = time.Now()
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (tw *throttledWriter) Write(b []byte) (int, error) {
+	if tw.start.IsZero() {
+		tw.WriteHeader(http.StatusOK)
+	}
+
+	chunkSize := tw.settings.GetChunkSize()
+
+	sent := 0
+	for sent < len(b) {
+		end := sent + chunkSize
+		if end > len(b) {
+			end = len(b)
+		}
+
+		err := tw.wait(tw.settings.NextChunkAt(tw.start, tw.lastChunk, tw.written+end-sent, tw.total))
+		if err != nil {
+			return sent, err
+		}
+
+		n, err := tw.ResponseWriter.Write(b[sent:end])
+		sent += n
+		tw.written += n
+		if err != nil {
+			return sent, err
+		}
+		tw.Flush()
+		tw.lastChunk = time.Now()
+	}
+
+	return sent, nil
+}
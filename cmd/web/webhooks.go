@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/onlysumitg/GoMockAPI/internal/models"
+	"github.com/onlysumitg/GoMockAPI/internal/worker"
+)
+
+const webhookHandlerName = "webhook"
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (app *application) startWebhookWorker() {
+	app.worker = worker.NewSimple()
+	app.worker.Register(webhookHandlerName, app.sendWebhook)
+	app.worker.Start(context.Background())
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (app *application) scheduleWebhooks(apiCall *models.ApiCall) {
+	for _, call := range apiCall.WebhookCalls() {
+		job := worker.Job{
+			Handler: webhookHandlerName,
+			Args:    worker.Args{"webhook": call},
+		}
+
+		err := app.worker.PerformIn(job, time.Duration(call.Delay)*time.Millisecond)
+		if err != nil {
+			apiCall.LogError(fmt.Sprintf("Webhook %s not scheduled: %s", call.URL, err.Error()))
+		}
+	}
+}
+
+// -----------------------------------------------------------------
+// every attempt is logged, failed attempts are retried
+// -----------------------------------------------------------------
+func (app *application) sendWebhook(args worker.Args) error {
+	call, ok := args["webhook"].(*models.WebhookCall)
+	if !ok {
+		return fmt.Errorf("webhook job without a webhook")
+	}
+
+	attempt := call.Send()
+	app.webhookLogs.Save(attempt)
+
+	if !attempt.Failed() {
+		app.infoLog.Printf("Webhook %s %s attempt %d: %d", call.Method, call.URL, call.Attempt, attempt.StatusCode)
+		return nil
+	}
+
+	app.errorLog.Printf("Webhook %s %s attempt %d failed: %d %s", call.Method, call.URL, call.Attempt, attempt.StatusCode, attempt.Error)
+	if call.Attempt > call.Retries {
+		return nil
+	}
+
+	retry := *call
+	retry.Attempt++
+	return app.worker.PerformIn(worker.Job{
+		Handler: webhookHandlerName,
+		Args:    worker.Args{"webhook": &retry},
+	}, call.NextRetryDelay())
+}
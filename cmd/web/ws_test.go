@@ -0,0 +1,61 @@
+package main
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/onlysumitg/GoMockAPI/internal/iwebsocket"
+	"github.com/onlysumitg/GoMockAPI/internal/models"
+)
+
+// messages after a rule closed the connection are not handled
+func Test_WsConnect_CloseRule(t *testing.T) {
+	app := newTestApplication(t)
+
+	wsEndPoint := &models.WsEndPoint{
+		Name: "chat",
+		Rules: []*models.WsRule{
+			{Operator: "EQUALS_TO", Value: "bye", Reply: "ciao", CloseCode: websocket.CloseGoingAway},
+			{Operator: "EQUALS_TO", Value: "slow", Reply: "late", Delay: 3000},
+		},
+	}
+	if err := app.wsEndPoints.Save(wsEndPoint); err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(app.routes())
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws/chat", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	conn.WriteMessage(websocket.TextMessage, []byte("bye"))
+	conn.WriteMessage(websocket.TextMessage, []byte("slow"))
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, message, err := conn.ReadMessage()
+	if err != nil || string(message) != "ciao" {
+		t.Fatalf("expected ciao but got %q %v", message, err)
+	}
+
+	_, message, err = conn.ReadMessage()
+	if !websocket.IsCloseError(err, websocket.CloseGoingAway) {
+		t.Fatalf("expected close %d but got %q %v", websocket.CloseGoingAway, message, err)
+	}
+
+	// the server ends the connection on the answer to its close frame,
+	// not after the delay of the slow rule
+	deadline := time.Now().Add(time.Second)
+	for len(iwebsocket.MockClients.List(wsEndPoint.ID)) > 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("connection still open after the close answer")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
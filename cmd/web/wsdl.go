@@ -0,0 +1,216 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/onlysumitg/GoMockAPI/internal/models"
+	"github.com/onlysumitg/GoMockAPI/utils/stringutils"
+)
+
+var wsdlClient = &http.Client{Timeout: 30 * time.Second}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (app *application) WsdlHandlers(router *chi.Mux) {
+	router.Route("/wsdl", func(r chi.Router) {
+		r.Use(app.RequireAuthentication)
+
+		// CSRF
+		r.Use(noSurf)
+		r.Get("/", app.wsdlUploader)
+		r.Post("/upload", app.wsdlUpload)
+		r.Post("/webget", app.wsdlFromWeb)
+	})
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (app *application) wsdlUploader(w http.ResponseWriter, r *http.Request) {
+	data := app.newTemplateData(r)
+
+	app.render(w, r, http.StatusOK, "wsdl_upload.tmpl", data)
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (app *application) wsdlUpload(w http.ResponseWriter, r *http.Request) {
+	user, err := app.GetUser(r)
+	if err != nil {
+		app.UnauthorizedError(w, r)
+		return
+	}
+
+	file, fileHeader, err := r.FormFile("file")
+	if err != nil {
+		app.sessionManager.Put(r.Context(), "error", fmt.Sprintf("Error reading file %s", err.Error()))
+		app.goBack(w, r, http.StatusSeeOther)
+		return
+	}
+	defer file.Close()
+
+	if fileHeader.Size > MAX_UPLOAD_SIZE {
+		app.sessionManager.Put(r.Context(), "error", fmt.Sprintf("The uploaded file is too big: %s. Please use an file less than 5MB in size", fileHeader.Filename))
+		app.goBack(w, r, http.StatusSeeOther)
+		return
+	}
+
+	wsdl, err := io.ReadAll(file)
+	if err != nil {
+		app.sessionManager.Put(r.Context(), "error", fmt.Sprintf("Error reading file %s", err.Error()))
+		app.goBack(w, r, http.StatusSeeOther)
+		return
+	}
+
+	data := app.newTemplateData(r)
+	data.Messages = app.ImportWsdl(wsdl, user)
+	app.render(w, r, http.StatusOK, "user_message.tmpl", data)
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (app *application) wsdlFromWeb(w http.ResponseWriter, r *http.Request) {
+	user, err := app.GetUser(r)
+	if err != nil {
+		app.UnauthorizedError(w, r)
+		return
+	}
+
+	err = r.ParseForm()
+	if err != nil {
+		app.sessionManager.Put(r.Context(), "error", fmt.Sprintf("001 Error processing form %s", err.Error()))
+		app.goBack(w, r, http.StatusSeeOther)
+		return
+	}
+
+	url := strings.TrimSpace(r.PostForm.Get("url"))
+	if url == "" {
+		app.sessionManager.Put(r.Context(), "error", "Invalid url")
+		app.goBack(w, r, http.StatusSeeOther)
+		return
+	}
+
+	response, err := wsdlClient.Get(url)
+	if err != nil {
+		app.sessionManager.Put(r.Context(), "error", err.Error())
+		app.goBack(w, r, http.StatusSeeOther)
+		return
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		app.sessionManager.Put(r.Context(), "error", fmt.Sprintf("%s answered %s", url, response.Status))
+		app.goBack(w, r, http.StatusSeeOther)
+		return
+	}
+
+	wsdl, err := io.ReadAll(io.LimitReader(response.Body, MAX_UPLOAD_SIZE))
+	if err != nil {
+		app.sessionManager.Put(r.Context(), "error", err.Error())
+		app.goBack(w, r, http.StatusSeeOther)
+		return
+	}
+
+	data := app.newTemplateData(r)
+	data.Messages = app.ImportWsdl(wsdl, user)
+	app.render(w, r, http.StatusOK, "user_message.tmpl", data)
+}
+
+// ------------------------------------------------------
+// a collection for the WSDL with an endpoint for every operation
+// ------------------------------------------------------
+func (app *application) ImportWsdl(data []byte, currentUser *models.User) []string {
+	messageList := make([]string, 0)
+
+	wsdl, err := models.ParseWsdl(data)
+	if err != nil {
+		messageList = append(messageList, fmt.Sprintf("Error: %s", err.Error()))
+		return messageList
+	}
+
+	collectionName := stringutils.RemoveSpecialChars(stringutils.RemoveMultipleSpaces(wsdl.Name))
+	for _, c := range app.collectionsModel.List() {
+		if strings.EqualFold(c.Name, collectionName) {
+			collectionName = fmt.Sprintf("%s_%s", collectionName, stringutils.RandomString(6))
+		}
+	}
+
+	collection := &models.Collection{
+		Name: collectionName,
+		Desc: fmt.Sprintf("%s WSDL", wsdl.Name),
+	}
+	messageList = append(messageList, fmt.Sprintf("Info: creating collection %s", collection.Name))
+	app.collectionsModel.Save(collection)
+
+	for _, operation := range wsdl.Operations {
+		service := stringutils.RemoveSpecialChars(operation.Service)
+
+		actualURL := operation.Location
+		if !strings.HasPrefix(strings.ToLower(actualURL), "http://") && !strings.HasPrefix(strings.ToLower(actualURL), "https://") {
+			actualURL = fmt.Sprintf("http://localhost/%s", service)
+		}
+
+		ep := &models.EndPoint{
+			Name:                    fmt.Sprintf("%s_%s", service, operation.Name),
+			CollectionID:            collection.ID,
+			CollectionName:          collection.Name,
+			Method:                  http.MethodPost,
+			ActualURL:               actualURL,
+			SampleRequest:           operation.Request,
+			SampleRequestType:       models.SoapType,
+			SampleRequestHeader:     "{}",
+			SampleRequestHeaderType: "JSON",
+			SoapService:             service,
+			SoapAction:              operation.Action,
+			SoapElement:             operation.Element,
+			SoapVersion:             operation.Version,
+		}
+
+		ep.SetResponse(&models.EndPointResponse{
+			Name:               "DEFAULT",
+			HttpCode:           http.StatusOK,
+			Response:           operation.Response,
+			ResponseType:       models.SoapType,
+			ResponseHeader:     "{}",
+			ResponseHeaderType: "JSON",
+		})
+		// the fault is a template so it follows the SOAP version
+		ep.SetResponse(&models.EndPointResponse{
+			Name:               "FAULT",
+			HttpCode:           http.StatusInternalServerError,
+			Response:           `{{soapFault "Server" "Server error"}}`,
+			ResponseType:       models.SoapType,
+			UseTemplate:        true,
+			ResponseHeader:     "{}",
+			ResponseHeaderType: "JSON",
+		})
+
+		messageList = append(messageList, fmt.Sprintf("Info: creating endpoint %s for operation %s", ep.Name, operation.Name))
+
+		ep.Prepare()
+		if !ep.Valid() {
+			for k, v := range ep.Validator.FieldErrors {
+				messageList = append(messageList, fmt.Sprintf("Error: %s %s %s", ep.Name, k, v))
+			}
+			continue
+		}
+
+		_, err := app.endpoints.Save(ep, currentUser.Email)
+		if err != nil {
+			messageList = append(messageList, fmt.Sprintf("Error: %s %s", ep.Name, err.Error()))
+		}
+	}
+
+	app.invalidateEndPointCache()
+	messageList = append(messageList, fmt.Sprintf("Info: call the operations on %s/api/%s/<service>", app.hostURL, collection.Name))
+
+	return messageList
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/onlysumitg/GoMockAPI/internal/models"
+)
+
+func Test_ImportWsdl_SoapRouting(t *testing.T) {
+	app := newTestApplication(t)
+
+	data, err := os.ReadFile("../../testdata/wsdl/stockquote.wsdl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, message := range app.ImportWsdl(data, &models.User{Email: "test@local"}) {
+		if strings.HasPrefix(message, "Error") {
+			t.Fatalf("import: %s", message)
+		}
+	}
+
+	server := httptest.NewServer(app.routes())
+	defer server.Close()
+
+	request11 := `<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/"><s:Body><q:GetQuote xmlns:q="http://example.com/stockquote"><q:symbol>ACME</q:symbol></q:GetQuote></s:Body></s:Envelope>`
+	request12 := `<e:Envelope xmlns:e="http://www.w3.org/2003/05/soap-envelope"><e:Body><PlaceOrder xmlns="http://example.com/stockquote"/></e:Body></e:Envelope>`
+
+	tests := []struct {
+		name        string
+		header      map[string]string
+		body        string
+		statusCode  int
+		contentType string
+		containsX   string
+	}{
+		{"by element", map[string]string{"Content-Type": "text/xml"}, request11, 200, "text/xml", "<m:price>0.0</m:price>"},
+		{"by SOAPAction", map[string]string{"SOAPAction": `"http://example.com/stockquote/PlaceOrder"`}, request11, 200, "text/xml", "<m:orderId>0</m:orderId>"},
+		{"action of 1.2", map[string]string{"Content-Type": `application/soap+xml; action="http://example.com/stockquote/GetQuote"`}, request12, 200, "application/soap+xml", "<m:price>0.0</m:price>"},
+		{"1.2 by element", map[string]string{"Content-Type": "application/soap+xml"}, request12, 200, "application/soap+xml", "http://www.w3.org/2003/05/soap-envelope"},
+		{"unknown operation", map[string]string{"SOAPAction": "urn:nope"}, `<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/"><s:Body><Nope/></s:Body></s:Envelope>`, 404, "", ""},
+	}
+
+	for _, test := range tests {
+		r, _ := http.NewRequest(http.MethodPost, server.URL+"/api/StockQuote/StockQuoteService", strings.NewReader(test.body))
+		for k, v := range test.header {
+			r.Header.Set(k, v)
+		}
+		response, err := http.DefaultClient.Do(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(response.Body)
+		response.Body.Close()
+
+		if response.StatusCode != test.statusCode {
+			t.Errorf("%s: expected %d but got %d %s", test.name, test.statusCode, response.StatusCode, body)
+			continue
+		}
+		if !strings.HasPrefix(response.Header.Get("Content-Type"), test.contentType) {
+			t.Errorf("%s: expected content type %s but got %s", test.name, test.contentType, response.Header.Get("Content-Type"))
+		}
+		if !strings.Contains(string(body), test.containsX) {
+			t.Errorf("%s: expected %q in %s", test.name, test.containsX, body)
+		}
+	}
+
+	// the FAULT template follows the version of the request
+	for _, endPoint := range app.endpoints.List() {
+		if endPoint.SoapElement != "GetQuote" {
+			continue
+		}
+		for _, response := range endPoint.ResponseMap {
+			if response.Name == "DEFAULT" {
+				endPoint.RemoveResponse(response.ID)
+			}
+		}
+		if _, err := app.endpoints.Save(endPoint, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	app.invalidateEndPointCache()
+
+	faults := []struct {
+		body  string
+		codeX string
+	}{
+		{request11, "<faultcode>soap:Server</faultcode>"},
+		{strings.ReplaceAll(request12, "PlaceOrder", "GetQuote"), "<soap:Value>soap:Receiver</soap:Value>"},
+	}
+	for _, fault := range faults {
+		response, err := http.Post(server.URL+"/api/StockQuote/StockQuoteService", "text/xml", strings.NewReader(fault.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(response.Body)
+		response.Body.Close()
+
+		if response.StatusCode != http.StatusInternalServerError || !strings.Contains(string(body), fault.codeX) {
+			t.Errorf("expected 500 with %s but got %d %s", fault.codeX, response.StatusCode, body)
+		}
+	}
+}
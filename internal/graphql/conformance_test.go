@@ -0,0 +1,229 @@
+package graphql
+
+import (
+	"testing"
+)
+
+// fragments, directives and variables with defaults, as the spec and
+// graphql-js answer them
+func Test_Execute_Conformance(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		variables map[string]any
+		mocks     string
+		expected  string
+	}{
+		// fragments
+		{
+			"inline fragment without a type condition",
+			`{ me { ... { id } name } }`, nil,
+			`{"User": {"id": "1", "name": "Ada"}}`,
+			`{"data":{"me":{"id":"1","name":"Ada"}}}`,
+		},
+		{
+			"nested fragment spreads",
+			`{ me { ...A } } fragment A on User { id ...B } fragment B on User { name address { ...C } } fragment C on Address { city }`, nil,
+			`{"User": {"id": "1", "name": "Ada"}, "Address.city": "Oslo"}`,
+			`{"data":{"me":{"id":"1","name":"Ada","address":{"city":"Oslo"}}}}`,
+		},
+		{
+			"a fragment spread twice is collected once",
+			`{ me { ...A ...A ... on User { ...A } } } fragment A on User { id }`, nil,
+			`{"User.id": "1"}`,
+			`{"data":{"me":{"id":"1"}}}`,
+		},
+		{
+			"the same fragment at different levels",
+			`{ me { ...A friends { ...A } } } fragment A on User { id }`, nil,
+			`{"User.id": "1", "User.friends": [{}]}`,
+			`{"data":{"me":{"id":"1","friends":[{"id":"1"}]}}}`,
+		},
+		{
+			"fragments that do not apply to the object",
+			`{ search(text: "x") { ...P ... on User { name } } } fragment P on Post { title }`, nil,
+			`{"Query.search": [{"__typename": "User", "name": "Ada"}]}`,
+			`{"data":{"search":[{"name":"Ada"}]}}`,
+		},
+		{
+			"fragment on a union inside a fragment on an interface",
+			`{ search(text: "x") { ... on Node { id ... on Post { title } } } }`, nil,
+			`{"Query.search": [{"__typename": "Post", "id": "2", "title": "Hi"}]}`,
+			`{"data":{"search":[{"id":"2","title":"Hi"}]}}`,
+		},
+
+		// directives
+		{
+			"@skip wins over @include",
+			`{ me { id name @skip(if: true) @include(if: true) email @skip(if: false) @include(if: true) } }`, nil,
+			`{"User": {"id": "1", "name": "Ada", "email": "a@b"}}`,
+			`{"data":{"me":{"id":"1","email":"a@b"}}}`,
+		},
+		{
+			"@include on a fragment spread and @skip on an inline fragment",
+			`query ($on: Boolean!) { me { id ...A @include(if: $on) ... on User @skip(if: $on) { email } } } fragment A on User { name }`,
+			map[string]any{"on": true},
+			`{"User": {"id": "1", "name": "Ada", "email": "a@b"}}`,
+			`{"data":{"me":{"id":"1","name":"Ada"}}}`,
+		},
+		{
+			"a field kept by one of its merged selections",
+			`{ me { name @skip(if: true) name } }`, nil,
+			`{"User.name": "Ada"}`,
+			`{"data":{"me":{"name":"Ada"}}}`,
+		},
+
+		// variables with defaults
+		{
+			"default when the variable is missing",
+			`query ($first: Int = 1, $skip: Boolean = false) { users(first: $first) { name @skip(if: $skip) } }`, nil,
+			`{"User.name": "Ada"}`,
+			`{"data":{"users":[{"name":"Ada"},{"name":"Ada"}]}}`,
+		},
+		{
+			"a provided value wins over the default",
+			`query ($skip: Boolean = false) { me { name @skip(if: $skip) id } }`,
+			map[string]any{"skip": true},
+			`{"User": {"id": "1", "name": "Ada"}}`,
+			`{"data":{"me":{"id":"1"}}}`,
+		},
+		{
+			"non-null variable with a default",
+			`query ($skip: Boolean! = true) { me { name @skip(if: $skip) id } }`, nil,
+			`{"User": {"id": "1", "name": "Ada"}}`,
+			`{"data":{"me":{"id":"1"}}}`,
+		},
+		{
+			"explicit null for a nullable variable with a default",
+			`query ($skip: Boolean = true) { me { name @include(if: $skip) id } }`,
+			map[string]any{"skip": nil},
+			`{"User": {"id": "1", "name": "Ada"}}`,
+			`{"data":{"me":{"id":"1"}}}`,
+		},
+		{
+			"object and list defaults",
+			`query ($f: Filter = {tags: ["x", "y"]}) { settings(filter: $f) }`, nil,
+			`{"Query.settings": "ok"}`,
+			`{"data":{"settings":"ok"}}`,
+		},
+	}
+
+	for _, test := range tests {
+		result := testExecute(t, test.query, "", test.variables, test.mocks)
+		if result != test.expected {
+			t.Errorf("%s:\nexpected %s\n     got %s", test.name, test.expected, result)
+		}
+	}
+}
+
+func Test_Execute_Conformance_Errors(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		variables map[string]any
+		expected  string
+	}{
+		// fragments
+		{
+			"unknown fragment",
+			`{ me { ...A } }`, nil,
+			`{"errors":[{"message":"Unknown fragment \"A\".","locations":[{"line":1,"column":8}]}]}`,
+		},
+		{
+			"fragment on an unknown type",
+			`{ me { ...A } } fragment A on Nope { id }`, nil,
+			`{"errors":[{"message":"Unknown type \"Nope\".","locations":[{"line":1,"column":17}]}]}`,
+		},
+		{
+			"fragment on a scalar",
+			`{ me { ... on String { id } } }`, nil,
+			`{"errors":[{"message":"Fragment cannot condition on non composite type \"String\".","locations":[{"line":1,"column":8}]}]}`,
+		},
+		{
+			"fragments that spread each other",
+			`{ me { ...A } } fragment A on User { ...B } fragment B on User { ...A }`, nil,
+			`{"errors":[{"message":"Cannot spread fragment \"A\" within itself.","locations":[{"line":1,"column":66}]}]}`,
+		},
+		{
+			"inline fragment on a type the object can never be",
+			`{ me { ... on Post { title } } }`, nil,
+			`{"errors":[{"message":"Fragment cannot be spread here as objects of type \"User\" can never be of type \"Post\".","locations":[{"line":1,"column":8}]}]}`,
+		},
+		{
+			"fragment spread on a type the object can never be",
+			`{ me { ...P } } fragment P on Post { title }`, nil,
+			`{"errors":[{"message":"Fragment \"P\" cannot be spread here as objects of type \"User\" can never be of type \"Post\".","locations":[{"line":1,"column":8}]}]}`,
+		},
+		{
+			"unknown field in an inline fragment",
+			`{ me { ... on User { title } } }`, nil,
+			`{"errors":[{"message":"Cannot query field \"title\" on type \"User\".","locations":[{"line":1,"column":22}]}]}`,
+		},
+
+		// directives
+		{
+			"unknown directive",
+			`{ me { id @foo } }`, nil,
+			`{"errors":[{"message":"Unknown directive \"@foo\".","locations":[{"line":1,"column":11}]}]}`,
+		},
+		{
+			"@skip without if",
+			`{ me { id @skip } }`, nil,
+			`{"errors":[{"message":"Directive \"@skip\" argument \"if\" of type \"Boolean!\" is required, but it was not provided.","locations":[{"line":1,"column":11}]}]}`,
+		},
+		{
+			"unknown argument of @include",
+			`{ me { id @include(if: true, when: true) } }`, nil,
+			`{"errors":[{"message":"Unknown argument \"when\" on directive \"@include\".","locations":[{"line":1,"column":11}]}]}`,
+		},
+		{
+			"undefined variable in a directive",
+			`{ me { id @skip(if: $no) } }`, nil,
+			`{"errors":[{"message":"Variable \"$no\" is not defined.","locations":[{"line":1,"column":11}]}]}`,
+		},
+
+		// variables with defaults
+		{
+			"variable in a default value",
+			`query ($a: Int, $b: Int = $a) { me { id } }`, nil,
+			`{"errors":[{"message":"Syntax Error: Unexpected \"$\".","locations":[{"line":1,"column":27}]}]}`,
+		},
+		{
+			"explicit null for a non-null variable with a default",
+			`query ($skip: Boolean! = true) { me { id @skip(if: $skip) } }`,
+			map[string]any{"skip": nil},
+			`{"errors":[{"message":"Variable \"$skip\" of non-null type \"Boolean!\" must not be null.","locations":[{"line":1,"column":8}]}]}`,
+		},
+		{
+			"variable of an output type",
+			`query ($u: User) { me { id } }`, nil,
+			`{"errors":[{"message":"Variable \"$u\" cannot be non-input type \"User\".","locations":[{"line":1,"column":8}]}]}`,
+		},
+	}
+
+	for _, test := range tests {
+		result := testExecute(t, test.query, "", test.variables, "")
+		if result != test.expected {
+			t.Errorf("%s:\nexpected %s\n     got %s", test.name, test.expected, result)
+		}
+	}
+}
+
+// directives defined in the SDL are not unknown
+func Test_Execute_SchemaDirectives(t *testing.T) {
+	schema, err := ParseSchema(`directive @upper on FIELD
+type Query { name: String }`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response := schema.Execute(&Request{Query: `{ name @upper }`}, Options{Mocks: map[string]any{"Query.name": "Ada"}})
+	if len(response.Errors) != 0 {
+		t.Errorf("expected no errors but got %v", response.Errors[0].Message)
+	}
+
+	response = schema.Execute(&Request{Query: `{ name @lower }`}, Options{})
+	if len(response.Errors) != 1 || response.Errors[0].Message != `Unknown directive "@lower".` {
+		t.Errorf("expected an unknown directive error but got %v", response.Errors)
+	}
+}
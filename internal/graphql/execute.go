@@ -0,0 +1,706 @@
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// list fields without a mock value get this many items
+const DefaultListLength = 2
+
+// ------------------------------------------------------
+// body of a GraphQL POST
+// ------------------------------------------------------
+type Request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// ------------------------------------------------------
+// data is left out when the request could not be run, and null when
+// a null for a non-null field reached the root
+// ------------------------------------------------------
+type Response struct {
+	Errors []*MockError `json:"errors,omitempty"`
+	Data   *Object      `json:"data,omitempty"`
+
+	executed bool
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (r *Response) MarshalJSON() ([]byte, error) {
+	o := NewObject()
+	if len(r.Errors) > 0 {
+		o.Set("errors", r.Errors)
+	}
+	if r.Data != nil || r.executed {
+		o.Set("data", r.Data)
+	}
+	return o.MarshalJSON()
+}
+
+// ------------------------------------------------------
+// Error with the extensions a mock can add
+// ------------------------------------------------------
+type MockError struct {
+	Error
+	Extensions map[string]any `json:"extensions,omitempty"`
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+type Options struct {
+	// Mock values by type ("User": {"name": "Ada"}) and by field
+	// ("Query.user": {...}, "User.name": "Ada"). The "errors" key is a
+	// list of errors added to the response.
+	Mocks map[string]any
+
+	// value of a scalar or enum field without a mock value
+	Generate func(t *Type, field string) any
+
+	ListLength int
+}
+
+// ------------------------------------------------------
+// JSON object that keeps the order of the selection set
+// ------------------------------------------------------
+type Object struct {
+	keys   []string
+	values map[string]any
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func NewObject() *Object {
+	return &Object{values: make(map[string]any)}
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (o *Object) Set(key string, value any) {
+	if _, found := o.values[key]; !found {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = value
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (o *Object) Get(key string) (any, bool) {
+	value, found := o.values[key]
+	return value, found
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (o *Object) Keys() []string {
+	return o.keys
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (o *Object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		v, err := json.Marshal(o.values[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// ------------------------------------------------------
+// ErrorResponse is a response with only the error
+// ------------------------------------------------------
+func ErrorResponse(err error) *Response {
+	e, ok := err.(*Error)
+	if !ok {
+		e = &Error{Message: err.Error()}
+	}
+	return &Response{Errors: []*MockError{{Error: *e}}}
+}
+
+// ------------------------------------------------------
+// runs the operation of the request against the mock values
+// ------------------------------------------------------
+func (s *Schema) Execute(request *Request, options Options) *Response {
+	document, err := ParseQuery(request.Query)
+	if err != nil {
+		return ErrorResponse(err)
+	}
+
+	operation, err := document.Operation(request.OperationName)
+	if err != nil {
+		return ErrorResponse(err)
+	}
+
+	root := s.RootType(operation.Type)
+	if root == nil {
+		return ErrorResponse(&Error{Message: fmt.Sprintf("Schema is not configured for %ss.", operation.Type), Locations: []Location{operation.Location}})
+	}
+
+	e := &executor{schema: s, document: document, options: options}
+	if e.options.Mocks == nil {
+		e.options.Mocks = make(map[string]any)
+	}
+	if e.options.ListLength <= 0 {
+		e.options.ListLength = DefaultListLength
+	}
+
+	e.validate(operation, root)
+	if len(e.errors) == 0 {
+		e.coerceVariables(operation, request.Variables)
+	}
+	if len(e.errors) > 0 {
+		return &Response{Errors: e.errors}
+	}
+
+	data := e.executeSelectionSet(root, nil, operation.SelectionSet, []any{})
+
+	response := &Response{Data: data, executed: true}
+	response.Errors = append(e.mockErrors(), e.errors...)
+	return response
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+type executor struct {
+	schema    *Schema
+	document  *Document
+	options   Options
+	variables map[string]any
+
+	// variables the operation defines
+	defined map[string]bool
+
+	errors []*MockError
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (e *executor) addError(message string, location *Location, path []any) {
+	err := &MockError{Error: Error{Message: message}}
+	if location != nil {
+		err.Locations = []Location{*location}
+	}
+	if path != nil {
+		err.Path = append([]any{}, path...)
+	}
+	e.errors = append(e.errors, err)
+}
+
+// ------------------------------------------------------
+// errors key of the mock values
+// ------------------------------------------------------
+func (e *executor) mockErrors() []*MockError {
+	errors := make([]*MockError, 0)
+
+	list, ok := e.options.Mocks["errors"].([]any)
+	if !ok {
+		return errors
+	}
+
+	for _, item := range list {
+		err := &MockError{}
+		switch v := item.(type) {
+		case string:
+			err.Message = v
+		case map[string]any:
+			err.Message = fmt.Sprint(v["message"])
+			if path, ok := v["path"].([]any); ok {
+				err.Path = path
+			}
+			if extensions, ok := v["extensions"].(map[string]any); ok {
+				err.Extensions = extensions
+			}
+		default:
+			err.Message = fmt.Sprint(v)
+		}
+		errors = append(errors, err)
+	}
+	return errors
+}
+
+// ------------------------------------------------------
+// fields, fragments and arguments exist in the schema
+// ------------------------------------------------------
+func (e *executor) validate(operation *Operation, root *Type) {
+	e.defined = make(map[string]bool)
+	for _, v := range operation.Variables {
+		if e.defined[v.Name] {
+			e.addError(fmt.Sprintf("There can be only one variable named \"$%s\".", v.Name), &v.Location, nil)
+		}
+		e.defined[v.Name] = true
+
+		t, found := e.schema.Types[v.Type.NamedType()]
+		if !found {
+			e.addError(fmt.Sprintf("Unknown type \"%s\".", v.Type.NamedType()), &v.Location, nil)
+			continue
+		}
+		if t.Kind == KindObject || t.IsAbstract() {
+			e.addError(fmt.Sprintf("Variable \"$%s\" cannot be non-input type \"%s\".", v.Name, v.Type.String()), &v.Location, nil)
+		}
+	}
+
+	e.validateSelections(root, operation.SelectionSet, map[string]bool{})
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (e *executor) validateSelections(t *Type, selections []*Selection, spreading map[string]bool) {
+	for _, s := range selections {
+		e.validateDirectives(s)
+
+		switch s.Kind {
+		case SelectionFragmentSpread:
+			fragment, found := e.document.Fragments[s.Name]
+			if !found {
+				e.addError(fmt.Sprintf("Unknown fragment \"%s\".", s.Name), &s.Location, nil)
+				continue
+			}
+			if spreading[s.Name] {
+				e.addError(fmt.Sprintf("Cannot spread fragment \"%s\" within itself.", s.Name), &s.Location, nil)
+				continue
+			}
+			condition := e.fragmentType(fragment.TypeCondition, &fragment.Location)
+			if condition == nil {
+				continue
+			}
+			if !e.schema.TypesOverlap(t, condition) {
+				e.addError(fmt.Sprintf("Fragment \"%s\" cannot be spread here as objects of type \"%s\" can never be of type \"%s\".", s.Name, t.Name, condition.Name), &s.Location, nil)
+				continue
+			}
+
+			spreading[s.Name] = true
+			e.validateSelections(condition, fragment.SelectionSet, spreading)
+			delete(spreading, s.Name)
+
+		case SelectionInlineFragment:
+			condition := t
+			if s.TypeCondition != "" {
+				condition = e.fragmentType(s.TypeCondition, &s.Location)
+				if condition == nil {
+					continue
+				}
+				if !e.schema.TypesOverlap(t, condition) {
+					e.addError(fmt.Sprintf("Fragment cannot be spread here as objects of type \"%s\" can never be of type \"%s\".", t.Name, condition.Name), &s.Location, nil)
+					continue
+				}
+			}
+			e.validateSelections(condition, s.SelectionSet, spreading)
+
+		case SelectionField:
+			if s.Name == "__typename" {
+				continue
+			}
+
+			field := e.schema.Field(t, s.Name)
+			if field == nil {
+				e.addError(fmt.Sprintf("Cannot query field \"%s\" on type \"%s\".", s.Name, t.Name), &s.Location, nil)
+				continue
+			}
+
+			e.validateArguments(t, field, s)
+
+			named := e.schema.Types[field.Type.NamedType()]
+			switch {
+			case named.IsLeaf() && len(s.SelectionSet) > 0:
+				e.addError(fmt.Sprintf("Field \"%s\" must not have a selection since type \"%s\" has no subfields.", s.Name, field.Type.String()), &s.Location, nil)
+			case !named.IsLeaf() && len(s.SelectionSet) == 0:
+				e.addError(fmt.Sprintf("Field \"%s\" of type \"%s\" must have a selection of subfields. Did you mean \"%s { ... }\"?", s.Name, field.Type.String(), s.Name), &s.Location, nil)
+			case !named.IsLeaf():
+				e.validateSelections(named, s.SelectionSet, spreading)
+			}
+		}
+	}
+}
+
+// ------------------------------------------------------
+// @skip and @include need if:, other directives must be in the SDL
+// ------------------------------------------------------
+func (e *executor) validateDirectives(s *Selection) {
+	for _, d := range s.Directives {
+		switch {
+		case d.Name == "skip" || d.Name == "include":
+			given := false
+			for _, a := range d.Arguments {
+				if a.Name != "if" {
+					e.addError(fmt.Sprintf("Unknown argument \"%s\" on directive \"@%s\".", a.Name, d.Name), &d.Location, nil)
+				}
+				given = given || a.Name == "if"
+			}
+			if !given {
+				e.addError(fmt.Sprintf("Directive \"@%s\" argument \"if\" of type \"Boolean!\" is required, but it was not provided.", d.Name), &d.Location, nil)
+			}
+		case !e.schema.directives[d.Name]:
+			e.addError(fmt.Sprintf("Unknown directive \"@%s\".", d.Name), &d.Location, nil)
+		}
+		e.validateVariables(d.Arguments, &d.Location)
+	}
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (e *executor) fragmentType(name string, location *Location) *Type {
+	t, found := e.schema.Types[name]
+	if !found {
+		e.addError(fmt.Sprintf("Unknown type \"%s\".", name), location, nil)
+		return nil
+	}
+	if t.IsLeaf() || t.Kind == KindInputObject {
+		e.addError(fmt.Sprintf("Fragment cannot condition on non composite type \"%s\".", name), location, nil)
+		return nil
+	}
+	return t
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (e *executor) validateArguments(t *Type, field *Field, s *Selection) {
+	given := make(map[string]bool)
+	for _, a := range s.Arguments {
+		given[a.Name] = true
+
+		known := false
+		for _, arg := range field.Args {
+			known = known || arg.Name == a.Name
+		}
+		if !known {
+			e.addError(fmt.Sprintf("Unknown argument \"%s\" on field \"%s.%s\".", a.Name, t.Name, field.Name), &s.Location, nil)
+		}
+	}
+
+	for _, arg := range field.Args {
+		if arg.Type.NonNull && arg.Default == nil && !given[arg.Name] {
+			e.addError(fmt.Sprintf("Field \"%s\" argument \"%s\" of type \"%s\" is required, but it was not provided.", field.Name, arg.Name, arg.Type.String()), &s.Location, nil)
+		}
+	}
+
+	e.validateVariables(s.Arguments, &s.Location)
+}
+
+// ------------------------------------------------------
+// variables in arguments are defined by the operation
+// ------------------------------------------------------
+func (e *executor) validateVariables(arguments []*Argument, location *Location) {
+	var check func(v *Value)
+	check = func(v *Value) {
+		switch v.Kind {
+		case ValueVariable:
+			if !e.defined[v.Raw] {
+				e.addError(fmt.Sprintf("Variable \"$%s\" is not defined.", v.Raw), location, nil)
+			}
+		case ValueList:
+			for _, item := range v.List {
+				check(item)
+			}
+		case ValueObject:
+			for _, f := range v.Fields {
+				check(f.Value)
+			}
+		}
+	}
+
+	for _, a := range arguments {
+		check(a.Value)
+	}
+}
+
+// ------------------------------------------------------
+// provided values and defaults, required ones must be there
+// ------------------------------------------------------
+func (e *executor) coerceVariables(operation *Operation, provided map[string]any) {
+	e.variables = make(map[string]any)
+
+	for _, v := range operation.Variables {
+		value, found := provided[v.Name]
+		if !found && v.Default != nil {
+			value, found = v.Default.Resolve(nil), true
+		}
+
+		if v.Type.NonNull && !found {
+			e.addError(fmt.Sprintf("Variable \"$%s\" of required type \"%s\" was not provided.", v.Name, v.Type.String()), &v.Location, nil)
+			continue
+		}
+		if v.Type.NonNull && value == nil {
+			e.addError(fmt.Sprintf("Variable \"$%s\" of non-null type \"%s\" must not be null.", v.Name, v.Type.String()), &v.Location, nil)
+			continue
+		}
+		if found {
+			e.variables[v.Name] = value
+		}
+	}
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+type collectedField struct {
+	key    string
+	fields []*Selection
+}
+
+// ------------------------------------------------------
+// fields of the selection set for the object type t, fields with the
+// same response key are merged
+// ------------------------------------------------------
+func (e *executor) collectFields(t *Type, selections []*Selection, collected []*collectedField, visited map[string]bool) []*collectedField {
+	for _, s := range selections {
+		if !e.included(s) {
+			continue
+		}
+
+		switch s.Kind {
+		case SelectionField:
+			merged := false
+			for _, c := range collected {
+				if c.key == s.ResponseKey() {
+					c.fields = append(c.fields, s)
+					merged = true
+					break
+				}
+			}
+			if !merged {
+				collected = append(collected, &collectedField{key: s.ResponseKey(), fields: []*Selection{s}})
+			}
+
+		case SelectionInlineFragment:
+			if e.schema.TypeApplies(t, s.TypeCondition) {
+				collected = e.collectFields(t, s.SelectionSet, collected, visited)
+			}
+
+		case SelectionFragmentSpread:
+			fragment := e.document.Fragments[s.Name]
+			if visited[s.Name] || !e.schema.TypeApplies(t, fragment.TypeCondition) {
+				continue
+			}
+			visited[s.Name] = true
+			collected = e.collectFields(t, fragment.SelectionSet, collected, visited)
+		}
+	}
+	return collected
+}
+
+// ------------------------------------------------------
+// @skip(if:) and @include(if:)
+// ------------------------------------------------------
+func (e *executor) included(s *Selection) bool {
+	for _, d := range s.Directives {
+		if d.Name != "skip" && d.Name != "include" {
+			continue
+		}
+		condition := false
+		for _, a := range d.Arguments {
+			if a.Name == "if" {
+				condition, _ = a.Value.Resolve(e.variables).(bool)
+			}
+		}
+		if (d.Name == "skip" && condition) || (d.Name == "include" && !condition) {
+			return false
+		}
+	}
+	return true
+}
+
+// ------------------------------------------------------
+// nil when a non-null field is null, the null goes up to the
+// nearest nullable parent
+// ------------------------------------------------------
+func (e *executor) executeSelectionSet(t *Type, parent map[string]any, selections []*Selection, path []any) *Object {
+	object := NewObject()
+
+	for _, c := range e.collectFields(t, selections, nil, map[string]bool{}) {
+		first := c.fields[0]
+		if first.Name == "__typename" {
+			object.Set(c.key, t.Name)
+			continue
+		}
+
+		field := e.schema.Field(t, first.Name)
+		subSelections := make([]*Selection, 0)
+		for _, f := range c.fields {
+			subSelections = append(subSelections, f.SelectionSet...)
+		}
+
+		value, found := e.lookup(t, parent, field.Name)
+		if field == schemaMetaField || field == typeMetaField {
+			value, found = e.introspect(field, first), true
+		}
+		fieldPath := append(append([]any{}, path...), c.key)
+		completed := e.complete(t, field, field.Type, value, found, subSelections, fieldPath, &first.Location)
+		if completed == nil && field.Type.NonNull {
+			return nil
+		}
+		object.Set(c.key, completed)
+	}
+
+	return object
+}
+
+// ------------------------------------------------------
+// parent value, then Type.field, then the Type defaults. A parent
+// value can be a function that builds it (introspection)
+// ------------------------------------------------------
+func (e *executor) lookup(t *Type, parent map[string]any, field string) (any, bool) {
+	if value, found := parent[field]; found {
+		if build, ok := value.(func() any); ok {
+			return build(), true
+		}
+		return value, true
+	}
+	if value, found := e.options.Mocks[t.Name+"."+field]; found {
+		return value, true
+	}
+	if defaults, ok := e.options.Mocks[t.Name].(map[string]any); ok {
+		if value, found := defaults[field]; found {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+// ------------------------------------------------------
+// value of the field shaped by its type, missing values are generated.
+// nil is null: the error is added where the null is returned, the
+// caller nulls its parent when ref is non-null
+// ------------------------------------------------------
+func (e *executor) complete(parent *Type, field *Field, ref *TypeRef, value any, found bool, selections []*Selection, path []any, location *Location) any {
+	if found && value == nil {
+		if ref.NonNull {
+			e.addError(fmt.Sprintf("Cannot return null for non-nullable field %s.%s.", parent.Name, field.Name), location, path)
+		}
+		return nil
+	}
+
+	if ref.IsList() {
+		items := make([]any, e.options.ListLength)
+		if found {
+			list, ok := value.([]any)
+			if !ok {
+				list = []any{value}
+			}
+			items = list
+		}
+
+		result := make([]any, 0, len(items))
+		for i, item := range items {
+			completed := e.complete(parent, field, ref.Elem, item, found, selections, append(append([]any{}, path...), i), location)
+			if completed == nil && ref.Elem.NonNull {
+				return nil
+			}
+			result = append(result, completed)
+		}
+		return result
+	}
+
+	named := e.schema.Types[ref.Name]
+	if named.IsLeaf() {
+		if found {
+			return value
+		}
+		return e.generate(named, field.Name)
+	}
+
+	object := map[string]any{}
+	if found {
+		o, ok := value.(map[string]any)
+		if !ok {
+			e.addError(fmt.Sprintf("Mock value of %s.%s must be an object.", parent.Name, field.Name), location, path)
+			return nil
+		}
+		object = o
+	}
+
+	concrete := e.concreteType(named, object)
+	if concrete == nil {
+		e.addError(fmt.Sprintf("Abstract type \"%s\" has no object type.", named.Name), location, path)
+		return nil
+	}
+
+	// a nil *Object is not a nil any
+	if completed := e.executeSelectionSet(concrete, object, selections, path); completed != nil {
+		return completed
+	}
+	return nil
+}
+
+// ------------------------------------------------------
+// __typename of the mock value, else the first possible type
+// ------------------------------------------------------
+func (e *executor) concreteType(t *Type, object map[string]any) *Type {
+	if !t.IsAbstract() {
+		return t
+	}
+
+	possibleTypes := e.schema.PossibleTypes(t)
+	if name, ok := object["__typename"].(string); ok {
+		for _, possible := range possibleTypes {
+			if strings.EqualFold(possible.Name, name) {
+				return possible
+			}
+		}
+	}
+
+	if len(possibleTypes) == 0 {
+		return nil
+	}
+	return possibleTypes[0]
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (e *executor) generate(t *Type, field string) any {
+	if e.options.Generate != nil {
+		return e.options.Generate(t, field)
+	}
+	return DefaultValue(t, field)
+}
+
+// ------------------------------------------------------
+// DefaultValue is a fixed value for a scalar or an enum
+// ------------------------------------------------------
+func DefaultValue(t *Type, field string) any {
+	switch {
+	case t.Kind == KindEnum && len(t.EnumValues) > 0:
+		return t.EnumValues[0]
+	case t.Name == "Int":
+		return 42
+	case t.Name == "Float":
+		return 4.2
+	case t.Name == "Boolean":
+		return true
+	case t.Name == "ID":
+		return "1"
+	}
+	return field
+}
@@ -0,0 +1,330 @@
+package graphql
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const testSchema = `
+"the root"
+type Query {
+  user(id: ID!): User
+  users(first: Int = 10): [User!]!
+  search(text: String!): [SearchResult]
+  node(id: ID!): Node
+  me: User!
+  settings(filter: Filter = {tags: ["a"], limit: 5}): String
+}
+
+type Mutation {
+  rename(id: ID!, name: String!): User
+}
+
+interface Node {
+  id: ID!
+}
+
+type User implements Node {
+  id: ID!
+  name: String
+  email: String!
+  role: Role
+  friends: [User!]
+  address: Address
+}
+
+type Address {
+  city: String!
+}
+
+type Post implements Node {
+  id: ID!
+  title: String!
+}
+
+union SearchResult = User | Post
+
+enum Role { ADMIN USER }
+
+input Filter {
+  tags: [String]
+  limit: Int = 1
+}
+`
+
+func testExecute(t *testing.T, query string, operationName string, variables map[string]any, mocks string) string {
+	t.Helper()
+
+	schema, err := ParseSchema(testSchema)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+
+	options := Options{Mocks: map[string]any{}}
+	if mocks != "" {
+		if err := json.Unmarshal([]byte(mocks), &options.Mocks); err != nil {
+			t.Fatalf("invalid mocks: %s", err.Error())
+		}
+	}
+
+	response := schema.Execute(&Request{Query: query, OperationName: operationName, Variables: variables}, options)
+	body, err := json.Marshal(response)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	return string(body)
+}
+
+func Test_Execute(t *testing.T) {
+	tests := []struct {
+		name          string
+		query         string
+		operationName string
+		variables     map[string]any
+		mocks         string
+		expected      string
+	}{
+		{
+			"mock values and generated values",
+			`{ user(id: "7") { id name role } }`, "", nil,
+			`{"Query.user": {"name": "Ada"}, "User": {"id": "7"}}`,
+			`{"data":{"user":{"id":"7","name":"Ada","role":"ADMIN"}}}`,
+		},
+		{
+			"aliases and __typename",
+			`{ a: user(id: "1") { __typename n: name } b: user(id: "2") { name } }`, "", nil,
+			`{"User.name": "Ada"}`,
+			`{"data":{"a":{"__typename":"User","n":"Ada"},"b":{"name":"Ada"}}}`,
+		},
+		{
+			"lists get two items, mock lists are kept",
+			`{ users { name friends { name } } }`, "", nil,
+			`{"User.name": "Ada", "User.friends": [{"name": "Bob"}]}`,
+			`{"data":{"users":[{"name":"Ada","friends":[{"name":"Bob"}]},{"name":"Ada","friends":[{"name":"Bob"}]}]}}`,
+		},
+		{
+			"named fragments and merged fields",
+			`query { user(id: "1") { ...UserParts name address { city } } }
+			 fragment UserParts on User { id address { c: city } }`, "", nil,
+			`{"Query.user": {"id": "1", "name": "Ada", "address": {"city": "Oslo"}}}`,
+			`{"data":{"user":{"id":"1","address":{"c":"Oslo","city":"Oslo"},"name":"Ada"}}}`,
+		},
+		{
+			"union by __typename",
+			`{ search(text: "x") { __typename ... on User { name } ... on Post { title } } }`, "", nil,
+			`{"Query.search": [{"__typename": "Post", "title": "Hi"}, {"__typename": "User", "name": "Ada"}, null]}`,
+			`{"data":{"search":[{"__typename":"Post","title":"Hi"},{"__typename":"User","name":"Ada"},null]}}`,
+		},
+		{
+			"interface, first possible type without __typename",
+			`{ node(id: "1") { id ... on Post { title } ... on User { name } } }`, "", nil,
+			`{"Query.node": {"id": "1"}, "User.name": "Ada"}`,
+			`{"data":{"node":{"id":"1","name":"Ada"}}}`,
+		},
+		{
+			"fragment on an interface",
+			`{ node(id: "1") { ...N } } fragment N on Node { id }`, "", nil,
+			`{"Query.node": {"__typename": "Post", "id": "9"}}`,
+			`{"data":{"node":{"id":"9"}}}`,
+		},
+		{
+			"@skip and @include with variables",
+			`query Q($withName: Boolean!, $skipRole: Boolean = true) {
+			   user(id: "1") { id name @include(if: $withName) role @skip(if: $skipRole) ... @include(if: false) { email } }
+			 }`, "", map[string]any{"withName": false},
+			`{"User": {"id": "1", "name": "Ada", "role": "USER", "email": "a@b"}}`,
+			`{"data":{"user":{"id":"1"}}}`,
+		},
+		{
+			"multiple operations by name",
+			`query One { me { name } } mutation Two($id: ID!) { rename(id: $id, name: "Bob") { name } }`, "Two", map[string]any{"id": "7"},
+			`{"Mutation.rename": {"name": "Bob"}}`,
+			`{"data":{"rename":{"name":"Bob"}}}`,
+		},
+		{
+			"mock errors keep data",
+			`{ me { name } }`, "", nil,
+			`{"User.name": "Ada", "errors": ["plain", {"message": "Forbidden", "path": ["me"], "extensions": {"code": "FORBIDDEN"}}]}`,
+			`{"errors":[{"message":"plain"},{"message":"Forbidden","path":["me"],"extensions":{"code":"FORBIDDEN"}}],"data":{"me":{"name":"Ada"}}}`,
+		},
+	}
+
+	for _, test := range tests {
+		result := testExecute(t, test.query, test.operationName, test.variables, test.mocks)
+		if result != test.expected {
+			t.Errorf("%s:\nexpected %s\n     got %s", test.name, test.expected, result)
+		}
+	}
+}
+
+func Test_Execute_Errors(t *testing.T) {
+	tests := []struct {
+		name          string
+		query         string
+		operationName string
+		variables     map[string]any
+		expected      string
+	}{
+		{
+			"syntax error",
+			`{ user(id: "1") { name }`, "", nil,
+			`{"errors":[{"message":"Syntax Error: Expected Name, found \u003cEOF\u003e.","locations":[{"line":1,"column":25}]}]}`,
+		},
+		{
+			"unknown field",
+			`{ user(id: "1") { nope } }`, "", nil,
+			`{"errors":[{"message":"Cannot query field \"nope\" on type \"User\".","locations":[{"line":1,"column":19}]}]}`,
+		},
+		{
+			"missing argument",
+			`{ user { name } }`, "", nil,
+			`{"errors":[{"message":"Field \"user\" argument \"id\" of type \"ID!\" is required, but it was not provided.","locations":[{"line":1,"column":3}]}]}`,
+		},
+		{
+			"missing variable",
+			`query Q($id: ID!) { user(id: $id) { name } }`, "", nil,
+			`{"errors":[{"message":"Variable \"$id\" of required type \"ID!\" was not provided.","locations":[{"line":1,"column":9}]}]}`,
+		},
+		{
+			"undefined variable",
+			`{ user(id: $id) { name } }`, "", nil,
+			`{"errors":[{"message":"Variable \"$id\" is not defined.","locations":[{"line":1,"column":3}]}]}`,
+		},
+		{
+			"no operation name with two operations",
+			`query A { me { name } } query B { me { name } }`, "", nil,
+			`{"errors":[{"message":"Must provide operation name if query contains multiple operations."}]}`,
+		},
+		{
+			"unknown operation name",
+			`query A { me { name } }`, "C", nil,
+			`{"errors":[{"message":"Unknown operation named \"C\"."}]}`,
+		},
+		{
+			"no subscription type",
+			`subscription { me { name } }`, "", nil,
+			`{"errors":[{"message":"Schema is not configured for subscriptions.","locations":[{"line":1,"column":1}]}]}`,
+		},
+		{
+			"leaf with selection",
+			`{ me { name { x } } }`, "", nil,
+			`{"errors":[{"message":"Field \"name\" must not have a selection since type \"String\" has no subfields.","locations":[{"line":1,"column":8}]}]}`,
+		},
+		{
+			"fragment on itself",
+			`{ me { ...A } } fragment A on User { ...A }`, "", nil,
+			`{"errors":[{"message":"Cannot spread fragment \"A\" within itself.","locations":[{"line":1,"column":38}]}]}`,
+		},
+	}
+
+	for _, test := range tests {
+		result := testExecute(t, test.query, test.operationName, test.variables, "")
+		if result != test.expected {
+			t.Errorf("%s:\nexpected %s\n     got %s", test.name, test.expected, result)
+		}
+	}
+}
+
+// a null for a non-null field nulls the nearest nullable parent
+func Test_Execute_NullPropagation(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		mocks    string
+		expected string
+	}{
+		{
+			"nullable field",
+			`{ user(id: "1") { name } }`,
+			`{"User.name": null}`,
+			`{"data":{"user":{"name":null}}}`,
+		},
+		{
+			"to the nullable object",
+			`{ user(id: "1") { id email } }`,
+			`{"Query.user": {"id": "1", "email": null}}`,
+			`{"errors":[{"message":"Cannot return null for non-nullable field User.email.","locations":[{"line":1,"column":22}],"path":["user","email"]}],"data":{"user":null}}`,
+		},
+		{
+			"up to the nullable parent only",
+			`{ me { address { city } } users { id } }`,
+			`{"Query.me": {"address": {"city": null}}}`,
+			`{"errors":[{"message":"Cannot return null for non-nullable field Address.city.","locations":[{"line":1,"column":18}],"path":["me","address","city"]}],"data":{"me":{"address":null},"users":[{"id":"1"},{"id":"1"}]}}`,
+		},
+		{
+			"non-null item nulls the list",
+			`{ user(id: "1") { friends { id } } }`,
+			`{"User.friends": [{"id": "2"}, null]}`,
+			`{"errors":[{"message":"Cannot return null for non-nullable field User.friends.","locations":[{"line":1,"column":19}],"path":["user","friends",1]}],"data":{"user":{"friends":null}}}`,
+		},
+		{
+			"non-null list item up to the root",
+			`{ users { email } }`,
+			`{"Query.users": [{"email": "a@b"}, {"email": null}]}`,
+			`{"errors":[{"message":"Cannot return null for non-nullable field User.email.","locations":[{"line":1,"column":11}],"path":["users",1,"email"]}],"data":null}`,
+		},
+	}
+
+	for _, test := range tests {
+		result := testExecute(t, test.query, "", nil, test.mocks)
+		if result != test.expected {
+			t.Errorf("%s:\nexpected %s\n     got %s", test.name, test.expected, result)
+		}
+	}
+}
+
+func Test_Execute_Introspection(t *testing.T) {
+	result := testExecute(t, `{ __type(name: "User") { kind name interfaces { name } fields { name args { name } type { kind name ofType { kind name ofType { name } } } } } }`, "", nil, "")
+	for _, expected := range []string{
+		`"kind":"OBJECT","name":"User","interfaces":[{"name":"Node"}]`,
+		`{"name":"id","args":[],"type":{"kind":"NON_NULL","name":null,"ofType":{"kind":"SCALAR","name":"ID","ofType":null}}}`,
+		`{"name":"friends","args":[],"type":{"kind":"LIST","name":null,"ofType":{"kind":"NON_NULL","name":null,"ofType":{"name":"User"}}}}`,
+	} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("expected %s in %s", expected, result)
+		}
+	}
+
+	result = testExecute(t, `query T($name: String!) { __type(name: $name) { name } }`, "", map[string]any{"name": "Nope"}, "")
+	if result != `{"data":{"__type":null}}` {
+		t.Errorf("unknown type: got %s", result)
+	}
+
+	result = testExecute(t, `{
+	  __schema {
+	    queryType { name } mutationType { name } subscriptionType { name }
+	    types { name }
+	    directives { name args { name defaultValue } }
+	  }
+	  search: __type(name: "SearchResult") { possibleTypes { name } }
+	  role: __type(name: "Role") { enumValues { name } }
+	  filter: __type(name: "Filter") { inputFields { name defaultValue } }
+	  query: __type(name: "Query") { fields { name args { name defaultValue } } }
+	}`, "", nil, "")
+
+	for _, expected := range []string{
+		`"queryType":{"name":"Query"},"mutationType":{"name":"Mutation"},"subscriptionType":null`,
+		`{"name":"String"}`, `{"name":"__Schema"}`, `{"name":"SearchResult"}`, `{"name":"Filter"}`,
+		`{"name":"skip","args":[{"name":"if","defaultValue":null}]}`,
+		`{"name":"deprecated","args":[{"name":"reason","defaultValue":"\"No longer supported\""}]}`,
+		`"search":{"possibleTypes":[{"name":"User"},{"name":"Post"}]}`,
+		`"role":{"enumValues":[{"name":"ADMIN"},{"name":"USER"}]}`,
+		`"filter":{"inputFields":[{"name":"tags","defaultValue":null},{"name":"limit","defaultValue":"1"}]}`,
+		`{"name":"users","args":[{"name":"first","defaultValue":"10"}]}`,
+		`{"name":"settings","args":[{"name":"filter","defaultValue":"{tags: [\"a\"], limit: 5}"}]}`,
+	} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("expected %s in %s", expected, result)
+		}
+	}
+	if strings.Contains(result, "__schema\"},{\"name") || strings.Contains(result, `"name":"__schema"`) {
+		t.Errorf("meta fields must not be fields of the query type: %s", result)
+	}
+
+	// __schema is only on the query type
+	result = testExecute(t, `{ me { __schema { types { name } } } }`, "", nil, "")
+	if !strings.Contains(result, `Cannot query field \"__schema\" on type \"User\".`) {
+		t.Errorf("expected an error, got %s", result)
+	}
+}
@@ -0,0 +1,302 @@
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// introspection types of the GraphQL spec, part of every schema
+const introspectionSchema = `
+type __Schema {
+  description: String
+  types: [__Type!]!
+  queryType: __Type!
+  mutationType: __Type
+  subscriptionType: __Type
+  directives: [__Directive!]!
+}
+
+type __Type {
+  kind: __TypeKind!
+  name: String
+  description: String
+  specifiedByURL: String
+  fields(includeDeprecated: Boolean = false): [__Field!]
+  interfaces: [__Type!]
+  possibleTypes: [__Type!]
+  enumValues(includeDeprecated: Boolean = false): [__EnumValue!]
+  inputFields(includeDeprecated: Boolean = false): [__InputValue!]
+  ofType: __Type
+}
+
+enum __TypeKind { SCALAR OBJECT INTERFACE UNION ENUM INPUT_OBJECT LIST NON_NULL }
+
+type __Field {
+  name: String!
+  description: String
+  args(includeDeprecated: Boolean = false): [__InputValue!]!
+  type: __Type!
+  isDeprecated: Boolean!
+  deprecationReason: String
+}
+
+type __InputValue {
+  name: String!
+  description: String
+  type: __Type!
+  defaultValue: String
+  isDeprecated: Boolean!
+  deprecationReason: String
+}
+
+type __EnumValue {
+  name: String!
+  description: String
+  isDeprecated: Boolean!
+  deprecationReason: String
+}
+
+type __Directive {
+  name: String!
+  description: String
+  locations: [__DirectiveLocation!]!
+  args(includeDeprecated: Boolean = false): [__InputValue!]!
+  isRepeatable: Boolean!
+}
+
+enum __DirectiveLocation {
+  QUERY MUTATION SUBSCRIPTION FIELD FRAGMENT_DEFINITION FRAGMENT_SPREAD INLINE_FRAGMENT VARIABLE_DEFINITION
+  SCHEMA SCALAR OBJECT FIELD_DEFINITION ARGUMENT_DEFINITION INTERFACE UNION ENUM ENUM_VALUE INPUT_OBJECT
+  INPUT_FIELD_DEFINITION
+}
+`
+
+// __schema and __type of the query type
+var (
+	schemaMetaField = &Field{Name: "__schema", Type: &TypeRef{Name: "__Schema", NonNull: true}}
+	typeMetaField   = &Field{
+		Name: "__type",
+		Args: []*InputValue{{Name: "name", Type: &TypeRef{Name: "String", NonNull: true}}},
+		Type: &TypeRef{Name: "__Type"},
+	}
+)
+
+// ------------------------------------------------------
+// value of __schema or __type, shaped by the selection set like
+// any mock value
+// ------------------------------------------------------
+func (e *executor) introspect(field *Field, s *Selection) any {
+	if field == schemaMetaField {
+		return e.schema.introspectSchema()
+	}
+
+	for _, a := range s.Arguments {
+		if a.Name != "name" {
+			continue
+		}
+		name, _ := a.Value.Resolve(e.variables).(string)
+		if t, found := e.schema.Types[name]; found {
+			return e.schema.introspectType(t)
+		}
+	}
+	return nil
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (s *Schema) introspectSchema() map[string]any {
+	types := make([]any, 0, len(s.Types))
+	for _, name := range builtinScalars {
+		types = append(types, s.introspectType(s.Types[name]))
+	}
+	for _, name := range s.order {
+		types = append(types, s.introspectType(s.Types[name]))
+	}
+
+	root := func(name string) any {
+		if name == "" {
+			return nil
+		}
+		return s.introspectType(s.Types[name])
+	}
+
+	return map[string]any{
+		"description":      nil,
+		"types":            types,
+		"queryType":        root(s.Query),
+		"mutationType":     root(s.Mutation),
+		"subscriptionType": root(s.Subscription),
+		"directives":       s.introspectDirectives(),
+	}
+}
+
+// ------------------------------------------------------
+// the lists are functions: types refer to each other, they are only
+// built when they are selected
+// ------------------------------------------------------
+func (s *Schema) introspectType(t *Type) map[string]any {
+	introspected := map[string]any{
+		"kind":           t.Kind,
+		"name":           t.Name,
+		"description":    nil,
+		"specifiedByURL": nil,
+		"fields":         nil,
+		"interfaces":     nil,
+		"possibleTypes":  nil,
+		"enumValues":     nil,
+		"inputFields":    nil,
+		"ofType":         nil,
+	}
+
+	switch t.Kind {
+	case KindObject, KindInterface:
+		introspected["fields"] = func() any {
+			fields := make([]any, 0, len(t.Fields))
+			for _, f := range t.Fields {
+				args := make([]any, 0, len(f.Args))
+				for _, a := range f.Args {
+					args = append(args, s.introspectInputValue(a.Name, a.Type, a.Default))
+				}
+				fields = append(fields, map[string]any{
+					"name":              f.Name,
+					"description":       nil,
+					"args":              args,
+					"type":              s.introspectTypeRef(f.Type),
+					"isDeprecated":      false,
+					"deprecationReason": nil,
+				})
+			}
+			return fields
+		}
+		introspected["interfaces"] = func() any {
+			interfaces := make([]any, 0, len(t.Interfaces))
+			for _, name := range t.Interfaces {
+				interfaces = append(interfaces, s.introspectType(s.Types[name]))
+			}
+			return interfaces
+		}
+	case KindInputObject:
+		introspected["inputFields"] = func() any {
+			inputFields := make([]any, 0, len(t.Fields))
+			for _, f := range t.Fields {
+				inputFields = append(inputFields, s.introspectInputValue(f.Name, f.Type, f.Default))
+			}
+			return inputFields
+		}
+	case KindEnum:
+		enumValues := make([]any, 0, len(t.EnumValues))
+		for _, value := range t.EnumValues {
+			enumValues = append(enumValues, map[string]any{
+				"name":              value,
+				"description":       nil,
+				"isDeprecated":      false,
+				"deprecationReason": nil,
+			})
+		}
+		introspected["enumValues"] = enumValues
+	}
+
+	if t.IsAbstract() {
+		introspected["possibleTypes"] = func() any {
+			possibleTypes := make([]any, 0)
+			for _, possible := range s.PossibleTypes(t) {
+				possibleTypes = append(possibleTypes, s.introspectType(possible))
+			}
+			return possibleTypes
+		}
+	}
+
+	return introspected
+}
+
+// ------------------------------------------------------
+// [User!]! => NON_NULL of LIST of NON_NULL of User
+// ------------------------------------------------------
+func (s *Schema) introspectTypeRef(ref *TypeRef) map[string]any {
+	if ref.NonNull {
+		inner := *ref
+		inner.NonNull = false
+		return map[string]any{"kind": "NON_NULL", "name": nil, "ofType": s.introspectTypeRef(&inner)}
+	}
+	if ref.IsList() {
+		return map[string]any{"kind": "LIST", "name": nil, "ofType": s.introspectTypeRef(ref.Elem)}
+	}
+	return s.introspectType(s.Types[ref.Name])
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (s *Schema) introspectInputValue(name string, ref *TypeRef, defaultValue *Value) map[string]any {
+	introspected := map[string]any{
+		"name":              name,
+		"description":       nil,
+		"type":              s.introspectTypeRef(ref),
+		"defaultValue":      nil,
+		"isDeprecated":      false,
+		"deprecationReason": nil,
+	}
+	if defaultValue != nil {
+		introspected["defaultValue"] = defaultValue.String()
+	}
+	return introspected
+}
+
+// ------------------------------------------------------
+// the directives the executor knows
+// ------------------------------------------------------
+func (s *Schema) introspectDirectives() []any {
+	directive := func(name string, description string, locations []any, arg map[string]any) map[string]any {
+		return map[string]any{
+			"name":         name,
+			"description":  description,
+			"locations":    locations,
+			"args":         []any{arg},
+			"isRepeatable": false,
+		}
+	}
+
+	condition := s.introspectInputValue("if", &TypeRef{Name: "Boolean", NonNull: true}, nil)
+	executable := []any{"FIELD", "FRAGMENT_SPREAD", "INLINE_FRAGMENT"}
+
+	return []any{
+		directive("include", "Directs the executor to include this field or fragment only when the `if` argument is true.", executable, condition),
+		directive("skip", "Directs the executor to skip this field or fragment when the `if` argument is true.", executable, condition),
+		directive("deprecated", "Marks an element of a GraphQL schema as no longer supported.",
+			[]any{"FIELD_DEFINITION", "ARGUMENT_DEFINITION", "INPUT_FIELD_DEFINITION", "ENUM_VALUE"},
+			s.introspectInputValue("reason", &TypeRef{Name: "String"}, &Value{Kind: ValueString, Raw: "No longer supported"})),
+	}
+}
+
+// ------------------------------------------------------
+// value as GraphQL, for defaultValue
+// ------------------------------------------------------
+func (v *Value) String() string {
+	switch v.Kind {
+	case ValueVariable:
+		return "$" + v.Raw
+	case ValueString:
+		var buf bytes.Buffer
+		encoder := json.NewEncoder(&buf)
+		encoder.SetEscapeHTML(false)
+		encoder.Encode(v.Raw)
+		return strings.TrimSpace(buf.String())
+	case ValueList:
+		items := make([]string, 0, len(v.List))
+		for _, item := range v.List {
+			items = append(items, item.String())
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case ValueObject:
+		fields := make([]string, 0, len(v.Fields))
+		for _, f := range v.Fields {
+			fields = append(fields, f.Name+": "+f.Value.String())
+		}
+		return "{" + strings.Join(fields, ", ") + "}"
+	}
+
+	// Int, Float, Boolean, Null and Enum
+	return v.Raw
+}
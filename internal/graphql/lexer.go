@@ -0,0 +1,381 @@
+package graphql
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// token kinds
+const (
+	tokenEOF = iota
+	tokenPunctuator
+	tokenName
+	tokenInt
+	tokenFloat
+	tokenString
+)
+
+type token struct {
+	kind   int
+	value  string
+	line   int
+	column int
+}
+
+// ------------------------------------------------------
+// lexer for both the schema SDL and queries, commas and
+// # comments are ignored like white space
+// ------------------------------------------------------
+type lexer struct {
+	source string
+	pos    int
+	line   int
+	column int
+
+	current token
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func newLexer(source string) (*lexer, error) {
+	l := &lexer{source: strings.TrimPrefix(source, "\ufeff"), line: 1, column: 1}
+	err := l.next()
+	return l, err
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (l *lexer) errorf(format string, args ...any) error {
+	return &Error{
+		Message:   fmt.Sprintf("Syntax Error: %s", fmt.Sprintf(format, args...)),
+		Locations: []Location{{Line: l.current.line, Column: l.current.column}},
+	}
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (l *lexer) advance(n int) {
+	for i := 0; i < n && l.pos < len(l.source); i++ {
+		if l.source[l.pos] == '\n' {
+			l.line++
+			l.column = 1
+		} else {
+			l.column++
+		}
+		l.pos++
+	}
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (l *lexer) skipIgnored() {
+	for l.pos < len(l.source) {
+		c := l.source[l.pos]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			l.advance(1)
+		case c == '#':
+			for l.pos < len(l.source) && l.source[l.pos] != '\n' {
+				l.advance(1)
+			}
+		default:
+			return
+		}
+	}
+}
+
+// ------------------------------------------------------
+// reads the next token into current
+// ------------------------------------------------------
+func (l *lexer) next() error {
+	l.skipIgnored()
+
+	l.current = token{line: l.line, column: l.column}
+	if l.pos >= len(l.source) {
+		l.current.kind = tokenEOF
+		return nil
+	}
+
+	c := l.source[l.pos]
+	switch {
+	case strings.HasPrefix(l.source[l.pos:], "..."):
+		l.current.kind = tokenPunctuator
+		l.current.value = "..."
+		l.advance(3)
+
+	case strings.IndexByte("!$&():=@[]{|}", c) >= 0:
+		l.current.kind = tokenPunctuator
+		l.current.value = string(c)
+		l.advance(1)
+
+	case c == '_' || isLetter(c):
+		start := l.pos
+		for l.pos < len(l.source) && (l.source[l.pos] == '_' || isLetter(l.source[l.pos]) || isDigit(l.source[l.pos])) {
+			l.advance(1)
+		}
+		l.current.kind = tokenName
+		l.current.value = l.source[start:l.pos]
+
+	case c == '-' || isDigit(c):
+		return l.readNumber()
+
+	case strings.HasPrefix(l.source[l.pos:], `"""`):
+		return l.readBlockString()
+
+	case c == '"':
+		return l.readString()
+
+	default:
+		r, _ := utf8.DecodeRuneInString(l.source[l.pos:])
+		return l.errorf("Unexpected character %q.", r)
+	}
+
+	return nil
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (l *lexer) readNumber() error {
+	start := l.pos
+	kind := tokenInt
+
+	if l.source[l.pos] == '-' {
+		l.advance(1)
+	}
+	digits := l.pos
+	for l.pos < len(l.source) && isDigit(l.source[l.pos]) {
+		l.advance(1)
+	}
+	if l.pos == digits {
+		return l.errorf("Invalid number, expected digit.")
+	}
+
+	if l.pos < len(l.source) && l.source[l.pos] == '.' {
+		kind = tokenFloat
+		l.advance(1)
+		for l.pos < len(l.source) && isDigit(l.source[l.pos]) {
+			l.advance(1)
+		}
+	}
+	if l.pos < len(l.source) && (l.source[l.pos] == 'e' || l.source[l.pos] == 'E') {
+		kind = tokenFloat
+		l.advance(1)
+		if l.pos < len(l.source) && (l.source[l.pos] == '+' || l.source[l.pos] == '-') {
+			l.advance(1)
+		}
+		for l.pos < len(l.source) && isDigit(l.source[l.pos]) {
+			l.advance(1)
+		}
+	}
+
+	l.current.kind = kind
+	l.current.value = l.source[start:l.pos]
+	return nil
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (l *lexer) readString() error {
+	l.advance(1)
+
+	var b strings.Builder
+	for {
+		if l.pos >= len(l.source) || l.source[l.pos] == '\n' {
+			return l.errorf("Unterminated string.")
+		}
+
+		c := l.source[l.pos]
+		if c == '"' {
+			l.advance(1)
+			break
+		}
+
+		if c != '\\' {
+			r, size := utf8.DecodeRuneInString(l.source[l.pos:])
+			b.WriteRune(r)
+			l.advance(size)
+			continue
+		}
+
+		if l.pos+1 >= len(l.source) {
+			return l.errorf("Unterminated string.")
+		}
+		escape := l.source[l.pos+1]
+		l.advance(2)
+		switch escape {
+		case '"', '\\', '/':
+			b.WriteByte(escape)
+		case 'b':
+			b.WriteByte('\b')
+		case 'f':
+			b.WriteByte('\f')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 't':
+			b.WriteByte('\t')
+		case 'u':
+			if l.pos+4 > len(l.source) {
+				return l.errorf("Invalid unicode escape.")
+			}
+			var r rune
+			_, err := fmt.Sscanf(l.source[l.pos:l.pos+4], "%04x", &r)
+			if err != nil {
+				return l.errorf("Invalid unicode escape.")
+			}
+			b.WriteRune(r)
+			l.advance(4)
+		default:
+			return l.errorf("Invalid character escape sequence \\%c.", escape)
+		}
+	}
+
+	l.current.kind = tokenString
+	l.current.value = b.String()
+	return nil
+}
+
+// ------------------------------------------------------
+// """block strings""" lose the common indentation
+// ------------------------------------------------------
+func (l *lexer) readBlockString() error {
+	l.advance(3)
+
+	end := strings.Index(l.source[l.pos:], `"""`)
+	for end > 0 && l.source[l.pos+end-1] == '\\' {
+		next := strings.Index(l.source[l.pos+end+3:], `"""`)
+		if next < 0 {
+			end = -1
+			break
+		}
+		end = end + 3 + next
+	}
+	if end < 0 {
+		return l.errorf("Unterminated string.")
+	}
+
+	raw := strings.ReplaceAll(l.source[l.pos:l.pos+end], `\"""`, `"""`)
+	l.advance(end + 3)
+
+	l.current.kind = tokenString
+	l.current.value = blockStringValue(raw)
+	return nil
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func blockStringValue(raw string) string {
+	lines := strings.Split(strings.ReplaceAll(raw, "\r\n", "\n"), "\n")
+
+	indent := -1
+	for _, line := range lines[1:] {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed == "" {
+			continue
+		}
+		if n := len(line) - len(trimmed); indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	if indent > 0 {
+		for i := 1; i < len(lines); i++ {
+			if len(lines[i]) >= indent {
+				lines[i] = lines[i][indent:]
+			} else {
+				lines[i] = strings.TrimLeft(lines[i], " \t")
+			}
+		}
+	}
+
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// ------------------------------------------------------
+// helpers for the parsers
+// ------------------------------------------------------
+func (l *lexer) peek(value string) bool {
+	return l.current.kind == tokenPunctuator && l.current.value == value
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (l *lexer) peekName(value string) bool {
+	return l.current.kind == tokenName && l.current.value == value
+}
+
+// ------------------------------------------------------
+// skips the punctuator when it is there
+// ------------------------------------------------------
+func (l *lexer) skip(value string) (bool, error) {
+	if !l.peek(value) {
+		return false, nil
+	}
+	return true, l.next()
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (l *lexer) expect(value string) error {
+	if !l.peek(value) {
+		return l.errorf("Expected %q, found %s.", value, l.describe())
+	}
+	return l.next()
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (l *lexer) expectName() (string, error) {
+	if l.current.kind != tokenName {
+		return "", l.errorf("Expected Name, found %s.", l.describe())
+	}
+	name := l.current.value
+	return name, l.next()
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (l *lexer) describe() string {
+	switch l.current.kind {
+	case tokenEOF:
+		return "<EOF>"
+	case tokenString:
+		return fmt.Sprintf("String %q", l.current.value)
+	case tokenName:
+		return fmt.Sprintf("Name %q", l.current.value)
+	default:
+		return fmt.Sprintf("%q", l.current.value)
+	}
+}
@@ -0,0 +1,527 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// value kinds
+const (
+	ValueVariable = "Variable"
+	ValueInt      = "Int"
+	ValueFloat    = "Float"
+	ValueString   = "String"
+	ValueBoolean  = "Boolean"
+	ValueNull     = "Null"
+	ValueEnum     = "Enum"
+	ValueList     = "List"
+	ValueObject   = "Object"
+)
+
+// ------------------------------------------------------
+// argument or default value as written in the document
+// ------------------------------------------------------
+type Value struct {
+	Kind string
+	Raw  string
+
+	List   []*Value
+	Fields []*ObjectField
+}
+
+type ObjectField struct {
+	Name  string
+	Value *Value
+}
+
+// ------------------------------------------------------
+// value with the variables replaced
+// ------------------------------------------------------
+func (v *Value) Resolve(variables map[string]any) any {
+	switch v.Kind {
+	case ValueVariable:
+		return variables[v.Raw]
+	case ValueInt:
+		i, err := strconv.ParseInt(v.Raw, 10, 64)
+		if err != nil {
+			return v.Raw
+		}
+		return i
+	case ValueFloat:
+		f, err := strconv.ParseFloat(v.Raw, 64)
+		if err != nil {
+			return v.Raw
+		}
+		return f
+	case ValueBoolean:
+		return v.Raw == "true"
+	case ValueNull:
+		return nil
+	case ValueList:
+		list := make([]any, 0, len(v.List))
+		for _, item := range v.List {
+			list = append(list, item.Resolve(variables))
+		}
+		return list
+	case ValueObject:
+		object := make(map[string]any, len(v.Fields))
+		for _, f := range v.Fields {
+			object[f.Name] = f.Value.Resolve(variables)
+		}
+		return object
+	}
+
+	// String and Enum
+	return v.Raw
+}
+
+type Directive struct {
+	Name      string
+	Arguments []*Argument
+	Location
+}
+
+type Argument struct {
+	Name  string
+	Value *Value
+}
+
+type VariableDefinition struct {
+	Name    string
+	Type    *TypeRef
+	Default *Value
+	Location
+}
+
+// selection kinds
+const (
+	SelectionField          = "Field"
+	SelectionFragmentSpread = "FragmentSpread"
+	SelectionInlineFragment = "InlineFragment"
+)
+
+// ------------------------------------------------------
+// field, ...FragmentName or ... on Type { }
+// ------------------------------------------------------
+type Selection struct {
+	Kind string
+
+	Alias      string
+	Name       string
+	Arguments  []*Argument
+	Directives []*Directive
+
+	// inline fragments
+	TypeCondition string
+
+	SelectionSet []*Selection
+	Location
+}
+
+// ------------------------------------------------------
+// key of the field in the response
+// ------------------------------------------------------
+func (s *Selection) ResponseKey() string {
+	if s.Alias != "" {
+		return s.Alias
+	}
+	return s.Name
+}
+
+type Fragment struct {
+	Name          string
+	TypeCondition string
+	SelectionSet  []*Selection
+	Location
+}
+
+type Operation struct {
+	// query, mutation or subscription
+	Type string
+	Name string
+
+	Variables    []*VariableDefinition
+	SelectionSet []*Selection
+	Location
+}
+
+type Document struct {
+	Operations []*Operation
+	Fragments  map[string]*Fragment
+}
+
+// ------------------------------------------------------
+// operation to run: the named one, or the only one
+// ------------------------------------------------------
+func (d *Document) Operation(name string) (*Operation, error) {
+	if name == "" {
+		if len(d.Operations) != 1 {
+			return nil, &Error{Message: "Must provide operation name if query contains multiple operations."}
+		}
+		return d.Operations[0], nil
+	}
+
+	for _, o := range d.Operations {
+		if o.Name == name {
+			return o, nil
+		}
+	}
+	return nil, &Error{Message: fmt.Sprintf("Unknown operation named %q.", name)}
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func ParseQuery(query string) (*Document, error) {
+	l, err := newLexer(query)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &Document{Fragments: make(map[string]*Fragment)}
+	for l.current.kind != tokenEOF {
+		location := Location{Line: l.current.line, Column: l.current.column}
+
+		switch {
+		case l.peek("{"):
+			selectionSet, err := parseSelectionSet(l)
+			if err != nil {
+				return nil, err
+			}
+			d.Operations = append(d.Operations, &Operation{Type: "query", SelectionSet: selectionSet, Location: location})
+
+		case l.peekName("query") || l.peekName("mutation") || l.peekName("subscription"):
+			o, err := parseOperation(l)
+			if err != nil {
+				return nil, err
+			}
+			d.Operations = append(d.Operations, o)
+
+		case l.peekName("fragment"):
+			f, err := parseFragment(l)
+			if err != nil {
+				return nil, err
+			}
+			if _, found := d.Fragments[f.Name]; found {
+				return nil, &Error{Message: fmt.Sprintf("There can be only one fragment named %q.", f.Name), Locations: []Location{f.Location}}
+			}
+			d.Fragments[f.Name] = f
+
+		default:
+			return nil, l.errorf("Unexpected %s.", l.describe())
+		}
+	}
+
+	if len(d.Operations) == 0 {
+		return nil, &Error{Message: "Syntax Error: document has no operation."}
+	}
+	return d, nil
+}
+
+// ------------------------------------------------------
+// query Name($id: ID!) @directive { }
+// ------------------------------------------------------
+func parseOperation(l *lexer) (*Operation, error) {
+	o := &Operation{Type: l.current.value, Location: Location{Line: l.current.line, Column: l.current.column}}
+	err := l.next()
+	if err != nil {
+		return nil, err
+	}
+
+	if l.current.kind == tokenName {
+		o.Name = l.current.value
+		if err = l.next(); err != nil {
+			return nil, err
+		}
+	}
+
+	if found, err := l.skip("("); found {
+		for !l.peek(")") {
+			v := &VariableDefinition{Location: Location{Line: l.current.line, Column: l.current.column}}
+			if err = l.expect("$"); err != nil {
+				return nil, err
+			}
+			if v.Name, err = l.expectName(); err != nil {
+				return nil, err
+			}
+			if err = l.expect(":"); err != nil {
+				return nil, err
+			}
+			if v.Type, err = parseTypeRef(l); err != nil {
+				return nil, err
+			}
+			if l.peek("=") {
+				if err = l.next(); err != nil {
+					return nil, err
+				}
+				if v.Default, err = parseValue(l, true); err != nil {
+					return nil, err
+				}
+			}
+			if err = skipDirectives(l); err != nil {
+				return nil, err
+			}
+			o.Variables = append(o.Variables, v)
+		}
+		if err = l.expect(")"); err != nil {
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err = skipDirectives(l); err != nil {
+		return nil, err
+	}
+
+	o.SelectionSet, err = parseSelectionSet(l)
+	return o, err
+}
+
+// ------------------------------------------------------
+// fragment Name on Type { }
+// ------------------------------------------------------
+func parseFragment(l *lexer) (*Fragment, error) {
+	f := &Fragment{Location: Location{Line: l.current.line, Column: l.current.column}}
+	err := l.next()
+	if err != nil {
+		return nil, err
+	}
+
+	if f.Name, err = l.expectName(); err != nil {
+		return nil, err
+	}
+	if !l.peekName("on") {
+		return nil, l.errorf("Expected \"on\", found %s.", l.describe())
+	}
+	if err = l.next(); err != nil {
+		return nil, err
+	}
+	if f.TypeCondition, err = l.expectName(); err != nil {
+		return nil, err
+	}
+	if err = skipDirectives(l); err != nil {
+		return nil, err
+	}
+
+	f.SelectionSet, err = parseSelectionSet(l)
+	return f, err
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func parseSelectionSet(l *lexer) ([]*Selection, error) {
+	err := l.expect("{")
+	if err != nil {
+		return nil, err
+	}
+
+	selections := make([]*Selection, 0)
+	for !l.peek("}") {
+		s, err := parseSelection(l)
+		if err != nil {
+			return nil, err
+		}
+		selections = append(selections, s)
+	}
+
+	if len(selections) == 0 {
+		return nil, l.errorf("Expected Name, found \"}\".")
+	}
+	return selections, l.expect("}")
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func parseSelection(l *lexer) (*Selection, error) {
+	s := &Selection{Location: Location{Line: l.current.line, Column: l.current.column}}
+	var err error
+
+	if l.peek("...") {
+		if err = l.next(); err != nil {
+			return nil, err
+		}
+
+		if l.current.kind == tokenName && l.current.value != "on" {
+			s.Kind = SelectionFragmentSpread
+			s.Name = l.current.value
+			if err = l.next(); err != nil {
+				return nil, err
+			}
+			s.Directives, err = parseDirectives(l, false)
+			return s, err
+		}
+
+		s.Kind = SelectionInlineFragment
+		if l.peekName("on") {
+			if err = l.next(); err != nil {
+				return nil, err
+			}
+			if s.TypeCondition, err = l.expectName(); err != nil {
+				return nil, err
+			}
+		}
+		if s.Directives, err = parseDirectives(l, false); err != nil {
+			return nil, err
+		}
+		s.SelectionSet, err = parseSelectionSet(l)
+		return s, err
+	}
+
+	s.Kind = SelectionField
+	if s.Name, err = l.expectName(); err != nil {
+		return nil, err
+	}
+	if found, err := l.skip(":"); found {
+		s.Alias = s.Name
+		if s.Name, err = l.expectName(); err != nil {
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	if l.peek("(") {
+		if s.Arguments, err = parseArguments(l, false); err != nil {
+			return nil, err
+		}
+	}
+	if s.Directives, err = parseDirectives(l, false); err != nil {
+		return nil, err
+	}
+	if l.peek("{") {
+		if s.SelectionSet, err = parseSelectionSet(l); err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+// ------------------------------------------------------
+// (name: value, ...)
+// ------------------------------------------------------
+func parseArguments(l *lexer, constant bool) ([]*Argument, error) {
+	err := l.expect("(")
+	if err != nil {
+		return nil, err
+	}
+
+	arguments := make([]*Argument, 0)
+	for !l.peek(")") {
+		a := &Argument{}
+		if a.Name, err = l.expectName(); err != nil {
+			return nil, err
+		}
+		if err = l.expect(":"); err != nil {
+			return nil, err
+		}
+		if a.Value, err = parseValue(l, constant); err != nil {
+			return nil, err
+		}
+		arguments = append(arguments, a)
+	}
+
+	return arguments, l.expect(")")
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func parseDirectives(l *lexer, constant bool) ([]*Directive, error) {
+	directives := make([]*Directive, 0)
+
+	for l.peek("@") {
+		d := &Directive{Location: Location{Line: l.current.line, Column: l.current.column}}
+		err := l.next()
+		if err != nil {
+			return nil, err
+		}
+
+		if d.Name, err = l.expectName(); err != nil {
+			return nil, err
+		}
+		if l.peek("(") {
+			if d.Arguments, err = parseArguments(l, constant); err != nil {
+				return nil, err
+			}
+		}
+		directives = append(directives, d)
+	}
+
+	return directives, nil
+}
+
+// ------------------------------------------------------
+// constant values can not use variables
+// ------------------------------------------------------
+func parseValue(l *lexer, constant bool) (*Value, error) {
+	t := l.current
+	v := &Value{Raw: t.value}
+
+	switch {
+	case t.kind == tokenPunctuator && t.value == "$" && !constant:
+		if err := l.next(); err != nil {
+			return nil, err
+		}
+		name, err := l.expectName()
+		if err != nil {
+			return nil, err
+		}
+		return &Value{Kind: ValueVariable, Raw: name}, nil
+
+	case t.kind == tokenPunctuator && t.value == "[":
+		v.Kind = ValueList
+		if err := l.next(); err != nil {
+			return nil, err
+		}
+		for !l.peek("]") {
+			item, err := parseValue(l, constant)
+			if err != nil {
+				return nil, err
+			}
+			v.List = append(v.List, item)
+		}
+		return v, l.expect("]")
+
+	case t.kind == tokenPunctuator && t.value == "{":
+		v.Kind = ValueObject
+		if err := l.next(); err != nil {
+			return nil, err
+		}
+		for !l.peek("}") {
+			name, err := l.expectName()
+			if err != nil {
+				return nil, err
+			}
+			if err = l.expect(":"); err != nil {
+				return nil, err
+			}
+			value, err := parseValue(l, constant)
+			if err != nil {
+				return nil, err
+			}
+			v.Fields = append(v.Fields, &ObjectField{Name: name, Value: value})
+		}
+		return v, l.expect("}")
+
+	case t.kind == tokenInt:
+		v.Kind = ValueInt
+	case t.kind == tokenFloat:
+		v.Kind = ValueFloat
+	case t.kind == tokenString:
+		v.Kind = ValueString
+	case t.kind == tokenName && (t.value == "true" || t.value == "false"):
+		v.Kind = ValueBoolean
+	case t.kind == tokenName && t.value == "null":
+		v.Kind = ValueNull
+	case t.kind == tokenName:
+		v.Kind = ValueEnum
+	default:
+		return nil, l.errorf("Unexpected %s.", l.describe())
+	}
+
+	return v, l.next()
+}
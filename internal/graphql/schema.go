@@ -0,0 +1,770 @@
+package graphql
+
+import (
+	"fmt"
+	"strings"
+)
+
+// type kinds
+const (
+	KindScalar      = "SCALAR"
+	KindObject      = "OBJECT"
+	KindInterface   = "INTERFACE"
+	KindUnion       = "UNION"
+	KindEnum        = "ENUM"
+	KindInputObject = "INPUT_OBJECT"
+)
+
+var builtinScalars = []string{"Int", "Float", "String", "Boolean", "ID"}
+
+// ------------------------------------------------------
+// error in the format of the errors list of a GraphQL response
+// ------------------------------------------------------
+type Error struct {
+	Message   string     `json:"message"`
+	Locations []Location `json:"locations,omitempty"`
+	Path      []any      `json:"path,omitempty"`
+}
+
+type Location struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (e *Error) Error() string {
+	if len(e.Locations) > 0 {
+		return fmt.Sprintf("%s (%d:%d)", e.Message, e.Locations[0].Line, e.Locations[0].Column)
+	}
+	return e.Message
+}
+
+// ------------------------------------------------------
+// list and non null wrappers around a named type
+// ------------------------------------------------------
+type TypeRef struct {
+	Name    string
+	NonNull bool
+	Elem    *TypeRef
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (t *TypeRef) IsList() bool {
+	return t.Elem != nil
+}
+
+// ------------------------------------------------------
+// the type inside the lists
+// ------------------------------------------------------
+func (t *TypeRef) NamedType() string {
+	for t.Elem != nil {
+		t = t.Elem
+	}
+	return t.Name
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (t *TypeRef) String() string {
+	s := t.Name
+	if t.Elem != nil {
+		s = "[" + t.Elem.String() + "]"
+	}
+	if t.NonNull {
+		s += "!"
+	}
+	return s
+}
+
+type InputValue struct {
+	Name    string
+	Type    *TypeRef
+	Default *Value
+}
+
+type Field struct {
+	Name string
+	Args []*InputValue
+	Type *TypeRef
+
+	// fields of input objects
+	Default *Value
+}
+
+type Type struct {
+	Kind string
+	Name string
+
+	// objects, interfaces and input objects
+	Fields []*Field
+
+	Interfaces    []string
+	PossibleTypes []string
+	EnumValues    []string
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (t *Type) Field(name string) *Field {
+	for _, f := range t.Fields {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (t *Type) IsAbstract() bool {
+	return t.Kind == KindInterface || t.Kind == KindUnion
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (t *Type) IsLeaf() bool {
+	return t.Kind == KindScalar || t.Kind == KindEnum
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+type Schema struct {
+	Types map[string]*Type
+
+	// names of the defined types, in the order of the SDL
+	order []string
+
+	// directives the SDL defines, next to @skip and @include
+	directives map[string]bool
+
+	Query        string
+	Mutation     string
+	Subscription string
+}
+
+// ------------------------------------------------------
+// root type of query, mutation or subscription
+// ------------------------------------------------------
+func (s *Schema) RootType(operation string) *Type {
+	name := ""
+	switch operation {
+	case "query":
+		name = s.Query
+	case "mutation":
+		name = s.Mutation
+	case "subscription":
+		name = s.Subscription
+	}
+	if name == "" {
+		return nil
+	}
+	return s.Types[name]
+}
+
+// ------------------------------------------------------
+// object types of an interface or a union
+// ------------------------------------------------------
+func (s *Schema) PossibleTypes(t *Type) []*Type {
+	types := make([]*Type, 0)
+
+	switch t.Kind {
+	case KindObject:
+		types = append(types, t)
+	case KindUnion:
+		for _, name := range t.PossibleTypes {
+			if possible, found := s.Types[name]; found {
+				types = append(types, possible)
+			}
+		}
+	case KindInterface:
+		for _, name := range s.order {
+			possible := s.Types[name]
+			if possible.Kind != KindObject {
+				continue
+			}
+			for _, i := range possible.Interfaces {
+				if i == t.Name {
+					types = append(types, possible)
+					break
+				}
+			}
+		}
+	}
+	return types
+}
+
+// ------------------------------------------------------
+// fragment on typeCondition applies to the object type t
+// ------------------------------------------------------
+func (s *Schema) TypeApplies(t *Type, typeCondition string) bool {
+	if typeCondition == "" || typeCondition == t.Name {
+		return true
+	}
+	condition, found := s.Types[typeCondition]
+	if !found {
+		return false
+	}
+	for _, possible := range s.PossibleTypes(condition) {
+		if possible.Name == t.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// ------------------------------------------------------
+// an object can be of both types
+// ------------------------------------------------------
+func (s *Schema) TypesOverlap(a *Type, b *Type) bool {
+	for _, x := range s.PossibleTypes(a) {
+		for _, y := range s.PossibleTypes(b) {
+			if x.Name == y.Name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ------------------------------------------------------
+// Schema from the SDL. Descriptions and directives are read and
+// ignored, extend adds to a type defined before it
+// ------------------------------------------------------
+func ParseSchema(sdl string) (*Schema, error) {
+	if strings.TrimSpace(sdl) == "" {
+		return nil, &Error{Message: "schema is empty"}
+	}
+
+	s := &Schema{Types: make(map[string]*Type), directives: make(map[string]bool)}
+	for _, name := range builtinScalars {
+		s.Types[name] = &Type{Kind: KindScalar, Name: name}
+	}
+
+	// the introspection types come first, the SDL can not redefine them
+	err := s.parseDefinitions(introspectionSchema)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.parseDefinitions(sdl)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.check()
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (s *Schema) parseDefinitions(sdl string) error {
+	l, err := newLexer(sdl)
+	if err != nil {
+		return err
+	}
+
+	p := &schemaParser{l: l, s: s}
+	for l.current.kind != tokenEOF {
+		err = p.parseDefinition()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ------------------------------------------------------
+// field of t, __schema and __type are fields of the query type
+// ------------------------------------------------------
+func (s *Schema) Field(t *Type, name string) *Field {
+	if t.Name == s.Query {
+		switch name {
+		case schemaMetaField.Name:
+			return schemaMetaField
+		case typeMetaField.Name:
+			return typeMetaField
+		}
+	}
+	return t.Field(name)
+}
+
+// ------------------------------------------------------
+// every referenced type is defined and there is a query type
+// ------------------------------------------------------
+func (s *Schema) check() error {
+	for _, name := range []string{"Query", "Mutation", "Subscription"} {
+		if _, found := s.Types[name]; found {
+			switch name {
+			case "Query":
+				if s.Query == "" {
+					s.Query = name
+				}
+			case "Mutation":
+				if s.Mutation == "" {
+					s.Mutation = name
+				}
+			case "Subscription":
+				if s.Subscription == "" {
+					s.Subscription = name
+				}
+			}
+		}
+	}
+
+	if s.Query == "" {
+		return &Error{Message: "schema has no query type"}
+	}
+
+	for _, root := range []string{s.Query, s.Mutation, s.Subscription} {
+		if root == "" {
+			continue
+		}
+		t, found := s.Types[root]
+		if !found {
+			return &Error{Message: fmt.Sprintf("unknown root type %s", root)}
+		}
+		if t.Kind != KindObject {
+			return &Error{Message: fmt.Sprintf("root type %s must be an object type", root)}
+		}
+	}
+
+	for _, t := range s.Types {
+		for _, f := range t.Fields {
+			if _, found := s.Types[f.Type.NamedType()]; !found {
+				return &Error{Message: fmt.Sprintf("unknown type %s of %s.%s", f.Type.NamedType(), t.Name, f.Name)}
+			}
+			for _, a := range f.Args {
+				if _, found := s.Types[a.Type.NamedType()]; !found {
+					return &Error{Message: fmt.Sprintf("unknown type %s of argument %s of %s.%s", a.Type.NamedType(), a.Name, t.Name, f.Name)}
+				}
+			}
+		}
+		for _, name := range append(append([]string{}, t.Interfaces...), t.PossibleTypes...) {
+			if _, found := s.Types[name]; !found {
+				return &Error{Message: fmt.Sprintf("unknown type %s used by %s", name, t.Name)}
+			}
+		}
+	}
+
+	return nil
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+type schemaParser struct {
+	l *lexer
+	s *Schema
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (p *schemaParser) parseDefinition() error {
+	l := p.l
+
+	// description
+	if l.current.kind == tokenString {
+		if err := l.next(); err != nil {
+			return err
+		}
+	}
+
+	extend := false
+	if l.peekName("extend") {
+		extend = true
+		if err := l.next(); err != nil {
+			return err
+		}
+	}
+
+	keyword, err := l.expectName()
+	if err != nil {
+		return err
+	}
+
+	switch keyword {
+	case "schema":
+		return p.parseSchemaDefinition()
+	case "scalar":
+		return p.parseType(KindScalar, extend)
+	case "type":
+		return p.parseType(KindObject, extend)
+	case "interface":
+		return p.parseType(KindInterface, extend)
+	case "union":
+		return p.parseType(KindUnion, extend)
+	case "enum":
+		return p.parseType(KindEnum, extend)
+	case "input":
+		return p.parseType(KindInputObject, extend)
+	case "directive":
+		return p.parseDirectiveDefinition()
+	}
+
+	return &Error{
+		Message:   fmt.Sprintf("Syntax Error: Unexpected Name %q.", keyword),
+		Locations: []Location{{Line: l.current.line, Column: l.current.column}},
+	}
+}
+
+// ------------------------------------------------------
+// schema { query: Query mutation: Mutation }
+// ------------------------------------------------------
+func (p *schemaParser) parseSchemaDefinition() error {
+	l := p.l
+
+	if err := skipDirectives(l); err != nil {
+		return err
+	}
+	if err := l.expect("{"); err != nil {
+		return err
+	}
+
+	for !l.peek("}") {
+		operation, err := l.expectName()
+		if err != nil {
+			return err
+		}
+		if err = l.expect(":"); err != nil {
+			return err
+		}
+		name, err := l.expectName()
+		if err != nil {
+			return err
+		}
+
+		switch operation {
+		case "query":
+			p.s.Query = name
+		case "mutation":
+			p.s.Mutation = name
+		case "subscription":
+			p.s.Subscription = name
+		default:
+			return l.errorf("Unknown operation type %q.", operation)
+		}
+	}
+	return l.expect("}")
+}
+
+// ------------------------------------------------------
+// directive @name(args) on LOCATION | LOCATION
+// ------------------------------------------------------
+func (p *schemaParser) parseDirectiveDefinition() error {
+	l := p.l
+
+	if err := l.expect("@"); err != nil {
+		return err
+	}
+	name, err := l.expectName()
+	if err != nil {
+		return err
+	}
+	p.s.directives[name] = true
+
+	if l.peek("(") {
+		if _, err := p.parseInputValues("(", ")"); err != nil {
+			return err
+		}
+	}
+	if l.peekName("repeatable") {
+		if err := l.next(); err != nil {
+			return err
+		}
+	}
+	if !l.peekName("on") {
+		return l.errorf("Expected \"on\", found %s.", l.describe())
+	}
+	if err := l.next(); err != nil {
+		return err
+	}
+
+	if _, err := l.skip("|"); err != nil {
+		return err
+	}
+	for {
+		if _, err := l.expectName(); err != nil {
+			return err
+		}
+		more, err := l.skip("|")
+		if err != nil {
+			return err
+		}
+		if !more {
+			return nil
+		}
+	}
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (p *schemaParser) parseType(kind string, extend bool) error {
+	l := p.l
+
+	line, column := l.current.line, l.current.column
+	name, err := l.expectName()
+	if err != nil {
+		return err
+	}
+
+	t, found := p.s.Types[name]
+	switch {
+	case extend && !found:
+		return &Error{Message: fmt.Sprintf("cannot extend unknown type %s", name), Locations: []Location{{Line: line, Column: column}}}
+	case extend && t.Kind != kind:
+		return &Error{Message: fmt.Sprintf("cannot extend %s as %s", name, kind), Locations: []Location{{Line: line, Column: column}}}
+	case !extend && found:
+		return &Error{Message: fmt.Sprintf("type %s is defined more than once", name), Locations: []Location{{Line: line, Column: column}}}
+	case !found:
+		t = &Type{Kind: kind, Name: name}
+		p.s.Types[name] = t
+		p.s.order = append(p.s.order, name)
+	}
+
+	if (kind == KindObject || kind == KindInterface) && l.peekName("implements") {
+		if err = l.next(); err != nil {
+			return err
+		}
+		if _, err = l.skip("&"); err != nil {
+			return err
+		}
+		for {
+			i, err := l.expectName()
+			if err != nil {
+				return err
+			}
+			t.Interfaces = append(t.Interfaces, i)
+
+			more, err := l.skip("&")
+			if err != nil {
+				return err
+			}
+			if !more {
+				break
+			}
+		}
+	}
+
+	if err = skipDirectives(l); err != nil {
+		return err
+	}
+
+	switch kind {
+	case KindObject, KindInterface:
+		if l.peek("{") {
+			return p.parseFields(t)
+		}
+
+	case KindInputObject:
+		if l.peek("{") {
+			values, err := p.parseInputValues("{", "}")
+			if err != nil {
+				return err
+			}
+			for _, v := range values {
+				t.Fields = append(t.Fields, &Field{Name: v.Name, Type: v.Type, Default: v.Default})
+			}
+		}
+
+	case KindUnion:
+		if found, err := l.skip("="); !found || err != nil {
+			return err
+		}
+		if _, err = l.skip("|"); err != nil {
+			return err
+		}
+		for {
+			member, err := l.expectName()
+			if err != nil {
+				return err
+			}
+			t.PossibleTypes = append(t.PossibleTypes, member)
+
+			more, err := l.skip("|")
+			if err != nil {
+				return err
+			}
+			if !more {
+				break
+			}
+		}
+
+	case KindEnum:
+		if found, err := l.skip("{"); !found || err != nil {
+			return err
+		}
+		for !l.peek("}") {
+			if l.current.kind == tokenString {
+				if err = l.next(); err != nil {
+					return err
+				}
+			}
+			value, err := l.expectName()
+			if err != nil {
+				return err
+			}
+			t.EnumValues = append(t.EnumValues, value)
+			if err = skipDirectives(l); err != nil {
+				return err
+			}
+		}
+		return l.expect("}")
+	}
+
+	return nil
+}
+
+// ------------------------------------------------------
+// { name(args): Type @directive }
+// ------------------------------------------------------
+func (p *schemaParser) parseFields(t *Type) error {
+	l := p.l
+
+	if err := l.expect("{"); err != nil {
+		return err
+	}
+
+	for !l.peek("}") {
+		if l.current.kind == tokenString {
+			if err := l.next(); err != nil {
+				return err
+			}
+		}
+
+		name, err := l.expectName()
+		if err != nil {
+			return err
+		}
+		field := &Field{Name: name}
+
+		if l.peek("(") {
+			field.Args, err = p.parseInputValues("(", ")")
+			if err != nil {
+				return err
+			}
+		}
+
+		if err = l.expect(":"); err != nil {
+			return err
+		}
+		field.Type, err = parseTypeRef(l)
+		if err != nil {
+			return err
+		}
+		if err = skipDirectives(l); err != nil {
+			return err
+		}
+
+		t.Fields = append(t.Fields, field)
+	}
+
+	return l.expect("}")
+}
+
+// ------------------------------------------------------
+// arguments and input fields: name: Type = default
+// ------------------------------------------------------
+func (p *schemaParser) parseInputValues(open string, close string) ([]*InputValue, error) {
+	l := p.l
+
+	if err := l.expect(open); err != nil {
+		return nil, err
+	}
+
+	values := make([]*InputValue, 0)
+	for !l.peek(close) {
+		if l.current.kind == tokenString {
+			if err := l.next(); err != nil {
+				return nil, err
+			}
+		}
+
+		name, err := l.expectName()
+		if err != nil {
+			return nil, err
+		}
+		if err = l.expect(":"); err != nil {
+			return nil, err
+		}
+		typeRef, err := parseTypeRef(l)
+		if err != nil {
+			return nil, err
+		}
+
+		value := &InputValue{Name: name, Type: typeRef}
+		if l.peek("=") {
+			if err = l.next(); err != nil {
+				return nil, err
+			}
+			value.Default, err = parseValue(l, true)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if err = skipDirectives(l); err != nil {
+			return nil, err
+		}
+
+		values = append(values, value)
+	}
+
+	return values, l.expect(close)
+}
+
+// ------------------------------------------------------
+// Name, [Type], Type!
+// ------------------------------------------------------
+func parseTypeRef(l *lexer) (*TypeRef, error) {
+	t := &TypeRef{}
+
+	if l.peek("[") {
+		if err := l.next(); err != nil {
+			return nil, err
+		}
+		elem, err := parseTypeRef(l)
+		if err != nil {
+			return nil, err
+		}
+		t.Elem = elem
+		if err = l.expect("]"); err != nil {
+			return nil, err
+		}
+	} else {
+		name, err := l.expectName()
+		if err != nil {
+			return nil, err
+		}
+		t.Name = name
+	}
+
+	nonNull, err := l.skip("!")
+	if err != nil {
+		return nil, err
+	}
+	t.NonNull = nonNull
+
+	return t, nil
+}
+
+// ------------------------------------------------------
+// directives of the schema have no meaning for the mock
+// ------------------------------------------------------
+func skipDirectives(l *lexer) error {
+	_, err := parseDirectives(l, true)
+	return err
+}
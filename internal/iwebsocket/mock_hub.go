@@ -0,0 +1,157 @@
+package iwebsocket
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+// MockClient is a client connected to a mock WebSocket endpoint.
+// gorilla allows one writer at a time, replies, pushes and the
+// admin api all write through Send.
+type MockClient struct {
+	conn *websocket.Conn
+	mu   sync.Mutex
+
+	closing bool // close frame sent, nothing else goes out
+
+	RemoteAddr  string    `json:"remoteaddr"`
+	ConnectedAt time.Time `json:"connectedat"`
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func NewMockClient(conn *websocket.Conn) *MockClient {
+	return &MockClient{
+		conn:        conn,
+		RemoteAddr:  conn.RemoteAddr().String(),
+		ConnectedAt: time.Now().Local(),
+	}
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (c *MockClient) Send(message string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closing {
+		return websocket.ErrCloseSent
+	}
+
+	c.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+	return c.conn.WriteMessage(websocket.TextMessage, []byte(message))
+}
+
+// ------------------------------------------------------
+// close frame first, the read loop ends when the client answers
+// or after a few seconds when it does not
+// ------------------------------------------------------
+func (c *MockClient) Close(code int, reason string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closing {
+		return websocket.ErrCloseSent
+	}
+	c.closing = true
+
+	c.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	err := c.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(time.Second))
+	if err != nil {
+		return c.conn.Close()
+	}
+	return nil
+}
+
+// ------------------------------------------------------
+// true once the close frame is sent
+// ------------------------------------------------------
+func (c *MockClient) Closing() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.closing
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+// MockHub keeps the connected clients by mock endpoint id
+type MockHub struct {
+	mu      sync.Mutex
+	clients map[string]map[*MockClient]bool
+}
+
+var MockClients = &MockHub{clients: make(map[string]map[*MockClient]bool)}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (h *MockHub) Add(endPointID string, client *MockClient) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.clients[endPointID] == nil {
+		h.clients[endPointID] = make(map[*MockClient]bool)
+	}
+	h.clients[endPointID][client] = true
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (h *MockHub) Remove(endPointID string, client *MockClient) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.clients[endPointID], client)
+	if len(h.clients[endPointID]) == 0 {
+		delete(h.clients, endPointID)
+	}
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (h *MockHub) List(endPointID string) []*MockClient {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	clients := make([]*MockClient, 0, len(h.clients[endPointID]))
+	for client := range h.clients[endPointID] {
+		clients = append(clients, client)
+	}
+	return clients
+}
+
+// ------------------------------------------------------
+// number of clients the message was sent to
+// ------------------------------------------------------
+func (h *MockHub) Push(endPointID string, message string) int {
+	sent := 0
+	for _, client := range h.List(endPointID) {
+		if client.Send(message) == nil {
+			sent++
+		}
+	}
+	return sent
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (h *MockHub) CloseAll(endPointID string, code int, reason string) int {
+	clients := h.List(endPointID)
+	for _, client := range clients {
+		client.Close(code, reason)
+	}
+	return len(clients)
+}
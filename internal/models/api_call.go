@@ -5,7 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"html"
-	"io/ioutil"
+	"io"
 	"log"
 	"math/rand"
 	"net/http"
@@ -16,6 +16,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/brianvoe/gofakeit/v6"
 	"github.com/onlysumitg/GoMockAPI/utils/httputils"
 	"github.com/onlysumitg/GoMockAPI/utils/xmlutils"
 	bolt "go.etcd.io/bbolt"
@@ -36,6 +37,8 @@ type CallResponse struct {
 	ResponseHeader     map[string]string `json:"header" db:"header" form:"header"`
 	ResponseHeaderType string            `json:"headertype" db:"headertype" form:"headertype"`
 
+	Cookies map[string]string `json:"cookies" db:"cookies" form:"cookies"`
+
 	Response     string `json:"response" db:"response" form:"response"`
 	ResponseType string `json:"responsetype" db:"responsetype" form:"responsetype"`
 }
@@ -51,7 +54,9 @@ type ApiCall struct {
 
 	FinalResponseString string
 	FinalResponseHeader map[string]string
+	FinalCookies        map[string]string
 	FinalResponseType   string
+	FinalContentType    string
 
 	StatusCode int
 
@@ -71,6 +76,13 @@ type ApiCall struct {
 
 	DB *bolt.DB
 
+	// main db: scenarios and other state shared across calls
+	DataDB *bolt.DB
+
+	// scenario states as seen at the start of the call and pending transitions
+	ScenarioStates       map[string]string
+	ScenarioStateChanges map[string]string
+
 	HttpRequest *http.Request
 
 	CurrentEndPoint *EndPoint
@@ -81,7 +93,47 @@ type ApiCall struct {
 
 	UsingAcutalUrlResponse bool
 
+	// the actual url response is saved, it is never streamed
+	Recording bool
+
 	ActualCallResult *httputils.HttpCallResult
+
+	// position of the repeated array element being filled, 0 outside repeats
+	RepeatIndex int
+
+	// <response id>_<param key> of the placeholders in repeated elements
+	repeatedParams map[string]bool
+
+	// outbound calls made after the response
+	PendingWebhooks WebhookList
+
+	requestBody     []byte
+	requestBodyRead bool
+
+	faker *gofakeit.Faker
+}
+
+// ------------------------------------------------------
+// body is read once and put back so it can be read again
+// ------------------------------------------------------
+func (a *ApiCall) RequestBody() []byte {
+	if a.requestBodyRead {
+		return a.requestBody
+	}
+	a.requestBodyRead = true
+
+	if a.HttpRequest == nil || a.HttpRequest.Body == nil {
+		return a.requestBody
+	}
+
+	body, err := io.ReadAll(a.HttpRequest.Body)
+	if err != nil {
+		a.LogError(fmt.Sprintf("Error reading request body: %s", err.Error()))
+	}
+	a.requestBody = body
+	a.HttpRequest.Body = io.NopCloser(bytes.NewReader(body))
+
+	return a.requestBody
 }
 
 // ------------------------------------------------------
@@ -95,13 +147,19 @@ func (a *ApiCall) CopyResponseString(e *EndPoint) {
 			Httpcode:           r.HttpCode,
 			Name:               r.Name,
 			ResponseHeader:     make(map[string]string),
+			Cookies:            make(map[string]string),
 			ResponseHeaderType: r.ResponseHeaderType,
 			Response:           html.UnescapeString(r.ResponsePlaceholder),
 			ResponseType:       r.ResponseType,
 		}
+		if IsTextResponseType(r.ResponseType) {
+			c.Response = r.ResponsePlaceholder // html entities are part of the text
+		}
 		a.ResponseMapXX[i] = c
 	}
 
+	a.ExpandRepeats(e)
+
 }
 
 // ------------------------------------------------------
@@ -129,6 +187,10 @@ func (a *ApiCall) Finalize() {
 
 					a.LogInfo(fmt.Sprintf("ResponseCode from ActualEndPoint %d", a.StatusCode))
 					a.FinalResponseString = httpCallResult.Body
+					a.FinalContentType = httpCallResult.Header.Get("Content-Type")
+					if httpCallResult.Stream != nil {
+						a.LogInfo("Streaming response from ActualEndPoint")
+					}
 					//json.Unmarshal([]byte(httpCallResult.Body), &a.Response)
 					//a.ResponseHeader = httputils.GetHeadersAsMap2(httpCallResult.Header)
 
@@ -147,9 +209,14 @@ func (a *ApiCall) Finalize() {
 			a.LogInfo("Skipped call ActualEndPoint due to blank URL")
 
 		}
+	}
 
-	} else {
+	// mock response, also when the actual url could not be called
+	if !a.UsingAcutalUrlResponse {
 		a.ProcessAdditionalResponseValues()
+		if a.CurrentEndPoint.ResourceMode {
+			a.ProcessResource()
+		}
 		a.CheckAdditionalDelay()
 	}
 
@@ -167,8 +234,21 @@ func (a *ApiCall) ProcessAdditionalResponseValues() {
 	a.FinalResponseString = r.Response
 	a.FinalResponseType = r.ResponseType
 	a.FinalResponseHeader = r.ResponseHeader
+	a.FinalCookies = r.Cookies
 	a.StatusCode = r.Httpcode
 
+	if a.CurrentEndPoint != nil {
+		response := a.CurrentEndPoint.GetResponseByID(r.ID)
+		a.FinalContentType = ResponseContentType(r.ResponseType, response.ContentType)
+		a.ApplyResponseTemplate(response)
+		if strings.EqualFold(a.FinalResponseType, SoapType) {
+			a.WrapSoapResponse(response)
+		}
+		if strings.EqualFold(a.FinalResponseType, GraphqlType) {
+			a.ResolveGraphqlResponse(response)
+		}
+		a.QueueWebhooks(response.Webhooks)
+	}
 }
 
 // ------------------------------------------------------
@@ -231,20 +311,20 @@ func (a *ApiCall) HTTPCall() *httputils.HttpCallResult {
 
 	a.ActualUrlToUse = finalUrlToUse
 
-	if a.CurrentEndPoint.isMethod("GET") {
-		return a.GETCall(finalUrlToUse)
-	}
-	if a.CurrentEndPoint.isMethod("POST") {
-		return a.POSTCall(finalUrlToUse)
-	}
-	if a.CurrentEndPoint.isMethod("PUT") {
-		return a.PUTCall(finalUrlToUse)
-	}
-	if a.CurrentEndPoint.isMethod("DELETE") {
-		return a.DELETECall(finalUrlToUse)
+	// the method of the call, resource endpoints answer more than one
+	method := strings.ToUpper(a.HttpRequest.Method)
+
+	var body []byte
+	if method != http.MethodGet && method != http.MethodHead {
+		body = a.RequestBody()
 	}
 
-	return nil
+	options := a.CurrentEndPoint.CallOptions()
+	options.Stream = options.Stream && !a.Recording
+	options.Context = a.HttpRequest.Context()
+
+	a.LogInfo(fmt.Sprintf("Calling %s %s, timeouts %s/%s", method, finalUrlToUse, options.ConnectTimeout, options.ReadTimeout))
+	return httputils.HttpCall(method, finalUrlToUse, httputils.ForwardedHeader(a.HttpRequest), body, options)
 }
 
 // ------------------------------------------------------
@@ -296,68 +376,6 @@ func (a *ApiCall) BuildUrlTOUse() (string, error) {
 	return baseUrl, nil
 }
 
-// ------------------------------------------------------
-//
-// ------------------------------------------------------
-func (a *ApiCall) GETCall(urlToUse string) *httputils.HttpCallResult {
-	var httpCallResult *httputils.HttpCallResult = nil
-	if a.CurrentEndPoint.isMethod("GET") {
-		httpCallResult = httputils.HttpGET(urlToUse, a.HttpRequest.Header)
-	}
-	return httpCallResult
-}
-
-// ------------------------------------------------------
-//
-// ------------------------------------------------------
-func (a *ApiCall) POSTCall(urlToUse string) *httputils.HttpCallResult {
-	var httpCallResult *httputils.HttpCallResult = nil
-
-	if a.CurrentEndPoint.isMethod("POST") {
-		body, err := ioutil.ReadAll(a.HttpRequest.Body)
-		if err != nil {
-			body = []byte("")
-		}
-
-		httpCallResult = httputils.HttpPOST(urlToUse, a.HttpRequest.Header, body)
-	}
-	return httpCallResult
-}
-
-// ------------------------------------------------------
-//
-// ------------------------------------------------------
-func (a *ApiCall) PUTCall(urlToUse string) *httputils.HttpCallResult {
-	var httpCallResult *httputils.HttpCallResult = nil
-
-	if a.CurrentEndPoint.isMethod("PUT") {
-		body, err := ioutil.ReadAll(a.HttpRequest.Body)
-		if err != nil {
-			body = []byte("")
-		}
-
-		httpCallResult = httputils.HttpPUT(urlToUse, a.HttpRequest.Header, body)
-	}
-	return httpCallResult
-}
-
-// ------------------------------------------------------
-//
-// ------------------------------------------------------
-func (a *ApiCall) DELETECall(urlToUse string) *httputils.HttpCallResult {
-	var httpCallResult *httputils.HttpCallResult = nil
-
-	if a.CurrentEndPoint.isMethod("DELETE") {
-		body, err := ioutil.ReadAll(a.HttpRequest.Body)
-		if err != nil {
-			body = []byte("")
-		}
-
-		httpCallResult = httputils.HttpDELETE(urlToUse, a.HttpRequest.Header, body)
-	}
-	return httpCallResult
-}
-
 // ------------------------------------------------------
 //
 // ------------------------------------------------------
@@ -371,6 +389,18 @@ func (apiCall *ApiCall) GetResponseToUse() *CallResponse {
 			return r
 		}
 	}
+
+	if selected := apiCall.CurrentEndPoint.SelectResponse(apiCall.SelectionSource()); selected != nil {
+		for _, r := range apiCall.ResponseMapXX {
+			if r.ID == selected.ID {
+				apiCall.LogInfo(fmt.Sprintf("Response %s %d selected by %s", r.Name, r.Httpcode, apiCall.CurrentEndPoint.ResponseSelection))
+				apiCall.ResponseID = r.ID
+				apiCall.StatusCode = r.Httpcode
+				return r
+			}
+		}
+	}
+
 	dResponse := apiCall.CurrentEndPoint.GetDefaultResponseID()
 	for _, r := range apiCall.ResponseMapXX {
 		if r.ID == dResponse.ID {
@@ -395,9 +425,10 @@ func (apiCall *ApiCall) GetHttpHeader() http.Header {
 
 	header["CORRELATIONID"] = []string{apiCall.ID}
 
-	for key, value := range apiCall.FinalResponseHeader {
-		header[key] = []string{value}
-	}
+	// Link[0], Link[1] are sent as two Link headers
+	buildMultiValueHeader(header, apiCall.FinalResponseHeader)
+
+	apiCall.addCookies(header)
 
 	delete(header, "Content-Length")
 
@@ -0,0 +1,209 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// an array element with a "*REPEAT" key is a template for the whole array:
+//
+//	"items": [{"*REPEAT": 5, "id": 1, "name": "x"}]
+//
+// the count is the response param of the key (items[0].*REPEAT): a number,
+// a random range like (1,10) or a REQUEST[INT]:pageSize override
+const RepeatKey = "*REPEAT"
+
+// override value for the position of the copy, starting at 1
+const RepeatIndexValue = "*INDEX"
+
+const MaxRepeat = 1000
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (a *ApiCall) repeatCount(response *EndPointResponse, key string) int {
+	var value any
+	for _, rp := range response.ResponseParams {
+		if rp.Key != key {
+			continue
+		}
+
+		value = rp.DefaultValue
+		if rp.OverrideValue != "" {
+			resolved, err := a.ResolveValue(rp.OverrideValue)
+			if err != nil {
+				a.LogError(err.Error())
+			}
+			value = resolved
+		}
+		break
+	}
+
+	countString := strings.TrimSpace(fmt.Sprint(value))
+
+	count := 0
+	matches := dealyRangeRegex.FindStringSubmatch(countString)
+	if len(matches) > 0 {
+		start, _ := strconv.Atoi(matches[dealyRangeRegex.SubexpIndex("Start")])
+		end, err := strconv.Atoi(matches[dealyRangeRegex.SubexpIndex("End")])
+		if err != nil || end < start {
+			end = start
+		}
+		count = start + a.RandomSource().Intn(end-start+1)
+	} else {
+		f, err := strconv.ParseFloat(countString, 64)
+		if err != nil {
+			a.LogError(fmt.Sprintf("Invalid repeat count %s for %s", countString, key))
+			return 1
+		}
+		count = int(f)
+	}
+
+	if count < 0 {
+		count = 0
+	}
+	if count > MaxRepeat {
+		a.LogInfo(fmt.Sprintf("Repeat count %d for %s capped at %d", count, key, MaxRepeat))
+		count = MaxRepeat
+	}
+	return count
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (a *ApiCall) expandRepeatValue(response *EndPointResponse, value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		// sorted keys so seeded random counts come out the same every call
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			v[k] = a.expandRepeatValue(response, v[k])
+		}
+		return v
+
+	case []any:
+		expanded := make([]any, 0, len(v))
+		for _, element := range v {
+			elementMap, ok := element.(map[string]any)
+			if !ok {
+				expanded = append(expanded, a.expandRepeatValue(response, element))
+				continue
+			}
+
+			placeholder, found := elementMap[RepeatKey]
+			if !found {
+				expanded = append(expanded, a.expandRepeatValue(response, element))
+				continue
+			}
+			delete(elementMap, RepeatKey)
+
+			key := strings.TrimSuffix(strings.TrimPrefix(fmt.Sprint(placeholder), "{{"), "}}")
+			count := a.repeatCount(response, key)
+			a.LogInfo(fmt.Sprintf("Repeating %s %d times", strings.TrimSuffix(key, "."+RepeatKey), count))
+
+			// same placeholders in every copy, process() resolves them one copy at a time
+			a.markRepeatedParams(response, elementMap)
+
+			// every copy expands its own nested *REPEAT, so ranged counts differ per copy
+			for i := 0; i < count; i++ {
+				expanded = append(expanded, a.expandRepeatValue(response, copyRepeatValue(elementMap)))
+			}
+		}
+		return expanded
+	}
+
+	return value
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func copyRepeatValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		copied := make(map[string]any, len(v))
+		for k, child := range v {
+			copied[k] = copyRepeatValue(child)
+		}
+		return copied
+	case []any:
+		copied := make([]any, len(v))
+		for i, child := range v {
+			copied[i] = copyRepeatValue(child)
+		}
+		return copied
+	}
+	return value
+}
+
+// -----------------------------------------------------------------
+// placeholders of a repeated element get *INDEX even for one copy
+// -----------------------------------------------------------------
+func (a *ApiCall) markRepeatedParams(response *EndPointResponse, value any) {
+	switch v := value.(type) {
+	case map[string]any:
+		for _, child := range v {
+			a.markRepeatedParams(response, child)
+		}
+	case []any:
+		for _, child := range v {
+			a.markRepeatedParams(response, child)
+		}
+	case string:
+		if strings.HasPrefix(v, "{{") && strings.HasSuffix(v, "}}") {
+			if a.repeatedParams == nil {
+				a.repeatedParams = make(map[string]bool)
+			}
+			key := strings.TrimSuffix(strings.TrimPrefix(v, "{{"), "}}")
+			a.repeatedParams[response.ID+"_"+key] = true
+		}
+	}
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (a *ApiCall) isRepeatedParam(responseID string, key string) bool {
+	return a.repeatedParams[responseID+"_"+key]
+}
+
+// -----------------------------------------------------------------
+// JSON responses only
+// -----------------------------------------------------------------
+func (a *ApiCall) ExpandRepeats(e *EndPoint) {
+	searchString := fmt.Sprintf("\"%s\"", RepeatKey)
+
+	for _, r := range a.ResponseMapXX {
+		if !strings.EqualFold(r.ResponseType, "JSON") || !strings.Contains(r.Response, searchString) {
+			continue
+		}
+
+		var parsed any
+		err := json.Unmarshal([]byte(r.Response), &parsed)
+		if err != nil {
+			a.LogError(fmt.Sprintf("Repeat skipped for %s %d: %s", r.Name, r.Httpcode, err.Error()))
+			continue
+		}
+
+		parsed = a.expandRepeatValue(e.GetResponseByID(r.ID), parsed)
+
+		buf := &bytes.Buffer{}
+		encoder := json.NewEncoder(buf)
+		encoder.SetEscapeHTML(false)
+		err = encoder.Encode(parsed)
+		if err != nil {
+			a.LogError(fmt.Sprintf("Repeat skipped for %s %d: %s", r.Name, r.Httpcode, err.Error()))
+			continue
+		}
+		r.Response = strings.TrimSpace(buf.String())
+	}
+}
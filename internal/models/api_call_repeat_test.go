@@ -0,0 +1,202 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/onlysumitg/GoMockAPI/utils/jsonutils"
+)
+
+// -----------------------------------------------------------------
+// call of a JSON response, params as a saved endpoint has them
+// -----------------------------------------------------------------
+func newRepeatCall(t *testing.T, response string, overrides map[string]string) (*ApiCall, *EndPoint) {
+	t.Helper()
+
+	placeholder, err := jsonutils.JsonToMapPlaceholder(response)
+	if err != nil {
+		t.Fatal(err)
+	}
+	placeholderJSON, _ := json.Marshal(placeholder)
+	flatmap, _ := jsonutils.JsonToFlatMap(response)
+
+	r := &EndPointResponse{ID: "r1", Name: "DEFAULT", HttpCode: 200, ResponseType: "JSON", Response: response}
+	for key, value := range flatmap {
+		r.ResponseParams = append(r.ResponseParams, &EndPointResponseParam{
+			OwnerId:         r.ID,
+			Key:             key,
+			DefaultValue:    value.Value,
+			DefaultDatatype: "INT",
+			OverrideValue:   overrides[key],
+		})
+	}
+
+	e := &EndPoint{ID: "repeat", ResponseMap: []*EndPointResponse{r}}
+	apiCall := &ApiCall{
+		CurrentEndPoint: e,
+		ResponseMapXX: []*CallResponse{
+			{ID: r.ID, Name: r.Name, Httpcode: 200, ResponseType: "JSON", Response: string(placeholderJSON)},
+		},
+		faker: gofakeit.NewUnlocked(1),
+	}
+	return apiCall, e
+}
+
+func Test_ApiCall_ExpandRepeats(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		override string
+		min, max int
+	}{
+		{"count", `{"items": [{"*REPEAT": 3, "id": 1}]}`, "", 3, 3},
+		{"zero", `{"items": [{"*REPEAT": 0, "id": 1}]}`, "", 0, 0},
+		{"negative", `{"items": [{"*REPEAT": -2, "id": 1}]}`, "", 0, 0},
+		{"not a number", `{"items": [{"*REPEAT": "many", "id": 1}]}`, "", 1, 1},
+		{"range", `{"items": [{"*REPEAT": "(2,4)", "id": 1}]}`, "", 2, 4},
+		{"override", `{"items": [{"*REPEAT": 1, "id": 1}]}`, "5", 5, 5},
+		{"capped", `{"items": [{"*REPEAT": 5000, "id": 1}]}`, "", MaxRepeat, MaxRepeat},
+		{"keeps other elements", `{"items": [{"id": 0}, {"*REPEAT": 2, "id": 1}, 7]}`, "", 4, 4},
+	}
+
+	for _, test := range tests {
+		apiCall, e := newRepeatCall(t, test.response, map[string]string{"items[0].*REPEAT": test.override, "items[1].*REPEAT": test.override})
+		apiCall.ExpandRepeats(e)
+
+		result := make(map[string][]any)
+		if err := json.Unmarshal([]byte(apiCall.ResponseMapXX[0].Response), &result); err != nil {
+			t.Errorf("%s: invalid response %s", test.name, apiCall.ResponseMapXX[0].Response)
+			continue
+		}
+		if n := len(result["items"]); n < test.min || n > test.max {
+			t.Errorf("%s: expected %d to %d items but got %d", test.name, test.min, test.max, n)
+		}
+		if strings.Contains(apiCall.ResponseMapXX[0].Response, RepeatKey) {
+			t.Errorf("%s: %s left in %s", test.name, RepeatKey, apiCall.ResponseMapXX[0].Response)
+		}
+	}
+}
+
+func Test_ApiCall_ExpandRepeats_Nested(t *testing.T) {
+	apiCall, e := newRepeatCall(t, `{"items": [{"*REPEAT": 3, "id": 1, "tags": [{"*REPEAT": 2, "t": 1}]}]}`, nil)
+	apiCall.ExpandRepeats(e)
+
+	result := make(map[string][]map[string]any)
+	if err := json.Unmarshal([]byte(apiCall.ResponseMapXX[0].Response), &result); err != nil {
+		t.Fatalf("invalid response %s", apiCall.ResponseMapXX[0].Response)
+	}
+	if len(result["items"]) != 3 {
+		t.Fatalf("expected 3 items but got %v", result)
+	}
+	for i, item := range result["items"] {
+		if tags, _ := item["tags"].([]any); len(tags) != 2 {
+			t.Errorf("item %d: expected 2 tags but got %v", i, item["tags"])
+		}
+	}
+
+	// a nested range is drawn for every outer copy, the seed repeats the draws
+	tagCounts := func() []int {
+		apiCall, e := newRepeatCall(t, `{"items": [{"*REPEAT": 12, "id": 1, "tags": [{"*REPEAT": "(1,6)", "t": 1}]}]}`, nil)
+		apiCall.ExpandRepeats(e)
+
+		result := make(map[string][]map[string]any)
+		if err := json.Unmarshal([]byte(apiCall.ResponseMapXX[0].Response), &result); err != nil {
+			t.Fatalf("invalid response %s", apiCall.ResponseMapXX[0].Response)
+		}
+		counts := make([]int, 0)
+		for _, item := range result["items"] {
+			tags, _ := item["tags"].([]any)
+			counts = append(counts, len(tags))
+		}
+		return counts
+	}
+
+	counts := tagCounts()
+	distinct := make(map[int]bool)
+	for i, n := range counts {
+		if n < 1 || n > 6 {
+			t.Errorf("item %d: expected 1 to 6 tags but got %d", i, n)
+		}
+		distinct[n] = true
+	}
+	if len(counts) != 12 || len(distinct) < 2 {
+		t.Errorf("expected 12 items with different tag counts but got %v", counts)
+	}
+	if again := tagCounts(); fmt.Sprint(again) != fmt.Sprint(counts) {
+		t.Errorf("expected %v again with the same seed but got %v", counts, again)
+	}
+
+	// a response without *REPEAT is left alone
+	apiCall, e = newRepeatCall(t, `{"items": [{"id": 1}]}`, nil)
+	before := apiCall.ResponseMapXX[0].Response
+	apiCall.ExpandRepeats(e)
+	if apiCall.ResponseMapXX[0].Response != before {
+		t.Errorf("expected %s but got %s", before, apiCall.ResponseMapXX[0].Response)
+	}
+}
+
+func Test_ApiCall_RepeatIndex(t *testing.T) {
+	tests := []struct {
+		name     string
+		count    int
+		expected []int
+	}{
+		{"several copies", 3, []int{1, 2, 3}},
+		{"single copy", 1, []int{1}},
+		{"no copies", 0, []int{}},
+	}
+
+	for _, test := range tests {
+		response := fmt.Sprintf(`{"items": [{"*REPEAT": %d, "id": 0}], "total": 0}`, test.count)
+		apiCall, e := newRepeatCall(t, response, map[string]string{"items[0].id": RepeatIndexValue, "total": RepeatIndexValue})
+		apiCall.ExpandRepeats(e)
+
+		for _, p := range e.ResponseMap[0].ResponseParams {
+			if !strings.HasPrefix(p.Key, "*") && !strings.HasSuffix(p.Key, RepeatKey) {
+				p.process(apiCall, "r1", "")
+			}
+		}
+
+		result := struct {
+			Items []struct {
+				ID int `json:"id"`
+			} `json:"items"`
+			Total int `json:"total"`
+		}{}
+		if err := json.Unmarshal([]byte(apiCall.ResponseMapXX[0].Response), &result); err != nil {
+			t.Errorf("%s: invalid response %s", test.name, apiCall.ResponseMapXX[0].Response)
+			continue
+		}
+
+		ids := make([]int, 0)
+		for _, item := range result.Items {
+			ids = append(ids, item.ID)
+		}
+		if fmt.Sprint(ids) != fmt.Sprint(test.expected) {
+			t.Errorf("%s: expected ids %v but got %v", test.name, test.expected, ids)
+		}
+
+		// outside a repeated element *INDEX is 0
+		if result.Total != 0 {
+			t.Errorf("%s: expected total 0 but got %d", test.name, result.Total)
+		}
+	}
+}
+
+// seeded calls repeat the same range count
+func Test_ApiCall_ExpandRepeats_SeededRange(t *testing.T) {
+	counts := make(map[int]bool)
+	for i := 0; i < 5; i++ {
+		apiCall, e := newRepeatCall(t, `{"items": [{"*REPEAT": "(1,50)", "id": 1}]}`, nil)
+		apiCall.faker = &gofakeit.Faker{Rand: rand.New(rand.NewSource(9))}
+		apiCall.ExpandRepeats(e)
+		counts[strings.Count(apiCall.ResponseMapXX[0].Response, `"{{items[0].id}}"`)] = true
+	}
+	if len(counts) != 1 {
+		t.Errorf("expected one count for one seed but got %v", counts)
+	}
+}
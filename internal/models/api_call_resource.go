@@ -0,0 +1,185 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// placeholder value in a response template that gets the resource payload
+const ResourcePayloadKey = "*RESOURCE"
+
+var ResourceMethods = []string{"get", "post", "put", "patch", "delete"}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (a *ApiCall) resourceItemFromBody() (map[string]any, error) {
+	item := make(map[string]any)
+
+	body := a.RequestBody()
+	if strings.TrimSpace(string(body)) == "" {
+		return item, errors.New("request body is required")
+	}
+
+	err := json.Unmarshal(body, &item)
+	if err != nil {
+		return item, fmt.Errorf("request body must be a JSON object: %s", err.Error())
+	}
+	return item, nil
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (a *ApiCall) resourceError(statusCode int, message string) {
+	a.LogError(fmt.Sprintf("Resource call failed %d: %s", statusCode, message))
+	a.StatusCode = statusCode
+	a.UseJSONResponseType()
+	buf, _ := json.Marshal(map[string]string{"error": message})
+	a.FinalResponseString = string(buf)
+}
+
+// ------------------------------------------------------
+// put the payload in place of "*RESOURCE" in the selected
+// response, or send the payload as is
+// ------------------------------------------------------
+func (a *ApiCall) resourceResult(statusCode int, payload any) {
+	buf, err := json.Marshal(payload)
+	if err != nil {
+		a.resourceError(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	a.StatusCode = statusCode
+
+	searchString := fmt.Sprintf("\"%s\"", ResourcePayloadKey)
+	if strings.EqualFold(a.FinalResponseType, "JSON") && strings.Contains(a.FinalResponseString, searchString) {
+		a.LogInfo("Wrapping resource payload in response template")
+		a.FinalResponseString = strings.ReplaceAll(a.FinalResponseString, searchString, string(buf))
+		return
+	}
+
+	a.UseJSONResponseType()
+	a.FinalResponseString = string(buf)
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (a *ApiCall) ProcessResource() {
+	e := a.CurrentEndPoint
+
+	if a.HasSet("*HTTP_STATUS_CODE") {
+		a.LogInfo("Response selected by condition group. Skipping resource processing")
+		return
+	}
+
+	if a.DataDB == nil {
+		a.resourceError(http.StatusInternalServerError, "resource store not available")
+		return
+	}
+
+	resourceModel := &ResourceModel{DB: a.DataDB}
+
+	id := ""
+	if len(a.PathParams) > 0 {
+		id = resourceIDString(a.PathParams[0].Value)
+	}
+
+	method := strings.ToUpper(a.HttpRequest.Method)
+	a.LogInfo(fmt.Sprintf("Resource %s: %s %s", e.GetResourceName(), method, id))
+
+	switch method {
+	case http.MethodGet, http.MethodHead:
+		if id == "" {
+			items, err := resourceModel.List(e)
+			if err != nil {
+				a.resourceError(http.StatusInternalServerError, err.Error())
+				return
+			}
+			a.resourceResult(http.StatusOK, items)
+			return
+		}
+
+		item, err := resourceModel.Get(e, id)
+		if err != nil {
+			a.resourceStoreError(err, id)
+			return
+		}
+		a.resourceResult(http.StatusOK, item)
+
+	case http.MethodPost:
+		if id != "" {
+			a.resourceError(http.StatusMethodNotAllowed, "POST is only allowed on the collection")
+			return
+		}
+
+		item, err := a.resourceItemFromBody()
+		if err != nil {
+			a.resourceError(http.StatusBadRequest, err.Error())
+			return
+		}
+
+		_, err = resourceModel.Insert(e, item)
+		if err != nil {
+			a.resourceStoreError(err, resourceIDString(item[e.GetResourceIDField()]))
+			return
+		}
+		a.resourceResult(http.StatusCreated, item)
+
+	case http.MethodPut, http.MethodPatch:
+		if id == "" {
+			a.resourceError(http.StatusMethodNotAllowed, fmt.Sprintf("%s needs an id in the path", method))
+			return
+		}
+
+		item, err := a.resourceItemFromBody()
+		if err != nil {
+			a.resourceError(http.StatusBadRequest, err.Error())
+			return
+		}
+
+		item, err = resourceModel.Update(e, id, item, method == http.MethodPatch)
+		if err != nil {
+			a.resourceStoreError(err, id)
+			return
+		}
+		a.resourceResult(http.StatusOK, item)
+
+	case http.MethodDelete:
+		if id == "" {
+			a.resourceError(http.StatusMethodNotAllowed, "DELETE needs an id in the path")
+			return
+		}
+
+		item, err := resourceModel.Get(e, id)
+		if err == nil {
+			err = resourceModel.Delete(e, id)
+		}
+		if err != nil {
+			a.resourceStoreError(err, id)
+			return
+		}
+		a.resourceResult(http.StatusOK, item)
+
+	default:
+		a.resourceError(http.StatusMethodNotAllowed, fmt.Sprintf("%s not supported", method))
+	}
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (a *ApiCall) resourceStoreError(err error, id string) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		a.resourceError(http.StatusNotFound, fmt.Sprintf("%s %s not found", a.CurrentEndPoint.GetResourceName(), id))
+	case errors.Is(err, ErrDuplicateResource):
+		a.resourceError(http.StatusConflict, fmt.Sprintf("%s %s already exists", a.CurrentEndPoint.GetResourceName(), id))
+	default:
+		a.resourceError(http.StatusInternalServerError, err.Error())
+	}
+}
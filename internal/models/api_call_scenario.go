@@ -0,0 +1,77 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ------------------------------------------------------
+// states are read once per call, so transitions made by
+// this call are not visible to its own condition groups
+// ------------------------------------------------------
+func (a *ApiCall) GetScenarioState(id string) (string, error) {
+	if a.ScenarioStates == nil {
+		a.ScenarioStates = make(map[string]string)
+	}
+
+	state, found := a.ScenarioStates[id]
+	if found {
+		return state, nil
+	}
+
+	if a.DataDB == nil {
+		return "", errors.New("scenario store not available")
+	}
+
+	scenarioModel := &ScenarioModel{DB: a.DataDB}
+	state, err := scenarioModel.GetState(id)
+	if err != nil {
+		return "", err
+	}
+
+	a.ScenarioStates[id] = state
+	return state, nil
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (a *ApiCall) QueueScenarioState(id string, state string) {
+	trackKey := fmt.Sprintf("*SCENARIO_%s", id)
+	if a.HasSet(trackKey) {
+		a.LogInfo(fmt.Sprintf("Scenario %s transition already assigned. No overrides", id))
+		return
+	}
+
+	if a.ScenarioStateChanges == nil {
+		a.ScenarioStateChanges = make(map[string]string)
+	}
+
+	a.ScenarioStateChanges[id] = NormalizeScenarioState(state)
+	a.SetKey(trackKey)
+	a.LogInfo(fmt.Sprintf("Scenario %s will move to state %s", id, NormalizeScenarioState(state)))
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (a *ApiCall) ApplyScenarioStates() {
+	if len(a.ScenarioStateChanges) == 0 || a.DataDB == nil {
+		return
+	}
+
+	scenarioModel := &ScenarioModel{DB: a.DataDB}
+	for id, state := range a.ScenarioStateChanges {
+		// the state this call matched on, blank when it did not read one
+		expected := a.ScenarioStates[id]
+
+		changed, err := scenarioModel.CompareAndSetState(id, expected, state)
+		if err != nil {
+			a.LogError(fmt.Sprintf("Scenario %s transition to %s failed: %s", id, state, err.Error()))
+		} else if !changed {
+			a.LogInfo(fmt.Sprintf("Scenario %s transition to %s skipped, no longer in state %s", id, state, expected))
+		} else {
+			a.LogInfo(fmt.Sprintf("Scenario %s moved to state %s", id, state))
+		}
+	}
+}
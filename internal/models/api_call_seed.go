@@ -0,0 +1,114 @@
+package models
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"strconv"
+	"strings"
+
+	"github.com/brianvoe/gofakeit/v6"
+)
+
+// request header with the seed for one call
+const RandomSeedHeader = "X-Mock-Seed"
+
+// -----------------------------------------------------------------
+// numbers are used as they are, any other text is hashed
+// -----------------------------------------------------------------
+func RandomSeedFromString(value string) int64 {
+	value = strings.TrimSpace(value)
+
+	seed, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		h := fnv.New64a()
+		h.Write([]byte(value))
+		seed = int64(h.Sum64())
+	}
+
+	// 0 makes gofakeit pick a random seed
+	if seed == 0 {
+		seed = 1
+	}
+	return seed
+}
+
+// -----------------------------------------------------------------
+// header first, then the endpoint, then the collection.
+// endpoint and collection seeds can be a value expression like
+// REQUEST[STRING]:customerId for the same data per entity
+// -----------------------------------------------------------------
+func (a *ApiCall) RandomSeed() (int64, bool) {
+	if a.HttpRequest != nil {
+		headerSeed := strings.TrimSpace(a.HttpRequest.Header.Get(RandomSeedHeader))
+		if headerSeed != "" {
+			a.LogInfo(fmt.Sprintf("Random seed from %s header: %s", RandomSeedHeader, headerSeed))
+			return RandomSeedFromString(headerSeed), true
+		}
+	}
+
+	seedSource := ""
+	seedOwner := ""
+	if a.CurrentEndPoint != nil {
+		seedSource = strings.TrimSpace(a.CurrentEndPoint.RandomSeed)
+		seedOwner = "endpoint"
+
+		// collection of the endpoint cache, no db read per call
+		if collection := a.CurrentEndPoint.Collection; seedSource == "" && collection != nil {
+			seedSource = strings.TrimSpace(collection.RandomSeed)
+			seedOwner = "collection"
+		}
+	}
+
+	if seedSource == "" {
+		return 0, false
+	}
+
+	seedValue, err := a.ResolveValue(seedSource)
+	if err != nil {
+		a.LogError(fmt.Sprintf("Random seed %s not used: %s", seedSource, err.Error()))
+		return 0, false
+	}
+
+	a.LogInfo(fmt.Sprintf("Random seed from %s: %v", seedOwner, seedValue))
+	return RandomSeedFromString(fmt.Sprint(seedValue)), true
+}
+
+// -----------------------------------------------------------------
+// one faker per call so seeded calls repeat the same sequence
+// -----------------------------------------------------------------
+func (a *ApiCall) RandomFaker() *gofakeit.Faker {
+	if a.faker != nil {
+		return a.faker
+	}
+
+	a.faker = randomFaker
+	seed, found := a.RandomSeed()
+	if found {
+		a.faker = gofakeit.New(seed)
+	}
+	return a.faker
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (a *ApiCall) RandomSource() *rand.Rand {
+	return a.RandomFaker().Rand
+}
+
+// -----------------------------------------------------------------
+// source of the RANDOM response selection. The endpoint and
+// collection seeds are not used: a source seeded fresh for every call
+// makes the same first draw, so one response would be picked forever.
+// Only the X-Mock-Seed header fixes it, to repeat one call.
+// -----------------------------------------------------------------
+func (a *ApiCall) SelectionSource() *rand.Rand {
+	if a.HttpRequest != nil {
+		headerSeed := strings.TrimSpace(a.HttpRequest.Header.Get(RandomSeedHeader))
+		if headerSeed != "" {
+			return rand.New(rand.NewSource(RandomSeedFromString(headerSeed)))
+		}
+	}
+	return randomFaker.Rand
+}
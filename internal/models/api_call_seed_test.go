@@ -0,0 +1,84 @@
+package models
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/onlysumitg/GoMockAPI/utils/xmlutils"
+)
+
+// endpoint seeds fix *RANDOM values, not the weighted response pick
+func Test_ApiCall_SelectionSource(t *testing.T) {
+	endPoint := &EndPoint{
+		ID:                "seeded",
+		RandomSeed:        "42",
+		ResponseSelection: ResponseSelectionRandom,
+		ResponseMap: []*EndPointResponse{
+			{ID: "ok", Weight: 50},
+			{ID: "error", Weight: 50},
+		},
+	}
+
+	picked := make(map[string]int)
+	for i := 0; i < 200; i++ {
+		apiCall := &ApiCall{
+			HttpRequest:     httptest.NewRequest(http.MethodGet, "/api/v1/seeded", nil),
+			CurrentEndPoint: endPoint,
+		}
+		picked[endPoint.SelectResponse(apiCall.SelectionSource()).ID]++
+	}
+	if picked["ok"] == 0 || picked["error"] == 0 {
+		t.Errorf("expected both responses with an endpoint seed but got %v", picked)
+	}
+
+	// the seed header repeats the pick
+	first := ""
+	for i := 0; i < 20; i++ {
+		request := httptest.NewRequest(http.MethodGet, "/api/v1/seeded", nil)
+		request.Header.Set(RandomSeedHeader, "7")
+		apiCall := &ApiCall{HttpRequest: request, CurrentEndPoint: endPoint}
+
+		id := endPoint.SelectResponse(apiCall.SelectionSource()).ID
+		if first == "" {
+			first = id
+		}
+		if id != first {
+			t.Fatalf("expected %s for every call with %s but got %s", first, RandomSeedHeader, id)
+		}
+	}
+}
+
+// header first, then the endpoint, then the collection of the endpoint cache
+func Test_ApiCall_RandomSeed(t *testing.T) {
+	collection := &Collection{ID: "C1", RandomSeed: "REQUEST[STRING]:customer"}
+	flatMap := map[string]xmlutils.ValueDatatype{"customer": {Value: "cust-9", DataType: "STRING"}}
+
+	tests := []struct {
+		name     string
+		header   string
+		endPoint *EndPoint
+		found    bool
+		seed     int64
+	}{
+		{"header", "7", &EndPoint{RandomSeed: "42", Collection: collection}, true, 7},
+		{"endpoint", "", &EndPoint{RandomSeed: "42", Collection: collection}, true, 42},
+		{"collection", "", &EndPoint{CollectionID: "C1", Collection: collection}, true, RandomSeedFromString("cust-9")},
+		{"none", "", &EndPoint{CollectionID: "C1"}, false, 0},
+		{"bad expression", "", &EndPoint{RandomSeed: "REQUEST[STRING]:missing"}, false, 0},
+	}
+
+	for _, test := range tests {
+		request := httptest.NewRequest(http.MethodGet, "/api/v1/seeded", nil)
+		if test.header != "" {
+			request.Header.Set(RandomSeedHeader, test.header)
+		}
+		// no db: the collection is not read per call
+		apiCall := &ApiCall{HttpRequest: request, CurrentEndPoint: test.endPoint, RequestFlatMap: flatMap}
+
+		seed, found := apiCall.RandomSeed()
+		if found != test.found || seed != test.seed {
+			t.Errorf("%s: expected %d %t but got %d %t", test.name, test.seed, test.found, seed, found)
+		}
+	}
+}
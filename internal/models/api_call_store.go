@@ -0,0 +1,165 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ------------------------------------------------------
+// value expressions used by OverrideValue, condition group
+// assignments and store writes:
+//
+//	REQUEST[STRING]:key   value from the request
+//	REQUEST[OBJECT]:key   object or array from the request
+//	*RANDOM:NAME          random value
+//	STORE:key             value from the collection store
+//	*INDEX                position in a repeated array element
+//	*NOW(+720h,RFC3339)   current time, see time_values.go
+//	anything else         used as is
+//
+// ------------------------------------------------------
+func (a *ApiCall) ResolveValue(value string) (any, error) {
+
+	if strings.TrimSpace(value) == RepeatIndexValue {
+		return strconv.Itoa(a.RepeatIndex), nil
+	}
+
+	// layouts have colons, check before splitting
+	if IsTimeValue(value) {
+		return ResolveTimeValue(value)
+	}
+
+	brokenValues := strings.Split(value, ":")
+
+	valueSource := strings.TrimSpace(brokenValues[0])
+	if strings.HasPrefix(valueSource, "REQUEST[") && len(brokenValues) > 1 {
+		valueKey := strings.TrimSpace(strings.Join(brokenValues[1:], ":"))
+		requestValue, found := a.RequestFlatMap[valueKey]
+		if found {
+			return requestValue.Value, nil
+		}
+
+		// only objects and arrays take the subtree, missing ones are sent as null
+		valueType := strings.TrimSuffix(strings.TrimPrefix(valueSource, "REQUEST["), "]")
+		if strings.EqualFold(valueType, SubtreeObject) || strings.EqualFold(valueType, SubtreeArray) {
+			subtree, found := a.RequestSubtree(valueKey)
+			if found {
+				return subtree, nil
+			}
+			a.LogInfo(fmt.Sprintf("Request %s not found. Using null", valueKey))
+			return nil, nil
+		}
+		return value, fmt.Errorf("Request Parameter not found:%s", value)
+
+	} else if strings.HasPrefix(valueSource, "*RANDOM") && len(brokenValues) > 1 {
+		// arguments can have colons: DATE(...,15:04:05)
+		randomValue, err := GenerateRandomWith(a.RandomFaker(), strings.Join(brokenValues[1:], ":"))
+		if err == nil {
+			return randomValue, nil
+		} else {
+			return value, err
+		}
+	} else if IsStoreReference(value) {
+		storeValue, err := a.GetStoreValue(StoreReferenceKey(value))
+		if err != nil {
+			return value, fmt.Errorf("Store value not found:%s", value)
+		}
+		return storeValue, nil
+	}
+
+	return value, nil
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (a *ApiCall) storeCollectionID() string {
+	if a.CurrentEndPoint == nil {
+		return ""
+	}
+	return a.CurrentEndPoint.CollectionID
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (a *ApiCall) GetStoreValue(key string) (any, error) {
+	if a.DataDB == nil {
+		return nil, errors.New("store not available")
+	}
+
+	storeModel := &StoreModel{DB: a.DataDB}
+	entry, err := storeModel.Get(a.storeCollectionID(), key)
+	if err != nil {
+		return nil, err
+	}
+	return entry.Value, nil
+}
+
+// ------------------------------------------------------
+// one "key = value expression" per line
+// ------------------------------------------------------
+func ParseStoreWrites(writes string) ([][2]string, error) {
+	parsed := make([][2]string, 0)
+
+	for i, line := range strings.Split(writes, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		key, value, found := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !found || key == "" {
+			return parsed, fmt.Errorf("line %d: use key = value", i+1)
+		}
+
+		parsed = append(parsed, [2]string{key, strings.TrimSpace(value)})
+	}
+
+	return parsed, nil
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (a *ApiCall) WriteStore(writes string, ttl string) {
+	if a.DataDB == nil {
+		a.LogError("Store not available. Skipping store writes")
+		return
+	}
+
+	parsed, err := ParseStoreWrites(writes)
+	if err != nil {
+		a.LogError(fmt.Sprintf("Invalid store writes: %s", err.Error()))
+		return
+	}
+
+	duration := time.Duration(0)
+	if strings.TrimSpace(ttl) != "" {
+		duration, err = time.ParseDuration(strings.TrimSpace(ttl))
+		if err != nil {
+			a.LogError(fmt.Sprintf("Invalid store TTL %s. Storing without TTL", ttl))
+			duration = 0
+		}
+	}
+
+	storeModel := &StoreModel{DB: a.DataDB}
+	for _, w := range parsed {
+		value, err := a.ResolveValue(w[1])
+		if err != nil {
+			a.LogError(fmt.Sprintf("Store %s skipped: %s", w[0], err.Error()))
+			continue
+		}
+
+		err = storeModel.Set(a.storeCollectionID(), w[0], value, duration)
+		if err != nil {
+			a.LogError(fmt.Sprintf("Store %s failed: %s", w[0], err.Error()))
+			continue
+		}
+		a.LogInfo(fmt.Sprintf("Stored %s = %v", w[0], value))
+	}
+}
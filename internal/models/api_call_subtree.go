@@ -0,0 +1,215 @@
+package models
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// REQUEST[OBJECT]:address and REQUEST[ARRAY]:items take the whole subtree
+// from the request, nil when the request does not have it
+const (
+	SubtreeObject = "OBJECT"
+	SubtreeArray  = "ARRAY"
+)
+
+var pathSegmentRegex = regexp.MustCompile(`[^.\[\]]+|\[\d+\]`)
+
+// -----------------------------------------------------------------
+// a.b[0].c ==> "a", "b", 0, "c"
+// -----------------------------------------------------------------
+func splitValuePath(path string) []any {
+	segments := make([]any, 0)
+	for _, s := range pathSegmentRegex.FindAllString(path, -1) {
+		if strings.HasPrefix(s, "[") {
+			i, _ := strconv.Atoi(strings.Trim(s, "[]"))
+			segments = append(segments, i)
+			continue
+		}
+		segments = append(segments, s)
+	}
+	return segments
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func valueAtPath(value any, segments []any) (any, bool) {
+	for _, segment := range segments {
+		switch s := segment.(type) {
+		case string:
+			m, ok := value.(map[string]any)
+			if !ok {
+				return nil, false
+			}
+			value, ok = m[s]
+			if !ok {
+				return nil, false
+			}
+		case int:
+			l, ok := value.([]any)
+			if !ok || s >= len(l) {
+				return nil, false
+			}
+			value = l[s]
+		}
+	}
+	return value, true
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func setValueAtPath(root any, segments []any, value any) any {
+	if len(segments) == 0 {
+		return value
+	}
+
+	switch s := segments[0].(type) {
+	case int:
+		l, ok := root.([]any)
+		if !ok {
+			l = make([]any, 0)
+		}
+		for len(l) <= s {
+			l = append(l, nil)
+		}
+		l[s] = setValueAtPath(l[s], segments[1:], value)
+		return l
+
+	case string:
+		m, ok := root.(map[string]any)
+		if !ok {
+			m = make(map[string]any)
+		}
+		m[s] = setValueAtPath(m[s], segments[1:], value)
+		return m
+	}
+
+	return root
+}
+
+// -----------------------------------------------------------------
+// nested value from the JSON body, or rebuilt from the flat request
+// map for XML and form requests
+// -----------------------------------------------------------------
+func (a *ApiCall) RequestSubtree(key string) (any, bool) {
+	segments := splitValuePath(key)
+	if len(segments) == 0 {
+		return nil, false
+	}
+
+	var body any
+	if json.Unmarshal(a.RequestBody(), &body) == nil {
+		value, found := valueAtPath(body, segments)
+		if found {
+			return value, true
+		}
+	}
+
+	var subtree any
+	found := false
+	for k, v := range a.RequestFlatMap {
+		if !strings.HasPrefix(k, key) {
+			continue
+		}
+		rest := k[len(key):]
+		if !strings.HasPrefix(rest, ".") && !strings.HasPrefix(rest, "[") {
+			continue
+		}
+		subtree = setValueAtPath(subtree, splitValuePath(rest), v.Value)
+		found = true
+	}
+
+	return subtree, found
+}
+
+// -----------------------------------------------------------------
+// parents of the flat request keys, for the autocomplete lists
+// -----------------------------------------------------------------
+func RequestSubtreeKeys(keys []string) []string {
+	subtrees := make(map[string]string)
+	for _, key := range keys {
+		for i := 1; i < len(key); i++ {
+			switch key[i] {
+			case '.':
+				subtrees[key[:i]] = SubtreeObject
+			case '[':
+				subtrees[key[:i]] = SubtreeArray
+			}
+		}
+	}
+
+	returnList := make([]string, 0, len(subtrees))
+	for k, t := range subtrees {
+		returnList = append(returnList, fmt.Sprintf("REQUEST[%s]: %s", t, k))
+	}
+	sort.Strings(returnList)
+	return returnList
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func isSubtree(value any) bool {
+	switch value.(type) {
+	case map[string]any, []any:
+		return true
+	}
+	return false
+}
+
+// -----------------------------------------------------------------
+// child elements for an XML response, arrays repeat the element
+// -----------------------------------------------------------------
+func subtreeToXML(value any) string {
+	builder := &strings.Builder{}
+	writeXMLValue(builder, "item", value, true)
+	return builder.String()
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func writeXMLValue(builder *strings.Builder, name string, value any, inner bool) {
+	switch v := value.(type) {
+	case map[string]any:
+		if !inner {
+			builder.WriteString("<" + name + ">")
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			if strings.HasPrefix(k, "*") { // *ATTR from XML requests
+				continue
+			}
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			writeXMLValue(builder, k, v[k], false)
+		}
+		if !inner {
+			builder.WriteString("</" + name + ">")
+		}
+
+	case []any:
+		for _, element := range v {
+			writeXMLValue(builder, name, element, false)
+		}
+
+	default:
+		if !inner {
+			builder.WriteString("<" + name + ">")
+		}
+		if v != nil {
+			xml.EscapeText(builder, []byte(fmt.Sprint(v)))
+		}
+		if !inner {
+			builder.WriteString("</" + name + ">")
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package models
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/onlysumitg/GoMockAPI/utils/xmlutils"
+)
+
+// -----------------------------------------------------------------
+// call of a single response, the request has the given body
+// -----------------------------------------------------------------
+func newSubtreeCall(body string, flatmap map[string]xmlutils.ValueDatatype, response string) *ApiCall {
+	return &ApiCall{
+		HttpRequest:    httptest.NewRequest("POST", "/api/orders", strings.NewReader(body)),
+		RequestFlatMap: flatmap,
+		ResponseMapXX:  []*CallResponse{{ID: "r1", Name: "DEFAULT", Httpcode: 200, Response: response}},
+	}
+}
+
+func Test_ResponseParam_JSONSubtree(t *testing.T) {
+	body := `{"shipping": {"street": "Main St", "zip": "10001"}, "items": [{"sku": "A1", "qty": 2}, {"sku": "B2", "qty": 1}], "user": "u1"}`
+	flatmap := map[string]xmlutils.ValueDatatype{
+		"shipping.street": {Value: "Main St", DataType: "STRING"},
+		"shipping.zip":    {Value: "10001", DataType: "STRING"},
+		"items[0].sku":    {Value: "A1", DataType: "STRING"},
+		"items[0].qty":    {Value: 2.0, DataType: "FLOAT64"},
+		"items[1].sku":    {Value: "B2", DataType: "STRING"},
+		"items[1].qty":    {Value: 1.0, DataType: "FLOAT64"},
+		"user":            {Value: "u1", DataType: "STRING"},
+	}
+
+	tests := []struct {
+		name     string
+		value    string
+		expected any
+	}{
+		{"object", "REQUEST[OBJECT]:shipping", map[string]any{"street": "Main St", "zip": "10001"}},
+		{"array", "REQUEST[ARRAY]:items", []any{map[string]any{"sku": "A1", "qty": 2.0}, map[string]any{"sku": "B2", "qty": 1.0}}},
+		{"array element", "REQUEST[OBJECT]:items[1]", map[string]any{"sku": "B2", "qty": 1.0}},
+		{"missing object", "REQUEST[OBJECT]:billing", nil},
+		{"missing array", "REQUEST[ARRAY]:payments", nil},
+		{"scalar", "REQUEST[STRING]:user", "u1"},
+	}
+
+	for _, test := range tests {
+		apiCall := newSubtreeCall(body, flatmap, `{"value": "{{value}}"}`)
+		p := &EndPointResponseParam{Key: "value", DefaultDatatype: "STRING", OverrideValue: test.value}
+		p.process(apiCall, "r1", "")
+
+		result := make(map[string]any)
+		if err := json.Unmarshal([]byte(apiCall.ResponseMapXX[0].Response), &result); err != nil {
+			t.Errorf("%s: invalid response %s", test.name, apiCall.ResponseMapXX[0].Response)
+			continue
+		}
+		if !reflect.DeepEqual(result["value"], test.expected) {
+			t.Errorf("%s: expected %v but got %v", test.name, test.expected, result["value"])
+		}
+	}
+}
+
+// scalar types do not take a subtree, even when the request has one
+func Test_ApiCall_ResolveValue_ScalarSubtree(t *testing.T) {
+	body := `{"shipping": {"street": "Main St"}}`
+	flatmap := map[string]xmlutils.ValueDatatype{"shipping.street": {Value: "Main St", DataType: "STRING"}}
+
+	for _, value := range []string{"REQUEST[STRING]:shipping", "REQUEST[INT]:shipping", "REQUEST[BOOL]:shipping"} {
+		apiCall := newSubtreeCall(body, flatmap, "")
+		got, err := apiCall.ResolveValue(value)
+		if err == nil || isSubtree(got) {
+			t.Errorf("%s: expected an error and no subtree but got %v %v", value, got, err)
+		}
+	}
+}
+
+func Test_ResponseParam_XMLSubtree(t *testing.T) {
+	body := `<order><item><sku>A1</sku><qty>2</qty></item><item><sku>B&amp;2</sku><qty>1</qty></item><note>fast</note></order>`
+	flatmap := map[string]xmlutils.ValueDatatype{
+		"order.item[0].sku": {Value: "A1", DataType: "STRING"},
+		"order.item[0].qty": {Value: "2", DataType: "STRING"},
+		"order.item[1].sku": {Value: "B&2", DataType: "STRING"},
+		"order.item[1].qty": {Value: "1", DataType: "STRING"},
+		"order.note":        {Value: "fast", DataType: "STRING"},
+	}
+
+	tests := []struct {
+		name     string
+		value    string
+		expected string
+	}{
+		{"object", "REQUEST[OBJECT]:order", `<item><qty>2</qty><sku>A1</sku></item><item><qty>1</qty><sku>B&amp;2</sku></item><note>fast</note>`},
+		{"array", "REQUEST[ARRAY]:order.item", `<item><qty>2</qty><sku>A1</sku></item><item><qty>1</qty><sku>B&amp;2</sku></item>`},
+		{"missing", "REQUEST[OBJECT]:order.customer", ``},
+	}
+
+	for _, test := range tests {
+		apiCall := newSubtreeCall(body, flatmap, `<result>"{{value}}"</result>`)
+		p := &EndPointResponseParam{Key: "value", DefaultDatatype: "XMLSTRING", OverrideValue: test.value}
+		p.process(apiCall, "r1", "")
+
+		if expected := "<result>" + test.expected + "</result>"; apiCall.ResponseMapXX[0].Response != expected {
+			t.Errorf("%s: expected %s but got %s", test.name, expected, apiCall.ResponseMapXX[0].Response)
+		}
+	}
+}
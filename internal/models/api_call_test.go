@@ -0,0 +1,43 @@
+package models
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// the body read for conditions is still sent to the actual url
+func Test_ApiCall_HTTPCall_ReusesBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Write([]byte(r.Method + " " + r.URL.RequestURI() + " " + r.Header.Get("X-Forwarded-For") + " " + string(body)))
+	}))
+	defer server.Close()
+
+	u, _ := url.Parse(server.URL)
+	request := httptest.NewRequest(http.MethodPost, "/api/v1/orders?id=7", strings.NewReader(`{"qty": 2}`))
+	request.RemoteAddr = "10.0.0.1:5000"
+
+	apiCall := &ApiCall{
+		HttpRequest: request,
+		CurrentEndPoint: &EndPoint{
+			ParsedUrl: map[string]string{"Scheme": u.Scheme, "Host": u.Host},
+		},
+	}
+
+	if string(apiCall.RequestBody()) != `{"qty": 2}` || string(apiCall.RequestBody()) != `{"qty": 2}` {
+		t.Fatalf("request body not kept")
+	}
+
+	result := apiCall.HTTPCall()
+	if result == nil || result.Err != nil {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	expected := `POST /?id=7 10.0.0.1 {"qty": 2}`
+	if result.Body != expected {
+		t.Errorf("expected %q but got %q", expected, result.Body)
+	}
+}
@@ -0,0 +1,115 @@
+package models
+
+import (
+	"sync"
+	"time"
+)
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+// VirtualClock is the server wide source of "now".
+// By default it follows the wall clock. Once a virtual time is set it either
+// stays frozen at that time or keeps ticking from it.
+type VirtualClock struct {
+	mutex sync.RWMutex
+
+	isSet  bool
+	frozen bool
+	fixed  time.Time
+	offset time.Duration
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+type ClockStatus struct {
+	Now     time.Time `json:"now"`
+	Virtual bool      `json:"virtual"`
+	Frozen  bool      `json:"frozen"`
+}
+
+var Clock *VirtualClock = &VirtualClock{}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func Now() time.Time {
+	return Clock.Now()
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (c *VirtualClock) Now() time.Time {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	if !c.isSet {
+		return time.Now()
+	}
+
+	if c.frozen {
+		return c.fixed
+	}
+
+	return time.Now().Add(c.offset)
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (c *VirtualClock) Set(now time.Time, frozen bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.isSet = true
+	c.frozen = frozen
+	c.fixed = now
+	c.offset = time.Until(now)
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (c *VirtualClock) Advance(d time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if !c.isSet {
+		c.isSet = true
+		c.fixed = time.Now()
+	}
+
+	c.fixed = c.fixed.Add(d)
+	c.offset = c.offset + d
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (c *VirtualClock) Reset() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.isSet = false
+	c.frozen = false
+	c.fixed = time.Time{}
+	c.offset = 0
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (c *VirtualClock) Status() ClockStatus {
+	now := c.Now()
+
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	return ClockStatus{
+		Now:     now,
+		Virtual: c.isSet,
+		Frozen:  c.frozen,
+	}
+}
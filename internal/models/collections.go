@@ -17,6 +17,13 @@ type Collection struct {
 	Name string `json:"name" db:"name" form:"name"`
 	Desc string `json:"desc" db:"desc" form:"desc"`
 
+	// seed for *RANDOM values of all endpoints, see EndPoint.RandomSeed
+	RandomSeed string `json:"randomseed" db:"randomseed" form:"randomseed"`
+
+	// record mode for all endpoints, see EndPoint.AutoUpdateResponse
+	Record           bool `json:"record" db:"record" form:"record"`
+	RecordConditions bool `json:"recordconditions" db:"recordconditions" form:"recordconditions"` // condition group per recorded value
+
 	validator.Validator `json:"-" db:"-" form:"-"`
 }
 
@@ -56,7 +56,17 @@ func (m *Condition) HasPassed(apiCall *ApiCall) bool {
 		return true
 	}
 
-	hasPassed := operatorFunc(requestValue.Value, m.Compareto, m.RequestParam.DefaultDatatype)
+	compareto := m.Compareto
+	if IsStoreReference(compareto) {
+		storeValue, err := apiCall.GetStoreValue(StoreReferenceKey(compareto))
+		if err != nil {
+			apiCall.LogError(fmt.Sprintf("Condition Failed. Store value not found %s", compareto))
+			return false
+		}
+		compareto = fmt.Sprint(storeValue)
+	}
+
+	hasPassed := operatorFunc(requestValue.Value, compareto, m.RequestParam.DefaultDatatype)
 	apiCall.LogInfo(fmt.Sprintf("Condition Passed? %t", hasPassed))
 
 	return hasPassed
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/onlysumitg/GoMockAPI/internal/validator"
@@ -51,6 +52,67 @@ type ConditionGroup struct {
 	//HttpStatusCode int `json:"httpstatuscode" db:"httpstatuscode" form:"httpstatuscode"`
 
 	ResponseID string `json:"httpstatuscode" db:"httpstatuscode" form:"httpstatuscode"`
+
+	// optional time window: group applies only between these dates/hours
+	ActiveFromTime string `json:"activefromtime" db:"activefromtime" form:"activefromtime"` // 15:04
+	ActiveToTime   string `json:"activetotime" db:"activetotime" form:"activetotime"`       // 15:04
+	ActiveFromDate string `json:"activefromdate" db:"activefromdate" form:"activefromdate"` // 2006-01-02
+	ActiveToDate   string `json:"activetodate" db:"activetodate" form:"activetodate"`       // 2006-01-02
+	ActiveTimeZone string `json:"activetimezone" db:"activetimezone" form:"activetimezone"` // default UTC
+
+	// optional scenario state machine
+	ScenarioID    string `json:"scenarioid" db:"scenarioid" form:"scenarioid"`
+	RequiredState string `json:"requiredstate" db:"requiredstate" form:"requiredstate"`
+	NewState      string `json:"newstate" db:"newstate" form:"newstate"`
+
+	// optional writes to the collection store: one "key = value" per line
+	StoreWrites string `json:"storewrites" db:"storewrites" form:"storewrites"`
+	StoreTTL    string `json:"storettl" db:"storettl" form:"storettl"` // 30m, blank: no expiry
+
+	// outbound calls made after the response when the group passes
+	Webhooks WebhookList `json:"webhooks" db:"webhooks" form:"webhooks"`
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (cg *ConditionGroup) HasStoreWrites() bool {
+	return strings.TrimSpace(cg.StoreWrites) != ""
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (cg *ConditionGroup) ValidateStoreWrites() {
+	cg.StoreTTL = strings.TrimSpace(cg.StoreTTL)
+
+	_, err := ParseStoreWrites(cg.StoreWrites)
+	if err != nil {
+		cg.CheckField(false, "storewrites", err.Error())
+	}
+
+	if cg.StoreTTL != "" {
+		d, err := time.ParseDuration(cg.StoreTTL)
+		cg.CheckField(err == nil && d > 0, "storettl", "Use a duration like 30s, 15m or 2h")
+	}
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (cg *ConditionGroup) ValidateWebhooks() {
+	cg.Webhooks = cg.Webhooks.Clean()
+	err := cg.Webhooks.Validate()
+	if err != nil {
+		cg.CheckField(false, "webhooks", err.Error())
+	}
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (cg *ConditionGroup) RequiresScenarioState() bool {
+	return cg.ScenarioID != "" && strings.TrimSpace(cg.RequiredState) != ""
 }
 
 // -----------------------------------------------------------------
@@ -165,11 +227,38 @@ func (c *ConditionGroup) RemoveInvalidResponseParam(params []*EndPointResponsePa
 // -----------------------------------------------------------------
 func (cg *ConditionGroup) Execute(apiCall *ApiCall) bool {
 
-	if len(cg.Conditions) <= 0 {
+	// a time window or a scenario state on its own is enough to drive the group;
+	// store writes run only after the group matched, they never make it match
+	if len(cg.Conditions) <= 0 && !cg.HasTimeWindow() && !cg.RequiresScenarioState() {
 		apiCall.LogInfo(fmt.Sprintf("SKIPPED Condition Group: %s. No condition to process.", cg.Name))
 		return false
 	}
 
+	if cg.HasTimeWindow() {
+		now := Now()
+		isActive, err := cg.IsActiveAt(now)
+		if err != nil {
+			apiCall.LogError(fmt.Sprintf("SKIPPED Condition Group: %s. Invalid time window: %s", cg.Name, err.Error()))
+			return false
+		}
+		if !isActive {
+			apiCall.LogInfo(fmt.Sprintf("SKIPPED Condition Group: %s. %s is outside time window %s", cg.Name, now.Format(time.RFC3339), cg.TimeWindowText()))
+			return false
+		}
+	}
+
+	if cg.RequiresScenarioState() {
+		currentState, err := apiCall.GetScenarioState(cg.ScenarioID)
+		if err != nil {
+			apiCall.LogError(fmt.Sprintf("SKIPPED Condition Group: %s. Scenario not found: %s", cg.Name, err.Error()))
+			return false
+		}
+		if !strings.EqualFold(currentState, NormalizeScenarioState(cg.RequiredState)) {
+			apiCall.LogInfo(fmt.Sprintf("SKIPPED Condition Group: %s. Scenario state is %s, required %s", cg.Name, currentState, cg.RequiredState))
+			return false
+		}
+	}
+
 	conditionFailed := false
 
 	apiCall.LogInfo(fmt.Sprintf("Processing Condition Group: %s", cg.Name))
@@ -200,6 +289,16 @@ func (cg *ConditionGroup) Execute(apiCall *ApiCall) bool {
 
 		apiCall.LogInfo("Condition Group Passes. Starting assignement")
 
+		if cg.ScenarioID != "" && strings.TrimSpace(cg.NewState) != "" {
+			apiCall.QueueScenarioState(cg.ScenarioID, cg.NewState)
+		}
+
+		if cg.HasStoreWrites() {
+			apiCall.WriteStore(cg.StoreWrites, cg.StoreTTL)
+		}
+
+		apiCall.QueueWebhooks(cg.Webhooks)
+
 		// set status code bases on condition group
 		if cg.ResponseID != "" {
 			if !apiCall.HasSet("*HTTP_STATUS_CODE") {
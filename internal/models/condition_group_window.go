@@ -0,0 +1,187 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	windowTimeLayout = "15:04"
+	windowDateLayout = "2006-01-02"
+)
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (cg *ConditionGroup) HasTimeWindow() bool {
+	return strings.TrimSpace(cg.ActiveFromTime) != "" ||
+		strings.TrimSpace(cg.ActiveToTime) != "" ||
+		strings.TrimSpace(cg.ActiveFromDate) != "" ||
+		strings.TrimSpace(cg.ActiveToDate) != ""
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (cg *ConditionGroup) getTimeWindowLocation() (*time.Location, error) {
+	tz := strings.TrimSpace(cg.ActiveTimeZone)
+	if tz == "" {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(tz)
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func minutesOfDay(value string) (int, error) {
+	t, err := time.Parse(windowTimeLayout, strings.TrimSpace(value))
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// -----------------------------------------------------------------
+// date range is inclusive on both ends
+// time range is [from, to) and may cross midnight: 22:00 - 02:00
+// -----------------------------------------------------------------
+func (cg *ConditionGroup) IsActiveAt(now time.Time) (bool, error) {
+	if !cg.HasTimeWindow() {
+		return true, nil
+	}
+
+	loc, err := cg.getTimeWindowLocation()
+	if err != nil {
+		return false, err
+	}
+
+	localNow := now.In(loc)
+	today := localNow.Format(windowDateLayout)
+
+	if fromDate := strings.TrimSpace(cg.ActiveFromDate); fromDate != "" {
+		if _, err := time.Parse(windowDateLayout, fromDate); err != nil {
+			return false, err
+		}
+		if today < fromDate {
+			return false, nil
+		}
+	}
+
+	if toDate := strings.TrimSpace(cg.ActiveToDate); toDate != "" {
+		if _, err := time.Parse(windowDateLayout, toDate); err != nil {
+			return false, err
+		}
+		if today > toDate {
+			return false, nil
+		}
+	}
+
+	fromTime := strings.TrimSpace(cg.ActiveFromTime)
+	toTime := strings.TrimSpace(cg.ActiveToTime)
+
+	if fromTime == "" && toTime == "" {
+		return true, nil
+	}
+
+	start := 0
+	end := 24 * 60
+
+	if fromTime != "" {
+		start, err = minutesOfDay(fromTime)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	if toTime != "" {
+		end, err = minutesOfDay(toTime)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	current := localNow.Hour()*60 + localNow.Minute()
+
+	if start <= end {
+		return current >= start && current < end, nil
+	}
+
+	// window crosses midnight
+	return current >= start || current < end, nil
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (cg *ConditionGroup) ValidateTimeWindow() {
+	cg.ActiveFromTime = strings.TrimSpace(cg.ActiveFromTime)
+	cg.ActiveToTime = strings.TrimSpace(cg.ActiveToTime)
+	cg.ActiveFromDate = strings.TrimSpace(cg.ActiveFromDate)
+	cg.ActiveToDate = strings.TrimSpace(cg.ActiveToDate)
+	cg.ActiveTimeZone = strings.TrimSpace(cg.ActiveTimeZone)
+
+	if cg.ActiveFromTime != "" {
+		_, err := minutesOfDay(cg.ActiveFromTime)
+		cg.CheckField(err == nil, "activefromtime", "Use HH:MM (24 hour) format")
+	}
+
+	if cg.ActiveToTime != "" {
+		_, err := minutesOfDay(cg.ActiveToTime)
+		cg.CheckField(err == nil, "activetotime", "Use HH:MM (24 hour) format")
+	}
+
+	if cg.ActiveFromDate != "" {
+		_, err := time.Parse(windowDateLayout, cg.ActiveFromDate)
+		cg.CheckField(err == nil, "activefromdate", "Use YYYY-MM-DD format")
+	}
+
+	if cg.ActiveToDate != "" {
+		_, err := time.Parse(windowDateLayout, cg.ActiveToDate)
+		cg.CheckField(err == nil, "activetodate", "Use YYYY-MM-DD format")
+	}
+
+	if cg.ActiveFromDate != "" && cg.ActiveToDate != "" {
+		cg.CheckField(cg.ActiveFromDate <= cg.ActiveToDate, "activetodate", "Must be on or after the from date")
+	}
+
+	if cg.ActiveTimeZone != "" {
+		_, err := cg.getTimeWindowLocation()
+		cg.CheckField(err == nil, "activetimezone", fmt.Sprintf("Unknown time zone %s", cg.ActiveTimeZone))
+	}
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func ifBlank(value string, defaultValue string) string {
+	if strings.TrimSpace(value) == "" {
+		return defaultValue
+	}
+	return value
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (cg *ConditionGroup) TimeWindowText() string {
+	if !cg.HasTimeWindow() {
+		return ""
+	}
+
+	tz := cg.ActiveTimeZone
+	if tz == "" {
+		tz = "UTC"
+	}
+
+	parts := make([]string, 0)
+	if cg.ActiveFromDate != "" || cg.ActiveToDate != "" {
+		parts = append(parts, fmt.Sprintf("%s to %s", ifBlank(cg.ActiveFromDate, "..."), ifBlank(cg.ActiveToDate, "...")))
+	}
+	if cg.ActiveFromTime != "" || cg.ActiveToTime != "" {
+		parts = append(parts, fmt.Sprintf("%s-%s", ifBlank(cg.ActiveFromTime, "00:00"), ifBlank(cg.ActiveToTime, "24:00")))
+	}
+
+	return fmt.Sprintf("%s %s", strings.Join(parts, " "), tz)
+}
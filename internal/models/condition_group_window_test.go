@@ -0,0 +1,73 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_ConditionGroup_IsActiveAt(t *testing.T) {
+	at := func(value string) time.Time {
+		t, _ := time.Parse("2006-01-02 15:04", value)
+		return t
+	}
+
+	tests := []struct {
+		name     string
+		group    ConditionGroup
+		now      time.Time
+		expected bool
+	}{
+		{"no window", ConditionGroup{}, at("2024-03-10 12:00"), true},
+		{"inside", ConditionGroup{ActiveFromTime: "09:00", ActiveToTime: "17:00"}, at("2024-03-10 09:00"), true},
+		{"end is left out", ConditionGroup{ActiveFromTime: "09:00", ActiveToTime: "17:00"}, at("2024-03-10 17:00"), false},
+		{"before", ConditionGroup{ActiveFromTime: "09:00", ActiveToTime: "17:00"}, at("2024-03-10 08:59"), false},
+		{"only from", ConditionGroup{ActiveFromTime: "09:00"}, at("2024-03-10 23:59"), true},
+		{"only to", ConditionGroup{ActiveToTime: "09:00"}, at("2024-03-10 00:00"), true},
+
+		// 22:00 - 02:00
+		{"midnight, late evening", ConditionGroup{ActiveFromTime: "22:00", ActiveToTime: "02:00"}, at("2024-03-10 23:30"), true},
+		{"midnight, at midnight", ConditionGroup{ActiveFromTime: "22:00", ActiveToTime: "02:00"}, at("2024-03-11 00:00"), true},
+		{"midnight, early morning", ConditionGroup{ActiveFromTime: "22:00", ActiveToTime: "02:00"}, at("2024-03-11 01:59"), true},
+		{"midnight, end", ConditionGroup{ActiveFromTime: "22:00", ActiveToTime: "02:00"}, at("2024-03-11 02:00"), false},
+		{"midnight, midday", ConditionGroup{ActiveFromTime: "22:00", ActiveToTime: "02:00"}, at("2024-03-10 12:00"), false},
+		{"midnight, just before", ConditionGroup{ActiveFromTime: "22:00", ActiveToTime: "02:00"}, at("2024-03-10 21:59"), false},
+
+		// 02:30 UTC is 21:30 the day before in New York
+		{"midnight in a time zone", ConditionGroup{ActiveFromTime: "21:00", ActiveToTime: "01:00", ActiveTimeZone: "America/New_York"}, at("2024-03-12 02:30"), true},
+		{"outside in a time zone", ConditionGroup{ActiveFromTime: "21:00", ActiveToTime: "01:00", ActiveTimeZone: "America/New_York"}, at("2024-03-12 06:30"), false},
+
+		{"dates are inclusive", ConditionGroup{ActiveFromDate: "2024-03-10", ActiveToDate: "2024-03-10"}, at("2024-03-10 23:59"), true},
+		{"after the dates", ConditionGroup{ActiveFromDate: "2024-03-01", ActiveToDate: "2024-03-10"}, at("2024-03-11 00:00"), false},
+		{"before the dates", ConditionGroup{ActiveFromDate: "2024-03-11"}, at("2024-03-10 23:59"), false},
+		// the date is the one of the time zone
+		{"dates in a time zone", ConditionGroup{ActiveFromDate: "2024-03-11", ActiveTimeZone: "Asia/Tokyo"}, at("2024-03-10 23:00"), true},
+		{"dates and a midnight window", ConditionGroup{ActiveToDate: "2024-03-10", ActiveFromTime: "22:00", ActiveToTime: "02:00"}, at("2024-03-11 01:00"), false},
+	}
+
+	for _, test := range tests {
+		active, err := test.group.IsActiveAt(test.now)
+		if err != nil {
+			t.Errorf("%s: unexpected error %s", test.name, err.Error())
+			continue
+		}
+		if active != test.expected {
+			t.Errorf("%s: expected %t but got %t", test.name, test.expected, active)
+		}
+	}
+}
+
+func Test_ConditionGroup_IsActiveAt_Errors(t *testing.T) {
+	tests := []ConditionGroup{
+		{ActiveFromTime: "9am"},
+		{ActiveToTime: "25:00"},
+		{ActiveFromDate: "10/03/2024"},
+		{ActiveFromTime: "09:00", ActiveTimeZone: "Mars/Base"},
+	}
+
+	for _, group := range tests {
+		active, err := group.IsActiveAt(time.Now())
+		if err == nil || active {
+			t.Errorf("%+v: expected an error and not active but got %t %v", group, active, err)
+		}
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/onlysumitg/GoMockAPI/internal/graphql"
 	"github.com/onlysumitg/GoMockAPI/internal/validator"
 	"github.com/onlysumitg/GoMockAPI/utils/httputils"
 	"github.com/onlysumitg/GoMockAPI/utils/stringutils"
@@ -27,6 +28,9 @@ type EndPoint struct {
 	CollectionID   string `json:"collectionid" db:"collectionid" form:"collectionid"`
 	CollectionName string `json:"collectionname" db:"collectionname" form:"-"`
 
+	// set on the endpoint cache, saving the collection rebuilds it
+	Collection *Collection `json:"-" db:"-" form:"-"`
+
 	Name string `json:"name" db:"name" form:"name"` // abc/asd?q=12
 	//Path string `json:"path" db:"path" form:"path"` // abc
 
@@ -40,7 +44,15 @@ type EndPoint struct {
 	MockUrl string `json:"mockurl" db:"mockurl" form:"-"`
 
 	AutoUpdateRequest  bool `json:"autoupdaterequest" db:"autoupdaterequest" form:"autoupdaterequest"`
-	AutoUpdateResponse bool `json:"autoupdateresponse" db:"autoupdateresponse" form:"autoupdateresponse"`
+	AutoUpdateResponse bool `json:"autoupdateresponse" db:"autoupdateresponse" form:"autoupdateresponse"` // record mode for this endpoint
+
+	// request value that tells recorded responses apart, like id or *HEADER_X-TENANT
+	RecordKey string `json:"recordkey" db:"recordkey" form:"recordkey"`
+
+	// actual url calls: timeouts in ms, 0 for the defaults, and the response sent as it arrives
+	ProxyConnectTimeout int  `json:"proxyconnecttimeout" db:"proxyconnecttimeout" form:"proxyconnecttimeout"`
+	ProxyReadTimeout    int  `json:"proxyreadtimeout" db:"proxyreadtimeout" form:"proxyreadtimeout"`
+	ProxyStream         bool `json:"proxystream" db:"proxystream" form:"proxystream"`
 
 	SampleRequest string `json:"samplerequest" db:"samplerequest" form:"samplerequest"`
 	//RequestPlaceholder map[string]any `json:"requestplaceholder" db:"requestplaceholder" form:"requestplaceholder"`
@@ -78,6 +90,70 @@ type EndPoint struct {
 	CreatedOn time.Time `json:"createdon" db:"createdon" form:"-"`
 
 	EnableLogging bool `json:"enablelogging" db:"enablelogging" form:"enablelogging"`
+
+	// resource endpoint: handles GET/POST/PUT/PATCH/DELETE for a set of records
+	ResourceMode    bool   `json:"resourcemode" db:"resourcemode" form:"resourcemode"`
+	ResourceName    string `json:"resourcename" db:"resourcename" form:"resourcename"`
+	ResourceIDField string `json:"resourceidfield" db:"resourceidfield" form:"resourceidfield"`
+
+	// how to pick a response when no condition group did: blank, SEQUENCE, SEQUENCE_STOP, RANDOM
+	ResponseSelection string `json:"responseselection" db:"responseselection" form:"responseselection"`
+
+	// seed for *RANDOM values: a number, text or a value expression like REQUEST[STRING]:customerId
+	RandomSeed string `json:"randomseed" db:"randomseed" form:"randomseed"`
+
+	// SOAP operation: calls to the service url are routed here by the SOAPAction header or the first element in the body
+	SoapService string `json:"soapservice" db:"soapservice" form:"soapservice"`
+	SoapAction  string `json:"soapaction" db:"soapaction" form:"soapaction"`
+	SoapElement string `json:"soapelement" db:"soapelement" form:"soapelement"`
+	SoapVersion string `json:"soapversion" db:"soapversion" form:"soapversion"` // 1.1 or 1.2, blank: as the request
+
+	// GraphQL endpoint: SDL the queries are checked and run against
+	GraphqlSchema string `json:"graphqlschema" db:"graphqlschema" form:"graphqlschema"`
+
+	// gRPC method: .proto source and the method served at /<GrpcService>/<GrpcMethod> on the gRPC port
+	GrpcService  string   `json:"grpcservice" db:"grpcservice" form:"grpcservice"` // package.Service
+	GrpcMethod   string   `json:"grpcmethod" db:"grpcmethod" form:"grpcmethod"`
+	ProtoSource  string   `json:"protosource" db:"protosource" form:"protosource"`    // file of the service
+	ProtoImports []string `json:"protoimports" db:"protoimports" form:"protoimports"` // files it imports, one per entry
+}
+
+// ------------------------------------------------------------
+//
+// ------------------------------------------------------------
+func (s *EndPoint) GetResourceName() string {
+	if strings.TrimSpace(s.ResourceName) == "" {
+		return s.Name
+	}
+	return strings.TrimSpace(s.ResourceName)
+}
+
+// ------------------------------------------------------------
+//
+// ------------------------------------------------------------
+func (s *EndPoint) CallOptions() httputils.CallOptions {
+	options := httputils.CallOptions{
+		ConnectTimeout: httputils.DefaultConnectTimeout,
+		ReadTimeout:    httputils.DefaultReadTimeout,
+		Stream:         s.ProxyStream,
+	}
+	if s.ProxyConnectTimeout > 0 {
+		options.ConnectTimeout = time.Duration(s.ProxyConnectTimeout) * time.Millisecond
+	}
+	if s.ProxyReadTimeout > 0 {
+		options.ReadTimeout = time.Duration(s.ProxyReadTimeout) * time.Millisecond
+	}
+	return options
+}
+
+// ------------------------------------------------------------
+//
+// ------------------------------------------------------------
+func (s *EndPoint) GetResourceIDField() string {
+	if strings.TrimSpace(s.ResourceIDField) == "" {
+		return DefaultResourceIDField
+	}
+	return strings.TrimSpace(s.ResourceIDField)
 }
 
 // ------------------------------------------------------------
@@ -231,6 +307,8 @@ func (s EndPoint) BuildResponse(apiCall *ApiCall) (err error) {
 
 	apiCall.LogInfo("**** FINISHED checking condition groups ****")
 
+	apiCall.ApplyScenarioStates()
+
 	apiCall.LogInfo("===================================================")
 
 	// process each response paramater
@@ -282,7 +360,7 @@ func (endpoint *EndPoint) PreValidations() {
 	endpoint.Method = strings.ToUpper(endpoint.Method)
 
 	// Get request type is always JSON
-	if endpoint.Method == "GET" {
+	if endpoint.Method == "GET" || endpoint.Method == "HEAD" {
 		endpoint.SampleRequestType = "JSON"
 	}
 
@@ -297,10 +375,53 @@ func (endpoint *EndPoint) PreValidations() {
 	endpoint.CheckField(validator.MustNotStartwith(endpoint.ActualURL, "{"), "actualurl", "Can not start with / or {")
 
 	endpoint.CheckField(validator.NotBlank(endpoint.Method), "method", "This field cannot be blank")
-	endpoint.CheckField(validator.MustBeFromList(endpoint.Method, "POST", "GET", "PUT", "DELETE"), "method", "Valid values are POST, GET, PUT, DELETE")
+	endpoint.CheckField(validator.MustBeFromList(endpoint.Method, "POST", "GET", "PUT", "DELETE", "PATCH", "HEAD"), "method", "Valid values are POST, GET, PUT, DELETE, PATCH, HEAD")
+	endpoint.CheckField(endpoint.ProxyConnectTimeout >= 0, "proxyconnecttimeout", "Can not be negative")
+	endpoint.CheckField(endpoint.ProxyReadTimeout >= 0, "proxyreadtimeout", "Can not be negative")
 
 	endpoint.CheckField(validator.NotBlank(endpoint.SampleRequestType), "samplerequesttype", "Please select one")
-	endpoint.CheckField(validator.MustBeFromList(endpoint.SampleRequestType, "JSON", "XML"), "samplerequesttype", "Valid values are JSON or XML")
+	endpoint.CheckField(validator.MustBeFromList(endpoint.SampleRequestType, "JSON", "XML", SoapType, GraphqlType, GrpcType), "samplerequesttype", "Valid values are JSON, XML, SOAP, GRAPHQL or GRPC")
+
+	if endpoint.IsSoap() {
+		endpoint.SoapService = stringutils.RemoveSpecialChars(strings.TrimSpace(endpoint.SoapService))
+		endpoint.SoapAction = strings.Trim(strings.TrimSpace(endpoint.SoapAction), `"`)
+		endpoint.SoapElement = strings.TrimSpace(endpoint.SoapElement)
+		endpoint.SoapVersion = strings.TrimSpace(endpoint.SoapVersion)
+		endpoint.CheckField(endpoint.Method == "POST", "method", "SOAP endpoints use POST")
+		endpoint.CheckField(endpoint.SoapVersion == "" || IsValidSoapVersion(endpoint.SoapVersion), "soapversion", "Valid values are 1.1 or 1.2")
+	}
+
+	if endpoint.IsGraphql() {
+		endpoint.CheckField(endpoint.Method == "POST", "method", "GraphQL endpoints use POST")
+		endpoint.CheckField(validator.NotBlank(endpoint.GraphqlSchema), "graphqlschema", "This field cannot be blank")
+	}
+
+	if endpoint.IsGrpc() {
+		endpoint.GrpcService = strings.Trim(strings.TrimSpace(endpoint.GrpcService), "/")
+		endpoint.GrpcMethod = strings.Trim(strings.TrimSpace(endpoint.GrpcMethod), "/")
+		endpoint.ProtoSource = strings.TrimSpace(endpoint.ProtoSource)
+		for i, file := range endpoint.ProtoImports {
+			endpoint.ProtoImports[i] = strings.TrimSpace(file)
+		}
+		endpoint.CheckField(endpoint.Method == "POST", "method", "gRPC endpoints use POST")
+		endpoint.CheckField(validator.NotBlank(endpoint.GrpcService), "grpcservice", "This field cannot be blank")
+		endpoint.CheckField(validator.NotBlank(endpoint.GrpcMethod), "grpcmethod", "This field cannot be blank")
+	}
+
+	endpoint.ResponseSelection = strings.ToUpper(strings.TrimSpace(endpoint.ResponseSelection))
+	if endpoint.ResponseSelection != ResponseSelectionDefault {
+		endpoint.CheckField(validator.MustBeFromList(endpoint.ResponseSelection, ResponseSelectionList...), "responseselection", "Please select one")
+	}
+
+	endpoint.RandomSeed = strings.TrimSpace(endpoint.RandomSeed)
+	endpoint.RecordKey = strings.TrimSpace(endpoint.RecordKey)
+
+	if endpoint.ResourceMode {
+		endpoint.ResourceName = strings.TrimSpace(endpoint.ResourceName)
+		endpoint.ResourceIDField = strings.TrimSpace(endpoint.ResourceIDField)
+		endpoint.CheckField(endpoint.SampleRequestType == "JSON", "samplerequesttype", "Resource endpoints need JSON requests")
+		endpoint.CheckField(validator.MustNotStartwith(endpoint.ResourceName, "/"), "resourcename", "Can not start with /")
+	}
 
 	endpoint.CheckField(validator.NotBlank(endpoint.SampleRequestHeaderType), "samplerequestheadertype", "Please select one")
 	endpoint.CheckField(validator.MustBeFromList(endpoint.SampleRequestHeaderType, "JSON", "XML"), "samplerequestheadertype", "Valid values are JSON or XML")
@@ -334,6 +455,37 @@ func (endpoint *EndPoint) PostValidations() {
 		endpoint.CheckField(validator.MustBeXML(endpoint.SampleRequestHeader), "samplerequestheader", "Must be a valid XML")
 	}
 
+	if endpoint.IsSoap() {
+		envelope, err := ParseSoapEnvelope(endpoint.SampleRequest)
+		if err != nil {
+			endpoint.CheckField(false, "samplerequest", fmt.Sprintf("Must be a SOAP 1.1 or 1.2 envelope: %s", err.Error()))
+		} else if endpoint.SoapElement == "" {
+			endpoint.SoapElement = envelope.Operation
+		}
+	}
+
+	if endpoint.IsGraphql() {
+		endpoint.CheckField(validator.MustBeJSON(endpoint.SampleRequest), "samplerequest", "Must be a valid JSON")
+		if strings.TrimSpace(endpoint.GraphqlSchema) != "" {
+			_, err := graphql.ParseSchema(endpoint.GraphqlSchema)
+			if err != nil {
+				endpoint.CheckField(false, "graphqlschema", fmt.Sprintf("Invalid schema: %s", err.Error()))
+			}
+		}
+		request, err := ParseGraphqlRequest([]byte(endpoint.SampleRequest))
+		if err == nil {
+			_, err = graphql.ParseQuery(request.Query)
+			if err != nil {
+				endpoint.CheckField(false, "samplerequest", fmt.Sprintf("Invalid query: %s", err.Error()))
+			}
+		}
+	}
+
+	if endpoint.IsGrpc() {
+		endpoint.CheckField(validator.MustBeJSON(endpoint.SampleRequest), "samplerequest", "Must be a valid JSON")
+		endpoint.validateGrpc()
+	}
+
 	// if endpoint.SampleResponseType == "JSON" {
 	// 	endpoint.CheckField(validator.MustBeJSON(endpoint.SampleResponse), "sampleresponse", "Must be a valid JSON")
 	// }
@@ -387,7 +539,7 @@ func (endpoint *EndPoint) Prepare() {
 	endpoint.ProcessPathParams()
 
 	switch endpoint.Method {
-	case "GET":
+	case "GET", "HEAD":
 		endpoint.preapreGETEndpoint()
 
 	case "POST":
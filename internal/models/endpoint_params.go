@@ -39,6 +39,10 @@ func (s EndPointModel) RebuildRequestParams(wg *sync.WaitGroup, endPoint *EndPoi
 		flatmap, err = jsonutils.JsonToFlatMap(endPoint.SampleRequest)
 	case "XML":
 		flatmap, _, err = xmlutils.XmlToFlatMapAndPlaceholder(endPoint.SampleRequest)
+	case SoapType:
+		flatmap, _, err = xmlutils.XmlToLocalFlatMapAndPlaceholder(endPoint.SampleRequest)
+	case GraphqlType, GrpcType:
+		flatmap, err = jsonutils.JsonToFlatMap(endPoint.SampleRequest)
 
 	default:
 		err = errors.New("Unknow Request Type")
@@ -62,6 +66,66 @@ func (s EndPointModel) RebuildRequestParams(wg *sync.WaitGroup, endPoint *EndPoi
 		}
 		paramMap["*CLIENT_IP"] = endPointRequestParam
 
+		// request method, url, raw query and body
+		for _, key := range RequestVarKeys {
+			paramMap[key] = &EndPointRequestParam{
+				EndpointID:      endPoint.ID,
+				Key:             key,
+				DefaultValue:    "",
+				DefaultDatatype: "string",
+			}
+		}
+
+		// operation of SOAP requests
+		if endPoint.IsSoap() {
+			paramMap[SoapOperationKey] = &EndPointRequestParam{
+				EndpointID:      endPoint.ID,
+				Key:             SoapOperationKey,
+				DefaultValue:    endPoint.SoapElement,
+				DefaultDatatype: "string",
+			}
+		}
+
+		// operation of GraphQL requests
+		if endPoint.IsGraphql() {
+			values := make(map[string]string)
+			request, err := ParseGraphqlRequest([]byte(endPoint.SampleRequest))
+			if err == nil {
+				values = GraphqlRequestValues(request)
+			}
+			for _, key := range GraphqlRequestKeys {
+				paramMap[key] = &EndPointRequestParam{
+					EndpointID:      endPoint.ID,
+					Key:             key,
+					DefaultValue:    values[key],
+					DefaultDatatype: "string",
+				}
+			}
+		}
+
+		// service and method of gRPC requests
+		if endPoint.IsGrpc() {
+			for key, value := range endPoint.GrpcRequestValues() {
+				paramMap[key] = &EndPointRequestParam{
+					EndpointID:      endPoint.ID,
+					Key:             key,
+					DefaultValue:    value,
+					DefaultDatatype: "string",
+				}
+			}
+		}
+
+		// cookies from the sample request header
+		for name, value := range sampleRequestCookies(endPoint.sampleRequestHeaderFlatMap()) {
+			key := CookieParamPrefix + name
+			paramMap[key] = &EndPointRequestParam{
+				EndpointID:      endPoint.ID,
+				Key:             key,
+				DefaultValue:    value,
+				DefaultDatatype: "string",
+			}
+		}
+
 		// create param based on current json
 		for key, jsonVal := range flatmap {
 			endPointRequestParam := &EndPointRequestParam{
@@ -282,3 +346,23 @@ func (s EndPointModel) RebuildResponseHeaderParams(wg *sync.WaitGroup, endPoint
 
 	return err
 }
+
+// ------------------------------------------------------------
+//
+// ------------------------------------------------------------
+func (endPoint *EndPoint) sampleRequestHeaderFlatMap() map[string]xmlutils.ValueDatatype {
+	var flatmap map[string]xmlutils.ValueDatatype
+	var err error
+
+	switch endPoint.SampleRequestHeaderType {
+	case "JSON":
+		flatmap, err = jsonutils.JsonToFlatMap(endPoint.SampleRequestHeader)
+	case "XML":
+		flatmap, _, err = xmlutils.XmlToFlatMapAndPlaceholder(endPoint.SampleRequestHeader)
+	}
+
+	if err != nil || flatmap == nil {
+		return make(map[string]xmlutils.ValueDatatype)
+	}
+	return flatmap
+}
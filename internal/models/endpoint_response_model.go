@@ -13,12 +13,13 @@ import (
 	bolt "go.etcd.io/bbolt"
 )
 
+var ResponseTypeList = []string{"JSON", "XML", SoapType, GraphqlType, ResponseTypeText, ResponseTypeHtml, ResponseTypeCsv, ResponseTypeForm, ResponseTypeSSE, ResponseTypeFile, ResponseTypeBase64}
+
 type EndPointResponse struct {
 	ID string `json:"id" db:"id" form:"id"`
 	// EndpointID string `json:"endpointid" db:"endpointid" form:"endpointid"`
 
 	Name string `json:"name" db:"name" form:"name"`
-	
 
 	HttpCode int `json:"httpcode" db:"httpcode" form:"httpcode"`
 
@@ -30,6 +31,34 @@ type EndPointResponse struct {
 	ResponsePlaceholder string `json:"responseplaceholder" db:"responseplaceholder" form:"-"`
 	ResponseType        string `json:"responsetype" db:"responsetype" form:"responsetype"`
 
+	// used by the endpoint response selection
+	SequenceOrder int `json:"sequenceorder" db:"sequenceorder" form:"sequenceorder"` // 0: not in the sequence
+	Weight        int `json:"weight" db:"weight" form:"weight"`
+
+	// response and header are Go text/templates, rendered for every call
+	UseTemplate bool `json:"usetemplate" db:"usetemplate" form:"usetemplate"`
+
+	// FILE and BASE64 responses
+	FileName           string `json:"filename" db:"filename" form:"filename"`
+	StoredFile         string `json:"storedfile" db:"storedfile" form:"-"`
+	ContentType        string `json:"contenttype" db:"contenttype" form:"contenttype"`                      // any response type, blank for the default
+	ContentDisposition string `json:"contentdisposition" db:"contentdisposition" form:"contentdisposition"` // blank, inline or attachment
+
+	// simulated failure: CLOSE, RESET, TRUNCATE, MALFORMED, BAD_LENGTH or HANG
+	Fault string `json:"fault" db:"fault" form:"fault"`
+
+	// SSE responses start over after the last event
+	EventLoop bool `json:"eventloop" db:"eventloop" form:"eventloop"`
+
+	// chunked and throttled body
+	StreamSettings
+
+	// Set-Cookie headers, values can be assigned as *COOKIE_name params
+	Cookies []*ResponseCookie `json:"cookies" db:"cookies" form:"cookies"`
+
+	// outbound calls made after this response is sent
+	Webhooks WebhookList `json:"webhooks" db:"webhooks" form:"webhooks"`
+
 	ResponseParams []*EndPointResponseParam `json:"-" db:"-" from:"-"`
 
 	validator.Validator `json:"-" db:"-" from:"-"`
@@ -40,7 +69,7 @@ type EndPointResponse struct {
 // ------------------------------------------------------------
 func (s *EndPointResponse) BuildResponsePlaceholder() {
 	switch s.ResponseType {
-	case "JSON":
+	case "JSON", GraphqlType:
 		uResponsePlaceholder, err := jsonutils.JsonToMapPlaceholder(s.Response)
 		if err != nil {
 		} else {
@@ -56,6 +85,16 @@ func (s *EndPointResponse) BuildResponsePlaceholder() {
 		} else {
 			s.ResponsePlaceholder = uResponsePlaceholder
 		}
+	case SoapType:
+		_, uResponsePlaceholder, err := xmlutils.XmlToLocalFlatMapAndPlaceholder(s.Response)
+		if err == nil {
+			s.ResponsePlaceholder = uResponsePlaceholder
+		}
+	case ResponseTypeText, ResponseTypeHtml, ResponseTypeCsv, ResponseTypeForm, ResponseTypeSSE:
+		_, uResponsePlaceholder, err := textResponseToFlatMapAndPlaceholder(s.ResponseType, s.Response)
+		if err == nil {
+			s.ResponsePlaceholder = uResponsePlaceholder
+		}
 	}
 
 }
@@ -100,11 +139,18 @@ func (s *EndPointResponse) RebuildResponseParams(wg *sync.WaitGroup, DB *bolt.DB
 	var flatmap map[string]xmlutils.ValueDatatype
 	//xmlPlaceholder := ""
 
-	switch s.ResponseType {
-	case "JSON":
+	switch {
+	case s.UseTemplate || s.IsBinary():
+		// templates and files have no placeholders, only the special params
+		flatmap = make(map[string]xmlutils.ValueDatatype)
+	case s.ResponseType == "JSON" || s.ResponseType == GraphqlType:
 		flatmap, err = jsonutils.JsonToFlatMap(s.Response)
-	case "XML":
+	case s.ResponseType == "XML":
 		flatmap, _, err = xmlutils.XmlToFlatMapAndPlaceholder(s.Response)
+	case s.ResponseType == SoapType:
+		flatmap, _, err = xmlutils.XmlToLocalFlatMapAndPlaceholder(s.Response)
+	case IsTextResponseType(s.ResponseType):
+		flatmap, _, err = textResponseToFlatMapAndPlaceholder(s.ResponseType, s.Response)
 
 	default:
 		err = errors.New("Unknow Request Type")
@@ -134,6 +180,23 @@ func (s *EndPointResponse) RebuildResponseParams(wg *sync.WaitGroup, DB *bolt.DB
 		}
 		paramMap["*DELAY_RESPONSE_MILLI_SEC"] = endPointRequestParam
 
+		paramMap[FaultParamKey] = &EndPointResponseParam{
+			OwnerId:         s.ID,
+			Key:             FaultParamKey,
+			DefaultValue:    s.Fault,
+			DefaultDatatype: "STRING",
+		}
+
+		for _, c := range s.Cookies {
+			keyToUse := CookieParamPrefix + c.Name
+			paramMap[keyToUse] = &EndPointResponseParam{
+				OwnerId:         s.ID,
+				Key:             keyToUse,
+				DefaultValue:    c.Value,
+				DefaultDatatype: "STRING",
+			}
+		}
+
 		for key, jsonVal := range flatmap {
 			endPointResponseParam := &EndPointResponseParam{
 				OwnerId:         s.ID,
@@ -181,10 +244,12 @@ func (s *EndPointResponse) RebuildResponseHeaderParams(wg *sync.WaitGroup, DB *b
 	var flatmap map[string]xmlutils.ValueDatatype
 	//xmlPlaceholder := ""
 
-	switch s.ResponseHeaderType {
-	case "JSON":
+	switch {
+	case s.UseTemplate:
+		flatmap = make(map[string]xmlutils.ValueDatatype)
+	case s.ResponseHeaderType == "JSON":
 		flatmap, err = jsonutils.JsonToFlatMap(s.ResponseHeader)
-	case "XML":
+	case s.ResponseHeaderType == "XML":
 		flatmap, _, err = xmlutils.XmlToFlatMapAndPlaceholder(s.ResponseHeader)
 
 	default:
@@ -23,9 +23,15 @@ type EndPointModel struct {
 func (m *EndPointModel) BuildEndPointCache(limit int) map[string]*EndPoint {
 
 	cache := make(map[string]*EndPoint)
+	resourceEndPoints := make([]*EndPoint, 0)
 
 	endPoints := m.List()
 
+	collections := make(map[string]*Collection)
+	for _, collection := range (&CollectionModel{DB: m.DB}).List() {
+		collections[strings.ToUpper(collection.ID)] = collection
+	}
+
 	for i, endPoint := range endPoints {
 		if limit > 0 && i+1 > limit {
 			break
@@ -34,7 +40,32 @@ func (m *EndPointModel) BuildEndPointCache(limit int) map[string]*EndPoint {
 		if endPoint.CollectionName == "" {
 			endPoint.CollectionName = "V1"
 		}
+		endPoint.Collection = collections[strings.ToUpper(endPoint.CollectionID)]
 		cache[fmt.Sprintf("%s_%s_%s", strings.ToLower(endPoint.CollectionName), strings.ToLower(endPoint.Name), strings.ToLower(endPoint.Method))] = endPoint
+
+		if endPoint.ResourceMode {
+			resourceEndPoints = append(resourceEndPoints, endPoint)
+		}
+	}
+
+	// resource endpoints answer every method not taken by an explicit endpoint
+	for _, endPoint := range resourceEndPoints {
+		for _, method := range ResourceMethods {
+			key := fmt.Sprintf("%s_%s_%s", strings.ToLower(endPoint.CollectionName), strings.ToLower(endPoint.Name), method)
+			if _, found := cache[key]; !found {
+				cache[key] = endPoint
+			}
+		}
+	}
+
+	// HEAD is answered by the GET endpoint, without the body
+	for key, endPoint := range cache {
+		if strings.HasSuffix(key, "_get") {
+			headKey := strings.TrimSuffix(key, "_get") + "_head"
+			if _, found := cache[headKey]; !found {
+				cache[headKey] = endPoint
+			}
+		}
 	}
 
 	return cache
@@ -70,6 +101,9 @@ func (m *EndPointModel) Save(u *EndPoint, userEmail string) (string, error) {
 
 	u.BuildResponsePlaceholder()
 
+	clearGraphqlSchema(u.ID)
+	clearProtoRegistry(u.ID)
+
 	//u.BuildResponseHeaderPlaceholder()
 
 	err := m.Update(u, false)
@@ -151,6 +185,9 @@ func (m *EndPointModel) Delete(id string) error {
 
 	// TODO ==> delete request and response params
 	if err == nil {
+		clearGraphqlSchema(id)
+		clearProtoRegistry(id)
+
 		go func() {
 			m1 := &UserModel{DB: m.DB}
 			m1.ClearEndPointowners(id)
@@ -167,6 +204,8 @@ func (m *EndPointModel) Delete(id string) error {
 			m5 := &ConditionGroupModel{DB: m.DB}
 			m5.ClearEndPointData(id)
 
+			ClearEndPointFiles(id)
+
 		}()
 	}
 	return err
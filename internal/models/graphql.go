@@ -0,0 +1,211 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/onlysumitg/GoMockAPI/internal/graphql"
+)
+
+// GRAPHQL is a request type and a response type. Requests are the usual
+// {"query", "operationName", "variables"} JSON so the variables are request
+// params (variables.id). Responses are mock values by type and by field
+// ({"Query.user": {...}, "User": {...}, "User.name": "Ada"}); the query is
+// run against the schema of the endpoint and what has no mock value is
+// generated.
+const (
+	GraphqlType = "GRAPHQL"
+
+	// operation that is run, query/mutation/subscription and its root fields
+	GraphqlOperationKey     = "*GRAPHQL_OPERATION"
+	GraphqlOperationTypeKey = "*GRAPHQL_OPERATION_TYPE"
+	GraphqlFieldsKey        = "*GRAPHQL_FIELDS"
+)
+
+var GraphqlRequestKeys = []string{GraphqlOperationKey, GraphqlOperationTypeKey, GraphqlFieldsKey}
+
+// parsed schemas by endpoint id
+var graphqlSchemas sync.Map
+
+type parsedGraphqlSchema struct {
+	sdl    string
+	schema *graphql.Schema
+}
+
+// -----------------------------------------------------------------
+// parsed once per endpoint, again when the SDL changes
+// -----------------------------------------------------------------
+func (e *EndPoint) ParsedGraphqlSchema() (*graphql.Schema, error) {
+	if cached, found := graphqlSchemas.Load(e.ID); found && cached.(*parsedGraphqlSchema).sdl == e.GraphqlSchema {
+		return cached.(*parsedGraphqlSchema).schema, nil
+	}
+
+	schema, err := graphql.ParseSchema(e.GraphqlSchema)
+	if err != nil {
+		return nil, err
+	}
+	graphqlSchemas.Store(e.ID, &parsedGraphqlSchema{sdl: e.GraphqlSchema, schema: schema})
+	return schema, nil
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func clearGraphqlSchema(endPointID string) {
+	graphqlSchemas.Delete(endPointID)
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (e *EndPoint) IsGraphql() bool {
+	return strings.EqualFold(e.SampleRequestType, GraphqlType)
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func ParseGraphqlRequest(body []byte) (*graphql.Request, error) {
+	request := &graphql.Request{}
+	err := json.Unmarshal(body, request)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(request.Query) == "" {
+		return nil, errors.New("query is missing")
+	}
+	return request, nil
+}
+
+// -----------------------------------------------------------------
+// operation name, type and root fields for the condition engine,
+// blank when the query does not parse
+// -----------------------------------------------------------------
+func GraphqlRequestValues(request *graphql.Request) map[string]string {
+	values := map[string]string{
+		GraphqlOperationKey:     "",
+		GraphqlOperationTypeKey: "",
+		GraphqlFieldsKey:        "",
+	}
+
+	document, err := graphql.ParseQuery(request.Query)
+	if err != nil {
+		return values
+	}
+	operation, err := document.Operation(request.OperationName)
+	if err != nil {
+		return values
+	}
+
+	fields := make([]string, 0)
+	for _, s := range operation.SelectionSet {
+		if s.Kind == graphql.SelectionField {
+			fields = append(fields, s.Name)
+		}
+	}
+
+	values[GraphqlOperationKey] = operation.Name
+	values[GraphqlOperationTypeKey] = operation.Type
+	values[GraphqlFieldsKey] = strings.Join(fields, ",")
+	return values
+}
+
+// -----------------------------------------------------------------
+// GraphqlErrorResponse is the body for a request that can not be run
+// -----------------------------------------------------------------
+func GraphqlErrorResponse(err error) []byte {
+	body, _ := json.Marshal(graphql.ErrorResponse(err))
+	return body
+}
+
+// -----------------------------------------------------------------
+// runs the query of the request with the response as mock values
+// -----------------------------------------------------------------
+func (a *ApiCall) ResolveGraphqlResponse(response *EndPointResponse) {
+	if strings.TrimSpace(response.ContentType) == "" {
+		a.FinalContentType = ResponseContentType(GraphqlType, "")
+	}
+
+	result := a.executeGraphql()
+	body, err := json.Marshal(result)
+	if err != nil {
+		body = GraphqlErrorResponse(err)
+	}
+	a.FinalResponseString = string(body)
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (a *ApiCall) executeGraphql() *graphql.Response {
+	if a.CurrentEndPoint == nil {
+		return graphql.ErrorResponse(errors.New("no endpoint"))
+	}
+
+	schema, err := a.CurrentEndPoint.ParsedGraphqlSchema()
+	if err != nil {
+		a.LogError(fmt.Sprintf("Invalid GraphQL schema: %s", err.Error()))
+		return graphql.ErrorResponse(err)
+	}
+
+	request, err := ParseGraphqlRequest(a.RequestBody())
+	if err != nil {
+		return graphql.ErrorResponse(err)
+	}
+
+	mocks := make(map[string]any)
+	if strings.TrimSpace(a.FinalResponseString) != "" {
+		err = json.Unmarshal([]byte(a.FinalResponseString), &mocks)
+		if err != nil {
+			a.LogError(fmt.Sprintf("Invalid GraphQL mock values: %s", err.Error()))
+			return graphql.ErrorResponse(fmt.Errorf("invalid mock values: %s", err.Error()))
+		}
+	}
+
+	return schema.Execute(request, graphql.Options{Mocks: mocks, Generate: a.graphqlValue})
+}
+
+// -----------------------------------------------------------------
+// generated value of a scalar or enum, from the seeded faker of the call
+// -----------------------------------------------------------------
+func (a *ApiCall) graphqlValue(t *graphql.Type, field string) any {
+	f := a.RandomFaker()
+	name := strings.ToLower(field)
+
+	switch {
+	case t.Kind == graphql.KindEnum && len(t.EnumValues) > 0:
+		return t.EnumValues[f.Number(0, len(t.EnumValues)-1)]
+	case t.Name == "Int":
+		return f.Number(1, 1000)
+	case t.Name == "Float":
+		return float64(f.Number(100, 100000)) / 100
+	case t.Name == "Boolean":
+		return f.Bool()
+	case t.Name == "ID":
+		return f.UUID()
+	case strings.Contains(strings.ToLower(t.Name), "date") || strings.Contains(strings.ToLower(t.Name), "time"):
+		return f.Date().UTC().Format("2006-01-02T15:04:05Z")
+	case strings.Contains(name, "email"):
+		return f.Email()
+	case strings.Contains(name, "firstname"):
+		return f.FirstName()
+	case strings.Contains(name, "lastname"):
+		return f.LastName()
+	case strings.Contains(name, "name"):
+		return f.Name()
+	case strings.Contains(name, "phone"):
+		return f.Phone()
+	case strings.Contains(name, "city"):
+		return f.City()
+	case strings.Contains(name, "country"):
+		return f.Country()
+	case strings.Contains(name, "url"):
+		return f.URL()
+	case strings.Contains(name, "title"), strings.Contains(name, "description"):
+		return f.Sentence(5)
+	}
+	return f.Word()
+}
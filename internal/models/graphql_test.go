@@ -0,0 +1,36 @@
+package models
+
+import "testing"
+
+func Test_ParsedGraphqlSchema(t *testing.T) {
+	endPoint := &EndPoint{ID: "graphql-test", GraphqlSchema: "type Query { a: String }"}
+	defer clearGraphqlSchema(endPoint.ID)
+
+	first, err := endPoint.ParsedGraphqlSchema()
+	if err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	second, _ := endPoint.ParsedGraphqlSchema()
+	if first != second {
+		t.Errorf("expected the cached schema")
+	}
+
+	endPoint.GraphqlSchema = "type Query { b: String }"
+	changed, _ := endPoint.ParsedGraphqlSchema()
+	if changed == first || changed.Types["Query"].Fields[0].Name != "b" {
+		t.Errorf("expected the schema to be parsed again")
+	}
+
+	clearGraphqlSchema(endPoint.ID)
+	if _, found := graphqlSchemas.Load(endPoint.ID); found {
+		t.Errorf("expected the schema to be removed")
+	}
+
+	endPoint.GraphqlSchema = "type Query {"
+	if _, err := endPoint.ParsedGraphqlSchema(); err == nil {
+		t.Errorf("expected an error")
+	}
+	if _, found := graphqlSchemas.Load(endPoint.ID); found {
+		t.Errorf("an invalid schema must not be cached")
+	}
+}
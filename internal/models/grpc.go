@@ -0,0 +1,302 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/onlysumitg/GoMockAPI/internal/protobuf"
+)
+
+// GRPC is a request type. The endpoint keeps the .proto source and the
+// service and method it mocks; requests are decoded to JSON for the
+// conditions and JSON responses are encoded to protobuf. gRPC calls are
+// served on their own port at /package.Service/Method.
+const (
+	GrpcType = "GRPC"
+
+	GrpcServiceKey = "*GRPC_SERVICE"
+	GrpcMethodKey  = "*GRPC_METHOD"
+
+	// messages of a server streaming response, {"*STREAM": [{...}, {...}]}
+	GrpcStreamKey = "*STREAM"
+
+	// response headers that become the status of the call
+	GrpcStatusHeader  = "grpc-status"
+	GrpcMessageHeader = "grpc-message"
+)
+
+// status codes by name
+var GrpcCodes = map[string]int{
+	"OK":                  0,
+	"CANCELLED":           1,
+	"UNKNOWN":             2,
+	"INVALID_ARGUMENT":    3,
+	"DEADLINE_EXCEEDED":   4,
+	"NOT_FOUND":           5,
+	"ALREADY_EXISTS":      6,
+	"PERMISSION_DENIED":   7,
+	"RESOURCE_EXHAUSTED":  8,
+	"FAILED_PRECONDITION": 9,
+	"ABORTED":             10,
+	"OUT_OF_RANGE":        11,
+	"UNIMPLEMENTED":       12,
+	"INTERNAL":            13,
+	"UNAVAILABLE":         14,
+	"DATA_LOSS":           15,
+	"UNAUTHENTICATED":     16,
+}
+
+// status of responses with an error http code and no grpc-status header
+var grpcCodesByHttpCode = map[int]int{
+	http.StatusBadRequest:          3,
+	http.StatusUnauthorized:        16,
+	http.StatusForbidden:           7,
+	http.StatusNotFound:            5,
+	http.StatusConflict:            6,
+	http.StatusPreconditionFailed:  9,
+	http.StatusTooManyRequests:     8,
+	http.StatusNotImplemented:      12,
+	http.StatusServiceUnavailable:  14,
+	http.StatusGatewayTimeout:      4,
+	http.StatusRequestTimeout:      4,
+	http.StatusInternalServerError: 13,
+}
+
+// parsed .proto files by endpoint id
+var protoRegistries sync.Map
+
+type parsedProto struct {
+	files    []string
+	registry *protobuf.Registry
+}
+
+// -----------------------------------------------------------------
+// imported files first, then the file of the service
+// -----------------------------------------------------------------
+func (e *EndPoint) ProtoFiles() []string {
+	files := make([]string, 0, len(e.ProtoImports)+1)
+	files = append(files, e.ProtoImports...)
+	return append(files, e.ProtoSource)
+}
+
+// -----------------------------------------------------------------
+// parsed once per endpoint, again when the files change. Endpoints that
+// are not saved yet are not cached.
+// -----------------------------------------------------------------
+func (e *EndPoint) ProtoRegistry() (*protobuf.Registry, error) {
+	files := e.ProtoFiles()
+	if cached, found := protoRegistries.Load(e.ID); found && sameFiles(cached.(*parsedProto).files, files) {
+		return cached.(*parsedProto).registry, nil
+	}
+
+	registry, err := protobuf.Parse(files...)
+	if err != nil {
+		return nil, err
+	}
+	if e.ID != "" {
+		protoRegistries.Store(e.ID, &parsedProto{files: files, registry: registry})
+	}
+	return registry, nil
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func sameFiles(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func clearProtoRegistry(endPointID string) {
+	protoRegistries.Delete(endPointID)
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (e *EndPoint) IsGrpc() bool {
+	return strings.EqualFold(e.SampleRequestType, GrpcType)
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (e *EndPoint) IsGrpcMethodOf(service string, method string) bool {
+	return e.IsGrpc() && e.GrpcService == service && e.GrpcMethod == method
+}
+
+// -----------------------------------------------------------------
+// /package.Service/Method
+// -----------------------------------------------------------------
+func (e *EndPoint) GrpcPath() string {
+	return fmt.Sprintf("/%s/%s", e.GrpcService, e.GrpcMethod)
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (e *EndPoint) GrpcMethodDescriptor() (*protobuf.Registry, *protobuf.Method, error) {
+	registry, err := e.ProtoRegistry()
+	if err != nil {
+		return nil, nil, err
+	}
+	method, err := registry.Method(e.GrpcService, e.GrpcMethod)
+	if err != nil {
+		return nil, nil, err
+	}
+	return registry, method, nil
+}
+
+// -----------------------------------------------------------------
+// service and method for the condition engine
+// -----------------------------------------------------------------
+func (e *EndPoint) GrpcRequestValues() map[string]string {
+	return map[string]string{
+		GrpcServiceKey: e.GrpcService,
+		GrpcMethodKey:  e.GrpcMethod,
+	}
+}
+
+// -----------------------------------------------------------------
+// sample request must be a valid request message
+// -----------------------------------------------------------------
+func (e *EndPoint) validateGrpc() {
+	e.CheckField(e.ProtoSource != "", "protosource", "This field cannot be blank")
+	if e.ProtoSource == "" {
+		return
+	}
+
+	registry, method, err := e.GrpcMethodDescriptor()
+	if err != nil {
+		e.CheckField(false, "protosource", fmt.Sprintf("Invalid proto: %s", err.Error()))
+		return
+	}
+
+	var request any
+	err = json.Unmarshal([]byte(e.SampleRequest), &request)
+	if err == nil {
+		_, err = registry.Marshal(method.Input, request)
+	}
+	if err != nil {
+		e.CheckField(false, "samplerequest", fmt.Sprintf("Must be a JSON %s: %s", method.Input, err.Error()))
+	}
+}
+
+// -----------------------------------------------------------------
+// JSON response of a method: the message, or the messages under
+// *STREAM for server streaming methods. The body of an error is only
+// sent when it has messages of a stream.
+// -----------------------------------------------------------------
+func GrpcResponseMessages(registry *protobuf.Registry, method *protobuf.Method, response string, isError bool) ([][]byte, error) {
+	if strings.TrimSpace(response) == "" {
+		return [][]byte{}, nil
+	}
+
+	value := make(map[string]any)
+	err := json.Unmarshal([]byte(response), &value)
+	if err != nil {
+		return nil, err
+	}
+
+	values := []any{value}
+	if stream, found := value[GrpcStreamKey]; found {
+		list, ok := stream.([]any)
+		if !ok || len(value) > 1 {
+			return nil, fmt.Errorf("%s must be the only key and a list of messages", GrpcStreamKey)
+		}
+		if !method.ServerStreaming {
+			return nil, fmt.Errorf("%s is not server streaming, the response must be one message", method.Name)
+		}
+		values = list
+	} else if isError {
+		return [][]byte{}, nil
+	}
+
+	messages := make([][]byte, 0, len(values))
+	for _, v := range values {
+		message, err := registry.Marshal(method.Output, v)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, message)
+	}
+	return messages, nil
+}
+
+// -----------------------------------------------------------------
+// ValidateGrpcResponse checks a JSON response of a gRPC endpoint
+// -----------------------------------------------------------------
+func (e *EndPoint) ValidateGrpcResponse(response string) error {
+	registry, method, err := e.GrpcMethodDescriptor()
+	if err != nil {
+		return err
+	}
+	_, err = GrpcResponseMessages(registry, method, response, false)
+	return err
+}
+
+// -----------------------------------------------------------------
+// response headers are not canonical, the keys are as typed
+// -----------------------------------------------------------------
+func headerValue(header http.Header, key string) string {
+	for k, values := range header {
+		if strings.EqualFold(k, key) && len(values) > 0 {
+			return values[0]
+		}
+	}
+	return ""
+}
+
+// -----------------------------------------------------------------
+// status code by number or name, then by the http code
+// -----------------------------------------------------------------
+func GrpcStatus(header http.Header, httpCode int) (int, string) {
+	message := headerValue(header, GrpcMessageHeader)
+
+	code := GrpcCodes["UNKNOWN"]
+	status := strings.ToUpper(strings.TrimSpace(headerValue(header, GrpcStatusHeader)))
+	if c, found := GrpcCodes[status]; found {
+		code = c
+	} else if c, err := strconv.Atoi(status); err == nil && c >= 0 {
+		code = c
+	} else if httpCode >= 200 && httpCode < 300 {
+		code = GrpcCodes["OK"]
+	} else if c, found := grpcCodesByHttpCode[httpCode]; found {
+		code = c
+	}
+
+	if code != GrpcCodes["OK"] && message == "" {
+		message = http.StatusText(httpCode)
+	}
+	return code, message
+}
+
+// -----------------------------------------------------------------
+// grpc-message is percent encoded
+// -----------------------------------------------------------------
+func EncodeGrpcMessage(message string) string {
+	var b strings.Builder
+	for i := 0; i < len(message); i++ {
+		c := message[i]
+		if c >= ' ' && c <= '~' && c != '%' {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
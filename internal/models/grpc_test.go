@@ -0,0 +1,50 @@
+package models
+
+import "testing"
+
+func Test_ProtoRegistry(t *testing.T) {
+	endPoint := &EndPoint{
+		ID:           "grpc-test",
+		ProtoImports: []string{"package common; message Page { int32 size = 1; }"},
+		ProtoSource:  "package demo; service S { rpc M(common.Page) returns (common.Page); }",
+	}
+	defer clearProtoRegistry(endPoint.ID)
+
+	first, err := endPoint.ProtoRegistry()
+	if err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	if first.Services["demo.S"].Source != 1 {
+		t.Errorf("expected the service in the file after its import")
+	}
+	second, _ := endPoint.ProtoRegistry()
+	if first != second {
+		t.Errorf("expected the cached registry")
+	}
+
+	// an import that changes is parsed again
+	endPoint.ProtoImports = []string{"package common; message Page { int32 size = 1; int32 page = 2; }"}
+	changed, _ := endPoint.ProtoRegistry()
+	if changed == first || len(changed.Messages["common.Page"].Fields) != 2 {
+		t.Errorf("expected the files to be parsed again")
+	}
+
+	clearProtoRegistry(endPoint.ID)
+	if _, found := protoRegistries.Load(endPoint.ID); found {
+		t.Errorf("expected the registry to be removed")
+	}
+
+	// one entry per endpoint, endpoints that are not saved are not cached
+	unsaved := &EndPoint{ProtoSource: endPoint.ProtoSource, ProtoImports: endPoint.ProtoImports}
+	if _, err := unsaved.ProtoRegistry(); err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	if _, found := protoRegistries.Load(""); found {
+		t.Errorf("an endpoint without id must not be cached")
+	}
+
+	endPoint.ProtoImports = nil
+	if _, err := endPoint.ProtoRegistry(); err == nil {
+		t.Errorf("expected an error for the missing import")
+	}
+}
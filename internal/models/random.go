@@ -1,81 +1,231 @@
 package models
 
 import (
+	"errors"
 	"fmt"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/brianvoe/gofakeit/v6"
 	"github.com/brianvoe/gofakeit/v6/data"
 )
 
-func FakeHTTPStatusCode() string {
-	return strconv.Itoa(gofakeit.HTTPStatusCode())
+func FakeHTTPStatusCode(f *gofakeit.Faker) string {
+	return strconv.Itoa(f.HTTPStatusCode())
 }
 
-func FakeVISACard() string {
+func FakeVISACard(f *gofakeit.Faker) string {
 
 	// []string{"visa", "mastercard", "american-express", "diners-club", "discover", "jcb", "unionpay", "maestro", "elo", "hiper", "hipercard"}
 
-	return gofakeit.CreditCardNumber(&gofakeit.CreditCardOptions{Types: []string{"visa"}})
+	return f.CreditCardNumber(&gofakeit.CreditCardOptions{Types: []string{"visa"}})
 
 }
-func FakeMasterCard() string {
+func FakeMasterCard(f *gofakeit.Faker) string {
 
 	// []string{"visa", "mastercard", "american-express", "diners-club", "discover", "jcb", "unionpay", "maestro", "elo", "hiper", "hipercard"}
 
-	return gofakeit.CreditCardNumber(&gofakeit.CreditCardOptions{Types: []string{"mastercard"}})
+	return f.CreditCardNumber(&gofakeit.CreditCardOptions{Types: []string{"mastercard"}})
 
 }
 
-func FakeCreditCard() string {
+func FakeCreditCard(f *gofakeit.Faker) string {
 
 	// []string{"visa", "mastercard", "american-express", "diners-club", "discover", "jcb", "unionpay", "maestro", "elo", "hiper", "hipercard"}
 
-	return gofakeit.CreditCardNumber(&gofakeit.CreditCardOptions{Types: data.CreditCardTypes})
+	return f.CreditCardNumber(&gofakeit.CreditCardOptions{Types: data.CreditCardTypes})
 
 }
 
-var RandomFunctionMap map[string]func() string = map[string]func() string{
-	"NAME":      gofakeit.Name,
-	"FIRSTNAME": gofakeit.FirstName,
-	"LASTNAME":  gofakeit.LastName,
-	"USERNAME":  gofakeit.Username,
-
-	"EMAIL":          gofakeit.Email,
-	"URL":            gofakeit.URL,
-	"DOMAIN":         gofakeit.DomainName,
-	"IPV4":           gofakeit.IPv4Address,
-	"IPV6":           gofakeit.IPv6Address,
+var RandomFunctionMap map[string]func(f *gofakeit.Faker) string = map[string]func(f *gofakeit.Faker) string{
+	"NAME":      (*gofakeit.Faker).Name,
+	"FIRSTNAME": (*gofakeit.Faker).FirstName,
+	"LASTNAME":  (*gofakeit.Faker).LastName,
+	"USERNAME":  (*gofakeit.Faker).Username,
+
+	"EMAIL":          (*gofakeit.Faker).Email,
+	"URL":            (*gofakeit.Faker).URL,
+	"DOMAIN":         (*gofakeit.Faker).DomainName,
+	"IPV4":           (*gofakeit.Faker).IPv4Address,
+	"IPV6":           (*gofakeit.Faker).IPv6Address,
 	"HTTPSTATUSCODE": FakeHTTPStatusCode,
 
-	"PHONE":   gofakeit.Phone,
-	"CITY":    gofakeit.City,
-	"STATE":   gofakeit.StateAbr,
-	"ZIP":     gofakeit.Zip,
-	"WORD":    gofakeit.Word,
-	"SETENCE": gofakeit.SentenceSimple,
+	"PHONE":   (*gofakeit.Faker).Phone,
+	"CITY":    (*gofakeit.Faker).City,
+	"STATE":   (*gofakeit.Faker).StateAbr,
+	"ZIP":     (*gofakeit.Faker).Zip,
+	"WORD":    (*gofakeit.Faker).Word,
+	"SETENCE": (*gofakeit.Faker).SentenceSimple,
 
 	"VISACARD":   FakeVISACard,
 	"MASTERCARD": FakeMasterCard,
 	"CREDITCARD": FakeCreditCard,
-	"CARDCVV":    gofakeit.CreditCardCvv,
-	"CARDEXPIRY": gofakeit.CreditCardExp,
+	"CARDCVV":    (*gofakeit.Faker).CreditCardCvv,
+	"CARDEXPIRY": (*gofakeit.Faker).CreditCardExp,
+
+	"BANKROUTING": (*gofakeit.Faker).AchRouting,
+	"BANKACCOUNT": (*gofakeit.Faker).AchAccount,
+
+	"COMPANYNAME": (*gofakeit.Faker).Company,
+
+	"APPNAME": (*gofakeit.Faker).AppName,
+
+	"COLOR": (*gofakeit.Faker).Color,
+
+	"LANGUAGE": (*gofakeit.Faker).Language,
+
+	"NUMBER": (*gofakeit.Faker).Digit,
+}
+
+// -----------------------------------------------------------------
+// generators with arguments: *RANDOM:INT(1,1000)
+// -----------------------------------------------------------------
+type RandomGenerator struct {
+	Name        string
+	Arguments   string
+	Example     string
+	Description string
+
+	RawArgument bool // whole text between the brackets is one argument
+
+	Generate func(f *gofakeit.Faker, args []string) (string, error)
+}
+
+var randomFaker = gofakeit.New(0)
+
+var randomCallRegex = regexp.MustCompile(`(?s)^\s*([A-Za-z0-9_]+)\s*\((.*)\)\s*$`)
+
+var RandomGeneratorMap map[string]*RandomGenerator = map[string]*RandomGenerator{
+	"INT": {
+		Name: "INT", Arguments: "min,max", Example: "*RANDOM:INT(1,1000)",
+		Description: "Whole number between min and max, both included",
+		Generate:    randomInt,
+	},
+	"FLOAT": {
+		Name: "FLOAT", Arguments: "min,max[,decimals]", Example: "*RANDOM:FLOAT(0,99.99,2)",
+		Description: "Decimal number between min and max, rounded to decimals (default 2)",
+		Generate:    randomFloat,
+	},
+	"UUID": {
+		Name: "UUID", Example: "*RANDOM:UUID",
+		Description: "Version 4 UUID",
+		Generate: func(f *gofakeit.Faker, args []string) (string, error) {
+			return f.UUID(), nil
+		},
+	},
+	"DATE": {
+		Name: "DATE", Arguments: "from,to[,layout]", Example: "*RANDOM:DATE(2020-01-01,2025-12-31,2006-01-02)",
+		Description: "Date between from and to (YYYY-MM-DD or RFC3339), formatted with a Go layout (default 2006-01-02)",
+		RawArgument: true,
+		Generate:    randomDate,
+	},
+	"REGEX": {
+		Name: "REGEX", Arguments: "pattern", Example: "*RANDOM:REGEX([A-Z]{3}\\d{4})",
+		Description: "String matching the pattern",
+		RawArgument: true,
+		Generate: func(f *gofakeit.Faker, args []string) (string, error) {
+			if _, err := regexp.Compile(args[0]); err != nil {
+				return "", fmt.Errorf("invalid REGEX pattern %s: %s", args[0], err.Error())
+			}
+			return f.Regex(args[0]), nil
+		},
+	},
+	"ONEOF": {
+		Name: "ONEOF", Arguments: "a,b,c...", Example: "*RANDOM:ONEOF(NEW,PAID,SHIPPED)",
+		Description: "One of the given values",
+		Generate: func(f *gofakeit.Faker, args []string) (string, error) {
+			return f.RandomString(args), nil
+		},
+	},
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func randomInt(f *gofakeit.Faker, args []string) (string, error) {
+	if len(args) != 2 {
+		return "", errors.New("INT needs min and max: INT(1,1000)")
+	}
+	min, err1 := strconv.Atoi(args[0])
+	max, err2 := strconv.Atoi(args[1])
+	if err1 != nil || err2 != nil || max < min {
+		return "", fmt.Errorf("invalid INT range %s,%s", args[0], args[1])
+	}
+	return strconv.Itoa(f.Number(min, max)), nil
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func randomFloat(f *gofakeit.Faker, args []string) (string, error) {
+	if len(args) < 2 || len(args) > 3 {
+		return "", errors.New("FLOAT needs min and max: FLOAT(0,99.99,2)")
+	}
+	min, err1 := strconv.ParseFloat(args[0], 64)
+	max, err2 := strconv.ParseFloat(args[1], 64)
+	if err1 != nil || err2 != nil || max < min {
+		return "", fmt.Errorf("invalid FLOAT range %s,%s", args[0], args[1])
+	}
 
-	"BANKROUTING": gofakeit.AchRouting,
-	"BANKACCOUNT": gofakeit.AchAccount,
+	decimals := 2
+	if len(args) == 3 {
+		d, err := strconv.Atoi(args[2])
+		if err != nil || d < 0 {
+			return "", fmt.Errorf("invalid FLOAT decimals %s", args[2])
+		}
+		decimals = d
+	}
 
-	"COMPANYNAME": gofakeit.Company,
+	value := min
+	if max > min {
+		value = f.Float64Range(min, max)
+	}
+	return strconv.FormatFloat(value, 'f', decimals, 64), nil
+}
 
-	"APPNAME": gofakeit.AppName,
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func parseRandomDate(value string) (time.Time, error) {
+	t, err := time.Parse("2006-01-02", value)
+	if err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
 
-	"COLOR": gofakeit.Color,
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func randomDate(f *gofakeit.Faker, args []string) (string, error) {
+	// layouts can have commas: Mon, 02 Jan 2006
+	parts := strings.SplitN(args[0], ",", 3)
+	if len(parts) < 2 {
+		return "", errors.New("DATE needs from and to: DATE(2020-01-01,2025-12-31)")
+	}
+	from, err := parseRandomDate(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return "", fmt.Errorf("invalid DATE from %s", parts[0])
+	}
+	to, err := parseRandomDate(strings.TrimSpace(parts[1]))
+	if err != nil || to.Before(from) {
+		return "", fmt.Errorf("invalid DATE to %s", parts[1])
+	}
 
-	"LANGUAGE": gofakeit.Language,
+	layout := "2006-01-02"
+	if len(parts) > 2 && strings.TrimSpace(parts[2]) != "" {
+		layout = strings.TrimSpace(parts[2])
+	}
 
-	"NUMBER": gofakeit.Digit,
+	return f.DateRange(from, to).Format(layout), nil
 }
 
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
 func GetRandonFunctiolist() []string {
 	returnMap := make([]string, 0)
 
@@ -84,13 +234,74 @@ func GetRandonFunctiolist() []string {
 		returnMap = append(returnMap, key)
 	}
 
+	for _, g := range RandomGeneratorMap {
+		returnMap = append(returnMap, g.Example)
+	}
+
+	sort.Strings(returnMap)
 	return returnMap
 }
 
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func RandomGeneratorList() []*RandomGenerator {
+	returnList := make([]*RandomGenerator, 0, len(RandomGeneratorMap))
+	for _, g := range RandomGeneratorMap {
+		returnList = append(returnList, g)
+	}
+	sort.Slice(returnList, func(i, j int) bool {
+		return returnList[i].Name < returnList[j].Name
+	})
+	return returnList
+}
+
+// -----------------------------------------------------------------
+// NAME, UUID or INT(1,1000)
+// -----------------------------------------------------------------
 func GenerateRandom(what string) (string, error) {
-	funcToUse, found := RandomFunctionMap[strings.TrimSpace(what)]
+	return GenerateRandomWith(randomFaker, what)
+}
+
+// -----------------------------------------------------------------
+// seeded fakers give the same values in the same order
+// -----------------------------------------------------------------
+func GenerateRandomWith(f *gofakeit.Faker, what string) (string, error) {
+	what = strings.TrimSpace(what)
+
+	name := strings.ToUpper(what)
+	argString := ""
+	hasArgs := false
+
+	matches := randomCallRegex.FindStringSubmatch(what)
+	if len(matches) > 0 {
+		name = strings.ToUpper(matches[1])
+		argString = matches[2]
+		hasArgs = true
+	}
+
+	generator, found := RandomGeneratorMap[name]
 	if found {
-		return funcToUse(), nil
+		args := make([]string, 0)
+		if generator.RawArgument {
+			if argString != "" {
+				args = append(args, argString)
+			}
+		} else if strings.TrimSpace(argString) != "" {
+			for _, a := range strings.Split(argString, ",") {
+				args = append(args, strings.TrimSpace(a))
+			}
+		}
+
+		if generator.Arguments != "" && len(args) == 0 {
+			return "", fmt.Errorf("RANDOM %s needs arguments: %s", name, generator.Example)
+		}
+		return generator.Generate(f, args)
+	}
+
+	funcToUse, found := RandomFunctionMap[name]
+	if found && !hasArgs {
+		return funcToUse(f), nil
 	}
 	return "", fmt.Errorf("RANDOM Identifier not found:%s", what)
 }
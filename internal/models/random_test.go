@@ -0,0 +1,139 @@
+package models
+
+import (
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v6"
+)
+
+func Test_GenerateRandomWith(t *testing.T) {
+	between := func(min, max float64) func(string) bool {
+		return func(value string) bool {
+			f, err := strconv.ParseFloat(value, 64)
+			return err == nil && f >= min && f <= max
+		}
+	}
+	matches := func(pattern string) func(string) bool {
+		return regexp.MustCompile(pattern).MatchString
+	}
+	dateBetween := func(layout, from, to string) func(string) bool {
+		fromDate, _ := time.Parse("2006-01-02", from)
+		toDate, _ := time.Parse("2006-01-02", to)
+		return func(value string) bool {
+			d, err := time.Parse(layout, value)
+			return err == nil && !d.Before(fromDate) && !d.After(toDate)
+		}
+	}
+
+	tests := []struct {
+		what  string
+		check func(string) bool
+	}{
+		{"INT(1,6)", between(1, 6)},
+		{" int( -5 , 5 ) ", between(-5, 5)},
+		{"INT(7,7)", matches(`^7$`)},
+		{"FLOAT(0,1)", matches(`^0\.\d{2}$|^1\.00$`)},
+		{"FLOAT(10,20,4)", matches(`^[12]\d\.\d{4}$`)},
+		{"FLOAT(2.5,2.5,1)", matches(`^2\.5$`)},
+		{"FLOAT(-1,1,0)", between(-1, 1)},
+		{"UUID", matches(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)},
+		{"DATE(2024-01-01,2024-01-31)", dateBetween("2006-01-02", "2024-01-01", "2024-01-31")},
+		{"DATE(2024-01-01,2024-01-01)", matches(`^2024-01-01$`)},
+		{"DATE(2024-01-01T00:00:00Z,2024-12-31T00:00:00Z,02/01/2006)", dateBetween("02/01/2006", "2024-01-01", "2024-12-31")},
+		// layouts can have commas
+		{"DATE(2024-01-01,2024-01-31,Mon, 02 Jan 2006)", dateBetween("Mon, 02 Jan 2006", "2024-01-01", "2024-01-31")},
+		{`REGEX([A-Z]{3}\d{4})`, matches(`^[A-Z]{3}\d{4}$`)},
+		// commas are part of the pattern
+		{`REGEX(ID-\d{2,3})`, matches(`^ID-\d{2,3}$`)},
+		{"ONEOF(NEW,PAID,SHIPPED)", matches(`^(NEW|PAID|SHIPPED)$`)},
+		{"oneof( a , b )", matches(`^(a|b)$`)},
+		{"ONEOF(ONLY)", matches(`^ONLY$`)},
+	}
+
+	f := gofakeit.New(7)
+	for _, test := range tests {
+		for i := 0; i < 20; i++ {
+			value, err := GenerateRandomWith(f, test.what)
+			if err != nil {
+				t.Errorf("%s: unexpected error %s", test.what, err.Error())
+				break
+			}
+			if !test.check(value) {
+				t.Errorf("%s: unexpected value %s", test.what, value)
+				break
+			}
+		}
+	}
+}
+
+func Test_GenerateRandomWith_Errors(t *testing.T) {
+	tests := []string{
+		"INT",
+		"INT()",
+		"INT(1)",
+		"INT(1,2,3)",
+		"INT(a,10)",
+		"INT(10,1)",
+		"INT(1.5,3)",
+		"FLOAT(1)",
+		"FLOAT(0,x)",
+		"FLOAT(5,1)",
+		"FLOAT(0,1,-1)",
+		"FLOAT(0,1,two)",
+		"FLOAT(0,1,2,3)",
+		"DATE(2024-01-01)",
+		"DATE(01/01/2024,2024-12-31)",
+		"DATE(2024-01-01,tomorrow)",
+		"DATE(2024-12-31,2024-01-01)",
+		"REGEX([A-Z)",
+		"ONEOF()",
+		"UNKNOWN(1,2)",
+		"NAME(1)",
+		"NOTAFUNCTION",
+	}
+
+	f := gofakeit.New(7)
+	for _, what := range tests {
+		if value, err := GenerateRandomWith(f, what); err == nil {
+			t.Errorf("%s: expected an error but got %s", what, value)
+		}
+	}
+}
+
+// the same seed gives the same values in the same order
+func Test_GenerateRandomWith_Seed(t *testing.T) {
+	generate := func(seed int64) []string {
+		f := gofakeit.New(seed)
+		values := make([]string, 0)
+		for _, what := range []string{"INT(1,1000000)", "FLOAT(0,1000,3)", "UUID", "DATE(2000-01-01,2030-12-31)", `REGEX([a-z]{12})`, "ONEOF(A,B,C,D,E,F,G,H)", "NAME"} {
+			for i := 0; i < 3; i++ {
+				value, err := GenerateRandomWith(f, what)
+				if err != nil {
+					t.Fatalf("%s: unexpected error %s", what, err.Error())
+				}
+				values = append(values, value)
+			}
+		}
+		return values
+	}
+
+	first, second, other := generate(42), generate(42), generate(43)
+	for i := range first {
+		if first[i] != second[i] {
+			t.Errorf("value %d: expected %s again but got %s", i, first[i], second[i])
+		}
+	}
+
+	same := 0
+	for i := range first {
+		if first[i] == other[i] {
+			same++
+		}
+	}
+	if same == len(first) {
+		t.Errorf("expected another seed to give other values but got %v", other)
+	}
+}
@@ -0,0 +1,145 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/onlysumitg/GoMockAPI/internal/validator"
+	"github.com/onlysumitg/GoMockAPI/utils/httputils"
+)
+
+// Record mode: calls are sent to the actual url and every new
+// status code + record key value is kept as a response of the endpoint
+const RecordedResponseName = "RECORDED"
+
+// upstream headers that are not part of a recorded response
+var recordSkippedHeaders = map[string]bool{
+	"Connection":          true,
+	"Keep-Alive":          true,
+	"Proxy-Authenticate":  true,
+	"Proxy-Authorization": true,
+	"Te":                  true,
+	"Trailer":             true,
+	"Transfer-Encoding":   true,
+	"Upgrade":             true,
+	"Content-Length":      true,
+	"Content-Encoding":    true,
+	"Date":                true,
+	"Set-Cookie":          true,
+}
+
+// -----------------------------------------------------------------
+// value of the record key in the request, blank without a key
+// -----------------------------------------------------------------
+func (e *EndPoint) RecordValue(apiCall *ApiCall) string {
+	if e.RecordKey == "" {
+		return ""
+	}
+	value, found := apiCall.RequestFlatMap[e.RecordKey]
+	if !found || value.Value == nil {
+		return ""
+	}
+	return strings.TrimSpace(fmt.Sprint(value.Value))
+}
+
+// -----------------------------------------------------------------
+// RECORDED or RECORDED <value>
+// -----------------------------------------------------------------
+func RecordedName(value string) string {
+	if value == "" {
+		return RecordedResponseName
+	}
+	// by rune, a byte cut can split a character
+	if runes := []rune(value); len(runes) > 40 {
+		value = string(runes[:40])
+	}
+	return strings.ToUpper(fmt.Sprintf("%s %s", RecordedResponseName, value))
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (e *EndPoint) RecordedResponse(httpCode int, name string) *EndPointResponse {
+	for _, r := range e.ResponseMap {
+		if r.HttpCode == httpCode && strings.EqualFold(r.Name, name) {
+			return r
+		}
+	}
+	return nil
+}
+
+// -----------------------------------------------------------------
+// text is valid UTF-8 without NUL bytes and, when the upstream
+// sends a content type, a text, JSON, XML or form media type
+// -----------------------------------------------------------------
+func isTextBody(mediaType string, body string) bool {
+	if !utf8.ValidString(body) || strings.ContainsRune(body, 0) {
+		return false
+	}
+	if mediaType == "" || strings.HasPrefix(mediaType, "text/") {
+		return true
+	}
+	for _, textual := range []string{"json", "xml", "javascript", "x-www-form-urlencoded", "graphql", "yaml"} {
+		if strings.Contains(mediaType, textual) {
+			return true
+		}
+	}
+	return false
+}
+
+// -----------------------------------------------------------------
+// JSON objects and XML are kept with params, other text as text and
+// binary bodies base64 encoded, with the upstream content type
+// -----------------------------------------------------------------
+func NewRecordedResponse(name string, result *httputils.HttpCallResult) *EndPointResponse {
+	header := make(map[string]string)
+	for k, v := range httputils.GetHeadersAsMap2(result.Header) {
+		if !recordSkippedHeaders[http.CanonicalHeaderKey(k)] {
+			header[k] = v
+		}
+	}
+	contentType := header["Content-Type"]
+	delete(header, "Content-Type")
+
+	headerJson, _ := json.Marshal(header)
+
+	response := &EndPointResponse{
+		Name:               name,
+		HttpCode:           result.StatusCode,
+		Response:           result.Body,
+		ResponseHeader:     string(headerJson),
+		ResponseHeaderType: "JSON",
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	mediaType = strings.ToLower(mediaType)
+	body := strings.TrimSpace(result.Body)
+
+	switch {
+	case !isTextBody(mediaType, result.Body):
+		response.ResponseType = ResponseTypeBase64
+		response.Response = base64.StdEncoding.EncodeToString([]byte(result.Body))
+	case strings.Contains(mediaType, "json") && strings.HasPrefix(body, "{") && validator.MustBeJSON(body):
+		response.ResponseType = "JSON"
+	case strings.Contains(mediaType, "xml") && validator.MustBeXML(body):
+		response.ResponseType = "XML"
+	case mediaType == "text/html":
+		response.ResponseType = ResponseTypeHtml
+	case mediaType == "text/csv":
+		response.ResponseType = ResponseTypeCsv
+	default:
+		response.ResponseType = ResponseTypeText
+	}
+
+	if response.ResponseType == ResponseTypeBase64 {
+		response.ContentType = contentType
+	} else if contentType != "" && contentType != ResponseContentType(response.ResponseType, "") {
+		response.ContentType = contentType
+	}
+	return response
+}
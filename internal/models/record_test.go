@@ -0,0 +1,99 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/onlysumitg/GoMockAPI/utils/httputils"
+)
+
+func Test_RecordedName(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{"", "RECORDED"},
+		{"42", "RECORDED 42"},
+		{"abc", "RECORDED ABC"},
+		{strings.Repeat("x", 45), "RECORDED " + strings.Repeat("X", 40)},
+		// 40 runes, not 40 bytes: no split character
+		{strings.Repeat("é", 45), "RECORDED " + strings.Repeat("É", 40)},
+	}
+
+	for _, test := range tests {
+		result := RecordedName(test.value)
+		if result != test.expected {
+			t.Errorf("%q: expected %q but got %q", test.value, test.expected, result)
+		}
+	}
+}
+
+func Test_NewRecordedResponse(t *testing.T) {
+	png := string([]byte{0x89, 'P', 'N', 'G', 0x0d, 0x0a, 0x1a, 0x0a, 0x00, 0xff})
+
+	tests := []struct {
+		name         string
+		contentType  string
+		body         string
+		responseType string
+		contentTypeX string // expected ContentType override
+	}{
+		{"json object", "application/json", `{"id": 42}`, "JSON", ""},
+		{"json array", "application/json", `[1, 2]`, ResponseTypeText, "application/json"},
+		{"xml", "application/xml", `<a><b>1</b></a>`, "XML", ""},
+		{"html", "text/html; charset=utf-8", `<p>hi</p>`, ResponseTypeHtml, ""},
+		{"csv", "text/csv", "a,b\n1,2", ResponseTypeCsv, "text/csv"},
+		{"text without type", "", "plain", ResponseTypeText, ""},
+		{"png", "image/png", png, ResponseTypeBase64, "image/png"},
+		{"binary without type", "", png, ResponseTypeBase64, ""},
+		{"text with binary type", "application/octet-stream", "abc", ResponseTypeBase64, "application/octet-stream"},
+	}
+
+	for _, test := range tests {
+		header := http.Header{}
+		if test.contentType != "" {
+			header.Set("Content-Type", test.contentType)
+		}
+		header.Set("X-Request-Id", "r1")
+		header.Set("Date", "Mon, 01 Jan 2024 00:00:00 GMT")
+		header.Set("Set-Cookie", "a=1")
+
+		response := NewRecordedResponse("RECORDED 1", &httputils.HttpCallResult{Header: header, StatusCode: 201, Body: test.body})
+
+		if response.ResponseType != test.responseType {
+			t.Errorf("%s: expected type %s but got %s", test.name, test.responseType, response.ResponseType)
+		}
+		if response.ContentType != test.contentTypeX {
+			t.Errorf("%s: expected content type %q but got %q", test.name, test.contentTypeX, response.ContentType)
+		}
+		if response.HttpCode != 201 || response.Name != "RECORDED 1" {
+			t.Errorf("%s: expected RECORDED 1 201 but got %s %d", test.name, response.Name, response.HttpCode)
+		}
+
+		if response.ResponseType == ResponseTypeBase64 {
+			content, err := base64.StdEncoding.DecodeString(response.Response)
+			if err != nil || string(content) != test.body {
+				t.Errorf("%s: body not kept byte for byte: %v", test.name, err)
+			}
+		} else if response.Response != test.body {
+			t.Errorf("%s: expected body %q but got %q", test.name, test.body, response.Response)
+		}
+
+		recordedHeader := make(map[string]string)
+		err := json.Unmarshal([]byte(response.ResponseHeader), &recordedHeader)
+		if err != nil {
+			t.Fatalf("%s: header is not JSON: %s", test.name, err.Error())
+		}
+		if recordedHeader["X-Request-Id"] != "r1" {
+			t.Errorf("%s: X-Request-Id not recorded: %v", test.name, recordedHeader)
+		}
+		for _, skipped := range []string{"Date", "Set-Cookie", "Content-Type"} {
+			if _, found := recordedHeader[skipped]; found {
+				t.Errorf("%s: %s should not be recorded", test.name, skipped)
+			}
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package models
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/onlysumitg/GoMockAPI/utils/xmlutils"
+)
+
+// request values added to the request flat map of every call
+const (
+	RequestVarMethod = "*REQUEST_METHOD"
+	RequestVarPath   = "*REQUEST_PATH"
+	RequestVarURL    = "*REQUEST_URL"
+	RequestVarHost   = "*REQUEST_HOST"
+	RequestVarScheme = "*REQUEST_SCHEME"
+	RequestVarQuery  = "*QUERY_RAW"
+	RequestVarBody   = "*BODY_RAW"
+)
+
+var RequestVarKeys = []string{
+	RequestVarMethod,
+	RequestVarPath,
+	RequestVarURL,
+	RequestVarHost,
+	RequestVarScheme,
+	RequestVarQuery,
+	RequestVarBody,
+}
+
+// -----------------------------------------------------------------
+// https when the server has TLS or a proxy says so
+// -----------------------------------------------------------------
+func requestScheme(r *http.Request) string {
+	if r.TLS != nil {
+		return "https"
+	}
+
+	forwarded := strings.TrimSpace(strings.Split(r.Header.Get("X-Forwarded-Proto"), ",")[0])
+	if forwarded != "" {
+		return strings.ToLower(forwarded)
+	}
+	return "http"
+}
+
+// -----------------------------------------------------------------
+// the body is put back so it can be read again
+// -----------------------------------------------------------------
+func InjectRequestVars(r *http.Request, flatMap map[string]xmlutils.ValueDatatype) {
+	body := []byte{}
+	if r.Body != nil {
+		b, err := io.ReadAll(r.Body)
+		if err == nil {
+			body = b
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	scheme := requestScheme(r)
+
+	flatMap[RequestVarMethod] = xmlutils.ValueDatatype{Value: r.Method, DataType: "STRING"}
+	flatMap[RequestVarPath] = xmlutils.ValueDatatype{Value: r.URL.Path, DataType: "STRING"}
+	flatMap[RequestVarURL] = xmlutils.ValueDatatype{Value: fmt.Sprintf("%s://%s%s", scheme, r.Host, r.URL.RequestURI()), DataType: "STRING"}
+	flatMap[RequestVarHost] = xmlutils.ValueDatatype{Value: r.Host, DataType: "STRING"}
+	flatMap[RequestVarScheme] = xmlutils.ValueDatatype{Value: scheme, DataType: "STRING"}
+	flatMap[RequestVarQuery] = xmlutils.ValueDatatype{Value: r.URL.RawQuery, DataType: "STRING"}
+	flatMap[RequestVarBody] = xmlutils.ValueDatatype{Value: string(body), DataType: "STRING"}
+
+	// *COOKIE_<name> for every request cookie
+	for _, c := range r.Cookies() {
+		flatMap[CookieParamPrefix+c.Name] = xmlutils.ValueDatatype{Value: c.Value, DataType: "STRING"}
+	}
+}
+
+// -----------------------------------------------------------------
+// cookies named in the Cookie header of the sample request
+// -----------------------------------------------------------------
+func sampleRequestCookies(headerFlatMap map[string]xmlutils.ValueDatatype) map[string]string {
+	cookies := make(map[string]string)
+	for k, v := range headerFlatMap {
+		if !strings.EqualFold(k, "Cookie") {
+			continue
+		}
+		header := http.Header{}
+		header.Add("Cookie", fmt.Sprint(v.Value))
+		for _, c := range (&http.Request{Header: header}).Cookies() {
+			cookies[c.Name] = c.Value
+		}
+	}
+	return cookies
+}
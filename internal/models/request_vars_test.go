@@ -0,0 +1,106 @@
+package models
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/onlysumitg/GoMockAPI/utils/xmlutils"
+)
+
+func Test_InjectRequestVars(t *testing.T) {
+	r := httptest.NewRequest("PUT", "http://mock.local:4081/api/v1/orders/7?expand=items&b=%20x", strings.NewReader(`{"id": 7}`))
+	r.Header.Set("Cookie", "session=abc; theme=dark")
+
+	flatMap := map[string]xmlutils.ValueDatatype{"id": {Value: 7.0, DataType: "FLOAT64"}}
+	InjectRequestVars(r, flatMap)
+
+	expected := map[string]any{
+		RequestVarMethod:              "PUT",
+		RequestVarPath:                "/api/v1/orders/7",
+		RequestVarURL:                 "http://mock.local:4081/api/v1/orders/7?expand=items&b=%20x",
+		RequestVarHost:                "mock.local:4081",
+		RequestVarScheme:              "http",
+		RequestVarQuery:               "expand=items&b=%20x",
+		RequestVarBody:                `{"id": 7}`,
+		CookieParamPrefix + "session": "abc",
+		CookieParamPrefix + "theme":   "dark",
+		"id":                          7.0,
+	}
+	got := make(map[string]any)
+	for k, v := range flatMap {
+		got[k] = v.Value
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v but got %v", expected, got)
+	}
+
+	// the body can be read again
+	if body, _ := io.ReadAll(r.Body); string(body) != `{"id": 7}` {
+		t.Errorf("expected the body again but got %q", body)
+	}
+
+	// a missing cookie is not a request value
+	apiCall := &ApiCall{RequestFlatMap: flatMap}
+	if value, err := apiCall.ResolveValue("REQUEST[STRING]:*COOKIE_session"); err != nil || value != "abc" {
+		t.Errorf("expected abc but got %v %v", value, err)
+	}
+	if value, err := apiCall.ResolveValue("REQUEST[STRING]:*COOKIE_missing"); err == nil {
+		t.Errorf("expected an error for a missing cookie but got %v", value)
+	}
+}
+
+func Test_InjectRequestVars_Empty(t *testing.T) {
+	nilBody := httptest.NewRequest("GET", "http://mock.local/api/v1/orders", nil)
+	nilBody.Body = nil
+	forwarded := httptest.NewRequest("GET", "http://mock.local/api/v1/orders", nil)
+	forwarded.Header.Set("X-Forwarded-Proto", "HTTPS, http")
+
+	tests := []struct {
+		name    string
+		request *http.Request
+		scheme  string
+		url     string
+	}{
+		{"no body", httptest.NewRequest("GET", "http://mock.local/api/v1/orders", nil), "http", "http://mock.local/api/v1/orders"},
+		{"nil body", nilBody, "http", "http://mock.local/api/v1/orders"},
+		{"empty body", httptest.NewRequest("POST", "https://mock.local/api/v1/orders", strings.NewReader("")), "https", "https://mock.local/api/v1/orders"},
+		{"behind a proxy", forwarded, "https", "https://mock.local/api/v1/orders"},
+	}
+
+	for _, test := range tests {
+		flatMap := make(map[string]xmlutils.ValueDatatype)
+		InjectRequestVars(test.request, flatMap)
+
+		if v := flatMap[RequestVarBody]; v.Value != "" || v.DataType != "STRING" {
+			t.Errorf("%s: expected an empty body but got %+v", test.name, v)
+		}
+		if v := flatMap[RequestVarQuery]; v.Value != "" {
+			t.Errorf("%s: expected an empty query but got %+v", test.name, v)
+		}
+		if flatMap[RequestVarScheme].Value != test.scheme || flatMap[RequestVarURL].Value != test.url {
+			t.Errorf("%s: expected %s %s but got %v %v", test.name, test.scheme, test.url, flatMap[RequestVarScheme].Value, flatMap[RequestVarURL].Value)
+		}
+		for k := range flatMap {
+			if strings.HasPrefix(k, CookieParamPrefix) {
+				t.Errorf("%s: expected no cookies but got %s", test.name, k)
+			}
+		}
+	}
+}
+
+func Test_SampleRequestCookies(t *testing.T) {
+	cookies := sampleRequestCookies(map[string]xmlutils.ValueDatatype{
+		"cookie":       {Value: "session=abc; theme=dark", DataType: "STRING"},
+		"Content-Type": {Value: "application/json", DataType: "STRING"},
+	})
+	if !reflect.DeepEqual(cookies, map[string]string{"session": "abc", "theme": "dark"}) {
+		t.Errorf("expected session and theme but got %v", cookies)
+	}
+	if cookies := sampleRequestCookies(nil); len(cookies) != 0 {
+		t.Errorf("expected no cookies but got %v", cookies)
+	}
+}
@@ -0,0 +1,395 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	bolt "go.etcd.io/bbolt"
+)
+
+const DefaultResourceIDField = "id"
+
+var ErrDuplicateResource = errors.New("models: resource already exists")
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+// ResourceModel keeps the records of resource endpoints.
+// Each resource gets its own nested bucket: resources -> COLLECTIONID_RESOURCENAME -> id
+type ResourceModel struct {
+	DB *bolt.DB
+}
+
+func (m *ResourceModel) getTableName() []byte {
+	return []byte("resources")
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func ResourceKey(e *EndPoint) string {
+	return strings.ToUpper(fmt.Sprintf("%s_%s", e.CollectionID, e.GetResourceName()))
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func resourceIDString(id any) string {
+	switch v := id.(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.TrimSpace(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return strings.TrimSpace(fmt.Sprint(v))
+	}
+}
+
+// -----------------------------------------------------------------
+// sample can be an array of objects, a single object or
+// an object wrapping an array: {"data": [...]}
+// -----------------------------------------------------------------
+func ResourceSeedItems(sample string, idField string) []map[string]any {
+	items := make([]map[string]any, 0)
+
+	var parsed any
+	err := json.Unmarshal([]byte(sample), &parsed)
+	if err != nil {
+		return items
+	}
+
+	var list []any
+	switch v := parsed.(type) {
+	case []any:
+		list = v
+	case map[string]any:
+		if _, found := v[idField]; found {
+			list = []any{v}
+		} else {
+			keys := make([]string, 0, len(v))
+			for k := range v {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				if l, ok := v[k].([]any); ok {
+					list = l
+					break
+				}
+			}
+		}
+	}
+
+	for _, i := range list {
+		if item, ok := i.(map[string]any); ok {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+// -----------------------------------------------------------------
+// numeric ids continue from the highest one, anything else gets a uuid
+// -----------------------------------------------------------------
+func nextResourceID(bucket *bolt.Bucket) any {
+	maxID := int64(0)
+	numeric := true
+
+	c := bucket.Cursor()
+	for k, _ := c.First(); k != nil; k, _ = c.Next() {
+		i, err := strconv.ParseInt(string(k), 10, 64)
+		if err != nil {
+			numeric = false
+			break
+		}
+		if i > maxID {
+			maxID = i
+		}
+	}
+
+	if numeric {
+		return maxID + 1
+	}
+	return uuid.NewString()
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func putResourceItem(bucket *bolt.Bucket, idField string, item map[string]any) (string, error) {
+	id := resourceIDString(item[idField])
+	if id == "" {
+		newID := nextResourceID(bucket)
+		item[idField] = newID
+		id = resourceIDString(newID)
+	}
+
+	buf, err := json.Marshal(item)
+	if err != nil {
+		return id, err
+	}
+
+	return id, bucket.Put([]byte(id), buf)
+}
+
+// -----------------------------------------------------------------
+// seeds the resource from the sample response on first use
+// -----------------------------------------------------------------
+func (m *ResourceModel) getBucket(tx *bolt.Tx, e *EndPoint) (*bolt.Bucket, error) {
+	table, err := tx.CreateBucketIfNotExists(m.getTableName())
+	if err != nil {
+		return nil, err
+	}
+
+	key := []byte(ResourceKey(e))
+	bucket := table.Bucket(key)
+	if bucket != nil {
+		return bucket, nil
+	}
+
+	bucket, err = table.CreateBucket(key)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range ResourceSeedItems(e.GetDefaultResponseID().Response, e.GetResourceIDField()) {
+		_, err = putResourceItem(bucket, e.GetResourceIDField(), item)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return bucket, nil
+}
+
+// -----------------------------------------------------------------
+// reads share the db, only the first use that seeds the resource
+// takes the writer lock
+// -----------------------------------------------------------------
+func (m *ResourceModel) read(e *EndPoint, readBucket func(bucket *bolt.Bucket) error) error {
+	seeded := false
+
+	err := m.DB.View(func(tx *bolt.Tx) error {
+		table := tx.Bucket(m.getTableName())
+		if table == nil {
+			return nil
+		}
+		bucket := table.Bucket([]byte(ResourceKey(e)))
+		if bucket == nil {
+			return nil
+		}
+		seeded = true
+		return readBucket(bucket)
+	})
+	if err != nil || seeded {
+		return err
+	}
+
+	return m.DB.Update(func(tx *bolt.Tx) error {
+		bucket, err := m.getBucket(tx, e)
+		if err != nil {
+			return err
+		}
+		return readBucket(bucket)
+	})
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (m *ResourceModel) List(e *EndPoint) ([]map[string]any, error) {
+	items := make([]map[string]any, 0)
+
+	err := m.read(e, func(bucket *bolt.Bucket) error {
+		return bucket.ForEach(func(k, v []byte) error {
+			item := make(map[string]any)
+			if err := json.Unmarshal(v, &item); err == nil {
+				items = append(items, item)
+			}
+			return nil
+		})
+	})
+
+	// numeric ids in numeric order
+	sort.SliceStable(items, func(i, j int) bool {
+		a := resourceIDString(items[i][e.GetResourceIDField()])
+		b := resourceIDString(items[j][e.GetResourceIDField()])
+		ai, errA := strconv.ParseInt(a, 10, 64)
+		bi, errB := strconv.ParseInt(b, 10, 64)
+		if errA == nil && errB == nil {
+			return ai < bi
+		}
+		return a < b
+	})
+
+	return items, err
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (m *ResourceModel) Get(e *EndPoint, id string) (map[string]any, error) {
+	var itemJSON []byte
+
+	err := m.read(e, func(bucket *bolt.Bucket) error {
+		itemJSON = bucket.Get([]byte(id))
+		if itemJSON == nil {
+			return ErrNotFound
+		}
+		itemJSON = append([]byte{}, itemJSON...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	item := make(map[string]any)
+	err = json.Unmarshal(itemJSON, &item)
+	return item, err
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (m *ResourceModel) Insert(e *EndPoint, item map[string]any) (string, error) {
+	id := ""
+
+	err := m.DB.Update(func(tx *bolt.Tx) error {
+		bucket, err := m.getBucket(tx, e)
+		if err != nil {
+			return err
+		}
+
+		if existingID := resourceIDString(item[e.GetResourceIDField()]); existingID != "" {
+			if bucket.Get([]byte(existingID)) != nil {
+				return ErrDuplicateResource
+			}
+		}
+
+		id, err = putResourceItem(bucket, e.GetResourceIDField(), item)
+		return err
+	})
+
+	return id, err
+}
+
+// -----------------------------------------------------------------
+// merge = true updates only the given fields (PATCH)
+// -----------------------------------------------------------------
+func (m *ResourceModel) Update(e *EndPoint, id string, item map[string]any, merge bool) (map[string]any, error) {
+	var finalItem map[string]any
+
+	err := m.DB.Update(func(tx *bolt.Tx) error {
+		bucket, err := m.getBucket(tx, e)
+		if err != nil {
+			return err
+		}
+
+		existingJSON := bucket.Get([]byte(id))
+		if existingJSON == nil {
+			return ErrNotFound
+		}
+
+		finalItem = item
+		if merge {
+			finalItem = make(map[string]any)
+			err = json.Unmarshal(existingJSON, &finalItem)
+			if err != nil {
+				return err
+			}
+			for k, v := range item {
+				finalItem[k] = v
+			}
+		}
+
+		// id in the path wins over the body
+		finalItem[e.GetResourceIDField()] = storedResourceID(existingJSON, e.GetResourceIDField(), id)
+
+		_, err = putResourceItem(bucket, e.GetResourceIDField(), finalItem)
+		return err
+	})
+
+	return finalItem, err
+}
+
+// -----------------------------------------------------------------
+// keep the stored id type: 1 stays a number, "1" stays a string
+// -----------------------------------------------------------------
+func storedResourceID(itemJSON []byte, idField string, id string) any {
+	item := make(map[string]any)
+	if err := json.Unmarshal(itemJSON, &item); err == nil {
+		if v, found := item[idField]; found {
+			return v
+		}
+	}
+	return id
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (m *ResourceModel) Delete(e *EndPoint, id string) error {
+	return m.DB.Update(func(tx *bolt.Tx) error {
+		bucket, err := m.getBucket(tx, e)
+		if err != nil {
+			return err
+		}
+
+		if bucket.Get([]byte(id)) == nil {
+			return ErrNotFound
+		}
+
+		return bucket.Delete([]byte(id))
+	})
+}
+
+// -----------------------------------------------------------------
+// drops the stored records, next call seeds again from the sample
+// -----------------------------------------------------------------
+func (m *ResourceModel) Reset(e *EndPoint) error {
+	return m.DB.Update(func(tx *bolt.Tx) error {
+		table, err := tx.CreateBucketIfNotExists(m.getTableName())
+		if err != nil {
+			return err
+		}
+
+		key := []byte(ResourceKey(e))
+		if table.Bucket(key) == nil {
+			return nil
+		}
+		return table.DeleteBucket(key)
+	})
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (m *ResourceModel) ClearCollectionData(collectionID string) {
+	prefix := []byte(strings.ToUpper(collectionID + "_"))
+
+	_ = m.DB.Update(func(tx *bolt.Tx) error {
+		table := tx.Bucket(m.getTableName())
+		if table == nil {
+			return nil
+		}
+
+		keys := make([][]byte, 0)
+		c := table.Cursor()
+		for k, _ := c.Seek(prefix); k != nil && strings.HasPrefix(string(k), string(prefix)); k, _ = c.Next() {
+			keys = append(keys, append([]byte{}, k...))
+		}
+
+		for _, k := range keys {
+			table.DeleteBucket(k)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+func Test_ResourceModel_Read(t *testing.T) {
+	db, err := bolt.Open(filepath.Join(t.TempDir(), "data.db"), 0600, nil)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	defer db.Close()
+
+	m := &ResourceModel{DB: db}
+	e := &EndPoint{
+		CollectionID: "C1",
+		Name:         "USERS",
+		ResourceMode: true,
+		ResponseMap: []*EndPointResponse{
+			{ID: "1", Name: "DEFAULT", HttpCode: 200, Response: `{"data": [{"id": 2, "name": "b"}, {"id": 1, "name": "a"}]}`},
+		},
+	}
+
+	// the first read seeds the resource
+	items, err := m.List(e)
+	if err != nil || len(items) != 2 || items[0]["name"] != "a" {
+		t.Fatalf("expected the seeded items in id order but got %v %v", items, err)
+	}
+
+	// later reads are read transactions
+	readsBefore := db.Stats().TxN
+	item, err := m.Get(e, "2")
+	if err != nil || item["name"] != "b" {
+		t.Errorf("expected item 2 but got %v %v", item, err)
+	}
+	if _, err := m.List(e); err != nil {
+		t.Errorf("unexpected error %s", err.Error())
+	}
+	if reads := db.Stats().TxN - readsBefore; reads != 2 {
+		t.Errorf("expected 2 read transactions but got %d", reads)
+	}
+
+	if _, err := m.Get(e, "9"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound but got %v", err)
+	}
+
+	// a resource read first by id is seeded too
+	other := *e
+	other.Name = "ORDERS"
+	if _, err := m.Get(&other, "1"); err != nil {
+		t.Errorf("expected the seeded item 1 but got %v", err)
+	}
+}
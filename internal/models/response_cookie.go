@@ -0,0 +1,204 @@
+package models
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cookie values are response params, so condition groups can assign them
+const CookieParamPrefix = "*COOKIE_"
+
+var SameSiteList = []string{"", "Lax", "Strict", "None"}
+
+type ResponseCookie struct {
+	Name   string `json:"name" db:"name" form:"name"`
+	Value  string `json:"value" db:"value" form:"value"`
+	Path   string `json:"path" db:"path" form:"path"`
+	Domain string `json:"domain" db:"domain" form:"domain"`
+
+	MaxAge  int    `json:"maxage" db:"maxage" form:"maxage"`    // seconds, 0: not set, -1: delete now
+	Expires string `json:"expires" db:"expires" form:"expires"` // date or a value like *NOW(+1d)
+
+	HttpOnly bool   `json:"httponly" db:"httponly" form:"httponly"`
+	Secure   bool   `json:"secure" db:"secure" form:"secure"`
+	SameSite string `json:"samesite" db:"samesite" form:"samesite"`
+}
+
+// Link[0], Link[1] ==> two Link headers
+var headerIndexRegex = regexp.MustCompile(`^(.+)\[(\d+)\]$`)
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func splitHeaderKey(key string) (string, int) {
+	matches := headerIndexRegex.FindStringSubmatch(key)
+	if len(matches) == 0 {
+		return key, 0
+	}
+	i, _ := strconv.Atoi(matches[2])
+	return matches[1], i
+}
+
+// -----------------------------------------------------------------
+// flat header keys to http headers, indexed keys in order
+// -----------------------------------------------------------------
+func buildMultiValueHeader(header http.Header, flatHeader map[string]string) {
+	keys := make([]string, 0, len(flatHeader))
+	for k := range flatHeader {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		nameI, indexI := splitHeaderKey(keys[i])
+		nameJ, indexJ := splitHeaderKey(keys[j])
+		if nameI != nameJ {
+			return nameI < nameJ
+		}
+		return indexI < indexJ
+	})
+
+	for _, k := range keys {
+		name, _ := splitHeaderKey(k)
+		header[name] = append(header[name], flatHeader[k])
+	}
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (c *ResponseCookie) Validate() error {
+	err := (&http.Cookie{Name: c.Name, Value: c.Value, Path: c.Path, Domain: c.Domain}).Valid()
+	if err != nil {
+		return fmt.Errorf("cookie %s: %s", c.Name, err.Error())
+	}
+
+	found := false
+	for _, s := range SameSiteList {
+		if strings.EqualFold(s, c.SameSite) {
+			found = true
+		}
+	}
+	if !found {
+		return fmt.Errorf("SameSite of %s must be Lax, Strict or None", c.Name)
+	}
+
+	if strings.EqualFold(c.SameSite, "None") && !c.Secure {
+		return fmt.Errorf("SameSite=None of %s needs Secure", c.Name)
+	}
+	return nil
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func parseCookieExpires(value string) (time.Time, error) {
+	for _, layout := range []string{http.TimeFormat, time.RFC3339, time.RFC1123, time.RFC1123Z, "2006-01-02"} {
+		t, err := time.Parse(layout, value)
+		if err == nil {
+			return t, nil
+		}
+	}
+
+	unix, err := strconv.ParseInt(value, 10, 64)
+	if err == nil {
+		return time.Unix(unix, 0), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid cookie expiry %s", value)
+}
+
+// -----------------------------------------------------------------
+// value is the resolved *COOKIE_ param
+// -----------------------------------------------------------------
+func (a *ApiCall) httpCookie(c *ResponseCookie, value string) *http.Cookie {
+	cookie := &http.Cookie{
+		Name:     c.Name,
+		Value:    value,
+		Path:     c.Path,
+		Domain:   c.Domain,
+		MaxAge:   c.MaxAge,
+		HttpOnly: c.HttpOnly,
+		Secure:   c.Secure,
+	}
+
+	switch strings.ToLower(c.SameSite) {
+	case "lax":
+		cookie.SameSite = http.SameSiteLaxMode
+	case "strict":
+		cookie.SameSite = http.SameSiteStrictMode
+	case "none":
+		cookie.SameSite = http.SameSiteNoneMode
+	}
+
+	if strings.TrimSpace(c.Expires) != "" {
+		expires, err := a.ResolveValue(strings.TrimSpace(c.Expires))
+		if err == nil {
+			var t time.Time
+			t, err = parseCookieExpires(fmt.Sprint(expires))
+			cookie.Expires = t
+		}
+		if err != nil {
+			a.LogError(fmt.Sprintf("Cookie %s expiry skipped: %s", c.Name, err.Error()))
+		}
+	}
+
+	return cookie
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (a *ApiCall) addCookies(header http.Header) {
+	if a.CurrentEndPoint == nil || a.UsingAcutalUrlResponse {
+		return
+	}
+
+	response := a.CurrentEndPoint.GetResponseByID(a.ResponseID)
+	for _, c := range response.Cookies {
+		value, found := a.FinalCookies[c.Name]
+		if !found {
+			value = c.Value
+		}
+
+		cookie := a.httpCookie(c, value).String()
+		if cookie == "" {
+			a.LogError(fmt.Sprintf("Cookie %s skipped: invalid name or value", c.Name))
+			continue
+		}
+		header.Add("Set-Cookie", cookie)
+	}
+}
+
+// -----------------------------------------------------------------
+// existing cookies and a blank row for a new one
+// -----------------------------------------------------------------
+func (s *EndPointResponse) CookieRows() []*ResponseCookie {
+	rows := make([]*ResponseCookie, 0, len(s.Cookies)+1)
+	rows = append(rows, s.Cookies...)
+	rows = append(rows, &ResponseCookie{Path: "/"})
+	return rows
+}
+
+// -----------------------------------------------------------------
+// blank rows from the form are dropped
+// -----------------------------------------------------------------
+func (s *EndPointResponse) CleanCookies() {
+	cookies := make([]*ResponseCookie, 0, len(s.Cookies))
+	for _, c := range s.Cookies {
+		if c == nil {
+			continue
+		}
+		c.Name = strings.TrimSpace(c.Name)
+		if c.Name == "" {
+			continue
+		}
+		c.Path = strings.TrimSpace(c.Path)
+		c.Domain = strings.TrimSpace(c.Domain)
+		c.Expires = strings.TrimSpace(c.Expires)
+		cookies = append(cookies, c)
+	}
+	s.Cookies = cookies
+}
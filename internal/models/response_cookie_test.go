@@ -0,0 +1,105 @@
+package models
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_BuildMultiValueHeader(t *testing.T) {
+	header := make(http.Header)
+	buildMultiValueHeader(header, map[string]string{
+		"Link[10]":     "<c>; rel=last",
+		"Link[2]":      "<b>; rel=next",
+		"Link[0]":      "<a>; rel=prev",
+		"X-Single":     "one",
+		"Vary":         "Accept",
+		"Vary[1]":      "Origin",
+		"X-Count[1]]":  "odd",
+		"Cache[Extra]": "kept",
+	})
+
+	expected := http.Header{
+		"Link":         {"<a>; rel=prev", "<b>; rel=next", "<c>; rel=last"},
+		"X-Single":     {"one"},
+		"Vary":         {"Accept", "Origin"},
+		"X-Count[1]]":  {"odd"},
+		"Cache[Extra]": {"kept"},
+	}
+	if !reflect.DeepEqual(header, expected) {
+		t.Errorf("expected %v but got %v", expected, header)
+	}
+
+	// values already in the header are kept
+	header = http.Header{"Link": {"<z>; rel=self"}}
+	buildMultiValueHeader(header, map[string]string{"Link[1]": "<b>", "Link[0]": "<a>"})
+	if !reflect.DeepEqual(header["Link"], []string{"<z>; rel=self", "<a>", "<b>"}) {
+		t.Errorf("expected the new values after the old one but got %v", header["Link"])
+	}
+}
+
+func Test_ApiCall_AddCookies(t *testing.T) {
+	Clock.Set(time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC), true)
+	defer Clock.Reset()
+
+	response := &EndPointResponse{ID: "r1", Cookies: []*ResponseCookie{
+		{Name: "session", Value: "abc", Path: "/", HttpOnly: true, Secure: true, SameSite: "Strict"},
+		{Name: "theme", Value: "dark", Path: "/app", Domain: "example.com", MaxAge: 3600, SameSite: "lax"},
+		{Name: "old", Value: "x", MaxAge: -1},
+		{Name: "cross", Value: "1", Secure: true, SameSite: "None"},
+		{Name: "dated", Value: "1", Expires: "2024-12-31"},
+		{Name: "later", Value: "1", Expires: "*NOW(+1d,RFC3339)"},
+		{Name: "badDate", Value: "1", Expires: "soon"},
+		{Name: "bad name", Value: "1"},
+	}}
+	apiCall := &ApiCall{
+		CurrentEndPoint:     &EndPoint{ResponseMap: []*EndPointResponse{response}},
+		ResponseID:          "r1",
+		FinalCookies:        map[string]string{"session": "assigned"},
+		FinalResponseHeader: map[string]string{"Set-Cookie": "from=header"},
+	}
+
+	header := apiCall.GetHttpHeader()
+	expected := []string{
+		"from=header",
+		"session=assigned; Path=/; HttpOnly; Secure; SameSite=Strict",
+		"theme=dark; Path=/app; Domain=example.com; Max-Age=3600; SameSite=Lax",
+		"old=x; Max-Age=0",
+		"cross=1; Secure; SameSite=None",
+		"dated=1; Expires=Tue, 31 Dec 2024 00:00:00 GMT",
+		"later=1; Expires=Mon, 11 Mar 2024 12:00:00 GMT",
+		"badDate=1",
+	}
+	if !reflect.DeepEqual(header["Set-Cookie"], expected) {
+		t.Errorf("expected %q but got %q", expected, header["Set-Cookie"])
+	}
+
+	// responses of the actual url do not get the mock cookies
+	apiCall.UsingAcutalUrlResponse = true
+	if header := apiCall.GetHttpHeader(); !reflect.DeepEqual(header["Set-Cookie"], []string{"from=header"}) {
+		t.Errorf("expected only the header cookie but got %q", header["Set-Cookie"])
+	}
+}
+
+func Test_ResponseCookie_Validate(t *testing.T) {
+	tests := []struct {
+		cookie ResponseCookie
+		valid  bool
+	}{
+		{ResponseCookie{Name: "a", Value: "1", Path: "/"}, true},
+		{ResponseCookie{Name: "a", SameSite: "strict"}, true},
+		{ResponseCookie{Name: "a", SameSite: "None", Secure: true}, true},
+		{ResponseCookie{Name: "a", SameSite: "None"}, false},
+		{ResponseCookie{Name: "a", SameSite: "Sometimes"}, false},
+		{ResponseCookie{Name: "a b"}, false},
+		{ResponseCookie{Name: "a", Value: "x;y"}, false},
+		{ResponseCookie{Name: "a", Domain: "exa mple.com"}, false},
+	}
+
+	for _, test := range tests {
+		if err := test.cookie.Validate(); (err == nil) != test.valid {
+			t.Errorf("%+v: expected valid %t but got %v", test.cookie, test.valid, err)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// failures a response can simulate instead of a normal reply
+const (
+	FaultNone      = ""
+	FaultClose     = "CLOSE"      // close the connection without a response
+	FaultReset     = "RESET"      // send half the body, then reset the connection
+	FaultTruncate  = "TRUNCATE"   // send half the body with a matching Content-Length
+	FaultMalformed = "MALFORMED"  // send a body that does not parse
+	FaultBadLength = "BAD_LENGTH" // Content-Length larger than the body
+	FaultHang      = "HANG"       // never answer, until the client gives up
+)
+
+// response param to set the fault from condition groups
+const FaultParamKey = "*FAULT"
+
+var FaultList = []string{FaultNone, FaultClose, FaultReset, FaultTruncate, FaultMalformed, FaultBadLength, FaultHang}
+
+// a hanging call is released after this, so connections do not pile up
+var MaxFaultHang = 10 * time.Minute
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func IsValidFault(fault string) bool {
+	for _, f := range FaultList {
+		if strings.EqualFold(f, strings.TrimSpace(fault)) {
+			return true
+		}
+	}
+	return false
+}
+
+// -----------------------------------------------------------------
+// *FAULT param first, then the fault of the selected response
+// -----------------------------------------------------------------
+func (a *ApiCall) GetFault() string {
+	if a.UsingAcutalUrlResponse || a.CurrentEndPoint == nil {
+		return FaultNone
+	}
+
+	fault := a.CurrentEndPoint.GetResponseByID(a.ResponseID).Fault
+
+	value, found := a.AdditionalResponseValues[fmt.Sprintf("%s_%s", a.ResponseID, FaultParamKey)]
+	if found {
+		fault = fmt.Sprint(value)
+	}
+
+	fault = strings.ToUpper(strings.TrimSpace(fault))
+	if !IsValidFault(fault) {
+		a.LogError(fmt.Sprintf("Unknown fault %s ignored", fault))
+		return FaultNone
+	}
+	return fault
+}
+
+// -----------------------------------------------------------------
+// cut before the last closing bracket and leave a string open
+// -----------------------------------------------------------------
+func MalformedBody(body string) string {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return "{\""
+	}
+
+	i := strings.LastIndexAny(body, "}]>")
+	if i > 0 {
+		body = body[:i]
+	}
+	return body + ",\"mal"
+}
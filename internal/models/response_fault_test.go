@@ -0,0 +1,94 @@
+package models
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+)
+
+func Test_MalformedBody(t *testing.T) {
+	tests := []struct {
+		body     string
+		expected string
+	}{
+		{"", `{"`},
+		{`{"id": 1}`, `{"id": 1,"mal`},
+		{`[1, 2, 3]`, `[1, 2, 3,"mal`},
+		{`<a><b>1</b></a>`, `<a><b>1</b></a,"mal`},
+		{"  {\"id\": 1}\n", `{"id": 1,"mal`},
+		{`plain`, `plain,"mal`},
+	}
+
+	for _, test := range tests {
+		result := MalformedBody(test.body)
+		if result != test.expected {
+			t.Errorf("%q: expected %q but got %q", test.body, test.expected, result)
+		}
+	}
+
+	// the point of the fault: it must not parse
+	var v any
+	for _, body := range []string{`{"id": 1}`, `[{"a": [1]}]`, `{}`} {
+		if json.Unmarshal([]byte(MalformedBody(body)), &v) == nil {
+			t.Errorf("%q: malformed body parses as JSON", body)
+		}
+	}
+	var element struct {
+		B string `xml:"b"`
+	}
+	if xml.Unmarshal([]byte(`<a><b>1</b></a>`), &element) != nil {
+		t.Fatalf("sample XML does not parse")
+	}
+	if xml.Unmarshal([]byte(MalformedBody(`<a><b>1</b></a>`)), &element) == nil {
+		t.Errorf("malformed body parses as XML")
+	}
+}
+
+func Test_GetFault(t *testing.T) {
+	endPoint := &EndPoint{
+		ResponseMap: []*EndPointResponse{
+			{ID: "R1", HttpCode: 200},
+			{ID: "R2", HttpCode: 500, Fault: "reset"},
+			{ID: "R3", HttpCode: 502, Fault: "NOT_A_FAULT"},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		responseID string
+		param      any // *FAULT of the response, nil when not set
+		actualURL  bool
+		expected   string
+	}{
+		{"no fault", "R1", nil, false, FaultNone},
+		{"response fault", "R2", nil, false, FaultReset},
+		{"param overrides", "R2", "hang", false, FaultHang},
+		{"param sets", "R1", " truncate ", false, FaultTruncate},
+		{"param clears", "R2", "", false, FaultNone},
+		{"unknown fault", "R3", nil, false, FaultNone},
+		{"unknown param", "R1", "EXPLODE", false, FaultNone},
+		{"actual url response", "R2", nil, true, FaultNone},
+		{"unknown response", "R9", nil, false, FaultNone},
+	}
+
+	for _, test := range tests {
+		apiCall := &ApiCall{
+			CurrentEndPoint:          endPoint,
+			ResponseID:               test.responseID,
+			UsingAcutalUrlResponse:   test.actualURL,
+			AdditionalResponseValues: make(map[string]any),
+		}
+		if test.param != nil {
+			apiCall.AdditionalResponseValues[test.responseID+"_"+FaultParamKey] = test.param
+		}
+
+		result := apiCall.GetFault()
+		if result != test.expected {
+			t.Errorf("%s: expected %q but got %q", test.name, test.expected, result)
+		}
+	}
+
+	if (&ApiCall{}).GetFault() != FaultNone {
+		t.Errorf("call without endpoint: expected no fault")
+	}
+}
@@ -0,0 +1,188 @@
+package models
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// binary response types, sent as they are
+const (
+	ResponseTypeFile   = "FILE"   // uploaded file kept in ResponseFilesDir
+	ResponseTypeBase64 = "BASE64" // Response holds base64 content
+)
+
+// uploaded response files live next to the db directory, not in bolt
+var ResponseFilesDir = "files"
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func IsBinaryResponseType(responseType string) bool {
+	return strings.EqualFold(responseType, ResponseTypeFile) || strings.EqualFold(responseType, ResponseTypeBase64)
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (s *EndPointResponse) IsBinary() bool {
+	return IsBinaryResponseType(s.ResponseType)
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (s *EndPointResponse) FilePath() string {
+	return filepath.Join(ResponseFilesDir, s.StoredFile)
+}
+
+// -----------------------------------------------------------------
+// custom value, then by file name, then octet-stream
+// -----------------------------------------------------------------
+func (s *EndPointResponse) GetContentType() string {
+	if strings.TrimSpace(s.ContentType) != "" {
+		return strings.TrimSpace(s.ContentType)
+	}
+
+	contentType := mime.TypeByExtension(filepath.Ext(s.FileName))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	return contentType
+}
+
+// -----------------------------------------------------------------
+// inline or attachment with the file name
+// -----------------------------------------------------------------
+func (s *EndPointResponse) GetContentDisposition() string {
+	disposition := strings.ToLower(strings.TrimSpace(s.ContentDisposition))
+	if disposition == "" {
+		return ""
+	}
+
+	if strings.TrimSpace(s.FileName) == "" {
+		return disposition
+	}
+	return mime.FormatMediaType(disposition, map[string]string{"filename": s.FileName})
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func ValidateBase64Response(content string) error {
+	_, err := base64.StdEncoding.DecodeString(strings.TrimSpace(content))
+	return err
+}
+
+// -----------------------------------------------------------------
+// content to serve, the caller closes it
+// -----------------------------------------------------------------
+func (s *EndPointResponse) OpenContent() (io.ReadSeekCloser, time.Time, error) {
+	switch strings.ToUpper(s.ResponseType) {
+	case ResponseTypeBase64:
+		content, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s.Response))
+		if err != nil {
+			return nil, time.Time{}, fmt.Errorf("invalid base64 content: %s", err.Error())
+		}
+		return nopReadSeekCloser{bytes.NewReader(content)}, time.Time{}, nil
+
+	case ResponseTypeFile:
+		if s.StoredFile == "" {
+			return nil, time.Time{}, errors.New("no file uploaded for the response")
+		}
+		file, err := os.Open(s.FilePath())
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		stat, err := file.Stat()
+		if err != nil {
+			file.Close()
+			return nil, time.Time{}, err
+		}
+		return file, stat.ModTime(), nil
+	}
+
+	return nil, time.Time{}, fmt.Errorf("%s is not a binary response type", s.ResponseType)
+}
+
+type nopReadSeekCloser struct {
+	io.ReadSeeker
+}
+
+func (nopReadSeekCloser) Close() error { return nil }
+
+// -----------------------------------------------------------------
+// file name on disk: ENDPOINTID_RESPONSEID.ext
+// -----------------------------------------------------------------
+func SaveResponseFile(endpointID string, response *EndPointResponse, fileName string, src io.Reader) error {
+	err := os.MkdirAll(ResponseFilesDir, 0750)
+	if err != nil {
+		return err
+	}
+
+	storedFile := strings.ToUpper(fmt.Sprintf("%s_%s", endpointID, response.ID)) + strings.ToLower(filepath.Ext(fileName))
+
+	// write to a temp file first so a failed upload keeps the old file
+	tmp, err := os.CreateTemp(ResponseFilesDir, "upload_*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = io.Copy(tmp, src)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	if response.StoredFile != "" && response.StoredFile != storedFile {
+		os.Remove(response.FilePath())
+	}
+
+	err = os.Rename(tmp.Name(), filepath.Join(ResponseFilesDir, storedFile))
+	if err != nil {
+		return err
+	}
+
+	response.StoredFile = storedFile
+
+	// a name typed by the user wins over the name of the upload
+	response.FileName = strings.TrimSpace(response.FileName)
+	if response.FileName == "" {
+		response.FileName = filepath.Base(fileName)
+	}
+	return nil
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func DeleteResponseFile(response *EndPointResponse) {
+	if response == nil || response.StoredFile == "" {
+		return
+	}
+	os.Remove(response.FilePath())
+	response.StoredFile = ""
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func ClearEndPointFiles(endpointID string) {
+	files, err := filepath.Glob(filepath.Join(ResponseFilesDir, strings.ToUpper(endpointID)+"_*"))
+	if err != nil {
+		return
+	}
+	for _, f := range files {
+		os.Remove(f)
+	}
+}
@@ -0,0 +1,135 @@
+package models
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func Test_EndPointResponse_OpenContent_Base64(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+		fails    bool
+	}{
+		{"valid", "aGVsbG8gd29ybGQ=", "hello world", false},
+		{"spaces around", "\n aGVsbG8gd29ybGQ= \n", "hello world", false},
+		{"empty", "", "", false},
+		{"not base64", "hello world!", "", true},
+		{"bad padding", "aGVsbG8gd29ybGQ", "", true},
+		{"url alphabet", "-_-_", "", true},
+	}
+
+	for _, test := range tests {
+		r := &EndPointResponse{ResponseType: ResponseTypeBase64, Response: test.content}
+
+		if err := ValidateBase64Response(test.content); (err != nil) != test.fails {
+			t.Errorf("%s: expected validation error %t but got %v", test.name, test.fails, err)
+		}
+
+		content, _, err := r.OpenContent()
+		if test.fails {
+			if err == nil || !strings.Contains(err.Error(), "invalid base64 content") {
+				t.Errorf("%s: expected an invalid base64 error but got %v", test.name, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error %s", test.name, err.Error())
+			continue
+		}
+		body, _ := io.ReadAll(content)
+		content.Close()
+		if string(body) != test.expected {
+			t.Errorf("%s: expected %q but got %q", test.name, test.expected, body)
+		}
+	}
+
+	if _, _, err := (&EndPointResponse{ResponseType: "JSON"}).OpenContent(); err == nil {
+		t.Errorf("expected an error for a JSON response")
+	}
+	if _, _, err := (&EndPointResponse{ResponseType: ResponseTypeFile}).OpenContent(); err == nil {
+		t.Errorf("expected an error for a response without a file")
+	}
+}
+
+func Test_EndPointResponse_ContentHeaders(t *testing.T) {
+	tests := []struct {
+		name        string
+		response    EndPointResponse
+		contentType string
+		disposition string
+	}{
+		{"guessed", EndPointResponse{FileName: "report.pdf"}, "application/pdf", ""},
+		{"typed content type", EndPointResponse{FileName: "report.pdf", ContentType: " text/plain "}, "text/plain", ""},
+		{"unknown extension", EndPointResponse{FileName: "data.zz9"}, "application/octet-stream", ""},
+		{"no file name", EndPointResponse{ContentDisposition: "Attachment"}, "application/octet-stream", "attachment"},
+		{"inline", EndPointResponse{FileName: "a.png", ContentDisposition: "inline"}, "image/png", "inline; filename=a.png"},
+		{"attachment", EndPointResponse{FileName: "my report.pdf", ContentDisposition: "attachment"}, "application/pdf", `attachment; filename="my report.pdf"`},
+	}
+
+	for _, test := range tests {
+		if contentType := test.response.GetContentType(); contentType != test.contentType {
+			t.Errorf("%s: expected Content-Type %s but got %s", test.name, test.contentType, contentType)
+		}
+		if disposition := test.response.GetContentDisposition(); disposition != test.disposition {
+			t.Errorf("%s: expected Content-Disposition %s but got %s", test.name, test.disposition, disposition)
+		}
+	}
+}
+
+// a file name typed by the user is kept, the upload name fills a blank one
+func Test_SaveResponseFile(t *testing.T) {
+	dir := ResponseFilesDir
+	ResponseFilesDir = filepath.Join(t.TempDir(), "files")
+	defer func() { ResponseFilesDir = dir }()
+
+	tests := []struct {
+		name     string
+		typed    string
+		upload   string
+		expected string
+		stored   string
+	}{
+		{"typed name", "invoice.pdf", "/tmp/scan_001.PDF", "invoice.pdf", "E1_R1.pdf"},
+		{"typed name with spaces", "  invoice.pdf ", "scan.pdf", "invoice.pdf", "E1_R1.pdf"},
+		{"blank name", "", "/tmp/scan_001.PDF", "scan_001.PDF", "E1_R1.pdf"},
+		{"blank name, other extension", " ", "logo.png", "logo.png", "E1_R1.png"},
+	}
+
+	// uploads again to the same response
+	r := &EndPointResponse{ID: "r1", ResponseType: ResponseTypeFile}
+	for _, test := range tests {
+		r.FileName = test.typed
+		if err := SaveResponseFile("e1", r, test.upload, strings.NewReader(test.name)); err != nil {
+			t.Errorf("%s: unexpected error %s", test.name, err.Error())
+			continue
+		}
+		if r.FileName != test.expected || r.StoredFile != test.stored {
+			t.Errorf("%s: expected %s stored as %s but got %s stored as %s", test.name, test.expected, test.stored, r.FileName, r.StoredFile)
+		}
+
+		content, _, err := r.OpenContent()
+		if err != nil {
+			t.Errorf("%s: unexpected error %s", test.name, err.Error())
+			continue
+		}
+		body, _ := io.ReadAll(content)
+		content.Close()
+		if string(body) != test.name {
+			t.Errorf("%s: expected the upload but got %q", test.name, body)
+		}
+	}
+
+	// a new extension replaces the old file
+	if _, err := os.Stat(filepath.Join(ResponseFilesDir, "E1_R1.pdf")); !os.IsNotExist(err) {
+		t.Errorf("expected the old file removed but got %v", err)
+	}
+	ClearEndPointFiles("e1")
+	if files, _ := filepath.Glob(filepath.Join(ResponseFilesDir, "*")); len(files) != 0 {
+		t.Errorf("expected no files after clear but got %v", files)
+	}
+}
@@ -5,13 +5,13 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/url"
 	"strconv"
 	"strings"
 
 	"github.com/onlysumitg/GoMockAPI/internal/validator"
 	"github.com/onlysumitg/GoMockAPI/utils/httputils"
 	"github.com/onlysumitg/GoMockAPI/utils/typeutils"
-	"github.com/onlysumitg/GoMockAPI/utils/xmlutils"
 	bolt "go.etcd.io/bbolt"
 )
 
@@ -38,37 +38,14 @@ type EndPointResponseParam struct {
 // -----------------------------------------------------------------
 //
 // -----------------------------------------------------------------
-func (p *EndPointResponseParam) getValueToUse(requestMap map[string]xmlutils.ValueDatatype, overrideValue string) (any, error) {
+func (p *EndPointResponseParam) getValueToUse(apiCall *ApiCall, overrideValue string) (any, error) {
 
 	if overrideValue == "" {
 		return p.DefaultValue, nil
 
 	}
 
-	brokenValues := strings.Split(overrideValue, ":")
-
-	valueSource := strings.TrimSpace(brokenValues[0])
-	if strings.HasPrefix(valueSource, "REQUEST[") && len(brokenValues) > 1 {
-		valueKey := strings.TrimSpace(strings.Join(brokenValues[1:], ":"))
-		requestValue, found := requestMap[valueKey]
-		if found {
-			return requestValue.Value, nil
-		} else {
-			return overrideValue, fmt.Errorf("Request Parameter not found:%s", overrideValue)
-		}
-
-	} else if strings.HasPrefix(valueSource, "*RANDOM") && len(brokenValues) > 1 {
-		randomValue, err := GenerateRandom(brokenValues[1])
-		if err == nil {
-			return randomValue, nil
-		} else {
-			return overrideValue, err
-		}
-	} else {
-		return overrideValue, nil
-	}
-
-	return p.DefaultValue, nil
+	return apiCall.ResolveValue(overrideValue)
 }
 
 // -----------------------------------------------------------------
@@ -147,6 +124,22 @@ func (p *EndPointResponseParam) processSpecials(apiCall *ApiCall, forResponse st
 
 		apiCall.SetKey(apiTrackKey)
 
+	case FaultParamKey:
+		apiCall.LogInfo(fmt.Sprintf("Setting FAULT %s", value))
+		apiCall.AdditionalResponseValues[apiTrackKey] = value
+		apiCall.SetKey(apiTrackKey)
+
+	case "*COOKIE":
+		apiCall.LogInfo(fmt.Sprintf("Setting COOKIE %s %s", specialKey, value))
+
+		for _, r := range apiCall.ResponseMapXX {
+			if r.ID == forResponse {
+				r.Cookies[specialKey] = value
+			}
+		}
+
+		apiCall.SetKey(apiTrackKey)
+
 	}
 
 }
@@ -156,26 +149,79 @@ func (p *EndPointResponseParam) processSpecials(apiCall *ApiCall, forResponse st
 // -----------------------------------------------------------------
 func (p *EndPointResponseParam) process(apiCall *ApiCall, forResponse string, value string) {
 	searchString := fmt.Sprintf("\"{{%s}}\"", p.Key)
+
+	replaceString, validStringValue := p.resolve(apiCall, value)
+
+	apiCall.LogInfo(fmt.Sprintf("Param %s assignement. Final Value %s", p.Key, replaceString))
+	if strings.HasPrefix(p.Key, "*") {
+		apiCall.LogInfo(fmt.Sprintf("Param %s assignement. Special variable.", p.Key))
+		p.processSpecials(apiCall, forResponse, validStringValue)
+	}
+
+	// if status code has been set --> only update that status code
+	// else => update all status code
+	for _, r := range apiCall.ResponseMapXX {
+
+		if r.ID == forResponse {
+			occurrences := strings.Count(r.Response, searchString)
+			if occurrences <= 1 && !apiCall.isRepeatedParam(r.ID, p.Key) {
+				r.Response = strings.ReplaceAll(r.Response, searchString, replaceString)
+				continue
+			}
+
+			// repeated array elements: every copy gets its own value
+			apiCall.LogInfo(fmt.Sprintf("Param %s assignement. Repeated %d times", p.Key, occurrences))
+			for i := 1; i <= occurrences; i++ {
+				apiCall.RepeatIndex = i
+				replaceString, _ = p.resolve(apiCall, value)
+				r.Response = strings.Replace(r.Response, searchString, replaceString, 1)
+			}
+			apiCall.RepeatIndex = 0
+		}
+
+	}
+	//apiCall.ResponseString = strings.ReplaceAll(apiCall.ResponseString, searchString, replaceString)
+}
+
+// -----------------------------------------------------------------
+// value to put in the response and the same value as plain string
+// -----------------------------------------------------------------
+func (p *EndPointResponseParam) resolve(apiCall *ApiCall, value string) (string, string) {
 	replaceString := ""
 
 	validStringValue := ""
 
 	var valueToUse any
 	if value != "" {
-		valueToUseX, err := p.getValueToUse(apiCall.RequestFlatMap, value)
+		valueToUseX, err := p.getValueToUse(apiCall, value)
 		if err != nil {
 			apiCall.LogError(err.Error())
 		}
 		valueToUse = valueToUseX
 	} else {
-		valueToUseX, err := p.getValueToUse(apiCall.RequestFlatMap, p.OverrideValue)
+		valueToUseX, err := p.getValueToUse(apiCall, p.OverrideValue)
 		if err != nil {
 			apiCall.LogError(err.Error())
 		}
 		valueToUse = valueToUseX
 	}
 
-	apiCall.LogInfo(fmt.Sprintf("Param %s assignement. Raw Value %s", p.Key, valueToUse))
+	apiCall.LogInfo(fmt.Sprintf("Param %s assignement. Raw Value %v", p.Key, valueToUse))
+
+	// objects and arrays go in as they are
+	if valueToUse == nil || isSubtree(valueToUse) {
+		if strings.EqualFold(p.DefaultDatatype, "XMLSTRING") {
+			replaceString = subtreeToXML(valueToUse)
+			return replaceString, replaceString
+		}
+
+		buf, err := json.Marshal(valueToUse)
+		if err != nil {
+			apiCall.LogError(err.Error())
+			return "null", "null"
+		}
+		return string(buf), string(buf)
+	}
 
 	switch strings.ToUpper(p.DefaultDatatype) {
 	case "BOOL": // without quotes
@@ -193,32 +239,25 @@ func (p *EndPointResponseParam) process(apiCall *ApiCall, forResponse string, va
 	case "INVALID":
 		replaceString = "null"
 		validStringValue = replaceString
-	case "XMLSTRING": // string without quotes
+	case "XMLSTRING", TextStringDatatype: // string without quotes
 		replaceString = fmt.Sprintf("%s", valueToUse)
 		validStringValue = fmt.Sprintf("%s", valueToUse)
+	case CsvStringDatatype:
+		replaceString = csvField(fmt.Sprintf("%s", valueToUse))
+		validStringValue = fmt.Sprintf("%s", valueToUse)
+	case FormStringDatatype:
+		replaceString = url.QueryEscape(fmt.Sprintf("%s", valueToUse))
+		validStringValue = fmt.Sprintf("%s", valueToUse)
+	case SSEDataDatatype, SSEFieldDatatype:
+		replaceString = sseValue(p.DefaultDatatype, fmt.Sprintf("%s", valueToUse))
+		validStringValue = fmt.Sprintf("%s", valueToUse)
 	default:
 		replaceString = strconv.Quote(fmt.Sprintf("%s", valueToUse)) // escape double quotes
 		validStringValue = fmt.Sprintf("%s", valueToUse)
 
 	}
 
-	apiCall.LogInfo(fmt.Sprintf("Param %s assignement. Final Value %s", p.Key, replaceString))
-	if strings.HasPrefix(p.Key, "*") {
-		apiCall.LogInfo(fmt.Sprintf("Param %s assignement. Special variable.", p.Key))
-		p.processSpecials(apiCall, forResponse, validStringValue)
-	}
-
-	// if status code has been set --> only update that status code
-	// else => update all status code
-	for _, r := range apiCall.ResponseMapXX {
-
-		if r.ID == forResponse {
-			r.Response = strings.ReplaceAll(r.Response, searchString, replaceString)
-
-		}
-
-	}
-	//apiCall.ResponseString = strings.ReplaceAll(apiCall.ResponseString, searchString, replaceString)
+	return replaceString, validStringValue
 }
 
 // -----------------------------------------------------------------
@@ -0,0 +1,147 @@
+package models
+
+import (
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// endpoint level response selection, used when no condition group picked a response
+const (
+	ResponseSelectionDefault      = ""
+	ResponseSelectionSequence     = "SEQUENCE"      // round robin
+	ResponseSelectionSequenceStop = "SEQUENCE_STOP" // in order, then keep the last one
+	ResponseSelectionRandom       = "RANDOM"        // weighted random
+)
+
+var ResponseSelectionList = []string{ResponseSelectionSequence, ResponseSelectionSequenceStop, ResponseSelectionRandom}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+type responseSequenceCounter struct {
+	mutex    sync.Mutex
+	counters map[string]int
+}
+
+var responseSequences = &responseSequenceCounter{counters: make(map[string]int)}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (c *responseSequenceCounter) next(endpointID string) int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	n := c.counters[endpointID]
+	c.counters[endpointID] = n + 1
+	return n
+}
+
+// -----------------------------------------------------------------
+// blank endpoint id resets every sequence
+// -----------------------------------------------------------------
+func ResetResponseSequence(endpointID string) {
+	responseSequences.mutex.Lock()
+	defer responseSequences.mutex.Unlock()
+
+	if endpointID == "" {
+		responseSequences.counters = make(map[string]int)
+		return
+	}
+	delete(responseSequences.counters, endpointID)
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (s *EndPoint) IsResponseSelection(selection string) bool {
+	return strings.EqualFold(s.ResponseSelection, selection)
+}
+
+// -----------------------------------------------------------------
+// responses with a sequence position, or all of them by http code
+// -----------------------------------------------------------------
+func (s *EndPoint) SequenceResponses() []*EndPointResponse {
+	responses := make([]*EndPointResponse, 0)
+	for _, r := range s.ResponseMap {
+		if r.SequenceOrder > 0 {
+			responses = append(responses, r)
+		}
+	}
+
+	if len(responses) == 0 {
+		responses = append(responses, s.ResponseMap...)
+		sort.SliceStable(responses, func(i, j int) bool {
+			if responses[i].HttpCode == responses[j].HttpCode {
+				return responses[i].Name < responses[j].Name
+			}
+			return responses[i].HttpCode < responses[j].HttpCode
+		})
+		return responses
+	}
+
+	sort.SliceStable(responses, func(i, j int) bool {
+		return responses[i].SequenceOrder < responses[j].SequenceOrder
+	})
+	return responses
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (s *EndPoint) weightedResponse(random *rand.Rand) *EndPointResponse {
+	total := 0
+	for _, r := range s.ResponseMap {
+		if r.Weight > 0 {
+			total += r.Weight
+		}
+	}
+
+	// no weights: all responses are equally likely
+	if total == 0 {
+		return s.ResponseMap[random.Intn(len(s.ResponseMap))]
+	}
+
+	pick := random.Intn(total)
+	for _, r := range s.ResponseMap {
+		if r.Weight <= 0 {
+			continue
+		}
+		if pick < r.Weight {
+			return r
+		}
+		pick -= r.Weight
+	}
+
+	return nil
+}
+
+// -----------------------------------------------------------------
+// nil when the endpoint uses the default response
+// -----------------------------------------------------------------
+func (s *EndPoint) SelectResponse(random *rand.Rand) *EndPointResponse {
+	if len(s.ResponseMap) == 0 {
+		return nil
+	}
+
+	switch strings.ToUpper(s.ResponseSelection) {
+	case ResponseSelectionSequence:
+		responses := s.SequenceResponses()
+		return responses[responseSequences.next(s.ID)%len(responses)]
+
+	case ResponseSelectionSequenceStop:
+		responses := s.SequenceResponses()
+		n := responseSequences.next(s.ID)
+		if n >= len(responses) {
+			n = len(responses) - 1
+		}
+		return responses[n]
+
+	case ResponseSelectionRandom:
+		return s.weightedResponse(random)
+	}
+
+	return nil
+}
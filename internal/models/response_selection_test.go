@@ -0,0 +1,85 @@
+package models
+
+import (
+	"encoding/json"
+	"math/rand"
+	"testing"
+)
+
+func Test_EndPoint_SelectResponse(t *testing.T) {
+	ok := &EndPointResponse{ID: "ok", Name: "OK", HttpCode: 200}
+	created := &EndPointResponse{ID: "created", Name: "CREATED", HttpCode: 201}
+	failed := &EndPointResponse{ID: "failed", Name: "FAILED", HttpCode: 500}
+
+	tests := []struct {
+		name      string
+		selection string
+		responses []*EndPointResponse
+		expected  []string
+	}{
+		{"default", "", []*EndPointResponse{ok, failed}, []string{"", ""}},
+		{"unknown", "NOPE", []*EndPointResponse{ok, failed}, []string{""}},
+		{"no responses", ResponseSelectionRandom, []*EndPointResponse{}, []string{""}},
+		{"sequence by http code", ResponseSelectionSequence, []*EndPointResponse{failed, created, ok}, []string{"ok", "created", "failed", "ok", "created"}},
+		{"sequence by position", "sequence", []*EndPointResponse{
+			{ID: "a", SequenceOrder: 2}, {ID: "b", SequenceOrder: 1}, {ID: "skipped"},
+		}, []string{"b", "a", "b", "a"}},
+		{"sequence stop", ResponseSelectionSequenceStop, []*EndPointResponse{
+			{ID: "a", SequenceOrder: 1}, {ID: "b", SequenceOrder: 2},
+		}, []string{"a", "b", "b", "b"}},
+		{"only weighted", ResponseSelectionRandom, []*EndPointResponse{
+			{ID: "zero", Weight: 0}, {ID: "negative", Weight: -5}, {ID: "weighted", Weight: 1},
+		}, []string{"weighted", "weighted", "weighted", "weighted"}},
+	}
+
+	for _, test := range tests {
+		e := &EndPoint{ID: "select-" + test.name, ResponseSelection: test.selection, ResponseMap: test.responses}
+		ResetResponseSequence(e.ID)
+		random := rand.New(rand.NewSource(1))
+
+		for i, expected := range test.expected {
+			id := ""
+			if selected := e.SelectResponse(random); selected != nil {
+				id = selected.ID
+			}
+			if id != expected {
+				t.Errorf("%s: call %d expected %q but got %q", test.name, i+1, expected, id)
+			}
+		}
+	}
+}
+
+func Test_EndPoint_SelectResponse_Weights(t *testing.T) {
+	tests := []struct {
+		name      string
+		responses string         // as stored
+		expected  map[string]int // share in percent, give or take 3
+	}{
+		{"90/8/2", `[{"id": "ok", "weight": 90}, {"id": "slow", "weight": 8}, {"id": "error", "weight": 2}]`,
+			map[string]int{"ok": 90, "slow": 8, "error": 2}},
+		{"no weights", `[{"id": "a"}, {"id": "b", "weight": 0}]`, map[string]int{"a": 50, "b": 50}},
+		{"negative is left out", `[{"id": "a", "weight": 3}, {"id": "b", "weight": -3}, {"id": "c", "weight": 1}]`,
+			map[string]int{"a": 75, "b": 0, "c": 25}},
+	}
+
+	for _, test := range tests {
+		e := &EndPoint{ID: "weights", ResponseSelection: ResponseSelectionRandom}
+		if err := json.Unmarshal([]byte(test.responses), &e.ResponseMap); err != nil {
+			t.Fatalf("%s: %s", test.name, err.Error())
+		}
+
+		random := rand.New(rand.NewSource(1))
+		picked := make(map[string]int)
+		const calls = 10000
+		for i := 0; i < calls; i++ {
+			picked[e.SelectResponse(random).ID]++
+		}
+
+		for id, share := range test.expected {
+			got := picked[id] * 100 / calls
+			if got < share-3 || got > share+3 {
+				t.Errorf("%s: expected about %d%% for %s but got %d%%", test.name, share, id, got)
+			}
+		}
+	}
+}
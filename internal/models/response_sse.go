@@ -0,0 +1,182 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/onlysumitg/GoMockAPI/utils/xmlutils"
+)
+
+// Server-Sent Events response, the response is a script of events
+// separated by blank lines:
+//
+//	delay: 500
+//	event: status
+//	id: 1
+//	data: {"status": "{{status=shipped}}"}
+//
+// delay (ms before the event) is not sent, the other fields are
+const ResponseTypeSSE = "SSE"
+
+// a looping stream is closed after this many events
+const MaxSSELoopEvents = 100000
+
+// param datatypes of the event script, values must not break the framing
+const (
+	SSEDataDatatype  = "SSEDATA"  // line breaks continue on a new data line
+	SSEFieldDatatype = "SSEFIELD" // id, event, comment: line breaks become spaces
+)
+
+type SSEEvent struct {
+	Delay   int
+	ID      string
+	Event   string
+	Retry   string
+	Data    []string
+	Comment []string
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func ParseEventScript(script string) ([]*SSEEvent, error) {
+	events := make([]*SSEEvent, 0)
+
+	var current *SSEEvent
+	lines := strings.Split(strings.ReplaceAll(script, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			if current != nil {
+				events = append(events, current)
+				current = nil
+			}
+			continue
+		}
+
+		if current == nil {
+			current = &SSEEvent{}
+		}
+
+		if strings.HasPrefix(line, ":") {
+			current.Comment = append(current.Comment, strings.TrimPrefix(strings.TrimPrefix(line, ":"), " "))
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+
+		switch strings.ToLower(strings.TrimSpace(field)) {
+		case "delay":
+			delay, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil || delay < 0 {
+				return events, fmt.Errorf("line %d: delay must be milliseconds", i+1)
+			}
+			current.Delay = delay
+		case "id":
+			current.ID = value
+		case "event":
+			current.Event = value
+		case "retry":
+			_, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil {
+				return events, fmt.Errorf("line %d: retry must be milliseconds", i+1)
+			}
+			current.Retry = strings.TrimSpace(value)
+		case "data":
+			current.Data = append(current.Data, value)
+		default:
+			return events, fmt.Errorf("line %d: unknown field %s", i+1, field)
+		}
+	}
+
+	if current != nil {
+		events = append(events, current)
+	}
+
+	if len(events) == 0 {
+		return events, errors.New("at least one event is needed")
+	}
+	return events, nil
+}
+
+// -----------------------------------------------------------------
+// placeholders on data lines and on other lines get their own datatype
+// -----------------------------------------------------------------
+func SSEToFlatMapAndPlaceholder(script string) (map[string]xmlutils.ValueDatatype, string) {
+	flatMap := make(map[string]xmlutils.ValueDatatype)
+
+	lines := strings.Split(strings.ReplaceAll(script, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		datatype := SSEFieldDatatype
+		field, _, _ := strings.Cut(line, ":")
+		if strings.EqualFold(strings.TrimSpace(field), "data") {
+			datatype = SSEDataDatatype
+		}
+
+		lineMap, placeholder := TextToFlatMapAndPlaceholder(line, datatype)
+		for k, v := range lineMap {
+			if _, found := flatMap[k]; !found || flatMap[k].Value == "" {
+				flatMap[k] = v
+			}
+		}
+		lines[i] = placeholder
+	}
+
+	return flatMap, strings.Join(lines, "\n")
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func sseValue(datatype string, value string) string {
+	value = strings.ReplaceAll(value, "\r\n", "\n")
+	value = strings.ReplaceAll(value, "\r", "\n")
+
+	if strings.EqualFold(datatype, SSEDataDatatype) {
+		return strings.ReplaceAll(value, "\n", "\ndata: ")
+	}
+	return strings.ReplaceAll(value, "\n", " ")
+}
+
+// -----------------------------------------------------------------
+// wire format, ends with the blank line
+// -----------------------------------------------------------------
+func (e *SSEEvent) String() string {
+	builder := &strings.Builder{}
+	for _, c := range e.Comment {
+		builder.WriteString(": " + c + "\n")
+	}
+	if e.Event != "" {
+		builder.WriteString("event: " + e.Event + "\n")
+	}
+	if e.ID != "" {
+		builder.WriteString("id: " + e.ID + "\n")
+	}
+	if e.Retry != "" {
+		builder.WriteString("retry: " + e.Retry + "\n")
+	}
+	for _, d := range e.Data {
+		builder.WriteString("data: " + d + "\n")
+	}
+	builder.WriteString("\n")
+	return builder.String()
+}
+
+// -----------------------------------------------------------------
+// events after Last-Event-ID, all of them when the id is not found
+// -----------------------------------------------------------------
+func EventsAfter(events []*SSEEvent, lastEventID string) []*SSEEvent {
+	lastEventID = strings.TrimSpace(lastEventID)
+	if lastEventID == "" {
+		return events
+	}
+
+	for i, e := range events {
+		if e.ID == lastEventID {
+			return events[i+1:]
+		}
+	}
+	return events
+}
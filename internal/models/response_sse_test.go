@@ -0,0 +1,146 @@
+package models
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_ParseEventScript(t *testing.T) {
+	script := "delay: 500\r\nevent: status\nid: 1\ndata: {\"status\": \"shipped\"}\n\n" +
+		": keep alive\n\n" +
+		"id: 2\nretry: 3000\ndata: line 1\ndata:line 2\n"
+
+	events, err := ParseEventScript(script)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+
+	expected := []*SSEEvent{
+		{Delay: 500, Event: "status", ID: "1", Data: []string{`{"status": "shipped"}`}},
+		{Comment: []string{"keep alive"}},
+		{ID: "2", Retry: "3000", Data: []string{"line 1", "line 2"}},
+	}
+	if !reflect.DeepEqual(events, expected) {
+		for i, e := range events {
+			t.Logf("%d: %+v", i, e)
+		}
+		t.Errorf("events do not match")
+	}
+
+	errorTests := []struct {
+		script string
+		errorX string
+	}{
+		{"", "at least one event"},
+		{"\n\n", "at least one event"},
+		{"data: a\nfoo: b", "line 2: unknown field foo"},
+		{"delay: soon\ndata: a", "line 1: delay"},
+		{"delay: -1\ndata: a", "line 1: delay"},
+		{"retry: x\ndata: a", "line 1: retry"},
+	}
+	for _, test := range errorTests {
+		_, err := ParseEventScript(test.script)
+		if err == nil || !strings.Contains(err.Error(), test.errorX) {
+			t.Errorf("%q: expected error %q but got %v", test.script, test.errorX, err)
+		}
+	}
+}
+
+func Test_SSEEvent_String(t *testing.T) {
+	tests := []struct {
+		event    *SSEEvent
+		expected string
+	}{
+		{&SSEEvent{Data: []string{"a"}}, "data: a\n\n"},
+		{&SSEEvent{Delay: 100, Event: "e", ID: "7", Retry: "10", Data: []string{"a", ""}},
+			"event: e\nid: 7\nretry: 10\ndata: a\ndata: \n\n"},
+		{&SSEEvent{Comment: []string{"ping"}}, ": ping\n\n"},
+	}
+
+	for _, test := range tests {
+		result := test.event.String()
+		if result != test.expected {
+			t.Errorf("%+v: expected %q but got %q", test.event, test.expected, result)
+		}
+	}
+}
+
+func Test_EventsAfter(t *testing.T) {
+	events := []*SSEEvent{{ID: "1"}, {ID: "2"}, {}, {ID: "3"}}
+
+	tests := []struct {
+		lastEventID string
+		expected    int
+	}{
+		{"", 4},
+		{"1", 3},
+		{" 2 ", 2},
+		{"3", 0},
+		{"unknown", 4},
+	}
+
+	for _, test := range tests {
+		result := EventsAfter(events, test.lastEventID)
+		if len(result) != test.expected {
+			t.Errorf("%q: expected %d events but got %d", test.lastEventID, test.expected, len(result))
+		}
+	}
+}
+
+func Test_SSEToFlatMapAndPlaceholder(t *testing.T) {
+	script := "id: {{id=1}}\nevent: {{kind=status}}\ndata: {\"note\": \"{{note}}\"}\n: {{comment}}\n"
+
+	flatMap, placeholder := SSEToFlatMapAndPlaceholder(script)
+
+	expectedPlaceholder := "id: \"{{id}}\"\nevent: \"{{kind}}\"\ndata: {\"note\": \"\"{{note}}\"\"}\n: \"{{comment}}\"\n"
+	if placeholder != expectedPlaceholder {
+		t.Errorf("expected placeholder %q but got %q", expectedPlaceholder, placeholder)
+	}
+
+	expectedTypes := map[string]string{"id": SSEFieldDatatype, "kind": SSEFieldDatatype, "note": SSEDataDatatype, "comment": SSEFieldDatatype}
+	for key, datatype := range expectedTypes {
+		if flatMap[key].DataType != datatype {
+			t.Errorf("%s: expected datatype %s but got %s", key, datatype, flatMap[key].DataType)
+		}
+	}
+	if flatMap["id"].Value != "1" || flatMap["kind"].Value != "status" {
+		t.Errorf("defaults not kept: %v", flatMap)
+	}
+}
+
+// values with line breaks keep one event with the same fields
+func Test_SSEValue_Framing(t *testing.T) {
+	_, placeholder := SSEToFlatMapAndPlaceholder("id: {{id}}\nevent: {{kind}}\ndata: {{note}}\n")
+
+	values := map[string]string{
+		"id":   "4\n2",
+		"kind": "a\r\nb",
+		"note": "first\r\nsecond\n\nlast",
+	}
+	dataTypes := map[string]string{"id": SSEFieldDatatype, "kind": SSEFieldDatatype, "note": SSEDataDatatype}
+
+	script := placeholder
+	for key, value := range values {
+		script = strings.ReplaceAll(script, "\"{{"+key+"}}\"", sseValue(dataTypes[key], value))
+	}
+
+	events, err := ParseEventScript(script)
+	if err != nil {
+		t.Fatalf("%q: unexpected error %s", script, err.Error())
+	}
+	if len(events) != 1 {
+		t.Fatalf("%q: expected 1 event but got %d", script, len(events))
+	}
+
+	event := events[0]
+	if event.ID != "4 2" || event.Event != "a b" {
+		t.Errorf("expected id %q and event %q but got %q and %q", "4 2", "a b", event.ID, event.Event)
+	}
+
+	// the client joins data lines with a line break
+	data := strings.Join(event.Data, "\n")
+	if data != "first\nsecond\n\nlast" {
+		t.Errorf("expected data %q but got %q", "first\nsecond\n\nlast", data)
+	}
+}
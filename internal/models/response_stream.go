@@ -0,0 +1,82 @@
+package models
+
+import (
+	"time"
+)
+
+// slow network settings of a response, all 0 sends the body at once
+type StreamSettings struct {
+	FirstByteDelay int `json:"firstbytedelay" db:"firstbytedelay" form:"firstbytedelay"` // ms before the status line and headers
+	ChunkSize      int `json:"chunksize" db:"chunksize" form:"chunksize"`                // bytes per write and flush
+	ChunkDelay     int `json:"chunkdelay" db:"chunkdelay" form:"chunkdelay"`             // ms between chunks
+	BytesPerSec    int `json:"bytespersec" db:"bytespersec" form:"bytespersec"`          // bandwidth limit
+	TotalDuration  int `json:"totalduration" db:"totalduration" form:"totalduration"`    // ms from the first to the last chunk
+}
+
+const defaultChunkSize = 1024
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (s StreamSettings) IsStreamed() bool {
+	return s.FirstByteDelay > 0 || s.ChunkSize > 0 || s.ChunkDelay > 0 || s.BytesPerSec > 0 || s.TotalDuration > 0
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (s StreamSettings) IsValid() bool {
+	return s.FirstByteDelay >= 0 && s.ChunkSize >= 0 && s.ChunkDelay >= 0 && s.BytesPerSec >= 0 && s.TotalDuration >= 0
+}
+
+// -----------------------------------------------------------------
+// about 10 writes a second for a bandwidth limit
+// -----------------------------------------------------------------
+func (s StreamSettings) GetChunkSize() int {
+	if s.ChunkSize > 0 {
+		return s.ChunkSize
+	}
+	if s.BytesPerSec > 0 && s.BytesPerSec < defaultChunkSize*10 {
+		if s.BytesPerSec < 10 {
+			return 1
+		}
+		return s.BytesPerSec / 10
+	}
+	return defaultChunkSize
+}
+
+// -----------------------------------------------------------------
+// earliest time to send a chunk ending at byte sentAfter,
+// the slowest of the limits wins. total is 0 when not known
+// -----------------------------------------------------------------
+func (s StreamSettings) NextChunkAt(start time.Time, lastChunk time.Time, sentAfter int, total int) time.Time {
+	next := start
+
+	if !lastChunk.IsZero() && s.ChunkDelay > 0 {
+		next = laterTime(next, lastChunk.Add(time.Duration(s.ChunkDelay)*time.Millisecond))
+	}
+
+	if s.BytesPerSec > 0 {
+		next = laterTime(next, start.Add(time.Duration(float64(sentAfter)/float64(s.BytesPerSec)*float64(time.Second))))
+	}
+
+	if s.TotalDuration > 0 && total > 0 {
+		if sentAfter > total {
+			sentAfter = total
+		}
+		duration := time.Duration(s.TotalDuration) * time.Millisecond
+		next = laterTime(next, start.Add(time.Duration(float64(duration)*float64(sentAfter)/float64(total))))
+	}
+
+	return next
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func laterTime(a time.Time, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_StreamSettings_GetChunkSize(t *testing.T) {
+	tests := []struct {
+		settings StreamSettings
+		expected int
+	}{
+		{StreamSettings{}, defaultChunkSize},
+		{StreamSettings{ChunkSize: 10}, 10},
+		{StreamSettings{ChunkSize: 10, BytesPerSec: 5}, 10},
+		{StreamSettings{BytesPerSec: 500}, 50},
+		{StreamSettings{BytesPerSec: 5}, 1},
+		{StreamSettings{BytesPerSec: 100000}, defaultChunkSize},
+		{StreamSettings{ChunkDelay: 100}, defaultChunkSize},
+	}
+
+	for _, test := range tests {
+		result := test.settings.GetChunkSize()
+		if result != test.expected {
+			t.Errorf("%+v: expected %d but got %d", test.settings, test.expected, result)
+		}
+	}
+}
+
+func Test_StreamSettings_NextChunkAt(t *testing.T) {
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	ms := func(n int) time.Time { return start.Add(time.Duration(n) * time.Millisecond) }
+
+	tests := []struct {
+		name      string
+		settings  StreamSettings
+		lastChunk time.Time
+		sentAfter int
+		total     int
+		expected  time.Time
+	}{
+		{"no limits", StreamSettings{ChunkSize: 10}, ms(5), 100, 1000, start},
+		{"first chunk has no delay", StreamSettings{ChunkDelay: 200}, time.Time{}, 10, 0, start},
+		{"chunk delay after the last chunk", StreamSettings{ChunkDelay: 200}, ms(300), 20, 0, ms(500)},
+		{"bandwidth", StreamSettings{BytesPerSec: 1000}, ms(100), 500, 0, ms(500)},
+		{"total duration", StreamSettings{TotalDuration: 2000}, ms(100), 250, 1000, ms(500)},
+		{"total duration, unknown size", StreamSettings{TotalDuration: 2000}, ms(100), 250, 0, start},
+		{"total duration, past the size", StreamSettings{TotalDuration: 2000}, ms(100), 1500, 1000, ms(2000)},
+		{"slowest limit wins", StreamSettings{ChunkDelay: 100, BytesPerSec: 1000, TotalDuration: 4000}, ms(1000), 500, 1000, ms(2000)},
+		{"chunk delay wins", StreamSettings{ChunkDelay: 3000, BytesPerSec: 1000}, ms(1000), 500, 0, ms(4000)},
+	}
+
+	for _, test := range tests {
+		result := test.settings.NextChunkAt(start, test.lastChunk, test.sentAfter, test.total)
+		if !result.Equal(test.expected) {
+			t.Errorf("%s: expected +%s but got +%s", test.name, test.expected.Sub(start), result.Sub(start))
+		}
+	}
+}
+
+func Test_StreamSettings_IsStreamed(t *testing.T) {
+	if (StreamSettings{}).IsStreamed() {
+		t.Errorf("empty settings: expected not streamed")
+	}
+	if !(StreamSettings{FirstByteDelay: 1}).IsStreamed() || !(StreamSettings{TotalDuration: 1}).IsStreamed() {
+		t.Errorf("expected streamed")
+	}
+	if (StreamSettings{ChunkSize: -1}).IsValid() || !(StreamSettings{ChunkSize: 1}).IsValid() {
+		t.Errorf("negative values must not be valid")
+	}
+}
@@ -0,0 +1,348 @@
+package models
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"html"
+	"math"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/onlysumitg/GoMockAPI/utils/httputils"
+	"github.com/onlysumitg/GoMockAPI/utils/xmlutils"
+)
+
+// -----------------------------------------------------------------
+// the request as seen by response templates: {{.Request.Query.id}}
+// -----------------------------------------------------------------
+type TemplateRequest struct {
+	Method  string
+	URL     string
+	Path    []string
+	Query   map[string]string
+	Headers map[string]string
+	Body    any            // parsed JSON body, nil when the body is not JSON
+	BodyRaw string         // body as sent
+	Values  map[string]any // flat request values: {{index .Request.Values "user.name"}}
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+type TemplateData struct {
+	Request    TemplateRequest
+	Store      map[string]any
+	StatusCode int
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func toFloat(value any) float64 {
+	switch v := value.(type) {
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case float64:
+		return v
+	case float32:
+		return float64(v)
+	case bool:
+		if v {
+			return 1
+		}
+		return 0
+	case json.Number:
+		f, _ := v.Float64()
+		return f
+	default:
+		f, _ := strconv.ParseFloat(strings.TrimSpace(fmt.Sprint(v)), 64)
+		return f
+	}
+}
+
+// -----------------------------------------------------------------
+// whole numbers stay whole: {{add 1 2}} is 3, not 3.000000
+// -----------------------------------------------------------------
+func numberResult(f float64) any {
+	if f == math.Trunc(f) && math.Abs(f) < 1e15 {
+		return int64(f)
+	}
+	return f
+}
+
+// -----------------------------------------------------------------
+// store and request lookups need the api call, nil is fine for parsing
+// -----------------------------------------------------------------
+func responseTemplateFuncs(a *ApiCall) template.FuncMap {
+	return template.FuncMap{
+		"uuid": func() string {
+			if a == nil {
+				return uuid.NewString()
+			}
+			return a.RandomFaker().UUID()
+		},
+		"now": Now,
+		"formatTime": func(layout string, t time.Time) string {
+			return t.Format(layout)
+		},
+		"unix": func(t time.Time) int64 {
+			return t.Unix()
+		},
+
+		"add": func(x, y any) any { return numberResult(toFloat(x) + toFloat(y)) },
+		"sub": func(x, y any) any { return numberResult(toFloat(x) - toFloat(y)) },
+		"mul": func(x, y any) any { return numberResult(toFloat(x) * toFloat(y)) },
+		"div": func(x, y any) (any, error) {
+			if toFloat(y) == 0 {
+				return nil, fmt.Errorf("division by zero")
+			}
+			return numberResult(toFloat(x) / toFloat(y)), nil
+		},
+		"mod": func(x, y any) (any, error) {
+			if int64(toFloat(y)) == 0 {
+				return nil, fmt.Errorf("division by zero")
+			}
+			return int64(toFloat(x)) % int64(toFloat(y)), nil
+		},
+		"round": func(places int, x any) float64 {
+			p := math.Pow(10, float64(places))
+			return math.Round(toFloat(x)*p) / p
+		},
+		"toInt":   func(x any) int64 { return int64(toFloat(x)) },
+		"toFloat": toFloat,
+
+		"upper":     strings.ToUpper,
+		"lower":     strings.ToLower,
+		"trim":      strings.TrimSpace,
+		"replace":   func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+		"contains":  func(sub, s string) bool { return strings.Contains(s, sub) },
+		"hasPrefix": func(prefix, s string) bool { return strings.HasPrefix(s, prefix) },
+		"hasSuffix": func(suffix, s string) bool { return strings.HasSuffix(s, suffix) },
+		"split":     func(sep, s string) []string { return strings.Split(s, sep) },
+		"join":      func(sep string, l []string) string { return strings.Join(l, sep) },
+		"repeat":    func(n int, s string) string { return strings.Repeat(s, n) },
+		"substr": func(start, end int, s string) string {
+			// by rune, a byte cut can split a character
+			runes := []rune(s)
+			if start < 0 {
+				start = 0
+			}
+			if end > len(runes) || end < 0 {
+				end = len(runes)
+			}
+			if start > end {
+				return ""
+			}
+			return string(runes[start:end])
+		},
+		"toString": func(x any) string { return fmt.Sprint(x) },
+		"default": func(defaultValue any, x any) any {
+			if x == nil || fmt.Sprint(x) == "" {
+				return defaultValue
+			}
+			return x
+		},
+
+		"b64enc": func(s string) string { return base64.StdEncoding.EncodeToString([]byte(s)) },
+		"b64dec": func(s string) (string, error) {
+			b, err := base64.StdEncoding.DecodeString(s)
+			return string(b), err
+		},
+
+		"toJson": func(x any) (string, error) {
+			b, err := json.Marshal(x)
+			return string(b), err
+		},
+		"fromJson": func(s string) (any, error) {
+			var x any
+			err := json.Unmarshal([]byte(s), &x)
+			return x, err
+		},
+
+		// Fault element of a SOAP response: {{soapFault "Client" "Unknown symbol"}}
+		"soapFault": func(code, reason string) string {
+			if a == nil {
+				return SoapFault(Soap11, code, reason)
+			}
+			return SoapFault(a.SoapVersion(), code, reason)
+		},
+
+		"store": func(key string) any {
+			if a == nil {
+				return nil
+			}
+			v, err := a.GetStoreValue(key)
+			if err != nil {
+				return nil
+			}
+			return v
+		},
+	}
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func ParseResponseTemplate(name string, text string) (*template.Template, error) {
+	return template.New(name).Funcs(responseTemplateFuncs(nil)).Option("missingkey=zero").Parse(text)
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (a *ApiCall) templateData() *TemplateData {
+	data := &TemplateData{
+		Request: TemplateRequest{
+			Path:    make([]string, 0),
+			Query:   make(map[string]string),
+			Headers: make(map[string]string),
+			Values:  make(map[string]any),
+		},
+		Store:      make(map[string]any),
+		StatusCode: a.StatusCode,
+	}
+
+	if a.HttpRequest != nil {
+		data.Request.Method = a.HttpRequest.Method
+		data.Request.URL = a.HttpRequest.URL.String()
+		for k, v := range a.HttpRequest.URL.Query() {
+			if len(v) > 0 {
+				data.Request.Query[k] = v[0]
+			}
+		}
+		data.Request.Headers = httputils.GetHeadersAsMap(a.HttpRequest)
+
+		body := a.RequestBody()
+		data.Request.BodyRaw = string(body)
+		var parsed any
+		if json.Unmarshal(body, &parsed) == nil {
+			data.Request.Body = parsed
+		}
+	}
+
+	for _, p := range a.PathParams {
+		data.Request.Path = append(data.Request.Path, fmt.Sprint(p.Value))
+	}
+
+	for k, v := range a.RequestFlatMap {
+		data.Request.Values[k] = v.Value
+	}
+
+	if a.DataDB != nil {
+		storeModel := &StoreModel{DB: a.DataDB}
+		for _, entry := range storeModel.List(a.storeCollectionID()) {
+			data.Store[entry.Key] = entry.Value
+		}
+	}
+
+	return data
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (a *ApiCall) renderTemplate(name string, text string, data any) (string, error) {
+	t, err := template.New(name).Funcs(responseTemplateFuncs(a)).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	buf := &bytes.Buffer{}
+	err = t.Execute(buf, data)
+	if err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// -----------------------------------------------------------------
+// header template output is a JSON object or XML like the sample header
+// -----------------------------------------------------------------
+func parseRenderedHeader(headerType string, rendered string) (map[string]string, error) {
+	headers := make(map[string]string)
+	if strings.TrimSpace(rendered) == "" {
+		return headers, nil
+	}
+
+	switch strings.ToUpper(headerType) {
+	case "XML":
+		flatMap, _, err := xmlutils.XmlToFlatMapAndPlaceholder(rendered)
+		if err != nil {
+			return headers, err
+		}
+		for k, v := range flatMap {
+			headers[k] = fmt.Sprint(v.Value)
+		}
+	default:
+		values := make(map[string]any)
+		err := json.Unmarshal([]byte(rendered), &values)
+		if err != nil {
+			return headers, err
+		}
+		for k, v := range values {
+			list, ok := v.([]any)
+			if !ok {
+				headers[k] = fmt.Sprint(v)
+				continue
+			}
+			// arrays are repeated headers
+			for i, listValue := range list {
+				headers[fmt.Sprintf("%s[%d]", k, i)] = fmt.Sprint(listValue)
+			}
+		}
+	}
+
+	return headers, nil
+}
+
+// -----------------------------------------------------------------
+// errors go to the call log and turn into a 500
+// -----------------------------------------------------------------
+func (a *ApiCall) ApplyResponseTemplate(response *EndPointResponse) {
+	if response == nil || !response.UseTemplate || response.IsBinary() {
+		return
+	}
+
+	a.LogInfo(fmt.Sprintf("Rendering response template %s %d", response.Name, response.HttpCode))
+
+	data := a.templateData()
+
+	body, err := a.renderTemplate("response", html.UnescapeString(response.Response), data)
+	if err != nil {
+		a.LogError(fmt.Sprintf("Response template error: %s", err.Error()))
+		a.StatusCode = 500
+		a.UseJSONResponseType()
+		buf, _ := json.Marshal(map[string]string{"error": fmt.Sprintf("response template error: %s", err.Error())})
+		a.FinalResponseString = string(buf)
+		return
+	}
+	a.FinalResponseString = body
+
+	if strings.TrimSpace(response.ResponseHeader) == "" {
+		return
+	}
+
+	renderedHeader, err := a.renderTemplate("header", html.UnescapeString(response.ResponseHeader), data)
+	if err == nil {
+		var headers map[string]string
+		headers, err = parseRenderedHeader(response.ResponseHeaderType, renderedHeader)
+		if err == nil {
+			if a.FinalResponseHeader == nil {
+				a.FinalResponseHeader = make(map[string]string)
+			}
+			for k, v := range headers {
+				a.FinalResponseHeader[k] = v
+			}
+		}
+	}
+	if err != nil {
+		a.LogError(fmt.Sprintf("Response header template error: %s", err.Error()))
+	}
+}
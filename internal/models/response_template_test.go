@@ -0,0 +1,190 @@
+package models
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/onlysumitg/GoMockAPI/utils/xmlutils"
+)
+
+func Test_ResponseTemplate_Substr(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected string
+	}{
+		{`{{substr 0 3 "abcdef"}}`, "abc"},
+		{`{{substr 2 -1 "abcdef"}}`, "cdef"},
+		{`{{substr -2 2 "abcdef"}}`, "ab"},
+		{`{{substr 4 99 "abcdef"}}`, "ef"},
+		{`{{substr 4 2 "abcdef"}}`, ""},
+		{`{{substr 0 2 "héllo"}}`, "hé"},
+		{`{{substr 1 3 "日本語テキスト"}}`, "本語"},
+		{`{{substr 0 1 "😀x"}}`, "😀"},
+	}
+
+	apiCall := &ApiCall{}
+	for _, test := range tests {
+		got, err := apiCall.renderTemplate("substr", test.text, nil)
+		if err != nil {
+			t.Errorf("%s: unexpected error %s", test.text, err.Error())
+			continue
+		}
+		if got != test.expected || !utf8.ValidString(got) {
+			t.Errorf("%s: expected %q but got %q", test.text, test.expected, got)
+		}
+	}
+}
+
+// -----------------------------------------------------------------
+// call of a POST /orders?id=7 with a JSON body
+// -----------------------------------------------------------------
+func newTemplateCall(t *testing.T) *ApiCall {
+	t.Helper()
+
+	db := newTestStoreDB(t)
+	(&StoreModel{DB: db}).Set("C1", "token", "abc", 0)
+
+	r := httptest.NewRequest("POST", "/api/v1/orders?id=7", strings.NewReader(`{"name": "ann", "qty": 2}`))
+	r.Header.Set("X-User", "u1")
+
+	return &ApiCall{
+		HttpRequest:     r,
+		DataDB:          db,
+		StatusCode:      201,
+		CurrentEndPoint: &EndPoint{CollectionID: "C1"},
+		RequestFlatMap:  map[string]xmlutils.ValueDatatype{"name": {Value: "ann", DataType: "STRING"}},
+	}
+}
+
+func Test_ApiCall_ApplyResponseTemplate(t *testing.T) {
+	apiCall := newTemplateCall(t)
+	apiCall.FinalResponseHeader = map[string]string{"X-Kept": "yes"}
+
+	response := &EndPointResponse{
+		Name:        "DEFAULT",
+		UseTemplate: true,
+		Response: `{"id": {{.Request.Query.id}}, "name": "{{upper .Request.Body.name}}", "total": {{mul .Request.Body.qty 2.5}}, ` +
+			`"user": "{{index .Request.Headers "X-User"}}", "value": "{{index .Request.Values "name"}}", "method": "{{.Request.Method}}", ` +
+			`"status": {{.StatusCode}}, "token": "{{store "token"}}", "missing": {{toJson (store "missing")}}, "all": {{toJson .Store}}, ` +
+			`"b64": "{{b64enc "hi"}}", "dec": "{{b64dec "aGk="}}", "parsed": {{toJson (fromJson "[1, 2]")}}}`,
+		ResponseHeader: `{"X-Order": "{{.Request.Query.id}}", "Link": ["<a>", "<{{store "token"}}>"]}`,
+	}
+	apiCall.ApplyResponseTemplate(response)
+
+	body := make(map[string]any)
+	if err := json.Unmarshal([]byte(apiCall.FinalResponseString), &body); err != nil {
+		t.Fatalf("invalid body %s", apiCall.FinalResponseString)
+	}
+	expected := map[string]any{
+		"id": 7.0, "name": "ANN", "total": 5.0, "user": "u1", "value": "ann", "method": "POST",
+		"status": 201.0, "token": "abc", "missing": nil, "all": map[string]any{"token": "abc"},
+		"b64": "aGk=", "dec": "hi", "parsed": []any{1.0, 2.0},
+	}
+	if !reflect.DeepEqual(body, expected) {
+		t.Errorf("expected %v but got %v", expected, body)
+	}
+
+	expectedHeader := map[string]string{"X-Kept": "yes", "X-Order": "7", "Link[0]": "<a>", "Link[1]": "<abc>"}
+	if !reflect.DeepEqual(apiCall.FinalResponseHeader, expectedHeader) {
+		t.Errorf("expected header %v but got %v", expectedHeader, apiCall.FinalResponseHeader)
+	}
+	if apiCall.StatusCode != 201 {
+		t.Errorf("expected status 201 but got %d", apiCall.StatusCode)
+	}
+}
+
+func Test_ApiCall_ApplyResponseTemplate_SoapFault(t *testing.T) {
+	tests := []struct {
+		version  string
+		expected string
+	}{
+		{"", SoapFault(Soap11, "Client", "bad & wrong")},
+		{Soap11, SoapFault(Soap11, "Client", "bad & wrong")},
+		{Soap12, SoapFault(Soap12, "Client", "bad & wrong")},
+	}
+
+	for _, test := range tests {
+		apiCall := newTemplateCall(t)
+		apiCall.CurrentEndPoint.SoapVersion = test.version
+		apiCall.ApplyResponseTemplate(&EndPointResponse{UseTemplate: true, Response: `{{soapFault "Client" "bad & wrong"}}`})
+
+		if apiCall.FinalResponseString != test.expected {
+			t.Errorf("%q: expected %s but got %s", test.version, test.expected, apiCall.FinalResponseString)
+		}
+	}
+	if !strings.Contains(SoapFault(Soap12, "Client", "bad & wrong"), "soap:Sender") {
+		t.Errorf("expected the SOAP 1.2 code for Client")
+	}
+}
+
+// a template that fails is a 500 with the error, and logged
+func Test_ApiCall_ApplyResponseTemplate_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		message  string
+	}{
+		{"parse", `{"id": {{.Request.Query.id}`, "bad character"},
+		{"unknown func", `{{nope 1}}`, `function "nope" not defined`},
+		{"division", `{{div 1 0}}`, "division by zero"},
+		{"base64", `{{b64dec "!!"}}`, "illegal base64"},
+		{"json", `{{fromJson "{"}}`, "unexpected end of JSON"},
+	}
+
+	for _, test := range tests {
+		apiCall := newTemplateCall(t)
+		apiCall.FinalResponseType = "XML"
+		apiCall.ApplyResponseTemplate(&EndPointResponse{UseTemplate: true, Response: test.template, ResponseHeader: `{"X-Order": "1"}`})
+
+		if apiCall.StatusCode != 500 || apiCall.GetContentType() != "application/json" {
+			t.Errorf("%s: expected a JSON 500 but got %d %s", test.name, apiCall.StatusCode, apiCall.GetContentType())
+		}
+		body := make(map[string]string)
+		json.Unmarshal([]byte(apiCall.FinalResponseString), &body)
+		if !strings.HasPrefix(body["error"], "response template error: ") || !strings.Contains(body["error"], test.message) {
+			t.Errorf("%s: expected the error in the body but got %s", test.name, apiCall.FinalResponseString)
+		}
+		if !strings.Contains(strings.Join(apiCall.Log, ""), "Response template error: ") {
+			t.Errorf("%s: expected the error in the log but got %v", test.name, apiCall.Log)
+		}
+		// the header template is not used
+		if _, found := apiCall.FinalResponseHeader["X-Order"]; found {
+			t.Errorf("%s: expected no template header but got %v", test.name, apiCall.FinalResponseHeader)
+		}
+	}
+}
+
+// a header template that fails is logged, the body is kept
+func Test_ApiCall_ApplyResponseTemplate_HeaderErrors(t *testing.T) {
+	for _, header := range []string{`{"X-Order": {{div 1 0}}}`, `{"X-Order": }`, `[1, 2]`} {
+		apiCall := newTemplateCall(t)
+		apiCall.ApplyResponseTemplate(&EndPointResponse{UseTemplate: true, Response: `ok {{.Request.Query.id}}`, ResponseHeader: header})
+
+		if apiCall.StatusCode != 201 || apiCall.FinalResponseString != "ok 7" {
+			t.Errorf("%s: expected the body with 201 but got %d %s", header, apiCall.StatusCode, apiCall.FinalResponseString)
+		}
+		if !strings.Contains(strings.Join(apiCall.Log, ""), "Response header template error: ") {
+			t.Errorf("%s: expected the error in the log but got %v", header, apiCall.Log)
+		}
+	}
+}
+
+func Test_ApiCall_ApplyResponseTemplate_NotUsed(t *testing.T) {
+	for _, response := range []*EndPointResponse{
+		nil,
+		{Response: `{{div 1 0}}`},
+		{UseTemplate: true, ResponseType: ResponseTypeBase64, Response: `{{div 1 0}}`},
+	} {
+		apiCall := newTemplateCall(t)
+		apiCall.FinalResponseString = "as it is"
+		apiCall.ApplyResponseTemplate(response)
+
+		if apiCall.StatusCode != 201 || apiCall.FinalResponseString != "as it is" {
+			t.Errorf("%+v: expected the response untouched but got %d %s", response, apiCall.StatusCode, apiCall.FinalResponseString)
+		}
+	}
+}
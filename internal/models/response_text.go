@@ -0,0 +1,196 @@
+package models
+
+import (
+	"fmt"
+	"mime"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/onlysumitg/GoMockAPI/utils/xmlutils"
+)
+
+// text response types, placeholders are marked in the sample:
+//
+//	Hello {{name}}, your order {{orderId=1001}} is ready
+//
+// FORM samples are a=1&b=x, every field is a param
+const (
+	ResponseTypeText = "TEXT"
+	ResponseTypeHtml = "HTML"
+	ResponseTypeCsv  = "CSV"
+	ResponseTypeForm = "FORM"
+)
+
+// param datatypes for the text types
+const (
+	TextStringDatatype = "TEXTSTRING" // as it is
+	CsvStringDatatype  = "CSVSTRING"  // quoted when it has a comma, quote or new line
+	FormStringDatatype = "FORMSTRING" // url encoded
+)
+
+var responseContentTypes = map[string]string{
+	"JSON":           "application/json",
+	"XML":            "application/xml",
+	SoapType:         "text/xml; charset=utf-8",
+	GraphqlType:      "application/json",
+	ResponseTypeText: "text/plain; charset=utf-8",
+	ResponseTypeHtml: "text/html; charset=utf-8",
+	ResponseTypeCsv:  "text/csv; charset=utf-8",
+	ResponseTypeForm: "application/x-www-form-urlencoded",
+	ResponseTypeSSE:  "text/event-stream",
+}
+
+var textVariableRegex = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_\-.\[\]]+)\s*(?:=([^}]*))?\}\}`)
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func IsTextResponseType(responseType string) bool {
+	switch strings.ToUpper(responseType) {
+	case ResponseTypeText, ResponseTypeHtml, ResponseTypeCsv, ResponseTypeForm, ResponseTypeSSE:
+		return true
+	}
+	return false
+}
+
+// -----------------------------------------------------------------
+// override first, then by response type
+// -----------------------------------------------------------------
+func ResponseContentType(responseType string, override string) string {
+	if strings.TrimSpace(override) != "" {
+		return strings.TrimSpace(override)
+	}
+
+	contentType, found := responseContentTypes[strings.ToUpper(responseType)]
+	if found {
+		return contentType
+	}
+	return fmt.Sprintf("application/%s", strings.ToLower(responseType))
+}
+
+// -----------------------------------------------------------------
+// error and resource bodies are JSON, a JSON content type override
+// like application/vnd.api+json is kept
+// -----------------------------------------------------------------
+func (a *ApiCall) UseJSONResponseType() {
+	if !strings.EqualFold(a.FinalResponseType, "JSON") || a.FinalContentType == "" {
+		a.FinalContentType = ResponseContentType("JSON", "")
+	}
+	a.FinalResponseType = "JSON"
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (a *ApiCall) GetContentType() string {
+	if a.FinalContentType != "" {
+		return a.FinalContentType
+	}
+	return ResponseContentType(a.FinalResponseType, "")
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func ValidateContentType(contentType string) error {
+	if strings.TrimSpace(contentType) == "" {
+		return nil
+	}
+	_, _, err := mime.ParseMediaType(contentType)
+	return err
+}
+
+// -----------------------------------------------------------------
+// {{name=default}} ==> "{{name}}"
+// -----------------------------------------------------------------
+func TextToFlatMapAndPlaceholder(text string, datatype string) (map[string]xmlutils.ValueDatatype, string) {
+	flatMap := make(map[string]xmlutils.ValueDatatype)
+
+	placeholder := textVariableRegex.ReplaceAllStringFunc(text, func(match string) string {
+		matches := textVariableRegex.FindStringSubmatch(match)
+		key := matches[1]
+
+		// first default wins when a variable is used more than once
+		_, found := flatMap[key]
+		if !found || flatMap[key].Value == "" {
+			flatMap[key] = xmlutils.ValueDatatype{Value: strings.TrimSpace(matches[2]), DataType: datatype}
+		}
+		return fmt.Sprintf("\"{{%s}}\"", key)
+	})
+
+	return flatMap, placeholder
+}
+
+// -----------------------------------------------------------------
+// a=1&b=x&a=2 ==> a, b, a[1]. field order is kept
+// -----------------------------------------------------------------
+func FormToFlatMapAndPlaceholder(text string) (map[string]xmlutils.ValueDatatype, string, error) {
+	flatMap := make(map[string]xmlutils.ValueDatatype)
+	counts := make(map[string]int)
+	fields := make([]string, 0)
+
+	for _, field := range strings.Split(strings.TrimSpace(text), "&") {
+		if field == "" {
+			continue
+		}
+		rawKey, rawValue, _ := strings.Cut(field, "=")
+
+		key, err := url.QueryUnescape(rawKey)
+		if err != nil {
+			return nil, "", err
+		}
+		value, err := url.QueryUnescape(rawValue)
+		if err != nil {
+			return nil, "", err
+		}
+
+		paramKey := key
+		if counts[key] > 0 {
+			paramKey = fmt.Sprintf("%s[%d]", key, counts[key])
+		}
+		counts[key]++
+
+		flatMap[paramKey] = xmlutils.ValueDatatype{Value: value, DataType: FormStringDatatype}
+		fields = append(fields, fmt.Sprintf("%s=\"{{%s}}\"", rawKey, paramKey))
+	}
+
+	return flatMap, strings.Join(fields, "&"), nil
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func ValidateFormResponse(text string) error {
+	_, err := url.ParseQuery(strings.TrimSpace(text))
+	return err
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func textResponseToFlatMapAndPlaceholder(responseType string, text string) (map[string]xmlutils.ValueDatatype, string, error) {
+	switch strings.ToUpper(responseType) {
+	case ResponseTypeForm:
+		return FormToFlatMapAndPlaceholder(text)
+	case ResponseTypeCsv:
+		flatMap, placeholder := TextToFlatMapAndPlaceholder(text, CsvStringDatatype)
+		return flatMap, placeholder, nil
+	case ResponseTypeSSE:
+		flatMap, placeholder := SSEToFlatMapAndPlaceholder(text)
+		return flatMap, placeholder, nil
+	}
+
+	flatMap, placeholder := TextToFlatMapAndPlaceholder(text, TextStringDatatype)
+	return flatMap, placeholder, nil
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func csvField(value string) string {
+	if !strings.ContainsAny(value, ",\"\r\n") {
+		return value
+	}
+	return "\"" + strings.ReplaceAll(value, "\"", "\"\"") + "\""
+}
@@ -0,0 +1,75 @@
+package models
+
+import "testing"
+
+func Test_ResponseContentType(t *testing.T) {
+	tests := []struct {
+		responseType string
+		override     string
+		expected     string
+	}{
+		{"TEXT", "", "text/plain; charset=utf-8"},
+		{"text", "", "text/plain; charset=utf-8"},
+		{"HTML", "", "text/html; charset=utf-8"},
+		{"CSV", "", "text/csv; charset=utf-8"},
+		{"FORM", "", "application/x-www-form-urlencoded"},
+		{"JSON", "", "application/json"},
+		{"XML", "", "application/xml"},
+		{"YAML", "", "application/yaml"},
+		{"TEXT", "text/markdown", "text/markdown"},
+		{"HTML", " application/xhtml+xml ", "application/xhtml+xml"},
+		{"CSV", "text/csv; header=present", "text/csv; header=present"},
+		{"FORM", "   ", "application/x-www-form-urlencoded"},
+		{"JSON", "application/vnd.api+json", "application/vnd.api+json"},
+	}
+
+	for _, test := range tests {
+		if got := ResponseContentType(test.responseType, test.override); got != test.expected {
+			t.Errorf("%s %q: expected %s but got %s", test.responseType, test.override, test.expected, got)
+		}
+	}
+}
+
+func Test_ApiCall_GetContentType(t *testing.T) {
+	tests := []struct {
+		name         string
+		responseType string
+		contentType  string
+		expected     string
+		json         string
+	}{
+		{"text default", "TEXT", "", "text/plain; charset=utf-8", "application/json"},
+		{"csv override", "CSV", "text/tab-separated-values", "text/tab-separated-values", "application/json"},
+		{"form default", "FORM", "", "application/x-www-form-urlencoded", "application/json"},
+		// a JSON override is kept for error bodies
+		{"json override", "JSON", "application/problem+json", "application/problem+json", "application/problem+json"},
+	}
+
+	for _, test := range tests {
+		a := &ApiCall{FinalResponseType: test.responseType}
+		if test.contentType != "" {
+			a.FinalContentType = ResponseContentType(test.responseType, test.contentType)
+		}
+		if got := a.GetContentType(); got != test.expected {
+			t.Errorf("%s: expected %s but got %s", test.name, test.expected, got)
+		}
+
+		a.UseJSONResponseType()
+		if got := a.GetContentType(); got != test.json || a.FinalResponseType != "JSON" {
+			t.Errorf("%s: expected JSON %s but got %s %s", test.name, test.json, a.FinalResponseType, got)
+		}
+	}
+}
+
+func Test_ValidateContentType(t *testing.T) {
+	for _, contentType := range []string{"", " ", "text/plain", "text/csv; charset=utf-8", "application/vnd.api+json"} {
+		if err := ValidateContentType(contentType); err != nil {
+			t.Errorf("%q: unexpected error %s", contentType, err.Error())
+		}
+	}
+	for _, contentType := range []string{"text/", "plain text", "text/plain; charset"} {
+		if err := ValidateContentType(contentType); err == nil {
+			t.Errorf("%q: expected an error", contentType)
+		}
+	}
+}
@@ -0,0 +1,370 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/onlysumitg/GoMockAPI/internal/validator"
+	"github.com/onlysumitg/GoMockAPI/utils/stringutils"
+	bolt "go.etcd.io/bbolt"
+)
+
+const DefaultScenarioState = "STARTED"
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+// Scenario is a named state machine shared by the endpoints of a collection.
+// Condition groups can require a state and move the scenario to a new one.
+type Scenario struct {
+	ID           string `json:"id" db:"id" form:"id"`
+	CollectionID string `json:"collectionid" db:"collectionid" form:"collectionid"`
+
+	Name string `json:"name" db:"name" form:"name"`
+	Desc string `json:"desc" db:"desc" form:"desc"`
+
+	InitialState string `json:"initialstate" db:"initialstate" form:"initialstate"`
+	CurrentState string `json:"currentstate" db:"currentstate" form:"-"`
+
+	UpdatedOn time.Time `json:"updatedon" db:"updatedon" form:"-"`
+
+	validator.Validator `json:"-" db:"-" form:"-"`
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func NormalizeScenarioState(state string) string {
+	return strings.ToUpper(strings.TrimSpace(state))
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+type ScenarioModel struct {
+	DB *bolt.DB
+}
+
+func (m *ScenarioModel) getTableName() []byte {
+	return []byte("scenarios")
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (m *ScenarioModel) Save(u *Scenario) error {
+	if u.ID == "" {
+		u.ID = uuid.NewString()
+	}
+
+	u.Name = stringutils.RemoveSpecialChars(stringutils.RemoveMultipleSpaces(strings.ToUpper(strings.TrimSpace(u.Name))))
+
+	u.InitialState = NormalizeScenarioState(u.InitialState)
+	if u.InitialState == "" {
+		u.InitialState = DefaultScenarioState
+	}
+
+	if u.CurrentState == "" {
+		u.CurrentState = u.InitialState
+		u.UpdatedOn = time.Now().Local()
+	}
+
+	return m.put(u)
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (m *ScenarioModel) put(u *Scenario) error {
+	err := m.DB.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(m.getTableName())
+		if err != nil {
+			return err
+		}
+
+		buf, err := json.Marshal(u)
+		if err != nil {
+			return err
+		}
+
+		key := strings.ToUpper(u.ID)
+
+		return bucket.Put([]byte(key), buf)
+	})
+
+	return err
+}
+
+// -----------------------------------------------------------------
+// condition groups using the scenario drop their state requirement
+// and transition, so they are not skipped as "Scenario not found"
+// -----------------------------------------------------------------
+func (m *ScenarioModel) Delete(id string) error {
+
+	err := m.DB.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(m.getTableName())
+		if err != nil {
+			return err
+		}
+		key := strings.ToUpper(id)
+		dbDeleteError := bucket.Delete([]byte(key))
+		if dbDeleteError != nil {
+			return dbDeleteError
+		}
+
+		return clearScenarioReferences(tx, id)
+	})
+
+	return err
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func clearScenarioReferences(tx *bolt.Tx, id string) error {
+	bucket := tx.Bucket((&ConditionGroupModel{}).getTableName())
+	if bucket == nil {
+		return nil
+	}
+
+	updated := make(map[string][]byte)
+	err := bucket.ForEach(func(k, v []byte) error {
+		cg := ConditionGroup{}
+		if json.Unmarshal(v, &cg) != nil || !strings.EqualFold(cg.ScenarioID, id) {
+			return nil
+		}
+
+		cg.ScenarioID = ""
+		cg.RequiredState = ""
+		cg.NewState = ""
+
+		buf, err := json.Marshal(cg)
+		if err != nil {
+			return err
+		}
+		updated[string(k)] = buf
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// keys are not changed while ForEach runs
+	for k, buf := range updated {
+		err = bucket.Put([]byte(k), buf)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (m *ScenarioModel) Get(id string) (*Scenario, error) {
+
+	if id == "" {
+		return nil, errors.New("blank id not allowed")
+	}
+	var scenarioJSON []byte
+
+	err := m.DB.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(m.getTableName())
+		if bucket == nil {
+			return errors.New("table does not exits")
+		}
+		scenarioJSON = bucket.Get([]byte(strings.ToUpper(id)))
+
+		return nil
+
+	})
+	scenario := Scenario{}
+	if err != nil {
+		return &scenario, err
+	}
+
+	if scenarioJSON != nil {
+		err := json.Unmarshal(scenarioJSON, &scenario)
+		return &scenario, err
+	}
+
+	return &scenario, ErrNotFound
+
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (m *ScenarioModel) List() []*Scenario {
+	scenarios := make([]*Scenario, 0)
+	_ = m.DB.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(m.getTableName())
+		if bucket == nil {
+			return errors.New("table does not exits")
+		}
+		c := bucket.Cursor()
+
+		for k, v := c.First(); k != nil; k, v = c.Next() {
+
+			scenario := Scenario{}
+			err := json.Unmarshal(v, &scenario)
+			if err == nil {
+				scenarios = append(scenarios, &scenario)
+			}
+		}
+
+		return nil
+	})
+
+	sort.Slice(scenarios, func(i, j int) bool {
+		return scenarios[i].Name < scenarios[j].Name
+	})
+
+	return scenarios
+
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (m *ScenarioModel) ListByCollectionID(collectionID string) []*Scenario {
+	scenarios := make([]*Scenario, 0)
+	for _, s := range m.List() {
+		if strings.EqualFold(s.CollectionID, collectionID) {
+			scenarios = append(scenarios, s)
+		}
+	}
+	return scenarios
+}
+
+// -----------------------------------------------------------------
+// scenarios a condition group of the collection can use: its own and
+// the global ones (blank collection)
+// -----------------------------------------------------------------
+func (m *ScenarioModel) ListAvailable(collectionID string) []*Scenario {
+	scenarios := make([]*Scenario, 0)
+	for _, s := range m.List() {
+		if s.CollectionID == "" || strings.EqualFold(s.CollectionID, collectionID) {
+			scenarios = append(scenarios, s)
+		}
+	}
+	return scenarios
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (m *ScenarioModel) DuplicateName(scenarioToCheck *Scenario) bool {
+	exists := false
+	for _, s := range m.ListByCollectionID(scenarioToCheck.CollectionID) {
+		if strings.EqualFold(s.Name, scenarioToCheck.Name) && !strings.EqualFold(s.ID, scenarioToCheck.ID) {
+			exists = true
+			break
+		}
+	}
+
+	return exists
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (m *ScenarioModel) GetState(id string) (string, error) {
+	scenario, err := m.Get(id)
+	if err != nil {
+		return "", err
+	}
+	return scenario.CurrentState, nil
+}
+
+// -----------------------------------------------------------------
+// read and write in one transaction so parallel calls do not lose updates
+// -----------------------------------------------------------------
+func (m *ScenarioModel) SetState(id string, state string) error {
+	_, err := m.CompareAndSetState(id, "", state)
+	return err
+}
+
+// -----------------------------------------------------------------
+// moves the scenario to state only if it is still in expected (blank:
+// any state), checked and written in one transaction; false when the
+// scenario was moved by another call in the meantime
+// -----------------------------------------------------------------
+func (m *ScenarioModel) CompareAndSetState(id string, expected string, state string) (bool, error) {
+	if id == "" {
+		return false, errors.New("blank id not allowed")
+	}
+
+	changed := false
+	err := m.DB.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(m.getTableName())
+		if err != nil {
+			return err
+		}
+
+		key := []byte(strings.ToUpper(id))
+		scenarioJSON := bucket.Get(key)
+		if scenarioJSON == nil {
+			return ErrNotFound
+		}
+
+		scenario := Scenario{}
+		err = json.Unmarshal(scenarioJSON, &scenario)
+		if err != nil {
+			return err
+		}
+
+		if expected != "" && !strings.EqualFold(scenario.CurrentState, NormalizeScenarioState(expected)) {
+			return nil
+		}
+
+		scenario.CurrentState = NormalizeScenarioState(state)
+		scenario.UpdatedOn = time.Now().Local()
+
+		buf, err := json.Marshal(scenario)
+		if err != nil {
+			return err
+		}
+
+		changed = true
+		return bucket.Put(key, buf)
+	})
+
+	return changed, err
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (m *ScenarioModel) Reset(id string) error {
+	scenario, err := m.Get(id)
+	if err != nil {
+		return err
+	}
+	return m.SetState(scenario.ID, scenario.InitialState)
+}
+
+// -----------------------------------------------------------------
+// blank collection id resets every scenario
+// -----------------------------------------------------------------
+func (m *ScenarioModel) ResetAll(collectionID string) {
+	for _, s := range m.List() {
+		if collectionID == "" || strings.EqualFold(s.CollectionID, collectionID) {
+			m.SetState(s.ID, s.InitialState)
+		}
+	}
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (m *ScenarioModel) ClearCollectionData(collectionID string) {
+	for _, s := range m.ListByCollectionID(collectionID) {
+		m.Delete(s.ID)
+	}
+}
@@ -0,0 +1,103 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+func Test_ScenarioModel_CompareAndSetState(t *testing.T) {
+	db, err := bolt.Open(filepath.Join(t.TempDir(), "data.db"), 0600, nil)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	defer db.Close()
+
+	m := &ScenarioModel{DB: db}
+	scenario := &Scenario{CollectionID: "C1", Name: "checkout"}
+	if err := m.Save(scenario); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		expected string
+		state    string
+		changed  bool
+		final    string
+	}{
+		{"from the current state", " started ", "cart", true, "CART"},
+		{"from another state", "STARTED", "PAID", false, "CART"},
+		{"from any state", "", "paid", true, "PAID"},
+	}
+
+	for _, test := range tests {
+		changed, err := m.CompareAndSetState(scenario.ID, test.expected, test.state)
+		if err != nil {
+			t.Errorf("%s: unexpected error %s", test.name, err.Error())
+		}
+		if changed != test.changed {
+			t.Errorf("%s: expected changed %t but got %t", test.name, test.changed, changed)
+		}
+		if state, _ := m.GetState(scenario.ID); state != test.final {
+			t.Errorf("%s: expected state %s but got %s", test.name, test.final, state)
+		}
+	}
+
+	if _, err := m.CompareAndSetState("missing", "", "PAID"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound but got %v", err)
+	}
+	if _, err := m.CompareAndSetState("", "", "PAID"); err == nil {
+		t.Errorf("expected an error for a blank id")
+	}
+}
+
+// calls racing from the same state: only one of them moves the scenario
+func Test_ScenarioModel_CompareAndSetState_Race(t *testing.T) {
+	db, err := bolt.Open(filepath.Join(t.TempDir(), "data.db"), 0600, nil)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	defer db.Close()
+
+	m := &ScenarioModel{DB: db}
+	scenario := &Scenario{CollectionID: "C1", Name: "checkout"}
+	if err := m.Save(scenario); err != nil {
+		t.Fatal(err)
+	}
+
+	const calls = 20
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	winners := make([]string, 0)
+
+	for i := 0; i < calls; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			state := fmt.Sprintf("PAID_%d", i)
+			changed, err := m.CompareAndSetState(scenario.ID, DefaultScenarioState, state)
+			if err != nil {
+				t.Errorf("unexpected error %s", err.Error())
+				return
+			}
+			if changed {
+				mutex.Lock()
+				winners = append(winners, state)
+				mutex.Unlock()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if len(winners) != 1 {
+		t.Fatalf("expected one transition but got %v", winners)
+	}
+	if state, _ := m.GetState(scenario.ID); state != winners[0] {
+		t.Errorf("expected state %s but got %s", winners[0], state)
+	}
+}
@@ -0,0 +1,240 @@
+package models
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// SOAP is a request type and a response type. Requests and responses are
+// XML with keys made of local names (Envelope.Body.GetQuote.symbol), so
+// conditions do not depend on the prefixes a client uses. SOAP responses
+// without an envelope are wrapped in one.
+const (
+	SoapType = "SOAP"
+
+	Soap11 = "1.1"
+	Soap12 = "1.2"
+
+	Soap11Namespace = "http://schemas.xmlsoap.org/soap/envelope/"
+	Soap12Namespace = "http://www.w3.org/2003/05/soap-envelope"
+
+	// first element in the body of a SOAP request
+	SoapOperationKey = "*SOAP_OPERATION"
+)
+
+var soapNamespaces = map[string]string{
+	Soap11: Soap11Namespace,
+	Soap12: Soap12Namespace,
+}
+
+var soapContentTypes = map[string]string{
+	Soap11: "text/xml; charset=utf-8",
+	Soap12: "application/soap+xml; charset=utf-8",
+}
+
+// SOAP 1.1 and 1.2 name the same fault codes differently
+var soapFaultCodes = map[string]map[string]string{
+	Soap11: {"SENDER": "Client", "RECEIVER": "Server", "CLIENT": "Client", "SERVER": "Server"},
+	Soap12: {"SENDER": "Sender", "RECEIVER": "Receiver", "CLIENT": "Sender", "SERVER": "Receiver"},
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+type SoapEnvelope struct {
+	Version string
+
+	// first element in the body
+	Operation          string
+	OperationNamespace string
+}
+
+// -----------------------------------------------------------------
+// version and operation of a SOAP 1.1 or 1.2 envelope
+// -----------------------------------------------------------------
+func ParseSoapEnvelope(body string) (*SoapEnvelope, error) {
+	decoder := xml.NewDecoder(strings.NewReader(body))
+
+	envelope := &SoapEnvelope{}
+	depth := 0
+	inBody := false
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			depth++
+			switch {
+			case depth == 1:
+				if t.Name.Local != "Envelope" {
+					return nil, errors.New("not a SOAP envelope")
+				}
+				for version, namespace := range soapNamespaces {
+					if t.Name.Space == namespace {
+						envelope.Version = version
+					}
+				}
+				if envelope.Version == "" {
+					return nil, fmt.Errorf("unknown SOAP envelope namespace %s", t.Name.Space)
+				}
+			case depth == 2 && t.Name.Local == "Body":
+				inBody = true
+			case depth == 3 && inBody:
+				envelope.Operation = t.Name.Local
+				envelope.OperationNamespace = t.Name.Space
+				return envelope, nil
+			}
+		case xml.EndElement:
+			depth--
+			inBody = inBody && depth >= 2
+		}
+	}
+
+	if envelope.Version == "" {
+		return nil, errors.New("not a SOAP envelope")
+	}
+	return envelope, nil
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func IsValidSoapVersion(version string) bool {
+	_, found := soapNamespaces[version]
+	return found
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func SoapContentType(version string) string {
+	contentType, found := soapContentTypes[version]
+	if !found {
+		return soapContentTypes[Soap11]
+	}
+	return contentType
+}
+
+// -----------------------------------------------------------------
+// SOAPAction header for 1.1, action parameter of the content type for 1.2
+// -----------------------------------------------------------------
+func SoapAction(r *http.Request) string {
+	action := strings.Trim(strings.TrimSpace(r.Header.Get("SOAPAction")), `"`)
+	if action != "" {
+		return action
+	}
+
+	_, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return ""
+	}
+	return strings.Trim(strings.TrimSpace(params["action"]), `"`)
+}
+
+// -----------------------------------------------------------------
+// body without an envelope is put in one
+// -----------------------------------------------------------------
+func WrapSoapEnvelope(version string, body string) string {
+	if _, err := ParseSoapEnvelope(body); err == nil {
+		return body
+	}
+
+	namespace, found := soapNamespaces[version]
+	if !found {
+		namespace = Soap11Namespace
+	}
+
+	body = strings.TrimSpace(body)
+	if strings.HasPrefix(body, "<?xml") {
+		if end := strings.Index(body, "?>"); end > 0 {
+			body = strings.TrimSpace(body[end+2:])
+		}
+	}
+
+	return fmt.Sprintf("<?xml version=\"1.0\" encoding=\"utf-8\"?>\n<soap:Envelope xmlns:soap=\"%s\">\n<soap:Body>\n%s\n</soap:Body>\n</soap:Envelope>", namespace, body)
+}
+
+// -----------------------------------------------------------------
+// Fault element for the body of an envelope that binds the soap prefix.
+// Client/Server and Sender/Receiver are translated for the version,
+// other codes are used as they are
+// -----------------------------------------------------------------
+func SoapFault(version string, code string, reason string) string {
+	if !IsValidSoapVersion(version) {
+		version = Soap11
+	}
+
+	if c, found := soapFaultCodes[version][strings.ToUpper(strings.TrimSpace(code))]; found {
+		code = c
+	}
+
+	var escaped bytes.Buffer
+	xml.EscapeText(&escaped, []byte(reason))
+
+	if version == Soap12 {
+		return fmt.Sprintf("<soap:Fault>\n<soap:Code>\n<soap:Value>soap:%s</soap:Value>\n</soap:Code>\n<soap:Reason>\n<soap:Text xml:lang=\"en\">%s</soap:Text>\n</soap:Reason>\n</soap:Fault>", code, escaped.String())
+	}
+	return fmt.Sprintf("<soap:Fault>\n<faultcode>soap:%s</faultcode>\n<faultstring>%s</faultstring>\n</soap:Fault>", code, escaped.String())
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (e *EndPoint) IsSoap() bool {
+	return strings.EqualFold(e.SampleRequestType, SoapType)
+}
+
+// -----------------------------------------------------------------
+// operation endpoints share the url of their service
+// -----------------------------------------------------------------
+func (e *EndPoint) IsSoapOperationOf(collection string, service string) bool {
+	return e.IsSoap() &&
+		strings.EqualFold(e.Method, http.MethodPost) &&
+		strings.EqualFold(e.CollectionName, collection) &&
+		e.SoapService != "" &&
+		strings.EqualFold(e.SoapService, service)
+}
+
+// -----------------------------------------------------------------
+// endpoint setting first, then the version the client used
+// -----------------------------------------------------------------
+func (a *ApiCall) SoapVersion() string {
+	if a.CurrentEndPoint != nil && IsValidSoapVersion(a.CurrentEndPoint.SoapVersion) {
+		return a.CurrentEndPoint.SoapVersion
+	}
+
+	envelope, err := ParseSoapEnvelope(string(a.RequestBody()))
+	if err == nil {
+		return envelope.Version
+	}
+	return Soap11
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (a *ApiCall) WrapSoapResponse(response *EndPointResponse) {
+	version := a.SoapVersion()
+
+	a.FinalResponseString = WrapSoapEnvelope(version, a.FinalResponseString)
+	if envelope, err := ParseSoapEnvelope(a.FinalResponseString); err == nil {
+		version = envelope.Version
+	}
+
+	if strings.TrimSpace(response.ContentType) == "" {
+		a.FinalContentType = SoapContentType(version)
+	}
+}
@@ -0,0 +1,157 @@
+package models
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func Test_ParseSoapEnvelope(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		version   string
+		operation string
+		errorX    string
+	}{
+		{"1.1", `<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/"><s:Header><a>1</a></s:Header><s:Body><m:GetQuote xmlns:m="urn:q"/></s:Body></s:Envelope>`, Soap11, "GetQuote", ""},
+		{"1.2", `<?xml version="1.0"?><env:Envelope xmlns:env="http://www.w3.org/2003/05/soap-envelope"><env:Body><GetQuote xmlns="urn:q"><symbol>A</symbol></GetQuote></env:Body></env:Envelope>`, Soap12, "GetQuote", ""},
+		{"empty body", `<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/"><s:Body></s:Body></s:Envelope>`, Soap11, "", ""},
+		{"header elements are not the operation", `<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/"><s:Header><Auth/></s:Header></s:Envelope>`, Soap11, "", ""},
+		{"not an envelope", `<GetQuote/>`, "", "", "not a SOAP envelope"},
+		{"unknown namespace", `<Envelope xmlns="urn:other"/>`, "", "", "unknown SOAP envelope namespace"},
+		{"empty", ``, "", "", "not a SOAP envelope"},
+		{"broken", `<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/"><s:Body>`, "", "", "EOF"},
+	}
+
+	for _, test := range tests {
+		envelope, err := ParseSoapEnvelope(test.body)
+		if test.errorX != "" {
+			if err == nil || !strings.Contains(err.Error(), test.errorX) {
+				t.Errorf("%s: expected error %q but got %v", test.name, test.errorX, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error %s", test.name, err.Error())
+			continue
+		}
+		if envelope.Version != test.version || envelope.Operation != test.operation {
+			t.Errorf("%s: expected %s %q but got %s %q", test.name, test.version, test.operation, envelope.Version, envelope.Operation)
+		}
+	}
+}
+
+func Test_SoapAction(t *testing.T) {
+	tests := []struct {
+		header   map[string]string
+		expected string
+	}{
+		{map[string]string{"SOAPAction": `"urn:GetQuote"`}, "urn:GetQuote"},
+		{map[string]string{"SOAPAction": " urn:GetQuote "}, "urn:GetQuote"},
+		{map[string]string{"Content-Type": `application/soap+xml; charset=utf-8; action="urn:GetQuote"`}, "urn:GetQuote"},
+		{map[string]string{"SOAPAction": `"urn:A"`, "Content-Type": `application/soap+xml; action="urn:B"`}, "urn:A"},
+		{map[string]string{"SOAPAction": `""`, "Content-Type": "text/xml"}, ""},
+		{map[string]string{"Content-Type": "broken;;"}, ""},
+	}
+
+	for _, test := range tests {
+		r := httptest.NewRequest(http.MethodPost, "/", nil)
+		for k, v := range test.header {
+			r.Header.Set(k, v)
+		}
+		result := SoapAction(r)
+		if result != test.expected {
+			t.Errorf("%v: expected %q but got %q", test.header, test.expected, result)
+		}
+	}
+}
+
+func Test_WrapSoapEnvelope(t *testing.T) {
+	envelope := `<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/"><s:Body><a/></s:Body></s:Envelope>`
+	if WrapSoapEnvelope(Soap12, envelope) != envelope {
+		t.Errorf("an envelope must be kept as it is")
+	}
+
+	for _, version := range []string{Soap11, Soap12, "9"} {
+		wrapped := WrapSoapEnvelope(version, "<?xml version=\"1.0\"?>\n<m:GetQuoteResponse xmlns:m=\"urn:q\"><m:price>1</m:price></m:GetQuoteResponse>")
+		parsed, err := ParseSoapEnvelope(wrapped)
+		if err != nil {
+			t.Errorf("%s: unexpected error %s in %s", version, err.Error(), wrapped)
+			continue
+		}
+
+		expected := version
+		if !IsValidSoapVersion(version) {
+			expected = Soap11
+		}
+		if parsed.Version != expected || parsed.Operation != "GetQuoteResponse" {
+			t.Errorf("%s: expected %s GetQuoteResponse but got %+v", version, expected, parsed)
+		}
+		if strings.Count(wrapped, "<?xml") != 1 {
+			t.Errorf("%s: expected one xml declaration in %s", version, wrapped)
+		}
+	}
+}
+
+func Test_SoapFault(t *testing.T) {
+	type fault11 struct {
+		Body struct {
+			Fault struct {
+				Code   string `xml:"faultcode"`
+				String string `xml:"faultstring"`
+			}
+		}
+	}
+	type fault12 struct {
+		Body struct {
+			Fault struct {
+				Code struct {
+					Value string
+				}
+				Reason struct {
+					Text string
+				}
+			}
+		}
+	}
+
+	tests := []struct {
+		version string
+		code    string
+		codeX   string
+	}{
+		{Soap11, "Server", "soap:Server"},
+		{Soap11, "sender", "soap:Client"},
+		{Soap11, "Custom.Code", "soap:Custom.Code"},
+		{Soap12, "Server", "soap:Receiver"},
+		{Soap12, "client", "soap:Sender"},
+		{"", "Server", "soap:Server"},
+	}
+
+	for _, test := range tests {
+		envelope := WrapSoapEnvelope(test.version, SoapFault(test.version, test.code, "price < 0 & more"))
+
+		code, reason := "", ""
+		var err error
+		if test.version == Soap12 {
+			f := &fault12{}
+			err = xml.Unmarshal([]byte(envelope), f)
+			code, reason = f.Body.Fault.Code.Value, f.Body.Fault.Reason.Text
+		} else {
+			f := &fault11{}
+			err = xml.Unmarshal([]byte(envelope), f)
+			code, reason = f.Body.Fault.Code, f.Body.Fault.String
+		}
+
+		if err != nil {
+			t.Errorf("%s %s: unexpected error %s in %s", test.version, test.code, err.Error(), envelope)
+			continue
+		}
+		if code != test.codeX || reason != "price < 0 & more" {
+			t.Errorf("%s %s: expected %s but got %q %q", test.version, test.code, test.codeX, code, reason)
+		}
+	}
+}
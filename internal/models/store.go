@@ -0,0 +1,255 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// prefix to read a value from the store: STORE:token
+const StoreValuePrefix = "STORE"
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+// StoreEntry is a value shared between calls of the same collection.
+type StoreEntry struct {
+	Key       string    `json:"key"`
+	Value     any       `json:"value"`
+	UpdatedOn time.Time `json:"updatedon"`
+	ExpiresOn time.Time `json:"expireson"` // zero: never expires
+}
+
+// -----------------------------------------------------------------
+// uses the virtual clock so TTLs can be tested
+// -----------------------------------------------------------------
+func (s *StoreEntry) IsExpired() bool {
+	return !s.ExpiresOn.IsZero() && !Now().Before(s.ExpiresOn)
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func IsStoreReference(value string) bool {
+	return strings.HasPrefix(strings.ToUpper(strings.TrimSpace(value)), StoreValuePrefix+":")
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func StoreReferenceKey(value string) string {
+	value = strings.TrimSpace(value)
+	return strings.TrimSpace(value[len(StoreValuePrefix)+1:])
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+type StoreModel struct {
+	DB *bolt.DB
+}
+
+func (m *StoreModel) getTableName() []byte {
+	return []byte("store")
+}
+
+// -----------------------------------------------------------------
+// blank collection is the default V1 collection
+// -----------------------------------------------------------------
+func storeScope(collectionID string) []byte {
+	if strings.TrimSpace(collectionID) == "" {
+		return []byte("V1")
+	}
+	return []byte(strings.ToUpper(collectionID))
+}
+
+// -----------------------------------------------------------------
+// ttl <= 0 keeps the value until it is deleted
+// -----------------------------------------------------------------
+func (m *StoreModel) Set(collectionID string, key string, value any, ttl time.Duration) error {
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return errors.New("blank key not allowed")
+	}
+
+	entry := StoreEntry{
+		Key:       key,
+		Value:     value,
+		UpdatedOn: Now(),
+	}
+	if ttl > 0 {
+		entry.ExpiresOn = entry.UpdatedOn.Add(ttl)
+	}
+
+	return m.DB.Update(func(tx *bolt.Tx) error {
+		table, err := tx.CreateBucketIfNotExists(m.getTableName())
+		if err != nil {
+			return err
+		}
+
+		bucket, err := table.CreateBucketIfNotExists(storeScope(collectionID))
+		if err != nil {
+			return err
+		}
+
+		buf, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+
+		return bucket.Put([]byte(strings.ToUpper(key)), buf)
+	})
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (m *StoreModel) Get(collectionID string, key string) (*StoreEntry, error) {
+	var entryJSON []byte
+
+	err := m.DB.View(func(tx *bolt.Tx) error {
+		table := tx.Bucket(m.getTableName())
+		if table == nil {
+			return ErrNotFound
+		}
+
+		bucket := table.Bucket(storeScope(collectionID))
+		if bucket == nil {
+			return ErrNotFound
+		}
+
+		entryJSON = bucket.Get([]byte(strings.ToUpper(strings.TrimSpace(key))))
+		if entryJSON == nil {
+			return ErrNotFound
+		}
+		entryJSON = append([]byte{}, entryJSON...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &StoreEntry{}
+	err = json.Unmarshal(entryJSON, entry)
+	if err != nil {
+		return nil, err
+	}
+
+	if entry.IsExpired() {
+		m.deleteExpired(collectionID, entry.Key)
+		return nil, ErrNotFound
+	}
+
+	return entry, nil
+}
+
+// -----------------------------------------------------------------
+// expired values are deleted when they are read, keys written again
+// in the meantime are kept
+// -----------------------------------------------------------------
+func (m *StoreModel) deleteExpired(collectionID string, keys ...string) error {
+	return m.DB.Update(func(tx *bolt.Tx) error {
+		table := tx.Bucket(m.getTableName())
+		if table == nil {
+			return nil
+		}
+
+		bucket := table.Bucket(storeScope(collectionID))
+		if bucket == nil {
+			return nil
+		}
+
+		for _, key := range keys {
+			dbKey := []byte(strings.ToUpper(strings.TrimSpace(key)))
+
+			entry := &StoreEntry{}
+			if err := json.Unmarshal(bucket.Get(dbKey), entry); err != nil || !entry.IsExpired() {
+				continue
+			}
+			if err := bucket.Delete(dbKey); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// -----------------------------------------------------------------
+// expired values are left out, and deleted
+// -----------------------------------------------------------------
+func (m *StoreModel) List(collectionID string) []*StoreEntry {
+	entries := make([]*StoreEntry, 0)
+	expired := make([]string, 0)
+
+	_ = m.DB.View(func(tx *bolt.Tx) error {
+		table := tx.Bucket(m.getTableName())
+		if table == nil {
+			return nil
+		}
+
+		bucket := table.Bucket(storeScope(collectionID))
+		if bucket == nil {
+			return nil
+		}
+
+		return bucket.ForEach(func(k, v []byte) error {
+			entry := &StoreEntry{}
+			if err := json.Unmarshal(v, entry); err != nil {
+				return nil
+			}
+			if entry.IsExpired() {
+				expired = append(expired, entry.Key)
+				return nil
+			}
+			entries = append(entries, entry)
+			return nil
+		})
+	})
+
+	if len(expired) > 0 {
+		m.deleteExpired(collectionID, expired...)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Key < entries[j].Key
+	})
+
+	return entries
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (m *StoreModel) Delete(collectionID string, key string) error {
+	return m.DB.Update(func(tx *bolt.Tx) error {
+		table := tx.Bucket(m.getTableName())
+		if table == nil {
+			return nil
+		}
+
+		bucket := table.Bucket(storeScope(collectionID))
+		if bucket == nil {
+			return nil
+		}
+
+		return bucket.Delete([]byte(strings.ToUpper(strings.TrimSpace(key))))
+	})
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (m *StoreModel) ClearCollectionData(collectionID string) error {
+	return m.DB.Update(func(tx *bolt.Tx) error {
+		table := tx.Bucket(m.getTableName())
+		if table == nil || table.Bucket(storeScope(collectionID)) == nil {
+			return nil
+		}
+
+		return table.DeleteBucket(storeScope(collectionID))
+	})
+}
@@ -0,0 +1,223 @@
+package models
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/onlysumitg/GoMockAPI/utils/xmlutils"
+	bolt "go.etcd.io/bbolt"
+)
+
+func newTestStoreDB(t *testing.T) *bolt.DB {
+	t.Helper()
+
+	db, err := bolt.Open(filepath.Join(t.TempDir(), "data.db"), 0600, nil)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// -----------------------------------------------------------------
+// keys in the bolt bucket, expired or not
+// -----------------------------------------------------------------
+func storedKeys(t *testing.T, db *bolt.DB, collectionID string) []string {
+	t.Helper()
+
+	keys := make([]string, 0)
+	db.View(func(tx *bolt.Tx) error {
+		table := tx.Bucket((&StoreModel{}).getTableName())
+		if table == nil || table.Bucket(storeScope(collectionID)) == nil {
+			return nil
+		}
+		return table.Bucket(storeScope(collectionID)).ForEach(func(k, v []byte) error {
+			keys = append(keys, string(k))
+			return nil
+		})
+	})
+	return keys
+}
+
+func Test_StoreModel_SetGet(t *testing.T) {
+	m := &StoreModel{DB: newTestStoreDB(t)}
+
+	if err := m.Set("", " token ", "abc", 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Set("C2", "token", map[string]any{"id": 1.0}, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Set("", " ", "x", 0); err == nil {
+		t.Errorf("expected an error for a blank key")
+	}
+
+	entry, err := m.Get("", "TOKEN")
+	if err != nil || entry.Value != "abc" || entry.Key != "token" {
+		t.Errorf("expected token abc but got %+v %v", entry, err)
+	}
+
+	// collections do not share values
+	entry, err = m.Get("c2", "token")
+	if err != nil || !reflect.DeepEqual(entry.Value, map[string]any{"id": 1.0}) {
+		t.Errorf("expected the C2 value but got %+v %v", entry, err)
+	}
+	if _, err := m.Get("C3", "token"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound but got %v", err)
+	}
+
+	if err := m.Delete("", "token"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Get("", "token"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound after delete but got %v", err)
+	}
+
+	if err := m.ClearCollectionData("C2"); err != nil {
+		t.Fatal(err)
+	}
+	if entries := m.List("C2"); len(entries) != 0 {
+		t.Errorf("expected no values after clear but got %v", entries)
+	}
+}
+
+func Test_StoreModel_TTL(t *testing.T) {
+	db := newTestStoreDB(t)
+	m := &StoreModel{DB: db}
+
+	Clock.Set(time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC), true)
+	defer Clock.Reset()
+
+	m.Set("", "short", 1, time.Minute)
+	m.Set("", "long", 2, time.Hour)
+	m.Set("", "forever", 3, 0)
+
+	Clock.Advance(59 * time.Second)
+	if _, err := m.Get("", "short"); err != nil {
+		t.Errorf("expected short before its TTL but got %v", err)
+	}
+
+	// read after the TTL: gone, and deleted from the bucket
+	Clock.Advance(time.Second)
+	if _, err := m.Get("", "short"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound after the TTL but got %v", err)
+	}
+	if keys := storedKeys(t, db, ""); !reflect.DeepEqual(keys, []string{"FOREVER", "LONG"}) {
+		t.Errorf("expected the expired key deleted but got %v", keys)
+	}
+
+	// listed after the TTL: left out and deleted
+	Clock.Advance(time.Hour)
+	entries := m.List("")
+	if len(entries) != 1 || entries[0].Key != "forever" {
+		t.Errorf("expected only forever but got %v", entries)
+	}
+	if keys := storedKeys(t, db, ""); !reflect.DeepEqual(keys, []string{"FOREVER"}) {
+		t.Errorf("expected the expired keys deleted but got %v", keys)
+	}
+
+	// written again: a fresh value is not deleted
+	m.Set("", "long", 4, time.Hour)
+	if entry, err := m.Get("", "long"); err != nil || entry.Value != 4.0 {
+		t.Errorf("expected the new value but got %+v %v", entry, err)
+	}
+}
+
+func Test_ApiCall_StoreValues(t *testing.T) {
+	db := newTestStoreDB(t)
+	m := &StoreModel{DB: db}
+	m.Set("C1", "token", "abc", 0)
+
+	apiCall := &ApiCall{
+		DataDB:          db,
+		CurrentEndPoint: &EndPoint{CollectionID: "C1"},
+		RequestFlatMap:  map[string]xmlutils.ValueDatatype{"token": {Value: "abc", DataType: "STRING"}, "user": {Value: "u1", DataType: "STRING"}},
+	}
+
+	// value expressions
+	if value, err := apiCall.ResolveValue("STORE:token"); err != nil || value != "abc" {
+		t.Errorf("expected abc but got %v %v", value, err)
+	}
+	if value, err := apiCall.ResolveValue(" store: token "); err != nil || value != "abc" {
+		t.Errorf("expected abc but got %v %v", value, err)
+	}
+	if _, err := apiCall.ResolveValue("STORE:missing"); err == nil {
+		t.Errorf("expected an error for a missing value")
+	}
+
+	// conditions compare to store values
+	tests := []struct {
+		name      string
+		key       string
+		compareto string
+		expected  bool
+	}{
+		{"equal to store", "token", "STORE:token", true},
+		{"not equal to store", "user", "STORE:token", false},
+		{"missing store value", "token", "STORE:missing", false},
+		{"not a reference", "token", "abc", true},
+	}
+	for _, test := range tests {
+		c := &Condition{
+			Operator:     "EQUALS_TO",
+			Compareto:    test.compareto,
+			RequestParam: &EndPointRequestParam{Key: test.key, DefaultDatatype: "STRING"},
+		}
+		if passed := c.HasPassed(apiCall); passed != test.expected {
+			t.Errorf("%s: expected %t but got %t", test.name, test.expected, passed)
+		}
+	}
+	if !IsStoreReference(" Store:x") || IsStoreReference("STORED:x") || IsStoreReference("x") {
+		t.Errorf("IsStoreReference does not tell references apart")
+	}
+}
+
+func Test_ConditionGroup_StoreWrites(t *testing.T) {
+	db := newTestStoreDB(t)
+	m := &StoreModel{DB: db}
+
+	Clock.Set(time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC), true)
+	defer Clock.Reset()
+
+	newCall := func() *ApiCall {
+		return &ApiCall{
+			DataDB:          db,
+			CurrentEndPoint: &EndPoint{CollectionID: "C1"},
+			RequestFlatMap:  map[string]xmlutils.ValueDatatype{"user": {Value: "u1", DataType: "STRING"}},
+		}
+	}
+	passing := &Condition{Operator: "EQUALS_TO", Compareto: "u1", RequestParam: &EndPointRequestParam{Key: "user", DefaultDatatype: "STRING"}}
+	failing := &Condition{Operator: "EQUALS_TO", Compareto: "u2", RequestParam: &EndPointRequestParam{Key: "user", DefaultDatatype: "STRING"}}
+	writes := "lastUser = REQUEST[STRING]:user\nstatus = PAID\nbad = REQUEST[STRING]:missing"
+
+	// writes alone do not make a group match
+	cg := &ConditionGroup{Name: "writes only", StoreWrites: writes}
+	if cg.Execute(newCall()) || len(m.List("C1")) != 0 {
+		t.Errorf("expected no match and no writes but got %v", m.List("C1"))
+	}
+
+	cg = &ConditionGroup{Name: "failing", Conditions: []*Condition{failing}, StoreWrites: writes}
+	if cg.Execute(newCall()) || len(m.List("C1")) != 0 {
+		t.Errorf("expected no writes for a failing group but got %v", m.List("C1"))
+	}
+
+	cg = &ConditionGroup{Name: "passing", Conditions: []*Condition{passing}, StoreWrites: writes, StoreTTL: "10m"}
+	if !cg.Execute(newCall()) {
+		t.Fatalf("expected the group to match")
+	}
+	values := make(map[string]any)
+	for _, entry := range m.List("C1") {
+		values[entry.Key] = entry.Value
+	}
+	if !reflect.DeepEqual(values, map[string]any{"lastUser": "u1", "status": "PAID"}) {
+		t.Errorf("expected lastUser and status but got %v", values)
+	}
+
+	Clock.Advance(10 * time.Minute)
+	if entries := m.List("C1"); len(entries) != 0 {
+		t.Errorf("expected the writes to expire but got %v", entries)
+	}
+}
@@ -0,0 +1,199 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	_ "time/tzdata" // time zones in the debian image and windows builds
+)
+
+// current time override values, all based on the virtual clock:
+//
+//	*NOW                                 RFC3339
+//	*NOW(+720h,RFC3339)                  offset, layout and time zone, all optional
+//	*NOW(RFC3339)  *NOW(-2d,2006-01-02 15:04,Asia/Tokyo)
+//	*TODAY(2006-01-02,America/New_York)  midnight, layout and time zone optional
+//	*EPOCH  *EPOCH_MILLI(+1h)            unix seconds and milliseconds, offset optional
+const (
+	TimeValueNow        = "*NOW"
+	TimeValueToday      = "*TODAY"
+	TimeValueEpoch      = "*EPOCH"
+	TimeValueEpochMilli = "*EPOCH_MILLI"
+)
+
+var TimeValueExamples = []string{
+	"*NOW",
+	"*NOW(+720h,RFC3339)",
+	"*NOW(-30m,2006-01-02 15:04:05,UTC)",
+	"*NOW(+1d,UNIX)",
+	"*TODAY(2006-01-02,America/New_York)",
+	"*EPOCH",
+	"*EPOCH_MILLI(+1h)",
+}
+
+// layout names besides Go layouts
+var timeLayouts = map[string]string{
+	"RFC3339":     time.RFC3339,
+	"RFC3339NANO": time.RFC3339Nano,
+	"RFC1123":     time.RFC1123,
+	"RFC1123Z":    time.RFC1123Z,
+	"RFC822":      time.RFC822,
+	"ISO8601":     "2006-01-02T15:04:05.000Z07:00",
+	"DATE":        "2006-01-02",
+	"DATETIME":    "2006-01-02 15:04:05",
+	"TIME":        "15:04:05",
+}
+
+var timeValueRegex = regexp.MustCompile(`(?s)^(\*NOW|\*TODAY|\*EPOCH_MILLI|\*EPOCH)(?:\((.*)\))?$`)
+
+var dayOffsetRegex = regexp.MustCompile(`^([+-]?\d+)d$`)
+
+// a number and a unit: 2006-01-02, 15:04 and -07:00 are layouts
+var timeOffsetRegex = regexp.MustCompile(`^[+-]?\d+(\.\d+)?[a-zµ]+`)
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func IsTimeValue(value string) bool {
+	return timeValueRegex.MatchString(strings.TrimSpace(value))
+}
+
+// -----------------------------------------------------------------
+// +720h, -30m, +2d
+// -----------------------------------------------------------------
+func parseTimeOffset(offset string) (time.Duration, error) {
+	offset = strings.TrimSpace(offset)
+	if offset == "" {
+		return 0, nil
+	}
+
+	matches := dayOffsetRegex.FindStringSubmatch(offset)
+	if len(matches) > 0 {
+		days, _ := strconv.Atoi(matches[1])
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+
+	return time.ParseDuration(strings.TrimPrefix(offset, "+"))
+}
+
+// -----------------------------------------------------------------
+// the offset is optional: the first argument is one when it looks
+// like one, otherwise all arguments are the layout and time zone
+// -----------------------------------------------------------------
+func splitTimeOffset(args string) (time.Duration, string, error) {
+	offsetString, rest, _ := strings.Cut(args, ",")
+	offsetString = strings.TrimSpace(offsetString)
+
+	if offsetString != "" && !timeOffsetRegex.MatchString(offsetString) {
+		return 0, args, nil
+	}
+
+	offset, err := parseTimeOffset(offsetString)
+	if err != nil {
+		return 0, "", fmt.Errorf("invalid time offset %s", offsetString)
+	}
+	return offset, rest, nil
+}
+
+// -----------------------------------------------------------------
+// last argument is a time zone when it loads as one
+// -----------------------------------------------------------------
+func splitLayoutAndZone(args string) (string, *time.Location, error) {
+	args = strings.TrimSpace(args)
+
+	i := strings.LastIndex(args, ",")
+	zoneName := strings.TrimSpace(args[i+1:])
+	if zoneName != "" && (strings.Contains(zoneName, "/") || strings.EqualFold(zoneName, "UTC") || strings.EqualFold(zoneName, "Local")) {
+		location, err := time.LoadLocation(zoneName)
+		if err != nil {
+			return "", nil, fmt.Errorf("unknown time zone %s", zoneName)
+		}
+		if i < 0 {
+			return "", location, nil
+		}
+		return strings.TrimSpace(args[:i]), location, nil
+	}
+
+	return args, nil, nil
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func formatTimeValue(t time.Time, layout string, defaultLayout string) string {
+	layout = strings.TrimSpace(layout)
+	if layout == "" {
+		layout = defaultLayout
+	}
+
+	switch strings.ToUpper(layout) {
+	case "UNIX":
+		return strconv.FormatInt(t.Unix(), 10)
+	case "UNIXMILLI", "UNIX_MILLI":
+		return strconv.FormatInt(t.UnixMilli(), 10)
+	}
+
+	namedLayout, found := timeLayouts[strings.ToUpper(layout)]
+	if found {
+		layout = namedLayout
+	}
+	return t.Format(layout)
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func ResolveTimeValue(value string) (string, error) {
+	matches := timeValueRegex.FindStringSubmatch(strings.TrimSpace(value))
+	if len(matches) == 0 {
+		return value, fmt.Errorf("not a time value: %s", value)
+	}
+
+	name := matches[1]
+	args := matches[2]
+	now := Now()
+
+	switch name {
+	case TimeValueEpoch, TimeValueEpochMilli:
+		offset, rest, err := splitTimeOffset(args)
+		if err != nil {
+			return value, err
+		}
+		if strings.TrimSpace(rest) != "" {
+			return value, fmt.Errorf("%s takes only an offset, like %s(+1h)", name, name)
+		}
+		if name == TimeValueEpochMilli {
+			return strconv.FormatInt(now.Add(offset).UnixMilli(), 10), nil
+		}
+		return strconv.FormatInt(now.Add(offset).Unix(), 10), nil
+
+	case TimeValueToday:
+		layout, location, err := splitLayoutAndZone(args)
+		if err != nil {
+			return value, err
+		}
+		if location != nil {
+			now = now.In(location)
+		}
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		return formatTimeValue(today, layout, "2006-01-02"), nil
+	}
+
+	// *NOW(offset,layout,zone)
+	offset, rest, err := splitTimeOffset(args)
+	if err != nil {
+		return value, err
+	}
+
+	layout, location, err := splitLayoutAndZone(rest)
+	if err != nil {
+		return value, err
+	}
+	if location != nil {
+		now = now.In(location)
+	}
+
+	return formatTimeValue(now.Add(offset), layout, time.RFC3339), nil
+}
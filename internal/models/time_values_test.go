@@ -0,0 +1,106 @@
+package models
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func Test_ResolveTimeValue(t *testing.T) {
+	now := time.Date(2024, 3, 10, 22, 30, 0, 0, time.UTC)
+	Clock.Set(now, true)
+	defer Clock.Reset()
+
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{"*NOW", "2024-03-10T22:30:00Z"},
+		{" *NOW ", "2024-03-10T22:30:00Z"},
+		{"*NOW()", "2024-03-10T22:30:00Z"},
+		{"*NOW(+720h,RFC3339)", "2024-04-09T22:30:00Z"},
+		{"*NOW(RFC3339)", "2024-03-10T22:30:00Z"},
+		{"*NOW(-30m,2006-01-02 15:04:05,UTC)", "2024-03-10 22:00:00"},
+		{"*NOW(-2d,2006-01-02 15:04,Asia/Tokyo)", "2024-03-09 07:30"},
+		{"*NOW(+1d,UNIX)", strconv.FormatInt(now.Add(24*time.Hour).Unix(), 10)},
+		{"*NOW(+1.5h,UNIXMILLI)", strconv.FormatInt(now.Add(90*time.Minute).UnixMilli(), 10)},
+		{"*NOW(DATE)", "2024-03-10"},
+		{"*NOW(15:04)", "22:30"},
+		{"*NOW(Asia/Tokyo)", "2024-03-11T07:30:00+09:00"},
+		{"*NOW(+1h,,America/New_York)", "2024-03-10T19:30:00-04:00"},
+		// layouts that start with a sign are not offsets
+		{"*NOW(-0700)", "+0000"},
+		{"*NOW(-07:00,Asia/Tokyo)", "+09:00"},
+		{"*NOW(+1h,-07:00,America/New_York)", "-04:00"},
+		{"*NOW(-2h,-0700 15:04,Asia/Tokyo)", "+0900 05:30"},
+		{"*TODAY(-07:00,America/New_York)", "-05:00"},
+		{"*TODAY", "2024-03-10"},
+		{"*TODAY(RFC3339,Asia/Tokyo)", "2024-03-11T00:00:00+09:00"},
+		{"*TODAY(2006-01-02,America/New_York)", "2024-03-10"},
+		{"*EPOCH", strconv.FormatInt(now.Unix(), 10)},
+		{"*EPOCH(-1m)", strconv.FormatInt(now.Add(-time.Minute).Unix(), 10)},
+		{"*EPOCH_MILLI", strconv.FormatInt(now.UnixMilli(), 10)},
+		{"*EPOCH_MILLI(+1h)", strconv.FormatInt(now.Add(time.Hour).UnixMilli(), 10)},
+	}
+
+	for _, test := range tests {
+		got, err := ResolveTimeValue(test.value)
+		if err != nil {
+			t.Errorf("%s: unexpected error %s", test.value, err.Error())
+			continue
+		}
+		if got != test.expected {
+			t.Errorf("%s: expected %s but got %s", test.value, test.expected, got)
+		}
+	}
+}
+
+func Test_ResolveTimeValue_Errors(t *testing.T) {
+	Clock.Set(time.Date(2024, 3, 10, 22, 30, 0, 0, time.UTC), true)
+	defer Clock.Reset()
+
+	tests := []string{
+		"*NOWISH",
+		"NOW",
+		"*NOW(+5x)",
+		"*NOW(-1d2h,DATE)",
+		"*NOW(+1h,DATE,Mars/Base)",
+		"*TODAY(DATE,Europe/Nowhere)",
+		"*EPOCH(RFC3339)",
+		"*EPOCH(+1h,UTC)",
+		"*EPOCH_MILLI(soon)",
+	}
+
+	for _, value := range tests {
+		got, err := ResolveTimeValue(value)
+		if err == nil {
+			t.Errorf("%s: expected an error but got %s", value, got)
+			continue
+		}
+		if got != value {
+			t.Errorf("%s: expected the value back with the error but got %s", value, got)
+		}
+	}
+}
+
+// a ticking virtual clock moves time values along
+func Test_ResolveTimeValue_VirtualClock(t *testing.T) {
+	Clock.Set(time.Date(2024, 12, 31, 23, 30, 0, 0, time.UTC), true)
+	defer Clock.Reset()
+
+	if got, _ := ResolveTimeValue("*TODAY"); got != "2024-12-31" {
+		t.Errorf("expected 2024-12-31 but got %s", got)
+	}
+
+	Clock.Advance(time.Hour)
+	if got, _ := ResolveTimeValue("*TODAY"); got != "2025-01-01" {
+		t.Errorf("expected 2025-01-01 after an hour but got %s", got)
+	}
+	if got, _ := ResolveTimeValue("*NOW(TIME)"); got != "00:30:00" {
+		t.Errorf("expected 00:30:00 but got %s", got)
+	}
+
+	if !IsTimeValue("*EPOCH_MILLI(+1h)") || IsTimeValue("*RANDOM:NAME") {
+		t.Errorf("IsTimeValue does not tell time values apart")
+	}
+}
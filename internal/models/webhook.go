@@ -0,0 +1,389 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	bolt "go.etcd.io/bbolt"
+)
+
+// a failing webhook is not retried more than this
+const MaxWebhookRetries = 10
+
+var webhookClient = &http.Client{Timeout: 30 * time.Second}
+
+// the webhook log keeps the latest attempts only
+var maxWebhookLogs = 1000
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+// Webhook is an outbound call made after a mocked call, e.g. the
+// callback of a payment provider. URL, headers and body are Go
+// text/templates with the data of response templates plus
+// .Response and .ResponseRaw
+type Webhook struct {
+	URL        string `json:"url" db:"url" form:"url"`
+	Method     string `json:"method" db:"method" form:"method"`
+	Headers    string `json:"headers" db:"headers" form:"headers"` // one "Name: value" per line
+	Body       string `json:"body" db:"body" form:"body"`
+	Delay      int    `json:"delay" db:"delay" form:"delay"`                // ms after the call
+	Retries    int    `json:"retries" db:"retries" form:"retries"`          // attempts after the first one
+	RetryDelay int    `json:"retrydelay" db:"retrydelay" form:"retrydelay"` // ms, doubled after every failed attempt
+}
+
+type WebhookList []*Webhook
+
+// -----------------------------------------------------------------
+// existing webhooks plus a blank row for the form
+// -----------------------------------------------------------------
+func (l WebhookList) Rows() []*Webhook {
+	rows := make([]*Webhook, 0, len(l)+1)
+	rows = append(rows, l...)
+	rows = append(rows, &Webhook{Method: http.MethodPost})
+	return rows
+}
+
+// -----------------------------------------------------------------
+// rows without a URL are dropped
+// -----------------------------------------------------------------
+func (l WebhookList) Clean() WebhookList {
+	webhooks := make(WebhookList, 0, len(l))
+	for _, w := range l {
+		if w == nil || strings.TrimSpace(w.URL) == "" {
+			continue
+		}
+		w.URL = strings.TrimSpace(w.URL)
+		w.Method = strings.ToUpper(strings.TrimSpace(w.Method))
+		if w.Method == "" {
+			w.Method = http.MethodPost
+		}
+		webhooks = append(webhooks, w)
+	}
+	return webhooks
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (l WebhookList) Validate() error {
+	for i, w := range l {
+		for name, text := range map[string]string{"url": w.URL, "headers": w.Headers, "body": w.Body} {
+			_, err := ParseResponseTemplate(name, html.UnescapeString(text))
+			if err != nil {
+				return fmt.Errorf("webhook %d %s: %s", i+1, name, err.Error())
+			}
+		}
+		if w.Delay < 0 || w.RetryDelay < 0 {
+			return fmt.Errorf("webhook %d: delays can not be negative", i+1)
+		}
+		if w.Retries < 0 || w.Retries > MaxWebhookRetries {
+			return fmt.Errorf("webhook %d: retries must be between 0 and %d", i+1, MaxWebhookRetries)
+		}
+	}
+	return nil
+}
+
+// -----------------------------------------------------------------
+// a rendered webhook, ready to send
+// -----------------------------------------------------------------
+type WebhookCall struct {
+	ID         string            `json:"id"`
+	CallID     string            `json:"callid"` // correlation id of the mocked call
+	EndPointID string            `json:"endpointid"`
+	URL        string            `json:"url"`
+	Method     string            `json:"method"`
+	Header     map[string]string `json:"header"`
+	Body       string            `json:"body"`
+	Delay      int               `json:"delay"`
+	Retries    int               `json:"retries"`
+	RetryDelay int               `json:"retrydelay"`
+	Attempt    int               `json:"attempt"`
+}
+
+// -----------------------------------------------------------------
+// wait before retrying attempt c.Attempt
+// -----------------------------------------------------------------
+func (c *WebhookCall) NextRetryDelay() time.Duration {
+	delay := time.Duration(c.RetryDelay) * time.Millisecond
+	for i := 1; i < c.Attempt && delay < time.Hour; i++ {
+		delay *= 2
+	}
+	return delay
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+type WebhookAttempt struct {
+	ID         string    `json:"id"`
+	WebhookID  string    `json:"webhookid"`
+	CallID     string    `json:"callid"`
+	EndPointID string    `json:"endpointid"`
+	URL        string    `json:"url"`
+	Method     string    `json:"method"`
+	Attempt    int       `json:"attempt"`
+	StatusCode int       `json:"statuscode"`
+	Response   string    `json:"response"`
+	Error      string    `json:"error"`
+	Duration   int64     `json:"duration"` // ms
+	SentAt     time.Time `json:"sentat"`
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (a *WebhookAttempt) Failed() bool {
+	return a.Error != "" || a.StatusCode < 200 || a.StatusCode > 299
+}
+
+// -----------------------------------------------------------------
+// any 2xx answer is a success
+// -----------------------------------------------------------------
+func (c *WebhookCall) Send() *WebhookAttempt {
+	attempt := &WebhookAttempt{
+		ID:         uuid.NewString(),
+		WebhookID:  c.ID,
+		CallID:     c.CallID,
+		EndPointID: c.EndPointID,
+		URL:        c.URL,
+		Method:     c.Method,
+		Attempt:    c.Attempt,
+		SentAt:     time.Now().Local(),
+	}
+
+	request, err := http.NewRequest(c.Method, c.URL, strings.NewReader(c.Body))
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	for k, v := range c.Header {
+		request.Header.Set(k, v)
+	}
+
+	start := time.Now()
+	response, err := webhookClient.Do(request)
+	attempt.Duration = time.Since(start).Milliseconds()
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	defer response.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+	attempt.StatusCode = response.StatusCode
+	attempt.Response = string(body)
+	return attempt
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (a *ApiCall) QueueWebhooks(webhooks WebhookList) {
+	a.PendingWebhooks = append(a.PendingWebhooks, webhooks...)
+}
+
+// -----------------------------------------------------------------
+// webhook templates also see the final response
+// -----------------------------------------------------------------
+type WebhookTemplateData struct {
+	*TemplateData
+	Response    any // parsed JSON response, nil when the response is not JSON
+	ResponseRaw string
+}
+
+// -----------------------------------------------------------------
+// renders the queued webhooks, a webhook that fails to render is skipped
+// -----------------------------------------------------------------
+func (a *ApiCall) WebhookCalls() []*WebhookCall {
+	calls := make([]*WebhookCall, 0, len(a.PendingWebhooks))
+	if len(a.PendingWebhooks) == 0 {
+		return calls
+	}
+
+	data := &WebhookTemplateData{
+		TemplateData: a.templateData(),
+		ResponseRaw:  a.FinalResponseString,
+	}
+	var parsed any
+	if json.Unmarshal([]byte(a.FinalResponseString), &parsed) == nil {
+		data.Response = parsed
+	}
+
+	endPointID := ""
+	if a.CurrentEndPoint != nil {
+		endPointID = a.CurrentEndPoint.ID
+	}
+
+	for _, w := range a.PendingWebhooks {
+		call, err := a.renderWebhook(w, data)
+		if err != nil {
+			a.LogError(fmt.Sprintf("Webhook %s skipped: %s", w.URL, err.Error()))
+			continue
+		}
+		call.CallID = a.ID
+		call.EndPointID = endPointID
+		a.LogInfo(fmt.Sprintf("Webhook %s %s in %d ms", call.Method, call.URL, call.Delay))
+		calls = append(calls, call)
+	}
+	return calls
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (a *ApiCall) renderWebhook(w *Webhook, data *WebhookTemplateData) (*WebhookCall, error) {
+	rendered := make(map[string]string)
+	for name, text := range map[string]string{"url": w.URL, "headers": w.Headers, "body": w.Body} {
+		t, err := a.renderTemplate(name, html.UnescapeString(text), data)
+		if err != nil {
+			return nil, err
+		}
+		rendered[name] = t
+	}
+
+	webhookURL := strings.TrimSpace(rendered["url"])
+	u, err := url.Parse(webhookURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid url %s", webhookURL)
+	}
+
+	header := make(map[string]string)
+	for _, line := range strings.Split(rendered["headers"], "\n") {
+		name, value, found := strings.Cut(line, ":")
+		if !found || strings.TrimSpace(name) == "" {
+			continue
+		}
+		header[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+	if _, found := header["Content-Type"]; !found && strings.TrimSpace(rendered["body"]) != "" {
+		header["Content-Type"] = "application/json"
+	}
+
+	return &WebhookCall{
+		ID:         uuid.NewString(),
+		URL:        webhookURL,
+		Method:     w.Method,
+		Header:     header,
+		Body:       rendered["body"],
+		Delay:      w.Delay,
+		Retries:    w.Retries,
+		RetryDelay: w.RetryDelay,
+		Attempt:    1,
+	}, nil
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+type WebhookLogModel struct {
+	DB *bolt.DB
+}
+
+func (m *WebhookLogModel) getTableName() []byte {
+	return []byte("webhooklogs")
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (m *WebhookLogModel) Save(attempt *WebhookAttempt) error {
+	return m.DB.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(m.getTableName())
+		if err != nil {
+			return err
+		}
+
+		buf, err := json.Marshal(attempt)
+		if err != nil {
+			return err
+		}
+
+		// time first so the cursor returns the attempts in order
+		key := fmt.Sprintf("%020d_%s", attempt.SentAt.UnixNano(), attempt.ID)
+		err = bucket.Put([]byte(key), buf)
+		if err != nil {
+			return err
+		}
+
+		return pruneWebhookLogs(bucket, maxWebhookLogs)
+	})
+}
+
+// -----------------------------------------------------------------
+// drops the oldest attempts above max
+// -----------------------------------------------------------------
+func pruneWebhookLogs(bucket *bolt.Bucket, max int) error {
+	keys := make([][]byte, 0)
+	c := bucket.Cursor()
+	for k, _ := c.First(); k != nil; k, _ = c.Next() {
+		keys = append(keys, append([]byte(nil), k...))
+	}
+
+	for i := 0; i < len(keys)-max; i++ {
+		err := bucket.Delete(keys[i])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// -----------------------------------------------------------------
+// blank call id and endpoint id list every attempt
+// -----------------------------------------------------------------
+func (m *WebhookLogModel) List(callID string, endPointID string) []*WebhookAttempt {
+	attempts := make([]*WebhookAttempt, 0)
+	_ = m.DB.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(m.getTableName())
+		if bucket == nil {
+			return errors.New("table does not exits")
+		}
+		c := bucket.Cursor()
+
+		for k, v := c.First(); k != nil; k, v = c.Next() {
+			attempt := WebhookAttempt{}
+			err := json.Unmarshal(v, &attempt)
+			if err != nil {
+				continue
+			}
+			if callID != "" && !strings.EqualFold(attempt.CallID, callID) {
+				continue
+			}
+			if endPointID != "" && !strings.EqualFold(attempt.EndPointID, endPointID) {
+				continue
+			}
+			attempts = append(attempts, &attempt)
+		}
+
+		return nil
+	})
+
+	sort.SliceStable(attempts, func(i, j int) bool {
+		return attempts[i].SentAt.Before(attempts[j].SentAt)
+	})
+
+	return attempts
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (m *WebhookLogModel) Clear() error {
+	return m.DB.Update(func(tx *bolt.Tx) error {
+		err := tx.DeleteBucket(m.getTableName())
+		if err == bolt.ErrBucketNotFound {
+			return nil
+		}
+		return err
+	})
+}
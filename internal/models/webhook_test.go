@@ -0,0 +1,182 @@
+package models
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+func Test_WebhookCall_NextRetryDelay(t *testing.T) {
+	tests := []struct {
+		retryDelay int
+		attempt    int
+		expected   time.Duration
+	}{
+		{0, 1, 0},
+		{0, 5, 0},
+		{100, 1, 100 * time.Millisecond},
+		{100, 2, 200 * time.Millisecond},
+		{100, 4, 800 * time.Millisecond},
+		{1000, 20, 4096 * time.Second}, // doubling stops past an hour
+	}
+
+	for _, test := range tests {
+		call := &WebhookCall{RetryDelay: test.retryDelay, Attempt: test.attempt}
+		result := call.NextRetryDelay()
+		if result != test.expected {
+			t.Errorf("%d ms attempt %d: expected %s but got %s", test.retryDelay, test.attempt, test.expected, result)
+		}
+	}
+}
+
+func Test_WebhookList_CleanAndValidate(t *testing.T) {
+	webhooks := WebhookList{nil, {URL: " "}, {URL: " http://x/cb ", Method: " put "}, {URL: "http://x/cb"}}.Clean()
+	if len(webhooks) != 2 {
+		t.Fatalf("expected 2 webhooks but got %d", len(webhooks))
+	}
+	if webhooks[0].URL != "http://x/cb" || webhooks[0].Method != "PUT" || webhooks[1].Method != "POST" {
+		t.Errorf("webhooks not cleaned: %+v %+v", webhooks[0], webhooks[1])
+	}
+	if err := webhooks.Validate(); err != nil {
+		t.Errorf("unexpected error %s", err.Error())
+	}
+
+	invalid := []*Webhook{
+		{URL: "http://x/{{.Request"},
+		{URL: "http://x", Body: "{{end}}"},
+		{URL: "http://x", Delay: -1},
+		{URL: "http://x", Retries: MaxWebhookRetries + 1},
+	}
+	for _, w := range invalid {
+		if (WebhookList{w}).Validate() == nil {
+			t.Errorf("%+v: expected an error", w)
+		}
+	}
+}
+
+func Test_ApiCall_WebhookCalls(t *testing.T) {
+	request := httptest.NewRequest(http.MethodPost, "/api/v1/pay?mode=test", nil)
+	apiCall := &ApiCall{
+		ID:                  "C1",
+		HttpRequest:         request,
+		CurrentEndPoint:     &EndPoint{ID: "E1"},
+		FinalResponseString: `{"id": "P9", "amount": 10}`,
+	}
+	apiCall.QueueWebhooks(WebhookList{
+		{
+			URL:        "http://example.com/cb/{{.Response.id}}?mode={{.Request.Query.mode}}",
+			Method:     http.MethodPost,
+			Headers:    "X-Signature: {{.Response.id}}\nnot a header\n",
+			Body:       `{&#34;raw&#34;: {{printf "%q" .ResponseRaw}}}`,
+			Delay:      50,
+			Retries:    2,
+			RetryDelay: 10,
+		},
+		{URL: "ftp://example.com/cb", Method: http.MethodPost},
+		{URL: "{{.Response.missing}}", Method: http.MethodGet},
+	})
+
+	calls := apiCall.WebhookCalls()
+	if len(calls) != 1 {
+		t.Fatalf("expected 1 webhook call but got %d", len(calls))
+	}
+
+	call := calls[0]
+	if call.URL != "http://example.com/cb/P9?mode=test" {
+		t.Errorf("expected url %q but got %q", "http://example.com/cb/P9?mode=test", call.URL)
+	}
+	if call.Header["X-Signature"] != "P9" || call.Header["Content-Type"] != "application/json" || len(call.Header) != 2 {
+		t.Errorf("unexpected header %v", call.Header)
+	}
+	if call.Body != `{"raw": "{\"id\": \"P9\", \"amount\": 10}"}` {
+		t.Errorf("unexpected body %q", call.Body)
+	}
+	if call.CallID != "C1" || call.EndPointID != "E1" || call.Attempt != 1 || call.Delay != 50 || call.Retries != 2 || call.RetryDelay != 10 {
+		t.Errorf("unexpected call %+v", call)
+	}
+
+	if len((&ApiCall{}).WebhookCalls()) != 0 {
+		t.Errorf("expected no calls without webhooks")
+	}
+}
+
+func Test_WebhookCall_Send(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		w.Write([]byte(r.Method + " " + r.Header.Get("X-Signature") + " " + string(body)))
+	}))
+	defer server.Close()
+
+	call := &WebhookCall{ID: "W1", CallID: "C1", URL: server.URL + "/ok", Method: http.MethodPut, Header: map[string]string{"X-Signature": "s"}, Body: "paid", Attempt: 2}
+	attempt := call.Send()
+	if attempt.Failed() || attempt.StatusCode != 200 || attempt.Response != "PUT s paid" {
+		t.Errorf("unexpected attempt %+v", attempt)
+	}
+	if attempt.WebhookID != "W1" || attempt.CallID != "C1" || attempt.Attempt != 2 {
+		t.Errorf("attempt not linked to the call: %+v", attempt)
+	}
+
+	call.URL = server.URL + "/fail"
+	if attempt = call.Send(); !attempt.Failed() || attempt.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected a failed attempt but got %+v", attempt)
+	}
+
+	server.Close()
+	if attempt = call.Send(); !attempt.Failed() || attempt.Error == "" {
+		t.Errorf("expected a connection error but got %+v", attempt)
+	}
+}
+
+func Test_WebhookLogModel(t *testing.T) {
+	db, err := bolt.Open(filepath.Join(t.TempDir(), "log.db"), 0600, nil)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	defer db.Close()
+
+	defer func(max int) { maxWebhookLogs = max }(maxWebhookLogs)
+	maxWebhookLogs = 3
+
+	m := &WebhookLogModel{DB: db}
+	if len(m.List("", "")) != 0 {
+		t.Errorf("expected an empty log")
+	}
+
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		callID := "C1"
+		if i%2 == 1 {
+			callID = "C2"
+		}
+		err := m.Save(&WebhookAttempt{ID: string(rune('a' + i)), CallID: callID, EndPointID: "E1", SentAt: start.Add(time.Duration(i) * time.Second)})
+		if err != nil {
+			t.Fatalf("unexpected error %s", err.Error())
+		}
+	}
+
+	attempts := m.List("", "")
+	if len(attempts) != 3 {
+		t.Fatalf("expected the log to keep 3 attempts but got %d", len(attempts))
+	}
+	for i, id := range []string{"c", "d", "e"} {
+		if attempts[i].ID != id {
+			t.Errorf("expected attempt %s at %d but got %s", id, i, attempts[i].ID)
+		}
+	}
+
+	if len(m.List("c1", "")) != 2 || len(m.List("", "E2")) != 0 {
+		t.Errorf("filters do not match")
+	}
+
+	if err := m.Clear(); err != nil || len(m.List("", "")) != 0 {
+		t.Errorf("expected an empty log after clear, error %v", err)
+	}
+}
@@ -0,0 +1,401 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/onlysumitg/GoMockAPI/internal/validator"
+	"github.com/onlysumitg/GoMockAPI/utils/jsonutils"
+	"github.com/onlysumitg/GoMockAPI/utils/stringutils"
+	"github.com/onlysumitg/GoMockAPI/utils/xmlutils"
+	bolt "go.etcd.io/bbolt"
+)
+
+// flat map key holding the whole text of a WebSocket message
+const WsMessageKey = "*MESSAGE"
+
+// line separating the messages sent on connect
+const WsMessageSeparator = "---"
+
+// {{key}} and {{key=default}} in a reply, key may be *MESSAGE
+var wsVariableRegex = regexp.MustCompile(`\{\{\s*(\*?[A-Za-z0-9_\-.\[\]]+)\s*(?:=([^}]*))?\}\}`)
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+// WsRule replies to a client message. Key is a flat key of the JSON
+// message (*MESSAGE for the whole text), a blank operator matches
+// every message.
+type WsRule struct {
+	Key       string `json:"key" db:"key" form:"key"`
+	Operator  string `json:"operator" db:"operator" form:"operator"`
+	Value     string `json:"value" db:"value" form:"value"`
+	DataType  string `json:"datatype" db:"datatype" form:"datatype"`
+	Reply     string `json:"reply" db:"reply" form:"reply"`
+	Delay     int    `json:"delay" db:"delay" form:"delay"`             // ms before the reply
+	CloseCode int    `json:"closecode" db:"closecode" form:"closecode"` // close after the reply, 0 keeps the connection
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+// WsEndPoint is a scripted WebSocket endpoint served at
+// /ws/<collection>/<name>, or /ws/<name> for the V1 collection.
+type WsEndPoint struct {
+	ID           string `json:"id" db:"id" form:"id"`
+	CollectionID string `json:"collectionid" db:"collectionid" form:"collectionid"`
+
+	Name string `json:"name" db:"name" form:"name"`
+	Desc string `json:"desc" db:"desc" form:"desc"`
+
+	OnConnect string    `json:"onconnect" db:"onconnect" form:"onconnect"` // messages separated by a --- line
+	Rules     []*WsRule `json:"rules" db:"rules" form:"rules"`
+
+	PushMessage  string `json:"pushmessage" db:"pushmessage" form:"pushmessage"`
+	PushInterval int    `json:"pushinterval" db:"pushinterval" form:"pushinterval"` // ms, 0 does not push
+
+	CloseAfter  int    `json:"closeafter" db:"closeafter" form:"closeafter"` // ms after connect, 0 keeps the connection
+	CloseCode   int    `json:"closecode" db:"closecode" form:"closecode"`
+	CloseReason string `json:"closereason" db:"closereason" form:"closereason"`
+
+	UpdatedOn time.Time `json:"updatedon" db:"updatedon" form:"-"`
+
+	validator.Validator `json:"-" db:"-" form:"-"`
+}
+
+// -----------------------------------------------------------------
+// 1005, 1006 and 1015 are reserved and can not be sent
+// -----------------------------------------------------------------
+func IsValidCloseCode(code int) bool {
+	switch {
+	case code >= 1000 && code <= 1003:
+		return true
+	case code >= 1007 && code <= 1014:
+		return true
+	case code >= 3000 && code <= 4999:
+		return true
+	}
+	return false
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func SplitWsMessages(text string) []string {
+	messages := make([]string, 0)
+
+	current := make([]string, 0)
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		if strings.TrimSpace(line) == WsMessageSeparator {
+			messages = appendWsMessage(messages, current)
+			current = current[:0]
+			continue
+		}
+		current = append(current, line)
+	}
+
+	return appendWsMessage(messages, current)
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func appendWsMessage(messages []string, lines []string) []string {
+	message := strings.TrimSpace(strings.Join(lines, "\n"))
+	if message == "" {
+		return messages
+	}
+	return append(messages, message)
+}
+
+// -----------------------------------------------------------------
+// *MESSAGE plus the flat keys when the message is a JSON object
+// -----------------------------------------------------------------
+func WsMessageFlatMap(message string) map[string]xmlutils.ValueDatatype {
+	flatMap, err := jsonutils.JsonToFlatMap(message)
+	if err != nil {
+		flatMap = make(map[string]xmlutils.ValueDatatype)
+	}
+	flatMap[WsMessageKey] = xmlutils.ValueDatatype{Value: message, DataType: "string"}
+	return flatMap
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (rule *WsRule) Matches(flatMap map[string]xmlutils.ValueDatatype) bool {
+	if rule.Operator == "" {
+		return true
+	}
+
+	operatorFunc, found := OperatorFuncMap[rule.Operator]
+	if !found {
+		return false
+	}
+
+	key := rule.Key
+	if key == "" {
+		key = WsMessageKey
+	}
+
+	value, found := flatMap[key]
+	if !found {
+		return false
+	}
+
+	return operatorFunc(value.Value, rule.Value, rule.DataType)
+}
+
+// -----------------------------------------------------------------
+// {{key}} and {{key=default}} are replaced from the message
+// -----------------------------------------------------------------
+func RenderWsReply(reply string, flatMap map[string]xmlutils.ValueDatatype) string {
+	return wsVariableRegex.ReplaceAllStringFunc(reply, func(match string) string {
+		matches := wsVariableRegex.FindStringSubmatch(match)
+		value, found := flatMap[matches[1]]
+		if found && value.Value != nil {
+			return fmt.Sprint(value.Value)
+		}
+		return matches[2]
+	})
+}
+
+// -----------------------------------------------------------------
+// first rule that matches the message, nil when none does
+// -----------------------------------------------------------------
+func (e *WsEndPoint) MatchRule(flatMap map[string]xmlutils.ValueDatatype) *WsRule {
+	for _, rule := range e.Rules {
+		if rule.Matches(flatMap) {
+			return rule
+		}
+	}
+	return nil
+}
+
+// -----------------------------------------------------------------
+// existing rules plus a blank row for the form
+// -----------------------------------------------------------------
+func (e *WsEndPoint) RuleRows() []*WsRule {
+	rows := make([]*WsRule, 0, len(e.Rules)+1)
+	rows = append(rows, e.Rules...)
+	rows = append(rows, &WsRule{})
+	return rows
+}
+
+// -----------------------------------------------------------------
+// rows without an operator and a reply are dropped
+// -----------------------------------------------------------------
+func (e *WsEndPoint) CleanRules() {
+	rules := make([]*WsRule, 0, len(e.Rules))
+	for _, rule := range e.Rules {
+		if rule == nil {
+			continue
+		}
+		rule.Key = strings.TrimSpace(rule.Key)
+		rule.Operator = strings.ToUpper(strings.TrimSpace(rule.Operator))
+		rule.DataType = strings.ToUpper(strings.TrimSpace(rule.DataType))
+		if rule.Operator == "" && strings.TrimSpace(rule.Reply) == "" && rule.CloseCode == 0 {
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	e.Rules = rules
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (e *WsEndPoint) ValidateRules() error {
+	for i, rule := range e.Rules {
+		if rule.Operator != "" {
+			if _, found := OperatorFuncMap[rule.Operator]; !found {
+				return fmt.Errorf("rule %d: unknown operator %s", i+1, rule.Operator)
+			}
+		}
+		if rule.Delay < 0 {
+			return fmt.Errorf("rule %d: delay can not be negative", i+1)
+		}
+		if rule.CloseCode != 0 && !IsValidCloseCode(rule.CloseCode) {
+			return fmt.Errorf("rule %d: invalid close code %d", i+1, rule.CloseCode)
+		}
+	}
+	return nil
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+type WsEndPointModel struct {
+	DB *bolt.DB
+}
+
+func (m *WsEndPointModel) getTableName() []byte {
+	return []byte("wsendpoints")
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (m *WsEndPointModel) Save(u *WsEndPoint) error {
+	if u.ID == "" {
+		u.ID = uuid.NewString()
+	}
+
+	u.Name = stringutils.RemoveSpecialChars(stringutils.RemoveMultipleSpaces(strings.ToLower(strings.TrimSpace(u.Name))))
+	u.UpdatedOn = time.Now().Local()
+
+	return m.DB.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(m.getTableName())
+		if err != nil {
+			return err
+		}
+
+		buf, err := json.Marshal(u)
+		if err != nil {
+			return err
+		}
+
+		key := strings.ToUpper(u.ID)
+
+		return bucket.Put([]byte(key), buf)
+	})
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (m *WsEndPointModel) Delete(id string) error {
+
+	err := m.DB.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(m.getTableName())
+		if err != nil {
+			return err
+		}
+		key := strings.ToUpper(id)
+		dbDeleteError := bucket.Delete([]byte(key))
+		return dbDeleteError
+	})
+
+	return err
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (m *WsEndPointModel) Get(id string) (*WsEndPoint, error) {
+
+	if id == "" {
+		return nil, errors.New("blank id not allowed")
+	}
+	var wsEndPointJSON []byte
+
+	err := m.DB.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(m.getTableName())
+		if bucket == nil {
+			return errors.New("table does not exits")
+		}
+		wsEndPointJSON = bucket.Get([]byte(strings.ToUpper(id)))
+
+		return nil
+
+	})
+	wsEndPoint := WsEndPoint{}
+	if err != nil {
+		return &wsEndPoint, err
+	}
+
+	if wsEndPointJSON != nil {
+		err := json.Unmarshal(wsEndPointJSON, &wsEndPoint)
+		return &wsEndPoint, err
+	}
+
+	return &wsEndPoint, ErrNotFound
+
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (m *WsEndPointModel) List() []*WsEndPoint {
+	wsEndPoints := make([]*WsEndPoint, 0)
+	_ = m.DB.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(m.getTableName())
+		if bucket == nil {
+			return errors.New("table does not exits")
+		}
+		c := bucket.Cursor()
+
+		for k, v := c.First(); k != nil; k, v = c.Next() {
+
+			wsEndPoint := WsEndPoint{}
+			err := json.Unmarshal(v, &wsEndPoint)
+			if err == nil {
+				wsEndPoints = append(wsEndPoints, &wsEndPoint)
+			}
+		}
+
+		return nil
+	})
+
+	sort.Slice(wsEndPoints, func(i, j int) bool {
+		return wsEndPoints[i].Name < wsEndPoints[j].Name
+	})
+
+	return wsEndPoints
+
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (m *WsEndPointModel) ListByCollectionID(collectionID string) []*WsEndPoint {
+	wsEndPoints := make([]*WsEndPoint, 0)
+	for _, e := range m.List() {
+		if strings.EqualFold(e.CollectionID, collectionID) {
+			wsEndPoints = append(wsEndPoints, e)
+		}
+	}
+	return wsEndPoints
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (m *WsEndPointModel) GetByName(collectionID string, name string) (*WsEndPoint, error) {
+	for _, e := range m.ListByCollectionID(collectionID) {
+		if strings.EqualFold(e.Name, name) {
+			return e, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (m *WsEndPointModel) DuplicateName(wsEndPointToCheck *WsEndPoint) bool {
+	exists := false
+	for _, e := range m.ListByCollectionID(wsEndPointToCheck.CollectionID) {
+		if strings.EqualFold(e.Name, wsEndPointToCheck.Name) && !strings.EqualFold(e.ID, wsEndPointToCheck.ID) {
+			exists = true
+			break
+		}
+	}
+
+	return exists
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (m *WsEndPointModel) ClearCollectionData(collectionID string) {
+	for _, e := range m.ListByCollectionID(collectionID) {
+		m.Delete(e.ID)
+	}
+}
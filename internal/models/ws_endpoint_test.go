@@ -0,0 +1,140 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_SplitWsMessages(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected []string
+	}{
+		{"", []string{}},
+		{"hello", []string{"hello"}},
+		{"{\"a\": 1}\r\n---\r\n{\"b\": 2}", []string{`{"a": 1}`, `{"b": 2}`}},
+		{"---\none\n  ---  \n\n---\ntwo\nlines\n---", []string{"one", "two\nlines"}},
+	}
+
+	for _, test := range tests {
+		result := SplitWsMessages(test.text)
+		if !reflect.DeepEqual(result, test.expected) {
+			t.Errorf("%q: expected %q but got %q", test.text, test.expected, result)
+		}
+	}
+}
+
+func Test_WsRule_Matches(t *testing.T) {
+	jsonMessage := WsMessageFlatMap(`{"type": "subscribe", "user": {"id": 42}, "qty": 5}`)
+	textMessage := WsMessageFlatMap("ping")
+
+	tests := []struct {
+		name     string
+		rule     WsRule
+		message  string
+		expected bool
+	}{
+		{"blank operator matches all", WsRule{}, "text", true},
+		{"whole text", WsRule{Operator: "EQUALS_TO", Value: "PING"}, "text", true},
+		{"whole text by key", WsRule{Key: WsMessageKey, Operator: "STARTS_WITH", Value: "pi"}, "text", true},
+		{"json key", WsRule{Key: "type", Operator: "EQUALS_TO", Value: "subscribe"}, "json", true},
+		{"json key no match", WsRule{Key: "type", Operator: "EQUALS_TO", Value: "unsubscribe"}, "json", false},
+		{"nested key", WsRule{Key: "user.id", Operator: "EQUALS_TO", Value: "42"}, "json", true},
+		{"number compare", WsRule{Key: "qty", Operator: "GREATER_THAN", Value: "3", DataType: "FLOAT64"}, "json", true},
+		{"missing key", WsRule{Key: "nope", Operator: "EQUALS_TO", Value: ""}, "json", false},
+		{"json key on text", WsRule{Key: "type", Operator: "EQUALS_TO", Value: "subscribe"}, "text", false},
+		{"unknown operator", WsRule{Operator: "LIKE", Value: "ping"}, "text", false},
+		{"whole json text", WsRule{Operator: "CONTAINS", Value: "subscribe"}, "json", true},
+	}
+
+	for _, test := range tests {
+		flatMap := jsonMessage
+		if test.message == "text" {
+			flatMap = textMessage
+		}
+		result := test.rule.Matches(flatMap)
+		if result != test.expected {
+			t.Errorf("%s: expected %t but got %t", test.name, test.expected, result)
+		}
+	}
+}
+
+func Test_WsEndPoint_MatchRule(t *testing.T) {
+	e := &WsEndPoint{Rules: []*WsRule{
+		{Key: "type", Operator: "EQUALS_TO", Value: "ping", Reply: "pong"},
+		{Key: "type", Operator: "EQUALS_TO", Value: "ping", Reply: "second"},
+		{Reply: "fallback"},
+	}}
+
+	rule := e.MatchRule(WsMessageFlatMap(`{"type": "ping"}`))
+	if rule == nil || rule.Reply != "pong" {
+		t.Errorf("expected the first matching rule, got %+v", rule)
+	}
+
+	rule = e.MatchRule(WsMessageFlatMap(`{"type": "other"}`))
+	if rule == nil || rule.Reply != "fallback" {
+		t.Errorf("expected the fallback rule, got %+v", rule)
+	}
+
+	e.Rules = e.Rules[:2]
+	if rule = e.MatchRule(WsMessageFlatMap("text")); rule != nil {
+		t.Errorf("expected no rule, got %+v", rule)
+	}
+}
+
+func Test_RenderWsReply(t *testing.T) {
+	flatMap := WsMessageFlatMap(`{"type": "subscribe", "user": {"id": 42}, "tags": ["a"]}`)
+
+	tests := []struct {
+		reply    string
+		expected string
+	}{
+		{"no variables", "no variables"},
+		{`{"ack": "{{type}}"}`, `{"ack": "subscribe"}`},
+		{"user {{ user.id }}", "user 42"},
+		{"{{missing}}|{{missing=none}}", "|none"},
+		{"{{type=x}}", "subscribe"},
+		{"echo: {{*MESSAGE}}", `echo: {"type": "subscribe", "user": {"id": 42}, "tags": ["a"]}`},
+	}
+
+	for _, test := range tests {
+		result := RenderWsReply(test.reply, flatMap)
+		if result != test.expected {
+			t.Errorf("%q: expected %q but got %q", test.reply, test.expected, result)
+		}
+	}
+}
+
+func Test_WsEndPoint_CleanAndValidateRules(t *testing.T) {
+	e := &WsEndPoint{Rules: []*WsRule{
+		nil,
+		{},
+		{Key: " type ", Operator: " equals_to ", DataType: "int", Reply: "x"},
+		{Reply: "always"},
+		{CloseCode: 1000},
+	}}
+
+	e.CleanRules()
+	if len(e.Rules) != 3 {
+		t.Fatalf("expected 3 rules but got %d", len(e.Rules))
+	}
+	if r := e.Rules[0]; r.Key != "type" || r.Operator != "EQUALS_TO" || r.DataType != "INT" {
+		t.Errorf("rule not cleaned: %+v", r)
+	}
+	if err := e.ValidateRules(); err != nil {
+		t.Errorf("unexpected error %s", err.Error())
+	}
+
+	invalid := []*WsRule{
+		{Operator: "LIKE"},
+		{Delay: -1},
+		{CloseCode: 1006},
+		{CloseCode: 999},
+	}
+	for _, rule := range invalid {
+		e.Rules = []*WsRule{rule}
+		if e.ValidateRules() == nil {
+			t.Errorf("%+v: expected an error", rule)
+		}
+	}
+}
@@ -0,0 +1,478 @@
+package models
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// nested types are followed this deep in the samples
+const wsdlSampleDepth = 8
+
+const (
+	wsdlSoap11Namespace = "http://schemas.xmlsoap.org/wsdl/soap/"
+	wsdlSoap12Namespace = "http://schemas.xmlsoap.org/wsdl/soap12/"
+)
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+// WsdlOperation is an operation of a SOAP binding with sample envelopes
+type WsdlOperation struct {
+	Service  string
+	Name     string
+	Action   string
+	Version  string // blank when the service has 1.1 and 1.2 bindings
+	Location string
+	Element  string // first element in the request body
+
+	Request  string // envelope
+	Response string // body, put in the envelope of the request version
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+type Wsdl struct {
+	Name       string
+	Operations []*WsdlOperation
+}
+
+// WSDL 1.1, tags without a namespace match any prefix
+type wsdlDefinitions struct {
+	XMLName         xml.Name
+	Name            string          `xml:"name,attr"`
+	TargetNamespace string          `xml:"targetNamespace,attr"`
+	Schemas         []*xsdSchema    `xml:"types>schema"`
+	Messages        []*wsdlMessage  `xml:"message"`
+	PortTypes       []*wsdlPortType `xml:"portType"`
+	Bindings        []*wsdlBinding  `xml:"binding"`
+	Services        []*wsdlService  `xml:"service"`
+}
+
+type wsdlMessage struct {
+	Name  string `xml:"name,attr"`
+	Parts []struct {
+		Name    string `xml:"name,attr"`
+		Element string `xml:"element,attr"`
+		Type    string `xml:"type,attr"`
+	} `xml:"part"`
+}
+
+type wsdlPortType struct {
+	Name       string `xml:"name,attr"`
+	Operations []struct {
+		Name  string `xml:"name,attr"`
+		Input struct {
+			Message string `xml:"message,attr"`
+		} `xml:"input"`
+		Output struct {
+			Message string `xml:"message,attr"`
+		} `xml:"output"`
+	} `xml:"operation"`
+}
+
+type wsdlBinding struct {
+	Name        string `xml:"name,attr"`
+	Type        string `xml:"type,attr"`
+	SoapBinding *struct {
+		XMLName xml.Name
+		Style   string `xml:"style,attr"`
+	} `xml:"binding"`
+	Operations []struct {
+		Name          string `xml:"name,attr"`
+		SoapOperation struct {
+			SoapAction string `xml:"soapAction,attr"`
+			Style      string `xml:"style,attr"`
+		} `xml:"operation"`
+		Input struct {
+			Body struct {
+				Namespace string `xml:"namespace,attr"`
+			} `xml:"body"`
+		} `xml:"input"`
+		Output struct {
+			Body struct {
+				Namespace string `xml:"namespace,attr"`
+			} `xml:"body"`
+		} `xml:"output"`
+	} `xml:"operation"`
+}
+
+type wsdlService struct {
+	Name  string `xml:"name,attr"`
+	Ports []struct {
+		Name    string `xml:"name,attr"`
+		Binding string `xml:"binding,attr"`
+		Address struct {
+			Location string `xml:"location,attr"`
+		} `xml:"address"`
+	} `xml:"port"`
+}
+
+type xsdSchema struct {
+	TargetNamespace    string            `xml:"targetNamespace,attr"`
+	ElementFormDefault string            `xml:"elementFormDefault,attr"`
+	Elements           []*xsdElement     `xml:"element"`
+	ComplexTypes       []*xsdComplexType `xml:"complexType"`
+}
+
+type xsdElement struct {
+	Name        string          `xml:"name,attr"`
+	Ref         string          `xml:"ref,attr"`
+	Type        string          `xml:"type,attr"`
+	ComplexType *xsdComplexType `xml:"complexType"`
+}
+
+type xsdComplexType struct {
+	Name      string        `xml:"name,attr"`
+	Sequence  []*xsdElement `xml:"sequence>element"`
+	All       []*xsdElement `xml:"all>element"`
+	Choice    []*xsdElement `xml:"choice>element"`
+	Extension *struct {
+		Base     string        `xml:"base,attr"`
+		Sequence []*xsdElement `xml:"sequence>element"`
+	} `xml:"complexContent>extension"`
+}
+
+// -----------------------------------------------------------------
+// ParseWsdl reads a WSDL 1.1 document. Every operation of a SOAP 1.1 or
+// 1.2 binding gets a sample request and response.
+// -----------------------------------------------------------------
+func ParseWsdl(data []byte) (*Wsdl, error) {
+	definitions := &wsdlDefinitions{}
+	err := xml.Unmarshal(data, definitions)
+	if err != nil {
+		return nil, err
+	}
+	if definitions.XMLName.Local != "definitions" {
+		return nil, errors.New("not a WSDL 1.1 document")
+	}
+
+	sampler := newXsdSampler(definitions.Schemas)
+
+	wsdl := &Wsdl{Name: definitions.Name, Operations: make([]*WsdlOperation, 0)}
+	seen := make(map[string]*WsdlOperation)
+
+	for _, service := range definitions.Services {
+		if wsdl.Name == "" {
+			wsdl.Name = service.Name
+		}
+
+		for _, port := range service.Ports {
+			binding := definitions.binding(port.Binding)
+			if binding == nil || binding.SoapBinding == nil {
+				continue
+			}
+
+			version := ""
+			switch binding.SoapBinding.XMLName.Space {
+			case wsdlSoap11Namespace:
+				version = Soap11
+			case wsdlSoap12Namespace:
+				version = Soap12
+			default:
+				continue // HTTP bindings
+			}
+
+			portType := definitions.portType(binding.Type)
+			if portType == nil {
+				continue
+			}
+
+			for _, bindingOperation := range binding.Operations {
+				// a service often has the same operations for 1.1 and 1.2,
+				// those answer in the version of the request
+				key := strings.ToLower(service.Name + "." + bindingOperation.Name)
+				if operation, found := seen[key]; found {
+					if operation.Version != version {
+						operation.Version = ""
+					}
+					continue
+				}
+
+				style := bindingOperation.SoapOperation.Style
+				if style == "" {
+					style = binding.SoapBinding.Style
+				}
+				rpc := strings.EqualFold(style, "rpc")
+
+				operation := &WsdlOperation{
+					Service:  service.Name,
+					Name:     bindingOperation.Name,
+					Action:   bindingOperation.SoapOperation.SoapAction,
+					Version:  version,
+					Location: port.Address.Location,
+				}
+
+				for _, o := range portType.Operations {
+					if o.Name != bindingOperation.Name {
+						continue
+					}
+
+					namespace := firstNotBlank(bindingOperation.Input.Body.Namespace, definitions.TargetNamespace)
+					body, element := sampler.message(definitions.message(o.Input.Message), rpc, o.Name, namespace)
+					operation.Request = WrapSoapEnvelope(version, body)
+					operation.Element = element
+
+					namespace = firstNotBlank(bindingOperation.Output.Body.Namespace, definitions.TargetNamespace)
+					body, _ = sampler.message(definitions.message(o.Output.Message), rpc, o.Name+"Response", namespace)
+					operation.Response = body
+				}
+
+				if operation.Element == "" {
+					operation.Element = operation.Name
+				}
+
+				seen[key] = operation
+				wsdl.Operations = append(wsdl.Operations, operation)
+			}
+		}
+	}
+
+	if len(wsdl.Operations) == 0 {
+		return nil, errors.New("no SOAP operations found")
+	}
+	return wsdl, nil
+}
+
+// -----------------------------------------------------------------
+// tns:Name => Name
+// -----------------------------------------------------------------
+func xmlLocalName(name string) string {
+	if i := strings.LastIndex(name, ":"); i >= 0 {
+		return name[i+1:]
+	}
+	return name
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func firstNotBlank(values ...string) string {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return strings.TrimSpace(v)
+		}
+	}
+	return ""
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (d *wsdlDefinitions) binding(name string) *wsdlBinding {
+	for _, b := range d.Bindings {
+		if b.Name == xmlLocalName(name) {
+			return b
+		}
+	}
+	return nil
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (d *wsdlDefinitions) portType(name string) *wsdlPortType {
+	for _, p := range d.PortTypes {
+		if p.Name == xmlLocalName(name) {
+			return p
+		}
+	}
+	return nil
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (d *wsdlDefinitions) message(name string) *wsdlMessage {
+	for _, m := range d.Messages {
+		if m.Name == xmlLocalName(name) {
+			return m
+		}
+	}
+	return nil
+}
+
+// -----------------------------------------------------------------
+// writes sample XML for schema elements and types
+// -----------------------------------------------------------------
+type xsdSampler struct {
+	elements     map[string]*xsdElement
+	complexTypes map[string]*xsdComplexType
+	schemas      map[string]*xsdSchema // schema of the global elements
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func newXsdSampler(schemas []*xsdSchema) *xsdSampler {
+	s := &xsdSampler{
+		elements:     make(map[string]*xsdElement),
+		complexTypes: make(map[string]*xsdComplexType),
+		schemas:      make(map[string]*xsdSchema),
+	}
+	for _, schema := range schemas {
+		for _, e := range schema.Elements {
+			s.elements[e.Name] = e
+			s.schemas[e.Name] = schema
+		}
+		for _, t := range schema.ComplexTypes {
+			s.complexTypes[t.Name] = t
+		}
+	}
+	return s
+}
+
+// -----------------------------------------------------------------
+// body of a message and the name of its first element. rpc messages
+// are wrapped in an element named after the operation
+// -----------------------------------------------------------------
+func (s *xsdSampler) message(m *wsdlMessage, rpc bool, wrapper string, namespace string) (string, string) {
+	var b strings.Builder
+
+	if m == nil {
+		return "", ""
+	}
+
+	if rpc {
+		fmt.Fprintf(&b, "<m:%s xmlns:m=\"%s\">\n", wrapper, namespace)
+		for _, part := range m.Parts {
+			s.part(&b, part.Name, part.Element, part.Type, 1)
+		}
+		fmt.Fprintf(&b, "</m:%s>", wrapper)
+		return b.String(), wrapper
+	}
+
+	first := ""
+	for _, part := range m.Parts {
+		if first == "" {
+			first = xmlLocalName(firstNotBlank(part.Element, part.Name))
+		}
+		s.part(&b, part.Name, part.Element, part.Type, 0)
+	}
+	return strings.TrimSpace(b.String()), first
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (s *xsdSampler) part(b *strings.Builder, name string, element string, typeName string, depth int) {
+	if element == "" {
+		s.element(b, &xsdElement{Name: name, Type: typeName}, "", depth)
+		return
+	}
+
+	e, found := s.elements[xmlLocalName(element)]
+	if !found {
+		s.element(b, &xsdElement{Name: xmlLocalName(element)}, "", depth)
+		return
+	}
+
+	schema := s.schemas[e.Name]
+	prefix := ""
+	if strings.EqualFold(schema.ElementFormDefault, "qualified") {
+		prefix = "m:"
+	}
+
+	// global elements are always qualified
+	fmt.Fprintf(b, "%s<m:%s xmlns:m=\"%s\">", indent(depth), e.Name, schema.TargetNamespace)
+	s.content(b, e, prefix, depth)
+	fmt.Fprintf(b, "</m:%s>\n", e.Name)
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func (s *xsdSampler) element(b *strings.Builder, e *xsdElement, prefix string, depth int) {
+	if e.Ref != "" {
+		ref, found := s.elements[xmlLocalName(e.Ref)]
+		if found {
+			e = ref
+		} else {
+			e = &xsdElement{Name: xmlLocalName(e.Ref)}
+		}
+	}
+
+	fmt.Fprintf(b, "%s<%s%s>", indent(depth), prefix, e.Name)
+	s.content(b, e, prefix, depth)
+	fmt.Fprintf(b, "</%s%s>\n", prefix, e.Name)
+}
+
+// -----------------------------------------------------------------
+// child elements of complex types, a sample value for simple types
+// -----------------------------------------------------------------
+func (s *xsdSampler) content(b *strings.Builder, e *xsdElement, prefix string, depth int) {
+	complexType := e.ComplexType
+	if complexType == nil && e.Type != "" {
+		complexType = s.complexTypes[xmlLocalName(e.Type)]
+	}
+
+	if complexType == nil {
+		b.WriteString(xsdSampleValue(e.Type))
+		return
+	}
+
+	children := s.children(complexType, 0)
+	if len(children) == 0 || depth >= wsdlSampleDepth {
+		return
+	}
+
+	b.WriteString("\n")
+	for _, child := range children {
+		s.element(b, child, prefix, depth+1)
+	}
+	b.WriteString(indent(depth))
+}
+
+// -----------------------------------------------------------------
+// base type elements come first
+// -----------------------------------------------------------------
+func (s *xsdSampler) children(t *xsdComplexType, depth int) []*xsdElement {
+	children := make([]*xsdElement, 0)
+
+	if t.Extension != nil && depth < wsdlSampleDepth {
+		if base, found := s.complexTypes[xmlLocalName(t.Extension.Base)]; found {
+			children = append(children, s.children(base, depth+1)...)
+		}
+		children = append(children, t.Extension.Sequence...)
+	}
+
+	children = append(children, t.Sequence...)
+	children = append(children, t.All...)
+	if len(t.Choice) > 0 {
+		children = append(children, t.Choice[0])
+	}
+	return children
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func indent(depth int) string {
+	return strings.Repeat("  ", depth)
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func xsdSampleValue(typeName string) string {
+	switch xmlLocalName(typeName) {
+	case "int", "integer", "long", "short", "byte", "nonNegativeInteger", "unsignedInt", "unsignedLong", "unsignedShort", "unsignedByte":
+		return "0"
+	case "positiveInteger":
+		return "1"
+	case "decimal", "double", "float":
+		return "0.0"
+	case "boolean":
+		return "false"
+	case "date":
+		return "2024-01-01"
+	case "dateTime":
+		return "2024-01-01T00:00:00"
+	case "time":
+		return "00:00:00"
+	default:
+		return "?"
+	}
+}
@@ -0,0 +1,90 @@
+package models
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/onlysumitg/GoMockAPI/utils/xmlutils"
+)
+
+func Test_ParseWsdl(t *testing.T) {
+	data, err := os.ReadFile("../../testdata/wsdl/stockquote.wsdl")
+	if err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+
+	wsdl, err := ParseWsdl(data)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	if wsdl.Name != "StockQuote" {
+		t.Errorf("expected name StockQuote but got %s", wsdl.Name)
+	}
+
+	// 1.1 and 1.2 bindings share the operations, the HTTP binding is skipped
+	if len(wsdl.Operations) != 2 {
+		t.Fatalf("expected 2 operations but got %d", len(wsdl.Operations))
+	}
+
+	tests := []struct {
+		name      string
+		action    string
+		requestX  map[string]string
+		responseX map[string]string
+	}{
+		{
+			"GetQuote", "http://example.com/stockquote/GetQuote",
+			map[string]string{"Envelope.Body.GetQuote.symbol": "?"},
+			map[string]string{"GetQuoteResponse.price": "0.0", "GetQuoteResponse.currency": "?"},
+		},
+		{
+			"PlaceOrder", "http://example.com/stockquote/PlaceOrder",
+			map[string]string{"Envelope.Body.PlaceOrder.trade.symbol": "?", "Envelope.Body.PlaceOrder.trade.quantity": "0", "Envelope.Body.PlaceOrder.limit": "false"},
+			map[string]string{"PlaceOrderResponse.orderId": "0"},
+		},
+	}
+
+	for i, test := range tests {
+		operation := wsdl.Operations[i]
+		if operation.Name != test.name || operation.Element != test.name || operation.Action != test.action {
+			t.Errorf("%s: unexpected operation %+v", test.name, operation)
+		}
+		if operation.Service != "StockQuoteService" || operation.Location != "http://example.com/stockquote" || operation.Version != "" {
+			t.Errorf("%s: unexpected service %s %s version %q", test.name, operation.Service, operation.Location, operation.Version)
+		}
+
+		envelope, err := ParseSoapEnvelope(operation.Request)
+		if err != nil || envelope.Version != Soap11 || envelope.Operation != test.name || envelope.OperationNamespace != "http://example.com/stockquote" {
+			t.Errorf("%s: unexpected request envelope %+v %v", test.name, envelope, err)
+		}
+
+		for sample, expected := range map[string]map[string]string{operation.Request: test.requestX, operation.Response: test.responseX} {
+			flatMap, _, err := xmlutils.XmlToLocalFlatMapAndPlaceholder(sample)
+			if err != nil {
+				t.Errorf("%s: unexpected error %s", test.name, err.Error())
+				continue
+			}
+			for key, value := range expected {
+				if flatMap[key].Value != value {
+					t.Errorf("%s: %s: expected %q but got %q", test.name, key, value, flatMap[key].Value)
+				}
+			}
+		}
+	}
+
+	errorTests := []struct {
+		data   string
+		errorX string
+	}{
+		{"not xml", "EOF"},
+		{`<schema/>`, "not a WSDL 1.1 document"},
+		{`<definitions xmlns="http://schemas.xmlsoap.org/wsdl/"/>`, "no SOAP operations"},
+	}
+	for _, test := range errorTests {
+		_, err := ParseWsdl([]byte(test.data))
+		if err == nil || !strings.Contains(err.Error(), test.errorX) {
+			t.Errorf("%q: expected error %q but got %v", test.data, test.errorX, err)
+		}
+	}
+}
@@ -0,0 +1,691 @@
+package protobuf
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ------------------------------------------------------
+// Marshal encodes the JSON value of a message. Fields are found by
+// their JSON name or their proto name, enums by name or number.
+// Proto3 fields without presence are not written at their default.
+// ------------------------------------------------------
+func (r *Registry) Marshal(message string, value any) ([]byte, error) {
+	m, found := r.Messages[message]
+	if !found {
+		return nil, fmt.Errorf("unknown message %s", message)
+	}
+	return r.marshalMessage(m, value, message)
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (r *Registry) marshalMessage(m *Message, value any, path string) ([]byte, error) {
+	value, err := r.wellKnownToMessage(m.FullName, value)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err.Error())
+	}
+
+	object, ok := value.(map[string]any)
+	if !ok {
+		if value == nil {
+			return []byte{}, nil
+		}
+		return nil, fmt.Errorf("%s: %s must be a JSON object", path, m.FullName)
+	}
+
+	// known fields in the order of their numbers
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		if m.Field(key) == nil {
+			return nil, fmt.Errorf("%s: %s has no field %s", path, m.FullName, key)
+		}
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return m.Field(keys[i]).Number < m.Field(keys[j]).Number })
+
+	// one member of a oneof at most
+	oneofs := make(map[string]string)
+	for _, key := range keys {
+		f := m.Field(key)
+		if f.Oneof == "" || object[key] == nil {
+			continue
+		}
+		if other, found := oneofs[f.Oneof]; found {
+			return nil, fmt.Errorf("%s: %s and %s are both set in oneof %s", path, other, key, f.Oneof)
+		}
+		oneofs[f.Oneof] = key
+	}
+
+	buf := make([]byte, 0)
+	for _, key := range keys {
+		f := m.Field(key)
+		v := object[key]
+		if v == nil && !isValueField(f) {
+			continue
+		}
+
+		fieldPath := path + "." + key
+		switch {
+		case f.IsMap():
+			entries, ok := v.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("%s: map must be a JSON object", fieldPath)
+			}
+			mapKeys := make([]string, 0, len(entries))
+			for k := range entries {
+				mapKeys = append(mapKeys, k)
+			}
+			sort.Strings(mapKeys)
+
+			for _, k := range mapKeys {
+				entry := make([]byte, 0)
+				entry, err = r.appendField(entry, f.MapKey, k, fieldPath)
+				if err != nil {
+					return nil, err
+				}
+				if entries[k] != nil || isValueField(f.MapValue) {
+					entry, err = r.appendField(entry, f.MapValue, entries[k], fieldPath+"."+k)
+					if err != nil {
+						return nil, err
+					}
+				}
+				buf = appendTag(buf, f.Number, wireBytes)
+				buf = binary.AppendUvarint(buf, uint64(len(entry)))
+				buf = append(buf, entry...)
+			}
+
+		case f.Repeated:
+			list, ok := v.([]any)
+			if !ok {
+				return nil, fmt.Errorf("%s: repeated field must be a JSON array", fieldPath)
+			}
+
+			if f.Packed && f.wireType() != wireBytes {
+				packed := make([]byte, 0)
+				for i, item := range list {
+					packed, err = r.appendValue(packed, f, item, fmt.Sprintf("%s[%d]", fieldPath, i))
+					if err != nil {
+						return nil, err
+					}
+				}
+				if len(list) > 0 {
+					buf = appendTag(buf, f.Number, wireBytes)
+					buf = binary.AppendUvarint(buf, uint64(len(packed)))
+					buf = append(buf, packed...)
+				}
+				continue
+			}
+
+			for i, item := range list {
+				buf, err = r.appendField(buf, f, item, fmt.Sprintf("%s[%d]", fieldPath, i))
+				if err != nil {
+					return nil, err
+				}
+			}
+
+		default:
+			if m.Syntax == "proto3" && f.Type != TypeMessage && f.Oneof == "" && !f.Optional {
+				// fields without presence are not written with their default value
+				encoded, err := r.appendValue(nil, f, v, fieldPath)
+				if err != nil {
+					return nil, err
+				}
+				if isZero(encoded) {
+					continue
+				}
+			}
+			buf, err = r.appendField(buf, f, v, fieldPath)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return buf, nil
+}
+
+// ------------------------------------------------------
+// null of a google.protobuf.Value is a value, not an unset field
+// ------------------------------------------------------
+func isValueField(f *Field) bool {
+	return f.Type == TypeMessage && f.TypeName == "google.protobuf.Value" && !f.Repeated
+}
+
+// ------------------------------------------------------
+// 0, "", false and the first enum value are encoded as zero bytes
+// ------------------------------------------------------
+func isZero(encoded []byte) bool {
+	for _, b := range encoded {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func appendTag(buf []byte, number int, wireType int) []byte {
+	return binary.AppendUvarint(buf, uint64(number)<<3|uint64(wireType))
+}
+
+// ------------------------------------------------------
+// tag and value
+// ------------------------------------------------------
+func (r *Registry) appendField(buf []byte, f *Field, value any, path string) ([]byte, error) {
+	buf = appendTag(buf, f.Number, f.wireType())
+	return r.appendValue(buf, f, value, path)
+}
+
+// ------------------------------------------------------
+// value without the tag
+// ------------------------------------------------------
+func (r *Registry) appendValue(buf []byte, f *Field, value any, path string) ([]byte, error) {
+	switch f.Type {
+	case TypeMessage:
+		encoded, err := r.marshalMessage(r.Messages[f.TypeName], value, path)
+		if err != nil {
+			return nil, err
+		}
+		buf = binary.AppendUvarint(buf, uint64(len(encoded)))
+		return append(buf, encoded...), nil
+
+	case TypeEnum:
+		number, err := enumNumber(r.Enums[f.TypeName], value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", path, err.Error())
+		}
+		return binary.AppendUvarint(buf, uint64(int64(number))), nil
+
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s: must be a string", path)
+		}
+		buf = binary.AppendUvarint(buf, uint64(len(s)))
+		return append(buf, s...), nil
+
+	case "bytes":
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s: must be a base64 string", path)
+		}
+		b, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			b, err = base64.URLEncoding.DecodeString(s)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: must be a base64 string", path)
+		}
+		buf = binary.AppendUvarint(buf, uint64(len(b)))
+		return append(buf, b...), nil
+
+	case "bool":
+		b, err := toBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", path, err.Error())
+		}
+		if b {
+			return append(buf, 1), nil
+		}
+		return append(buf, 0), nil
+
+	case "double", "float":
+		n, err := toFloat(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", path, err.Error())
+		}
+		if f.Type == "float" {
+			return binary.LittleEndian.AppendUint32(buf, math.Float32bits(float32(n))), nil
+		}
+		return binary.LittleEndian.AppendUint64(buf, math.Float64bits(n)), nil
+
+	case "uint32", "uint64", "fixed32", "fixed64":
+		n, err := toUint(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", path, err.Error())
+		}
+		if strings.HasSuffix(f.Type, "32") && n > math.MaxUint32 {
+			return nil, fmt.Errorf("%s: %d is out of range for %s", path, n, f.Type)
+		}
+		switch f.Type {
+		case "fixed32":
+			return binary.LittleEndian.AppendUint32(buf, uint32(n)), nil
+		case "fixed64":
+			return binary.LittleEndian.AppendUint64(buf, n), nil
+		}
+		return binary.AppendUvarint(buf, n), nil
+
+	default:
+		n, err := toInt(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", path, err.Error())
+		}
+		if strings.HasSuffix(f.Type, "32") && (n < math.MinInt32 || n > math.MaxInt32) {
+			return nil, fmt.Errorf("%s: %d is out of range for %s", path, n, f.Type)
+		}
+		switch f.Type {
+		case "sint32", "sint64":
+			return binary.AppendUvarint(buf, uint64(n<<1)^uint64(n>>63)), nil
+		case "sfixed32":
+			return binary.LittleEndian.AppendUint32(buf, uint32(int32(n))), nil
+		case "sfixed64":
+			return binary.LittleEndian.AppendUint64(buf, uint64(n)), nil
+		}
+		return binary.AppendUvarint(buf, uint64(n)), nil
+	}
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func enumNumber(e *Enum, value any) (int, error) {
+	if name, ok := value.(string); ok {
+		if v, found := e.ValueByName(name); found {
+			return v.Number, nil
+		}
+		if _, err := strconv.Atoi(name); err != nil {
+			return 0, fmt.Errorf("%s has no value %s", e.FullName, name)
+		}
+	}
+	n, err := toInt(value)
+	if err == nil && (n < math.MinInt32 || n > math.MaxInt32) {
+		return 0, fmt.Errorf("%d is out of range for %s", n, e.FullName)
+	}
+	return int(n), err
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func toInt(value any) (int64, error) {
+	switch v := value.(type) {
+	case float64:
+		if v != math.Trunc(v) {
+			return 0, fmt.Errorf("%v is not an integer", v)
+		}
+		if v < math.MinInt64 || v >= math.MaxInt64 {
+			return 0, fmt.Errorf("%v is out of range", v)
+		}
+		return int64(v), nil
+	case int:
+		return int64(v), nil
+	case int64:
+		return v, nil
+	case json.Number:
+		return v.Int64()
+	case string:
+		n, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("%q is not an integer", v)
+		}
+		return n, nil
+	}
+	return 0, fmt.Errorf("%v is not an integer", value)
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func toUint(value any) (uint64, error) {
+	if s, ok := value.(string); ok {
+		n, err := strconv.ParseUint(strings.TrimSpace(s), 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("%q is not an unsigned integer", s)
+		}
+		return n, nil
+	}
+	n, err := toInt(value)
+	if err != nil {
+		return 0, err
+	}
+	if n < 0 {
+		return 0, fmt.Errorf("%d is negative", n)
+	}
+	return uint64(n), nil
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func toFloat(value any) (float64, error) {
+	switch v := value.(type) {
+	case float64:
+		return v, nil
+	case int:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	case json.Number:
+		return v.Float64()
+	case string:
+		switch v {
+		case "NaN":
+			return math.NaN(), nil
+		case "Infinity":
+			return math.Inf(1), nil
+		case "-Infinity":
+			return math.Inf(-1), nil
+		}
+		n, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return 0, fmt.Errorf("%q is not a number", v)
+		}
+		return n, nil
+	}
+	return 0, fmt.Errorf("%v is not a number", value)
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func toBool(value any) (bool, error) {
+	switch v := value.(type) {
+	case bool:
+		return v, nil
+	case string:
+		b, err := strconv.ParseBool(v)
+		if err == nil {
+			return b, nil
+		}
+	}
+	return false, fmt.Errorf("%v is not a boolean", value)
+}
+
+// ------------------------------------------------------
+// Unmarshal decodes a message to its JSON value. Unset scalars, enums,
+// lists and maps get their default value so conditions can use them;
+// unset messages and oneof fields are left out.
+// ------------------------------------------------------
+func (r *Registry) Unmarshal(message string, data []byte) (any, error) {
+	m, found := r.Messages[message]
+	if !found {
+		return nil, fmt.Errorf("unknown message %s", message)
+	}
+	return r.unmarshalMessage(m, data)
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (r *Registry) unmarshalMessage(m *Message, data []byte) (any, error) {
+	object := make(map[string]any)
+
+	// bytes of the messages read so far, a message that comes again
+	// is merged into them
+	messages := make(map[int][]byte)
+
+	for len(data) > 0 {
+		tag, n := binary.Uvarint(data)
+		if n <= 0 {
+			return nil, errors.New("invalid tag")
+		}
+		data = data[n:]
+
+		number, wireType := int(tag>>3), int(tag&7)
+		f := m.byNumber[number]
+
+		raw, rest, err := readWireValue(data, wireType)
+		if err != nil {
+			return nil, fmt.Errorf("%s field %d: %s", m.FullName, number, err.Error())
+		}
+		data = rest
+
+		// unknown fields, and known ones with another wire type, are skipped
+		if f == nil || !acceptsWireType(f, wireType) {
+			continue
+		}
+
+		switch {
+		case f.IsMap():
+			entries, _ := object[f.JSONName].(map[string]any)
+			if entries == nil {
+				entries = make(map[string]any)
+			}
+			key, value, err := r.unmarshalMapEntry(f, raw.bytes)
+			if err != nil {
+				return nil, err
+			}
+			entries[key] = value
+			object[f.JSONName] = entries
+
+		case f.Repeated:
+			list, _ := object[f.JSONName].([]any)
+			if wireType == wireBytes && f.wireType() != wireBytes {
+				// packed
+				items := raw.bytes
+				for len(items) > 0 {
+					item, rest, err := readWireValue(items, f.wireType())
+					if err != nil {
+						return nil, fmt.Errorf("%s.%s: %s", m.FullName, f.Name, err.Error())
+					}
+					items = rest
+					value, err := r.decodeValue(f, item)
+					if err != nil {
+						return nil, err
+					}
+					list = append(list, value)
+				}
+			} else {
+				value, err := r.decodeValue(f, raw)
+				if err != nil {
+					return nil, err
+				}
+				list = append(list, value)
+			}
+			object[f.JSONName] = list
+
+		default:
+			if f.Oneof != "" {
+				// last field of a oneof wins
+				for _, other := range m.Fields {
+					if other.Oneof == f.Oneof && other != f {
+						delete(object, other.JSONName)
+						delete(messages, other.Number)
+					}
+				}
+			}
+			if f.Type == TypeMessage {
+				raw.bytes = append(messages[f.Number], raw.bytes...)
+				messages[f.Number] = raw.bytes
+			}
+			value, err := r.decodeValue(f, raw)
+			if err != nil {
+				return nil, err
+			}
+			object[f.JSONName] = value
+		}
+	}
+
+	for _, f := range m.Fields {
+		if _, found := object[f.JSONName]; found || f.Oneof != "" || f.Optional {
+			continue
+		}
+		switch {
+		case f.IsMap():
+			object[f.JSONName] = map[string]any{}
+		case f.Repeated:
+			object[f.JSONName] = []any{}
+		case f.Type != TypeMessage:
+			object[f.JSONName] = r.defaultValue(f)
+		}
+	}
+
+	return r.wellKnownFromMessage(m.FullName, object)
+}
+
+// ------------------------------------------------------
+// wire type of the field, or a packed list of its values
+// ------------------------------------------------------
+func acceptsWireType(f *Field, wireType int) bool {
+	switch {
+	case f.IsMap():
+		return wireType == wireBytes
+	case wireType == f.wireType():
+		return true
+	}
+	return f.Repeated && wireType == wireBytes
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (r *Registry) unmarshalMapEntry(f *Field, data []byte) (string, any, error) {
+	entry := &Message{FullName: "map entry", byNumber: map[int]*Field{1: f.MapKey, 2: f.MapValue}, Fields: []*Field{f.MapKey, f.MapValue}}
+	decoded, err := r.unmarshalMessage(entry, data)
+	if err != nil {
+		return "", nil, err
+	}
+
+	object := decoded.(map[string]any)
+	value, found := object["value"]
+	if !found && f.MapValue.Type == TypeMessage {
+		// a missing message value is an empty message
+		value, err = r.unmarshalMessage(r.Messages[f.MapValue.TypeName], nil)
+		if err != nil {
+			return "", nil, err
+		}
+	}
+	return fmt.Sprint(object["key"]), value, nil
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (r *Registry) defaultValue(f *Field) any {
+	switch f.Type {
+	case TypeEnum:
+		if v, found := r.Enums[f.TypeName].ValueByNumber(0); found {
+			return v.Name
+		}
+		return r.Enums[f.TypeName].Values[0].Name
+	case "string", "bytes":
+		return ""
+	case "bool":
+		return false
+	}
+	return 0
+}
+
+// ------------------------------------------------------
+// value read from the wire
+// ------------------------------------------------------
+type wireValue struct {
+	number uint64
+	bytes  []byte
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func readWireValue(data []byte, wireType int) (wireValue, []byte, error) {
+	switch wireType {
+	case wireVarint:
+		v, n := binary.Uvarint(data)
+		if n <= 0 {
+			return wireValue{}, nil, errors.New("invalid varint")
+		}
+		return wireValue{number: v}, data[n:], nil
+
+	case wireFixed64:
+		if len(data) < 8 {
+			return wireValue{}, nil, errors.New("unexpected end of fixed64")
+		}
+		return wireValue{number: binary.LittleEndian.Uint64(data)}, data[8:], nil
+
+	case wireFixed32:
+		if len(data) < 4 {
+			return wireValue{}, nil, errors.New("unexpected end of fixed32")
+		}
+		return wireValue{number: uint64(binary.LittleEndian.Uint32(data))}, data[4:], nil
+
+	case wireBytes:
+		length, n := binary.Uvarint(data)
+		if n <= 0 || uint64(len(data)-n) < length {
+			return wireValue{}, nil, errors.New("unexpected end of length delimited value")
+		}
+		return wireValue{bytes: data[n : n+int(length)]}, data[n+int(length):], nil
+
+	case wireStartGroup:
+		// groups are skipped up to their end tag
+		for len(data) > 0 {
+			tag, n := binary.Uvarint(data)
+			if n <= 0 {
+				return wireValue{}, nil, errors.New("invalid tag")
+			}
+			data = data[n:]
+			if int(tag&7) == wireEndGroup {
+				return wireValue{}, data, nil
+			}
+			_, rest, err := readWireValue(data, int(tag&7))
+			if err != nil {
+				return wireValue{}, nil, err
+			}
+			data = rest
+		}
+		return wireValue{}, nil, errors.New("unexpected end of group")
+	}
+
+	return wireValue{}, nil, fmt.Errorf("invalid wire type %d", wireType)
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (r *Registry) decodeValue(f *Field, raw wireValue) (any, error) {
+	switch f.Type {
+	case TypeMessage:
+		return r.unmarshalMessage(r.Messages[f.TypeName], raw.bytes)
+	case TypeEnum:
+		if v, found := r.Enums[f.TypeName].ValueByNumber(int(int32(raw.number))); found {
+			return v.Name, nil
+		}
+		return int64(int32(raw.number)), nil
+	case "string":
+		return string(raw.bytes), nil
+	case "bytes":
+		return base64.StdEncoding.EncodeToString(raw.bytes), nil
+	case "bool":
+		return raw.number != 0, nil
+	case "double":
+		return jsonFloat(math.Float64frombits(raw.number)), nil
+	case "float":
+		return jsonFloat(float64(math.Float32frombits(uint32(raw.number)))), nil
+	case "int32", "sfixed32":
+		return int64(int32(raw.number)), nil
+	case "int64", "sfixed64":
+		return int64(raw.number), nil
+	case "sint32":
+		n := uint32(raw.number)
+		return int64(int32(n>>1) ^ -int32(n&1)), nil
+	case "sint64":
+		return int64(raw.number>>1) ^ -int64(raw.number&1), nil
+	case "uint32", "fixed32":
+		return uint64(uint32(raw.number)), nil
+	}
+	return raw.number, nil
+}
+
+// ------------------------------------------------------
+// NaN and infinities are strings in JSON
+// ------------------------------------------------------
+func jsonFloat(f float64) any {
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case math.IsInf(f, 1):
+		return "Infinity"
+	case math.IsInf(f, -1):
+		return "-Infinity"
+	}
+	return f
+}
@@ -0,0 +1,303 @@
+package protobuf
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const testProto = `
+syntax = "proto3";
+package test;
+
+import "google/protobuf/timestamp.proto";
+import "google/protobuf/duration.proto";
+
+enum Color {
+  RED = 0;
+  GREEN = 1;
+  BLUE = 2;
+}
+
+message All {
+  int32 i32 = 1;
+  sint32 s32 = 2;
+  repeated int32 packed = 3;
+  map<string, int32> counts = 4;
+  Color color = 5;
+  oneof choice {
+    string name = 6;
+    int64 id = 7;
+  }
+  google.protobuf.Timestamp at = 8;
+  google.protobuf.Duration took = 9;
+  repeated string tags = 10;
+  sint64 s64 = 11;
+  Inner inner = 12;
+}
+
+message Inner {
+  string value = 1;
+}
+
+message Known {
+  google.protobuf.Struct data = 1;
+  google.protobuf.Value value = 2;
+  google.protobuf.ListValue list = 3;
+  google.protobuf.Any any = 4;
+  google.protobuf.FieldMask mask = 5;
+}
+`
+
+func testRegistry(t *testing.T) *Registry {
+	t.Helper()
+	registry, err := Parse(testProto)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	return registry
+}
+
+func Test_Marshal(t *testing.T) {
+	registry := testRegistry(t)
+
+	tests := []struct {
+		name     string
+		value    string
+		expected string
+		errorX   string
+	}{
+		{"negative int32 is ten bytes", `{"i32": -1}`, "08ffffffffffffffffff01", ""},
+		{"int32 as string", `{"i32": "150"}`, "089601", ""},
+		{"sint32 zigzag -1", `{"s32": -1}`, "1001", ""},
+		{"sint32 zigzag 1", `{"s32": 1}`, "1002", ""},
+		{"sint32 zigzag -64", `{"s32": -64}`, "107f", ""},
+		{"sint32 zigzag 64", `{"s32": 64}`, "108001", ""},
+		{"sint64 zigzag", `{"s64": -3}`, "5805", ""},
+		{"packed repeated", `{"packed": [1, 2, 300]}`, "1a040102ac02", ""},
+		{"empty packed repeated", `{"packed": []}`, "", ""},
+		{"repeated strings are not packed", `{"tags": ["a", "b"]}`, "520161520162", ""},
+		{"map entries by key", `{"counts": {"b": 2, "a": 1}}`, "22050a0161100122050a01621002", ""},
+		{"enum by name", `{"color": "BLUE"}`, "2802", ""},
+		{"enum by number", `{"color": 1}`, "2801", ""},
+		{"oneof string", `{"name": "x"}`, "320178", ""},
+		{"oneof int64 as string", `{"id": "5"}`, "3805", ""},
+		{"timestamp", `{"at": "1970-01-01T00:00:01.5Z"}`, "420808011080cab5ee01", ""},
+		{"timestamp as message", `{"at": {"seconds": 1, "nanos": 500000000}}`, "420808011080cab5ee01", ""},
+		{"duration", `{"took": "1.5s"}`, "4a08080110" + "80cab5ee01", ""},
+		{"fields by number, proto names", `{"inner": {"value": "v"}, "i32": 1}`, "0801" + "62030a0176", ""},
+		{"null is unset", `{"inner": null, "name": null}`, "", ""},
+		{"unknown enum", `{"color": "PINK"}`, "", "test.Color has no value PINK"},
+		{"unknown field", `{"nope": 1}`, "", "test.All has no field nope"},
+		{"not an integer", `{"i32": 1.5}`, "", "test.All.i32: 1.5 is not an integer"},
+		{"bad timestamp", `{"at": "yesterday"}`, "", "timestamp must be an RFC 3339 string"},
+		{"bad duration", `{"took": "1.5"}`, "", "duration must be a string like 1.5s"},
+	}
+
+	for _, test := range tests {
+		var value any
+		if err := json.Unmarshal([]byte(test.value), &value); err != nil {
+			t.Fatalf("%s: invalid JSON %s", test.name, err.Error())
+		}
+
+		encoded, err := registry.Marshal("test.All", value)
+		if test.errorX != "" {
+			if err == nil || !strings.Contains(err.Error(), test.errorX) {
+				t.Errorf("%s: expected error %q but got %v", test.name, test.errorX, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error %s", test.name, err.Error())
+			continue
+		}
+		if hex.EncodeToString(encoded) != test.expected {
+			t.Errorf("%s: expected %s but got %x", test.name, test.expected, encoded)
+		}
+	}
+}
+
+func Test_Unmarshal(t *testing.T) {
+	registry := testRegistry(t)
+
+	tests := []struct {
+		name     string
+		data     string
+		expected string
+	}{
+		{
+			"defaults, unset messages and oneofs are left out", "",
+			`{"color":"RED","counts":{},"i32":0,"packed":[],"s32":0,"s64":0,"tags":[]}`,
+		},
+		{
+			"every kind of field",
+			"08ffffffffffffffffff01" + "1001" + "1a040102ac02" + "22050a01611001" + "2802" + "320178" + "3805" +
+				"420808011080cab5ee01" + "4a08080110" + "80cab5ee01" + "5805",
+			`{"at":"1970-01-01T00:00:01.5Z","color":"BLUE","counts":{"a":1},"i32":-1,"id":5,"packed":[1,2,300],"s32":-1,"s64":-3,"tags":[],"took":"1.5s"}`,
+		},
+		{
+			"unpacked repeated and unknown fields",
+			"1801" + "1802" + "980601" + "fa0601ff",
+			`{"color":"RED","counts":{},"i32":0,"packed":[1,2],"s32":0,"s64":0,"tags":[]}`,
+		},
+		{
+			"unknown enum number",
+			"2809",
+			`{"color":9,"counts":{},"i32":0,"packed":[],"s32":0,"s64":0,"tags":[]}`,
+		},
+	}
+
+	for _, test := range tests {
+		data, _ := hex.DecodeString(test.data)
+		decoded, err := registry.Unmarshal("test.All", data)
+		if err != nil {
+			t.Errorf("%s: unexpected error %s", test.name, err.Error())
+			continue
+		}
+		result, _ := json.Marshal(decoded)
+		if string(result) != test.expected {
+			t.Errorf("%s: expected %s but got %s", test.name, test.expected, result)
+		}
+	}
+
+	for _, data := range []string{"08", "0a05ab", "0f"} {
+		raw, _ := hex.DecodeString(data)
+		if _, err := registry.Unmarshal("test.All", raw); err == nil {
+			t.Errorf("%s: expected an error", data)
+		}
+	}
+}
+
+func Test_WellKnownTypes(t *testing.T) {
+	registry := testRegistry(t)
+
+	// JSON, encoded and decoded again
+	tests := []struct {
+		name   string
+		value  string
+		errorX string
+	}{
+		{"struct", `{"data":{"a":1,"b":[true,null,"x"],"c":{"d":null}}}`, ""},
+		{"empty struct", `{"data":{}}`, ""},
+		{"null value", `{"value":null}`, ""},
+		{"string value is not null", `{"value":"NULL_VALUE"}`, ""},
+		{"list value", `{"list":[1.5,"a",{"b":false},[]]}`, ""},
+		{"field mask", `{"mask":"user.displayName,id"}`, ""},
+		{"any of a message", `{"any":{"@type":"type.googleapis.com/test.Inner","value":"v"}}`, ""},
+		{"any of a well known type", `{"any":{"@type":"type.googleapis.com/google.protobuf.Duration","value":"2s"}}`, ""},
+		{"any of an any", `{"any":{"@type":"type.googleapis.com/google.protobuf.Any","value":{"@type":"type.googleapis.com/google.protobuf.Empty"}}}`, ""},
+		{"any of an unknown message", `{"any":{"@type":"type.googleapis.com/other.Message"}}`, `unknown message "type.googleapis.com/other.Message" of Any`},
+		{"struct must be an object", `{"data":[1]}`, "struct must be a JSON object"},
+		{"list value must be an array", `{"list":"a"}`, "list value must be a JSON array"},
+	}
+
+	for _, test := range tests {
+		var value any
+		if err := json.Unmarshal([]byte(test.value), &value); err != nil {
+			t.Fatalf("%s: invalid JSON %s", test.name, err.Error())
+		}
+
+		encoded, err := registry.Marshal("test.Known", value)
+		if test.errorX != "" {
+			if err == nil || !strings.Contains(err.Error(), test.errorX) {
+				t.Errorf("%s: expected error %q but got %v", test.name, test.errorX, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error %s", test.name, err.Error())
+			continue
+		}
+
+		decoded, err := registry.Unmarshal("test.Known", encoded)
+		if err != nil {
+			t.Errorf("%s: unexpected error %s", test.name, err.Error())
+			continue
+		}
+		result, _ := json.Marshal(decoded)
+		if string(result) != test.value {
+			t.Errorf("%s: expected %s but got %s", test.name, test.value, result)
+		}
+	}
+
+	// wire form of the JSON mappings
+	wireTests := []struct {
+		name     string
+		value    string
+		expected string
+	}{
+		{"struct is a map of values", `{"data":{"a":true}}`, "0a09" + "0a07" + "0a0161" + "12022001"},
+		{"null value is the enum", `{"value":null}`, "12020800"},
+		{"field mask paths are snake case", `{"mask":"displayName"}`, "2a0e" + "0a0c" + hex.EncodeToString([]byte("display_name"))},
+		{"any has the type url and the message", `{"any":{"@type":"t/test.Inner","value":"v"}}`, "2213" + "0a0c" + hex.EncodeToString([]byte("t/test.Inner")) + "1203" + "0a0176"},
+	}
+	for _, test := range wireTests {
+		var value any
+		json.Unmarshal([]byte(test.value), &value)
+		encoded, err := registry.Marshal("test.Known", value)
+		if err != nil {
+			t.Errorf("%s: unexpected error %s", test.name, err.Error())
+			continue
+		}
+		if hex.EncodeToString(encoded) != test.expected {
+			t.Errorf("%s: expected %s but got %x", test.name, test.expected, encoded)
+		}
+	}
+
+	// samples can be encoded
+	for _, message := range []string{"test.All", "test.Known"} {
+		if _, err := registry.Marshal(message, registry.Sample(message)); err != nil {
+			t.Errorf("sample of %s: unexpected error %s", message, err.Error())
+		}
+	}
+}
+
+func Test_Parse(t *testing.T) {
+	common := `
+syntax = "proto2";
+package common;
+message Page { repeated int32 sizes = 1; }
+`
+	service := `
+syntax = "proto3";
+package shop.v1;
+import "common.proto";
+message ListRequest { common.Page page = 1; repeated int32 ids = 2; }
+service Shop { rpc List(ListRequest) returns (common.Page); }
+`
+
+	registry, err := Parse(common, service)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+
+	s, found := registry.Services["shop.v1.Shop"]
+	if !found || s.Source != 1 {
+		t.Fatalf("expected shop.v1.Shop in source 1 but got %+v", s)
+	}
+	method, err := registry.Method("shop.v1.Shop", "List")
+	if err != nil || method.Input != "shop.v1.ListRequest" || method.Output != "common.Page" {
+		t.Errorf("unexpected method %+v %v", method, err)
+	}
+
+	// syntax is per file: proto2 repeated fields are not packed
+	if registry.Messages["common.Page"].Fields[0].Packed || !registry.Messages["shop.v1.ListRequest"].Fields[1].Packed {
+		t.Errorf("expected packed fields of proto3 only")
+	}
+
+	errorTests := []struct {
+		sources []string
+		errorX  string
+	}{
+		{[]string{service}, "common.Page"},
+		{[]string{common, common}, "common.Page"},
+		{[]string{"message A { int32 a = 0; }"}, "invalid field number 0"},
+	}
+	for _, test := range errorTests {
+		_, err := Parse(test.sources...)
+		if err == nil || !strings.Contains(err.Error(), test.errorX) {
+			t.Errorf("%v: expected error %q but got %v", test.sources, test.errorX, err)
+		}
+	}
+}
@@ -0,0 +1,267 @@
+package protobuf
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const conformanceProto = `
+syntax = "proto3";
+package conf;
+
+enum Kind {
+  ZERO = 0;
+  ONE = 1;
+  MINUS = -1;
+}
+
+message Scalars {
+  int32 i32 = 1;
+  sint32 s32 = 2;
+  sfixed32 f32 = 3;
+  uint32 u32 = 4;
+  Kind kind = 5;
+  optional int32 maybe = 6;
+  string text = 7;
+  Scalars child = 8;
+}
+
+message Item {
+  int32 id = 1;
+  string name = 2;
+}
+
+message Lists {
+  repeated int32 packed = 1;
+  repeated int32 unpacked = 2 [packed = false];
+  repeated sint32 zigzag = 3;
+  repeated fixed32 fixed = 4;
+  repeated double doubles = 5;
+  repeated Kind kinds = 6;
+  repeated Item items = 7;
+}
+
+message Choice {
+  oneof pick {
+    int32 number = 1;
+    string text = 2;
+    Item item = 3;
+  }
+}
+
+message Maps {
+  map<int32, string> by_id = 1;
+  map<bool, int32> flags = 2;
+  map<string, Item> items = 3;
+  map<string, Kind> kinds = 4;
+}
+`
+
+const conformanceProto2 = `
+syntax = "proto2";
+package old;
+
+message Lists {
+  repeated int32 plain = 1;
+  repeated int32 packed = 2 [packed = true];
+  optional int32 i32 = 3;
+}
+`
+
+func conformanceRegistry(t *testing.T, source string) *Registry {
+	t.Helper()
+	registry, err := Parse(source)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	return registry
+}
+
+// JSON values encoded as protoc and the Go protobuf runtime encode them
+func Test_Marshal_Conformance(t *testing.T) {
+	registry := conformanceRegistry(t, conformanceProto)
+	registry2 := conformanceRegistry(t, conformanceProto2)
+
+	tests := []struct {
+		name     string
+		registry *Registry
+		message  string
+		value    string
+		expected string
+		errorX   string
+	}{
+		// negative int32
+		{"int32 -1", registry, "conf.Scalars", `{"i32": -1}`, "08ffffffffffffffffff01", ""},
+		{"int32 min", registry, "conf.Scalars", `{"i32": -2147483648}`, "0880808080f8ffffffff01", ""},
+		{"sint32 min", registry, "conf.Scalars", `{"s32": -2147483648}`, "10ffffffff0f", ""},
+		{"sfixed32 -2", registry, "conf.Scalars", `{"f32": -2}`, "1dfeffffff", ""},
+		{"negative enum", registry, "conf.Scalars", `{"kind": "MINUS"}`, "28ffffffffffffffffff01", ""},
+		{"int32 above the range", registry, "conf.Scalars", `{"i32": 2147483648}`, "", "2147483648 is out of range for int32"},
+		{"int32 below the range", registry, "conf.Scalars", `{"i32": "-2147483649"}`, "", "-2147483649 is out of range for int32"},
+		{"sfixed32 above the range", registry, "conf.Scalars", `{"f32": 2147483648}`, "", "out of range for sfixed32"},
+		{"uint32 above the range", registry, "conf.Scalars", `{"u32": 4294967296}`, "", "4294967296 is out of range for uint32"},
+		{"negative uint32", registry, "conf.Scalars", `{"u32": -1}`, "", "-1 is negative"},
+		{"enum number above the range", registry, "conf.Scalars", `{"kind": 2147483648}`, "", "out of range for conf.Kind"},
+
+		// defaults
+		{"proto3 defaults are not written", registry, "conf.Scalars", `{"i32": 0, "u32": "0", "text": "", "kind": "ZERO", "f32": 0}`, "", ""},
+		{"optional default is written", registry, "conf.Scalars", `{"maybe": 0}`, "3000", ""},
+		{"empty message is written", registry, "conf.Scalars", `{"child": {}}`, "4200", ""},
+		{"proto2 default is written", registry2, "old.Lists", `{"i32": 0}`, "1800", ""},
+
+		// packed and unpacked repeated fields
+		{"packed with a negative value", registry, "conf.Lists", `{"packed": [1, -1]}`, "0a0b01ffffffffffffffffff01", ""},
+		{"packed=false", registry, "conf.Lists", `{"unpacked": [1, 2]}`, "10011002", ""},
+		{"empty unpacked", registry, "conf.Lists", `{"unpacked": []}`, "", ""},
+		{"packed sint32", registry, "conf.Lists", `{"zigzag": [-1, 1]}`, "1a020102", ""},
+		{"packed fixed32", registry, "conf.Lists", `{"fixed": [1, 2]}`, "22080100000002000000", ""},
+		{"packed double", registry, "conf.Lists", `{"doubles": [1.5]}`, "2a08000000000000f83f", ""},
+		{"packed enum", registry, "conf.Lists", `{"kinds": ["ONE", "MINUS"]}`, "320b01ffffffffffffffffff01", ""},
+		{"messages are never packed", registry, "conf.Lists", `{"items": [{"id": 1}, {}]}`, "3a0208013a00", ""},
+		{"proto2 is unpacked", registry2, "old.Lists", `{"plain": [1, 2]}`, "08010802", ""},
+		{"proto2 packed=true", registry2, "old.Lists", `{"packed": [1, 2]}`, "12020102", ""},
+
+		// oneof
+		{"oneof zero is written", registry, "conf.Choice", `{"number": 0}`, "0800", ""},
+		{"oneof empty string is written", registry, "conf.Choice", `{"text": ""}`, "1200", ""},
+		{"oneof empty message", registry, "conf.Choice", `{"item": {}}`, "1a00", ""},
+		{"oneof with null members", registry, "conf.Choice", `{"number": 1, "text": null}`, "0801", ""},
+		{"two members of a oneof", registry, "conf.Choice", `{"number": 1, "text": "a"}`, "", "number and text are both set in oneof pick"},
+
+		// maps
+		{"int32 keys", registry, "conf.Maps", `{"by_id": {"2": "b", "10": "a"}}`, "0a05080a1201610a050802120162", ""},
+		{"bool keys, zero values are written", registry, "conf.Maps", `{"flags": {"true": 1, "false": 0}}`, "120408001000120408011001", ""},
+		{"message values", registry, "conf.Maps", `{"items": {"a": {"id": 1}}}`, "1a070a016112020801", ""},
+		{"enum values", registry, "conf.Maps", `{"kinds": {"a": "MINUS"}}`, "220e0a016110ffffffffffffffffff01", ""},
+		{"key that is not an integer", registry, "conf.Maps", `{"by_id": {"x": "a"}}`, "", `"x" is not an integer`},
+		{"key out of range", registry, "conf.Maps", `{"by_id": {"2147483648": "a"}}`, "", "out of range for int32"},
+	}
+
+	for _, test := range tests {
+		var value any
+		if err := json.Unmarshal([]byte(test.value), &value); err != nil {
+			t.Fatalf("%s: invalid JSON %s", test.name, err.Error())
+		}
+
+		encoded, err := test.registry.Marshal(test.message, value)
+		if test.errorX != "" {
+			if err == nil || !strings.Contains(err.Error(), test.errorX) {
+				t.Errorf("%s: expected error %q but got %v", test.name, test.errorX, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error %s", test.name, err.Error())
+			continue
+		}
+		if hex.EncodeToString(encoded) != test.expected {
+			t.Errorf("%s: expected %s but got %x", test.name, test.expected, encoded)
+		}
+	}
+}
+
+// wire data decoded as the Go protobuf runtime decodes it, checked on one
+// field of the JSON value
+func Test_Unmarshal_Conformance(t *testing.T) {
+	registry := conformanceRegistry(t, conformanceProto)
+	registry2 := conformanceRegistry(t, conformanceProto2)
+
+	tests := []struct {
+		name     string
+		registry *Registry
+		message  string
+		data     string
+		field    string
+		expected string
+	}{
+		// negative int32
+		{"int32 -1", registry, "conf.Scalars", "08ffffffffffffffffff01", "i32", "-1"},
+		{"int32 -1 in five bytes", registry, "conf.Scalars", "08ffffffff0f", "i32", "-1"},
+		{"int32 min", registry, "conf.Scalars", "0880808080f8ffffffff01", "i32", "-2147483648"},
+		{"int32 from a 64 bit value", registry, "conf.Scalars", "088080808010", "i32", "0"},
+		{"sint32 min", registry, "conf.Scalars", "10ffffffff0f", "s32", "-2147483648"},
+		{"sint32 from a 64 bit value", registry, "conf.Scalars", "10ffffffffffffffffff01", "s32", "-2147483648"},
+		{"sfixed32 -2", registry, "conf.Scalars", "1dfeffffff", "f32", "-2"},
+		{"uint32 max", registry, "conf.Scalars", "20ffffffff0f", "u32", "4294967295"},
+		{"negative enum", registry, "conf.Scalars", "28ffffffffffffffffff01", "kind", `"MINUS"`},
+
+		// unknown fields
+		{"unknown varint", registry, "conf.Scalars", "a00696010801", "i32", "1"},
+		{"unknown fixed64", registry, "conf.Scalars", "a106010203040506070808010801", "i32", "1"},
+		{"unknown length delimited", registry, "conf.Scalars", "a20602abcd0801", "i32", "1"},
+		{"unknown fixed32", registry, "conf.Scalars", "a506010203040801", "i32", "1"},
+		{"unknown group", registry, "conf.Scalars", "a3060801a4060801", "i32", "1"},
+		{"unknown nested group", registry, "conf.Scalars", "a306130801140a00a4060801", "i32", "1"},
+		{"known number with another wire type", registry, "conf.Scalars", "0d01000000" + "3805" + "0801", "text", `""`},
+		{"known number with another wire type is not a value", registry, "conf.Scalars", "0d01000000", "i32", "0"},
+
+		// last value wins, messages are merged
+		{"last scalar wins", registry, "conf.Scalars", "08010802", "i32", "2"},
+		{"messages are merged", registry, "conf.Scalars", "42020801" + "42021002", "child", `{"f32":0,"i32":1,"kind":"ZERO","s32":1,"text":"","u32":0}`},
+		{"last scalar of merged messages wins", registry, "conf.Scalars", "42020801" + "42020802", "child", `{"f32":0,"i32":2,"kind":"ZERO","s32":0,"text":"","u32":0}`},
+
+		// packed and unpacked repeated fields
+		{"packed in two chunks", registry, "conf.Lists", "0a020102" + "0a0103", "packed", "[1,2,3]"},
+		{"packed field sent unpacked", registry, "conf.Lists", "08010802", "packed", "[1,2]"},
+		{"packed and unpacked mixed", registry, "conf.Lists", "0801" + "0a020203", "packed", "[1,2,3]"},
+		{"packed=false field sent packed", registry, "conf.Lists", "12020102", "unpacked", "[1,2]"},
+		{"packed negative int32", registry, "conf.Lists", "0a0b01ffffffffffffffffff01", "packed", "[1,-1]"},
+		{"packed sint32", registry, "conf.Lists", "1a020102", "zigzag", "[-1,1]"},
+		{"packed fixed32", registry, "conf.Lists", "22080100000002000000", "fixed", "[1,2]"},
+		{"packed double", registry, "conf.Lists", "2a08000000000000f83f", "doubles", "[1.5]"},
+		{"packed enum with an unknown number", registry, "conf.Lists", "32020102", "kinds", `["ONE",2]`},
+		{"repeated messages", registry, "conf.Lists", "3a020801" + "3a00", "items", `[{"id":1,"name":""},{"id":0,"name":""}]`},
+		{"proto2 field sent packed", registry2, "old.Lists", "0a020102", "plain", "[1,2]"},
+		{"proto2 packed field sent unpacked", registry2, "old.Lists", "10011002", "packed", "[1,2]"},
+
+		// oneof
+		{"oneof zero", registry, "conf.Choice", "0800", "number", "0"},
+		{"last oneof member wins", registry, "conf.Choice", "0801" + "120161", "text", `"a"`},
+		{"earlier oneof member is dropped", registry, "conf.Choice", "0801" + "120161", "number", "null"},
+		{"oneof messages are merged", registry, "conf.Choice", "1a020801" + "1a03120162", "item", `{"id":1,"name":"b"}`},
+		{"another member resets the message", registry, "conf.Choice", "1a020801" + "0802" + "1a03120162", "item", `{"id":0,"name":"b"}`},
+
+		// maps
+		{"int32 key", registry, "conf.Maps", "0a05080a120161", "byId", `{"10":"a"}`},
+		{"missing key", registry, "conf.Maps", "0a03120161", "byId", `{"0":"a"}`},
+		{"missing value", registry, "conf.Maps", "0a020801", "byId", `{"1":""}`},
+		{"value before the key", registry, "conf.Maps", "0a051201610801", "byId", `{"1":"a"}`},
+		{"last duplicate key wins", registry, "conf.Maps", "0a050801120161" + "0a050801120162", "byId", `{"1":"b"}`},
+		{"bool key", registry, "conf.Maps", "120408011005", "flags", `{"true":5}`},
+		{"missing message value", registry, "conf.Maps", "1a030a0161", "items", `{"a":{"id":0,"name":""}}`},
+		{"unknown enum value", registry, "conf.Maps", "22050a01611007", "kinds", `{"a":7}`},
+		{"map with another wire type", registry, "conf.Maps", "0801", "byId", `{}`},
+	}
+
+	for _, test := range tests {
+		data, _ := hex.DecodeString(test.data)
+		decoded, err := test.registry.Unmarshal(test.message, data)
+		if err != nil {
+			t.Errorf("%s: unexpected error %s", test.name, err.Error())
+			continue
+		}
+		result, _ := json.Marshal(decoded.(map[string]any)[test.field])
+		if string(result) != test.expected {
+			t.Errorf("%s: expected %s but got %s", test.name, test.expected, result)
+		}
+	}
+
+	// malformed data
+	for _, data := range []string{
+		"0e",                       // wire type 6
+		"0f",                       // wire type 7
+		"0c",                       // end of a group that did not start
+		"a3060801",                 // group without its end
+		"a1060102",                 // truncated fixed64
+		"0a030102",                 // truncated packed list
+		"0a0180",                   // truncated varint in a packed list
+		"08ffffffffffffffffffff01", // varint longer than ten bytes
+	} {
+		raw, _ := hex.DecodeString(data)
+		if _, err := registry.Unmarshal("conf.Lists", raw); err == nil {
+			t.Errorf("%s: expected an error", data)
+		}
+	}
+}
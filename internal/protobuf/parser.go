@@ -0,0 +1,770 @@
+package protobuf
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// token kinds
+const (
+	tokenEOF = iota
+	tokenIdent
+	tokenNumber
+	tokenString
+	tokenSymbol
+)
+
+type token struct {
+	kind  int
+	value string
+	line  int
+}
+
+// ------------------------------------------------------
+// tokens of a .proto source, comments are dropped
+// ------------------------------------------------------
+func tokenize(source string) ([]token, error) {
+	tokens := make([]token, 0)
+	line := 1
+
+	for i := 0; i < len(source); {
+		c := source[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+
+		case c == ' ' || c == '\t' || c == '\r' || c == '\f' || c == '\v':
+			i++
+
+		case strings.HasPrefix(source[i:], "//"):
+			for i < len(source) && source[i] != '\n' {
+				i++
+			}
+
+		case strings.HasPrefix(source[i:], "/*"):
+			end := strings.Index(source[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated comment", line)
+			}
+			line += strings.Count(source[i:i+2+end], "\n")
+			i += end + 4
+
+		case c == '"' || c == '\'':
+			start := i
+			i++
+			for i < len(source) && source[i] != c {
+				if source[i] == '\\' {
+					i++
+				}
+				if i < len(source) && source[i] == '\n' {
+					return nil, fmt.Errorf("line %d: unterminated string", line)
+				}
+				i++
+			}
+			if i >= len(source) {
+				return nil, fmt.Errorf("line %d: unterminated string", line)
+			}
+			i++
+			value, err := strconv.Unquote(`"` + strings.ReplaceAll(source[start+1:i-1], `"`, `\"`) + `"`)
+			if err != nil {
+				value = source[start+1 : i-1]
+			}
+			tokens = append(tokens, token{kind: tokenString, value: value, line: line})
+
+		case c == '_' || unicode.IsLetter(rune(c)):
+			start := i
+			for i < len(source) && (source[i] == '_' || source[i] == '.' || unicode.IsLetter(rune(source[i])) || unicode.IsDigit(rune(source[i]))) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, value: source[start:i], line: line})
+
+		case unicode.IsDigit(rune(c)) || (c == '.' && i+1 < len(source) && unicode.IsDigit(rune(source[i+1]))):
+			start := i
+			for i < len(source) && (source[i] == '.' || source[i] == '_' || unicode.IsLetter(rune(source[i])) || unicode.IsDigit(rune(source[i])) ||
+				((source[i] == '-' || source[i] == '+') && (source[i-1] == 'e' || source[i-1] == 'E'))) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, value: source[start:i], line: line})
+
+		default:
+			tokens = append(tokens, token{kind: tokenSymbol, value: string(c), line: line})
+			i++
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, line: line}), nil
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+type parser struct {
+	tokens []token
+	pos    int
+
+	registry *Registry
+
+	// file that is read: its index in the sources, package and syntax
+	source int
+	pkg    string
+	syntax string
+
+	// fields whose type is resolved when all files are read
+	pending []*pendingField
+}
+
+type pendingField struct {
+	field *Field
+	scope string
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (p *parser) current() token {
+	return p.tokens[p.pos]
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (p *parser) errorf(format string, args ...any) error {
+	return fmt.Errorf("line %d: %s", p.current().line, fmt.Sprintf(format, args...))
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (p *parser) peek(value string) bool {
+	t := p.current()
+	return (t.kind == tokenSymbol || t.kind == tokenIdent) && t.value == value
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (p *parser) skip(value string) bool {
+	if p.peek(value) {
+		p.next()
+		return true
+	}
+	return false
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (p *parser) expect(value string) error {
+	if !p.skip(value) {
+		return p.errorf("expected %q, found %q", value, p.current().value)
+	}
+	return nil
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (p *parser) expectIdent() (string, error) {
+	t := p.current()
+	if t.kind != tokenIdent {
+		return "", p.errorf("expected a name, found %q", t.value)
+	}
+	p.next()
+	return t.value, nil
+}
+
+// ------------------------------------------------------
+// names of types may start with a dot
+// ------------------------------------------------------
+func (p *parser) expectTypeName() (string, error) {
+	if p.skip(".") {
+		name, err := p.expectIdent()
+		return "." + name, err
+	}
+	return p.expectIdent()
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (p *parser) expectNumber() (int, error) {
+	negative := p.skip("-")
+	t := p.current()
+	if t.kind != tokenNumber {
+		return 0, p.errorf("expected a number, found %q", t.value)
+	}
+	p.next()
+
+	n, err := strconv.ParseInt(t.value, 0, 64)
+	if err != nil {
+		return 0, p.errorf("invalid number %q", t.value)
+	}
+	if negative {
+		n = -n
+	}
+	return int(n), nil
+}
+
+// ------------------------------------------------------
+// statement or block that has no meaning for the mock
+// ------------------------------------------------------
+func (p *parser) skipStatement() error {
+	depth := 0
+	for {
+		t := p.next()
+		switch {
+		case t.kind == tokenEOF:
+			return p.errorf("unexpected end of file")
+		case t.kind == tokenSymbol && t.value == "{":
+			depth++
+		case t.kind == tokenSymbol && t.value == "}":
+			depth--
+			if depth == 0 {
+				return nil
+			}
+		case t.kind == tokenSymbol && t.value == ";" && depth == 0:
+			return nil
+		}
+	}
+}
+
+// ------------------------------------------------------
+// [packed = true, deprecated = true]
+// ------------------------------------------------------
+func (p *parser) parseFieldOptions() (map[string]string, error) {
+	options := make(map[string]string)
+	if !p.skip("[") {
+		return options, nil
+	}
+
+	for !p.peek("]") {
+		t := p.next()
+		if t.kind == tokenEOF {
+			return nil, p.errorf("unexpected end of file")
+		}
+		if t.kind == tokenIdent && p.peek("=") {
+			p.next()
+			value := p.next()
+			options[t.value] = value.value
+		}
+	}
+	p.next()
+	return options, nil
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (p *parser) parseFile() error {
+	for p.current().kind != tokenEOF {
+		if p.skip(";") {
+			continue
+		}
+
+		keyword, err := p.expectIdent()
+		if err != nil {
+			return err
+		}
+
+		switch keyword {
+		case "syntax", "edition":
+			if err = p.expect("="); err != nil {
+				return err
+			}
+			p.syntax = p.next().value
+			if err = p.expect(";"); err != nil {
+				return err
+			}
+
+		case "package":
+			if p.pkg, err = p.expectIdent(); err != nil {
+				return err
+			}
+			if err = p.expect(";"); err != nil {
+				return err
+			}
+
+		case "import", "option":
+			// imported files are uploaded together
+			if err = p.skipStatement(); err != nil {
+				return err
+			}
+
+		case "message":
+			if _, err = p.parseMessage(p.pkg); err != nil {
+				return err
+			}
+
+		case "enum":
+			if _, err = p.parseEnum(p.pkg); err != nil {
+				return err
+			}
+
+		case "service":
+			if err = p.parseService(); err != nil {
+				return err
+			}
+
+		case "extend":
+			if _, err = p.expectIdent(); err != nil {
+				return err
+			}
+			if err = p.skipStatement(); err != nil {
+				return err
+			}
+
+		default:
+			return p.errorf("unexpected %q", keyword)
+		}
+	}
+	return nil
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func qualify(scope string, name string) string {
+	if scope == "" {
+		return name
+	}
+	return scope + "." + name
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (p *parser) parseMessage(scope string) (*Message, error) {
+	name, err := p.expectIdent()
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Message{Name: name, FullName: qualify(scope, name), Syntax: p.syntax}
+	if _, found := p.registry.Messages[m.FullName]; found {
+		return nil, p.errorf("message %s is defined more than once", m.FullName)
+	}
+	p.registry.Messages[m.FullName] = m
+
+	if err = p.expect("{"); err != nil {
+		return nil, err
+	}
+	if err = p.parseMessageBody(m, ""); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ------------------------------------------------------
+// fields of a message or of a oneof in it
+// ------------------------------------------------------
+func (p *parser) parseMessageBody(m *Message, oneof string) error {
+	for !p.skip("}") {
+		if p.current().kind == tokenEOF {
+			return p.errorf("unexpected end of file in message %s", m.FullName)
+		}
+		if p.skip(";") {
+			continue
+		}
+
+		switch {
+		case p.peek("message") && oneof == "":
+			p.next()
+			if _, err := p.parseMessage(m.FullName); err != nil {
+				return err
+			}
+
+		case p.peek("enum") && oneof == "":
+			p.next()
+			if _, err := p.parseEnum(m.FullName); err != nil {
+				return err
+			}
+
+		case p.peek("oneof") && oneof == "":
+			p.next()
+			name, err := p.expectIdent()
+			if err != nil {
+				return err
+			}
+			if err = p.expect("{"); err != nil {
+				return err
+			}
+			if err = p.parseMessageBody(m, name); err != nil {
+				return err
+			}
+
+		case p.peek("option"), p.peek("reserved"), p.peek("extensions"), p.peek("extend"):
+			if err := p.skipStatement(); err != nil {
+				return err
+			}
+
+		case p.peek("map"):
+			p.next()
+			if err := p.parseMapField(m); err != nil {
+				return err
+			}
+
+		default:
+			if err := p.parseField(m, oneof); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ------------------------------------------------------
+// [repeated|optional|required] type name = number [options];
+// ------------------------------------------------------
+func (p *parser) parseField(m *Message, oneof string) error {
+	f := &Field{Oneof: oneof}
+
+	switch {
+	case p.skip("repeated"):
+		f.Repeated = true
+	case p.skip("optional"):
+		f.Optional = true
+	case p.skip("required"):
+	}
+
+	typeName, err := p.expectTypeName()
+	if err != nil {
+		return err
+	}
+	if typeName == "group" {
+		return p.errorf("groups are not supported")
+	}
+
+	if f.Name, err = p.expectIdent(); err != nil {
+		return err
+	}
+	if err = p.expect("="); err != nil {
+		return err
+	}
+	if f.Number, err = p.expectNumber(); err != nil {
+		return err
+	}
+
+	options, err := p.parseFieldOptions()
+	if err != nil {
+		return err
+	}
+	if err = p.expect(";"); err != nil {
+		return err
+	}
+
+	f.JSONName = jsonName(f.Name)
+	if name, found := options["json_name"]; found {
+		f.JSONName = name
+	}
+	f.Packed = f.Repeated && p.syntax != `proto2`
+	if packed, found := options["packed"]; found {
+		f.Packed = f.Repeated && packed == "true"
+	}
+
+	p.setType(f, typeName, m.FullName)
+	return m.addField(f)
+}
+
+// ------------------------------------------------------
+// map<key, value> name = number;
+// ------------------------------------------------------
+func (p *parser) parseMapField(m *Message) error {
+	if err := p.expect("<"); err != nil {
+		return err
+	}
+	keyType, err := p.expectIdent()
+	if err != nil {
+		return err
+	}
+	if _, found := scalarWireTypes[keyType]; !found || keyType == "float" || keyType == "double" || keyType == "bytes" {
+		return p.errorf("invalid map key type %s", keyType)
+	}
+	if err = p.expect(","); err != nil {
+		return err
+	}
+	valueType, err := p.expectTypeName()
+	if err != nil {
+		return err
+	}
+	if err = p.expect(">"); err != nil {
+		return err
+	}
+
+	f := &Field{Repeated: true}
+	if f.Name, err = p.expectIdent(); err != nil {
+		return err
+	}
+	if err = p.expect("="); err != nil {
+		return err
+	}
+	if f.Number, err = p.expectNumber(); err != nil {
+		return err
+	}
+	if _, err = p.parseFieldOptions(); err != nil {
+		return err
+	}
+	if err = p.expect(";"); err != nil {
+		return err
+	}
+	f.JSONName = jsonName(f.Name)
+
+	// entries are messages with the key as field 1 and the value as field 2
+	f.MapKey = &Field{Name: "key", JSONName: "key", Number: 1, Type: keyType}
+	f.MapValue = &Field{Name: "value", JSONName: "value", Number: 2}
+	p.setType(f.MapValue, valueType, m.FullName)
+
+	return m.addField(f)
+}
+
+// ------------------------------------------------------
+// scalar types are known, messages and enums are resolved later
+// ------------------------------------------------------
+func (p *parser) setType(f *Field, typeName string, scope string) {
+	if _, found := scalarWireTypes[typeName]; found {
+		f.Type = typeName
+		return
+	}
+	f.TypeName = typeName
+	p.pending = append(p.pending, &pendingField{field: f, scope: scope})
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (p *parser) parseEnum(scope string) (*Enum, error) {
+	name, err := p.expectIdent()
+	if err != nil {
+		return nil, err
+	}
+
+	e := &Enum{Name: name, FullName: qualify(scope, name)}
+	if _, found := p.registry.Enums[e.FullName]; found {
+		return nil, p.errorf("enum %s is defined more than once", e.FullName)
+	}
+	p.registry.Enums[e.FullName] = e
+
+	if err = p.expect("{"); err != nil {
+		return nil, err
+	}
+
+	for !p.skip("}") {
+		if p.current().kind == tokenEOF {
+			return nil, p.errorf("unexpected end of file in enum %s", e.FullName)
+		}
+		if p.skip(";") {
+			continue
+		}
+		if p.peek("option") || p.peek("reserved") {
+			if err = p.skipStatement(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		value := &EnumValue{}
+		if value.Name, err = p.expectIdent(); err != nil {
+			return nil, err
+		}
+		if err = p.expect("="); err != nil {
+			return nil, err
+		}
+		if value.Number, err = p.expectNumber(); err != nil {
+			return nil, err
+		}
+		if _, err = p.parseFieldOptions(); err != nil {
+			return nil, err
+		}
+		if err = p.expect(";"); err != nil {
+			return nil, err
+		}
+		e.Values = append(e.Values, value)
+	}
+
+	if len(e.Values) == 0 {
+		return nil, p.errorf("enum %s has no values", e.FullName)
+	}
+	return e, nil
+}
+
+// ------------------------------------------------------
+// service Name { rpc Method (stream Request) returns (stream Response); }
+// ------------------------------------------------------
+func (p *parser) parseService() error {
+	name, err := p.expectIdent()
+	if err != nil {
+		return err
+	}
+
+	s := &Service{Name: name, FullName: qualify(p.pkg, name), Source: p.source}
+	if _, found := p.registry.Services[s.FullName]; found {
+		return p.errorf("service %s is defined more than once", s.FullName)
+	}
+	p.registry.Services[s.FullName] = s
+	p.registry.serviceOrder = append(p.registry.serviceOrder, s.FullName)
+
+	if err = p.expect("{"); err != nil {
+		return err
+	}
+
+	for !p.skip("}") {
+		if p.current().kind == tokenEOF {
+			return p.errorf("unexpected end of file in service %s", s.FullName)
+		}
+		if p.skip(";") {
+			continue
+		}
+		if p.peek("option") {
+			if err = p.skipStatement(); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err = p.expect("rpc"); err != nil {
+			return err
+		}
+
+		m := &Method{}
+		if m.Name, err = p.expectIdent(); err != nil {
+			return err
+		}
+
+		if err = p.expect("("); err != nil {
+			return err
+		}
+		m.ClientStreaming = p.peek("stream") && p.tokens[p.pos+1].kind == tokenIdent
+		if m.ClientStreaming {
+			p.next()
+		}
+		input, err := p.expectTypeName()
+		if err != nil {
+			return err
+		}
+		if err = p.expect(")"); err != nil {
+			return err
+		}
+
+		if err = p.expect("returns"); err != nil {
+			return err
+		}
+		if err = p.expect("("); err != nil {
+			return err
+		}
+		m.ServerStreaming = p.peek("stream") && p.tokens[p.pos+1].kind == tokenIdent
+		if m.ServerStreaming {
+			p.next()
+		}
+		output, err := p.expectTypeName()
+		if err != nil {
+			return err
+		}
+		if err = p.expect(")"); err != nil {
+			return err
+		}
+
+		if p.peek("{") {
+			if err = p.skipStatement(); err != nil {
+				return err
+			}
+		} else if err = p.expect(";"); err != nil {
+			return err
+		}
+
+		// request and response are resolved like field types
+		m.input = &Field{TypeName: input}
+		m.output = &Field{TypeName: output}
+		p.pending = append(p.pending, &pendingField{field: m.input, scope: s.FullName}, &pendingField{field: m.output, scope: s.FullName})
+
+		s.Methods = append(s.Methods, m)
+	}
+	return nil
+}
+
+// ------------------------------------------------------
+// names are looked up from the innermost scope out, a leading
+// dot makes the name absolute
+// ------------------------------------------------------
+func (p *parser) resolve() error {
+	for _, pending := range p.pending {
+		f := pending.field
+		name := f.TypeName
+
+		candidates := make([]string, 0)
+		if strings.HasPrefix(name, ".") {
+			candidates = append(candidates, strings.TrimPrefix(name, "."))
+		} else {
+			scope := pending.scope
+			for {
+				candidates = append(candidates, qualify(scope, name))
+				if scope == "" {
+					break
+				}
+				if i := strings.LastIndex(scope, "."); i >= 0 {
+					scope = scope[:i]
+				} else {
+					scope = ""
+				}
+			}
+		}
+
+		resolved := false
+		for _, candidate := range candidates {
+			if _, found := p.registry.Messages[candidate]; found {
+				f.Type, f.TypeName, resolved = TypeMessage, candidate, true
+				break
+			}
+			if _, found := p.registry.Enums[candidate]; found {
+				f.Type, f.TypeName, resolved = TypeEnum, candidate, true
+				break
+			}
+		}
+		if !resolved {
+			return fmt.Errorf("unknown type %s used in %s", name, pending.scope)
+		}
+	}
+
+	for _, s := range p.registry.Services {
+		for _, m := range s.Methods {
+			if m.input.Type != TypeMessage || m.output.Type != TypeMessage {
+				return fmt.Errorf("request and response of %s/%s must be messages", s.FullName, m.Name)
+			}
+			m.Input = m.input.TypeName
+			m.Output = m.output.TypeName
+		}
+	}
+	return nil
+}
+
+// ------------------------------------------------------
+// lowerCamelCase like protoc
+// ------------------------------------------------------
+func jsonName(name string) string {
+	var b strings.Builder
+	upper := false
+	for _, r := range name {
+		if r == '_' {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
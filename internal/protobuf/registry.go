@@ -0,0 +1,384 @@
+package protobuf
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// field types that are not scalars
+const (
+	TypeMessage = "message"
+	TypeEnum    = "enum"
+)
+
+// wire types
+const (
+	wireVarint     = 0
+	wireFixed64    = 1
+	wireBytes      = 2
+	wireStartGroup = 3
+	wireEndGroup   = 4
+	wireFixed32    = 5
+)
+
+var scalarWireTypes = map[string]int{
+	"double":   wireFixed64,
+	"float":    wireFixed32,
+	"int32":    wireVarint,
+	"int64":    wireVarint,
+	"uint32":   wireVarint,
+	"uint64":   wireVarint,
+	"sint32":   wireVarint,
+	"sint64":   wireVarint,
+	"fixed32":  wireFixed32,
+	"fixed64":  wireFixed64,
+	"sfixed32": wireFixed32,
+	"sfixed64": wireFixed64,
+	"bool":     wireVarint,
+	"string":   wireBytes,
+	"bytes":    wireBytes,
+}
+
+// well known types, usable without uploading their files
+const wellKnownTypes = `
+syntax = "proto3";
+package google.protobuf;
+message Empty {}
+message Timestamp { int64 seconds = 1; int32 nanos = 2; }
+message Duration { int64 seconds = 1; int32 nanos = 2; }
+message DoubleValue { double value = 1; }
+message FloatValue { float value = 1; }
+message Int64Value { int64 value = 1; }
+message UInt64Value { uint64 value = 1; }
+message Int32Value { int32 value = 1; }
+message UInt32Value { uint32 value = 1; }
+message BoolValue { bool value = 1; }
+message StringValue { string value = 1; }
+message BytesValue { bytes value = 1; }
+message Struct { map<string, Value> fields = 1; }
+message Value {
+  oneof kind {
+    NullValue null_value = 1;
+    double number_value = 2;
+    string string_value = 3;
+    bool bool_value = 4;
+    Struct struct_value = 5;
+    ListValue list_value = 6;
+  }
+}
+enum NullValue { NULL_VALUE = 0; }
+message ListValue { repeated Value values = 1; }
+message FieldMask { repeated string paths = 1; }
+message Any { string type_url = 1; bytes value = 2; }
+`
+
+type Field struct {
+	Name     string
+	JSONName string
+	Number   int
+
+	// scalar type name, TypeMessage or TypeEnum
+	Type string
+	// full name of the message or enum
+	TypeName string
+
+	Repeated bool
+	Optional bool
+	Packed   bool
+	Oneof    string
+
+	// map fields are repeated key/value entries
+	MapKey   *Field
+	MapValue *Field
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (f *Field) IsMap() bool {
+	return f.MapKey != nil
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (f *Field) wireType() int {
+	switch f.Type {
+	case TypeMessage:
+		return wireBytes
+	case TypeEnum:
+		return wireVarint
+	}
+	return scalarWireTypes[f.Type]
+}
+
+type Message struct {
+	Name     string
+	FullName string
+	Syntax   string
+
+	Fields   []*Field
+	byNumber map[int]*Field
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (m *Message) addField(f *Field) error {
+	if m.byNumber == nil {
+		m.byNumber = make(map[int]*Field)
+	}
+	if f.Number < 1 || f.Number > 536870911 {
+		return fmt.Errorf("invalid field number %d of %s.%s", f.Number, m.FullName, f.Name)
+	}
+	if existing, found := m.byNumber[f.Number]; found {
+		return fmt.Errorf("field number %d of %s is used by %s and %s", f.Number, m.FullName, existing.Name, f.Name)
+	}
+	m.byNumber[f.Number] = f
+	m.Fields = append(m.Fields, f)
+	return nil
+}
+
+// ------------------------------------------------------
+// by the JSON name or the proto name
+// ------------------------------------------------------
+func (m *Message) Field(name string) *Field {
+	for _, f := range m.Fields {
+		if f.JSONName == name || f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+type EnumValue struct {
+	Name   string
+	Number int
+}
+
+type Enum struct {
+	Name     string
+	FullName string
+	Values   []*EnumValue
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (e *Enum) ValueByName(name string) (*EnumValue, bool) {
+	for _, v := range e.Values {
+		if v.Name == name {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (e *Enum) ValueByNumber(number int) (*EnumValue, bool) {
+	for _, v := range e.Values {
+		if v.Number == number {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+type Method struct {
+	Name string
+
+	// full names of the messages
+	Input  string
+	Output string
+
+	ClientStreaming bool
+	ServerStreaming bool
+
+	input  *Field
+	output *Field
+}
+
+type Service struct {
+	Name     string
+	FullName string
+	Methods  []*Method
+
+	// index of the source passed to Parse that defines the service
+	Source int
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (s *Service) Method(name string) *Method {
+	for _, m := range s.Methods {
+		if m.Name == name {
+			return m
+		}
+	}
+	return nil
+}
+
+// ------------------------------------------------------
+// messages, enums and services of a set of .proto files
+// ------------------------------------------------------
+type Registry struct {
+	Messages map[string]*Message
+	Enums    map[string]*Enum
+	Services map[string]*Service
+
+	serviceOrder []string
+}
+
+// ------------------------------------------------------
+// Parse reads .proto files, one source per file. Imports are not read,
+// the imported files are passed in the same call.
+// ------------------------------------------------------
+func Parse(sources ...string) (*Registry, error) {
+	r := &Registry{
+		Messages: make(map[string]*Message),
+		Enums:    make(map[string]*Enum),
+		Services: make(map[string]*Service),
+	}
+
+	p := &parser{registry: r}
+	for i, source := range append([]string{wellKnownTypes}, sources...) {
+		tokens, err := tokenize(source)
+		if err != nil {
+			return nil, err
+		}
+		p.tokens, p.pos, p.source, p.pkg, p.syntax = tokens, 0, i-1, "", "proto2"
+
+		err = p.parseFile()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err := p.resolve()
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// ------------------------------------------------------
+// services in the order of the sources
+// ------------------------------------------------------
+func (r *Registry) ServiceList() []*Service {
+	services := make([]*Service, 0, len(r.serviceOrder))
+	for _, name := range r.serviceOrder {
+		services = append(services, r.Services[name])
+	}
+	return services
+}
+
+// ------------------------------------------------------
+// method of /package.Service/Method
+// ------------------------------------------------------
+func (r *Registry) Method(service string, method string) (*Method, error) {
+	s, found := r.Services[service]
+	if !found {
+		return nil, fmt.Errorf("unknown service %s", service)
+	}
+	m := s.Method(method)
+	if m == nil {
+		return nil, fmt.Errorf("unknown method %s of %s", method, service)
+	}
+	return m, nil
+}
+
+// ------------------------------------------------------
+// Sample is a JSON value of the message with every field set
+// ------------------------------------------------------
+func (r *Registry) Sample(message string) map[string]any {
+	return r.sample(message, map[string]bool{})
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (r *Registry) sample(message string, seen map[string]bool) map[string]any {
+	value := make(map[string]any)
+
+	m, found := r.Messages[message]
+	if !found || seen[message] {
+		return value
+	}
+	seen[message] = true
+	defer delete(seen, message)
+
+	oneofs := make(map[string]bool)
+	for _, f := range m.Fields {
+		if f.Oneof != "" {
+			if oneofs[f.Oneof] {
+				continue
+			}
+			oneofs[f.Oneof] = true
+		}
+
+		switch {
+		case f.IsMap():
+			value[f.JSONName] = map[string]any{sampleMapKey(f.MapKey.Type): r.sampleValue(f.MapValue, seen)}
+		case f.Repeated:
+			value[f.JSONName] = []any{r.sampleValue(f, seen)}
+		default:
+			value[f.JSONName] = r.sampleValue(f, seen)
+		}
+	}
+	return value
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (r *Registry) sampleValue(f *Field, seen map[string]bool) any {
+	switch f.Type {
+	case TypeMessage:
+		if special, found := wellKnownSamples[f.TypeName]; found {
+			return special
+		}
+		return r.sample(f.TypeName, seen)
+	case TypeEnum:
+		return r.Enums[f.TypeName].Values[0].Name
+	case "string":
+		return f.Name
+	case "bytes":
+		return ""
+	case "bool":
+		return true
+	case "double", "float":
+		return 1.5
+	}
+	return 1
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func sampleMapKey(keyType string) string {
+	switch keyType {
+	case "string":
+		return "key"
+	case "bool":
+		return "true"
+	}
+	return "1"
+}
+
+// ------------------------------------------------------
+// MessageNames sorted, for messages in errors and pages
+// ------------------------------------------------------
+func (r *Registry) MessageNames() []string {
+	names := make([]string, 0, len(r.Messages))
+	for name := range r.Messages {
+		if !strings.HasPrefix(name, "google.protobuf.") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
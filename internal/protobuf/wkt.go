@@ -0,0 +1,281 @@
+package protobuf
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// prefix of the type url of Any
+const anyTypePrefix = "type.googleapis.com/"
+
+// values of the well known types in JSON samples
+var wellKnownSamples = map[string]any{
+	"google.protobuf.Empty":       map[string]any{},
+	"google.protobuf.Timestamp":   "2023-01-01T00:00:00Z",
+	"google.protobuf.Duration":    "1.5s",
+	"google.protobuf.DoubleValue": 1.5,
+	"google.protobuf.FloatValue":  1.5,
+	"google.protobuf.Int64Value":  1,
+	"google.protobuf.UInt64Value": 1,
+	"google.protobuf.Int32Value":  1,
+	"google.protobuf.UInt32Value": 1,
+	"google.protobuf.BoolValue":   true,
+	"google.protobuf.StringValue": "value",
+	"google.protobuf.BytesValue":  "",
+	"google.protobuf.Struct":      map[string]any{"key": "value"},
+	"google.protobuf.Value":       "value",
+	"google.protobuf.ListValue":   []any{"value"},
+	"google.protobuf.FieldMask":   "fieldName",
+	"google.protobuf.Any":         map[string]any{"@type": anyTypePrefix + "google.protobuf.StringValue", "value": "value"},
+}
+
+// wrappers are their value in JSON
+var wrappers = map[string]bool{
+	"google.protobuf.DoubleValue": true,
+	"google.protobuf.FloatValue":  true,
+	"google.protobuf.Int64Value":  true,
+	"google.protobuf.UInt64Value": true,
+	"google.protobuf.Int32Value":  true,
+	"google.protobuf.UInt32Value": true,
+	"google.protobuf.BoolValue":   true,
+	"google.protobuf.StringValue": true,
+	"google.protobuf.BytesValue":  true,
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func isWrapper(message string) bool {
+	return wrappers[message]
+}
+
+// ------------------------------------------------------
+// messages with their own JSON form, in an Any they are under "value"
+// ------------------------------------------------------
+func hasJSONForm(message string) bool {
+	_, found := wellKnownSamples[message]
+	return found && message != "google.protobuf.Empty"
+}
+
+// ------------------------------------------------------
+// Timestamps are RFC 3339 strings, durations "1.5s", wrappers their
+// value, Struct/Value/ListValue any JSON, field masks "a,b.cD" and Any
+// {"@type": "type.googleapis.com/pkg.Message", ...fields}. The message
+// form is accepted as well, except for Struct and Value.
+// ------------------------------------------------------
+func (r *Registry) wellKnownToMessage(message string, value any) (any, error) {
+	switch message {
+	case "google.protobuf.Struct":
+		if _, ok := value.(map[string]any); !ok && value != nil {
+			return nil, fmt.Errorf("struct must be a JSON object")
+		}
+		if value == nil {
+			return nil, nil
+		}
+		return map[string]any{"fields": value}, nil
+
+	case "google.protobuf.Value":
+		switch v := value.(type) {
+		case nil:
+			return map[string]any{"nullValue": "NULL_VALUE"}, nil
+		case bool:
+			return map[string]any{"boolValue": v}, nil
+		case string:
+			return map[string]any{"stringValue": v}, nil
+		case float64, int, int64, json.Number:
+			return map[string]any{"numberValue": v}, nil
+		case []any:
+			return map[string]any{"listValue": v}, nil
+		case map[string]any:
+			return map[string]any{"structValue": v}, nil
+		}
+		return nil, fmt.Errorf("%v is not a JSON value", value)
+
+	case "google.protobuf.Any":
+		if object, ok := value.(map[string]any); ok {
+			if _, found := object["@type"]; found {
+				return r.anyToMessage(object)
+			}
+		}
+	}
+
+	if _, ok := value.(map[string]any); ok || value == nil {
+		return value, nil
+	}
+
+	switch {
+	case message == "google.protobuf.Timestamp":
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("timestamp must be an RFC 3339 string")
+		}
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return nil, fmt.Errorf("timestamp must be an RFC 3339 string: %s", err.Error())
+		}
+		return map[string]any{"seconds": t.Unix(), "nanos": int64(t.Nanosecond())}, nil
+
+	case message == "google.protobuf.Duration":
+		s, ok := value.(string)
+		if !ok || !strings.HasSuffix(s, "s") {
+			return nil, fmt.Errorf("duration must be a string like 1.5s")
+		}
+		seconds, err := strconv.ParseFloat(strings.TrimSuffix(s, "s"), 64)
+		if err != nil {
+			return nil, fmt.Errorf("duration must be a string like 1.5s")
+		}
+		d := time.Duration(seconds * float64(time.Second))
+		return map[string]any{"seconds": int64(d / time.Second), "nanos": int64(d % time.Second)}, nil
+
+	case message == "google.protobuf.ListValue":
+		if _, ok := value.([]any); !ok {
+			return nil, fmt.Errorf("list value must be a JSON array")
+		}
+		return map[string]any{"values": value}, nil
+
+	case message == "google.protobuf.FieldMask":
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("field mask must be a string like a,b.cD")
+		}
+		paths := make([]any, 0)
+		for _, path := range strings.Split(s, ",") {
+			if path = strings.TrimSpace(path); path != "" {
+				paths = append(paths, snakeCase(path))
+			}
+		}
+		return map[string]any{"paths": paths}, nil
+
+	case isWrapper(message):
+		return map[string]any{"value": value}, nil
+	}
+
+	return value, nil
+}
+
+// ------------------------------------------------------
+// the message of the type url is encoded in value
+// ------------------------------------------------------
+func (r *Registry) anyToMessage(object map[string]any) (any, error) {
+	typeURL, _ := object["@type"].(string)
+	name := typeURL[strings.LastIndex(typeURL, "/")+1:]
+	if _, found := r.Messages[name]; !found {
+		return nil, fmt.Errorf("unknown message %q of Any, upload its .proto with the service", typeURL)
+	}
+
+	var payload any
+	if hasJSONForm(name) {
+		payload = object["value"]
+	} else {
+		fields := make(map[string]any, len(object))
+		for k, v := range object {
+			if k != "@type" {
+				fields[k] = v
+			}
+		}
+		payload = fields
+	}
+
+	encoded, err := r.Marshal(name, payload)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"typeUrl": typeURL, "value": base64.StdEncoding.EncodeToString(encoded)}, nil
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (r *Registry) wellKnownFromMessage(message string, object map[string]any) (any, error) {
+	switch {
+	case message == "google.protobuf.Timestamp":
+		seconds, _ := object["seconds"].(int64)
+		nanos, _ := object["nanos"].(int64)
+		return time.Unix(seconds, nanos).UTC().Format(time.RFC3339Nano), nil
+
+	case message == "google.protobuf.Duration":
+		seconds, _ := object["seconds"].(int64)
+		nanos, _ := object["nanos"].(int64)
+		d := time.Duration(seconds)*time.Second + time.Duration(nanos)
+		return strconv.FormatFloat(d.Seconds(), 'f', -1, 64) + "s", nil
+
+	case message == "google.protobuf.Struct":
+		return object["fields"], nil
+
+	case message == "google.protobuf.Value":
+		// only the field of the oneof that is set, none is null
+		for k, v := range object {
+			if k == "nullValue" {
+				return nil, nil
+			}
+			return v, nil
+		}
+		return nil, nil
+
+	case message == "google.protobuf.ListValue":
+		return object["values"], nil
+
+	case message == "google.protobuf.FieldMask":
+		paths := make([]string, 0)
+		list, _ := object["paths"].([]any)
+		for _, path := range list {
+			paths = append(paths, jsonName(fmt.Sprint(path)))
+		}
+		return strings.Join(paths, ","), nil
+
+	case message == "google.protobuf.Any":
+		return r.anyFromMessage(object)
+
+	case isWrapper(message):
+		return object["value"], nil
+	}
+
+	return object, nil
+}
+
+// ------------------------------------------------------
+//
+// ------------------------------------------------------
+func (r *Registry) anyFromMessage(object map[string]any) (any, error) {
+	typeURL, _ := object["typeUrl"].(string)
+	if typeURL == "" {
+		return map[string]any{}, nil
+	}
+
+	name := typeURL[strings.LastIndex(typeURL, "/")+1:]
+	if _, found := r.Messages[name]; !found {
+		return nil, fmt.Errorf("unknown message %q of Any, upload its .proto with the service", typeURL)
+	}
+
+	encoded, _ := base64.StdEncoding.DecodeString(fmt.Sprint(object["value"]))
+	decoded, err := r.Unmarshal(name, encoded)
+	if err != nil {
+		return nil, err
+	}
+
+	if fields, ok := decoded.(map[string]any); ok && !hasJSONForm(name) {
+		fields["@type"] = typeURL
+		return fields, nil
+	}
+	return map[string]any{"@type": typeURL, "value": decoded}, nil
+}
+
+// ------------------------------------------------------
+// paths of field masks are lowerCamelCase in JSON
+// ------------------------------------------------------
+func snakeCase(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		if unicode.IsUpper(r) {
+			b.WriteByte('_')
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
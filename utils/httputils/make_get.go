@@ -2,7 +2,9 @@ package httputils
 
 import (
 	"bytes"
-	"io/ioutil"
+	"context"
+	"fmt"
+	"io"
 	"net/http"
 	"time"
 )
@@ -12,110 +14,135 @@ type HttpCallResult struct {
 	StatusCode int
 	Body       string
 	Err        error
+
+	// body of a streamed call, Body is blank. Must be closed.
+	Stream io.ReadCloser
+}
+
+// --------------------------------------------------------
+// zero timeouts use the defaults
+// --------------------------------------------------------
+type CallOptions struct {
+	ConnectTimeout time.Duration // dial and TLS handshake
+	ReadTimeout    time.Duration // wait for the response headers, and for each read of the body
+	Stream         bool          // leave the body in HttpCallResult.Stream
+
+	// context of the incoming request: the call ends when the client
+	// goes away. nil is context.Background()
+	Context context.Context
 }
 
+const (
+	DefaultConnectTimeout = 10 * time.Second
+	DefaultReadTimeout    = 30 * time.Second
+)
+
 //--------------------------------------------------------
 //
 //--------------------------------------------------------
 
 func HttpGET(url string, header http.Header) *HttpCallResult {
-
-	httpCallResult := &HttpCallResult{}
-
-	req, err := http.NewRequest(http.MethodGet, url, nil)
-	if err != nil {
-		httpCallResult.Err = err
-		return httpCallResult
-	}
-
-	req.Header = header
-
-	return HttpProcessRequest(req)
-
+	return HttpCall(http.MethodGet, url, header, nil, CallOptions{})
 }
 
 // --------------------------------------------------------
 //
 // --------------------------------------------------------
 func HttpPOST(url string, header http.Header, requestPayLoad []byte) *HttpCallResult {
-	httpCallResult := &HttpCallResult{}
-	bodyReader := bytes.NewReader(requestPayLoad)
-
-	req, err := http.NewRequest(http.MethodPost, url, bodyReader)
-	if err != nil {
-		httpCallResult.Err = err
-		return httpCallResult
-	}
-
-	req.Header = header
-
-	return HttpProcessRequest(req)
+	return HttpCall(http.MethodPost, url, header, requestPayLoad, CallOptions{})
 }
 
 // --------------------------------------------------------
 //
 // --------------------------------------------------------
 func HttpPUT(url string, header http.Header, requestPayLoad []byte) *HttpCallResult {
-	httpCallResult := &HttpCallResult{}
-	bodyReader := bytes.NewReader(requestPayLoad)
-
-	req, err := http.NewRequest(http.MethodPut, url, bodyReader)
-	if err != nil {
-		httpCallResult.Err = err
-		return httpCallResult
-	}
-
-	req.Header = header
-
-	return HttpProcessRequest(req)
+	return HttpCall(http.MethodPut, url, header, requestPayLoad, CallOptions{})
 }
 
 // --------------------------------------------------------
 //
 // --------------------------------------------------------
 func HttpDELETE(url string, header http.Header, requestPayLoad []byte) *HttpCallResult {
+	return HttpCall(http.MethodDelete, url, header, requestPayLoad, CallOptions{})
+}
+
+// --------------------------------------------------------
+// HttpCall passes a request on: hop-by-hop headers are dropped both
+// ways and redirects are returned, not followed
+// --------------------------------------------------------
+func HttpCall(method string, url string, header http.Header, body []byte, options CallOptions) *HttpCallResult {
 	httpCallResult := &HttpCallResult{}
-	bodyReader := bytes.NewReader(requestPayLoad)
 
-	req, err := http.NewRequest(http.MethodDelete, url, bodyReader)
-	if err != nil {
-		httpCallResult.Err = err
-		return httpCallResult
+	if options.ConnectTimeout <= 0 {
+		options.ConnectTimeout = DefaultConnectTimeout
+	}
+	if options.ReadTimeout <= 0 {
+		options.ReadTimeout = DefaultReadTimeout
 	}
 
-	req.Header = header
-
-	return HttpProcessRequest(req)
-}
+	var bodyReader io.Reader
+	if len(body) > 0 {
+		bodyReader = bytes.NewReader(body)
+	}
 
-//--------------------------------------------------------
-//
-//--------------------------------------------------------
+	parent := options.Context
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithCancel(parent)
 
-func HttpProcessRequest(req *http.Request) *HttpCallResult {
-	httpCallResult := &HttpCallResult{}
+	req, err := http.NewRequestWithContext(ctx, method, url, bodyReader)
+	if err != nil {
+		cancel()
+		httpCallResult.Err = err
+		return httpCallResult
+	}
 
-	req.Header.Set("Content-Type", "application/json")
+	if header != nil {
+		req.Header = header.Clone()
+	}
+	RemoveHopHeaders(req.Header)
+	req.Header.Del("Content-Length")
+	// the transport asks for gzip and unzips the body
+	req.Header.Del("Accept-Encoding")
+	if len(body) > 0 && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
 
-	client := http.Client{
-		Timeout: 30 * time.Second,
+	client := &http.Client{
+		Transport: callTransport(options.ConnectTimeout, options.ReadTimeout),
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
 	}
 
 	res, err := client.Do(req)
 	if err != nil {
+		cancel()
 		httpCallResult.Err = err
 		return httpCallResult
 	}
 
+	RemoveHopHeaders(res.Header)
 	httpCallResult.StatusCode = res.StatusCode
 	httpCallResult.Header = res.Header
 
-	body, err := ioutil.ReadAll(res.Body)
-	if err == nil {
-		httpCallResult.Body = string(body)
+	stream := newTimeoutReader(res.Body, options.ReadTimeout, cancel)
+	if options.Stream {
+		httpCallResult.Stream = stream
+		return httpCallResult
 	}
+	defer stream.Close()
+
+	// the body is sent again by the caller
+	res.Header.Del("Content-Length")
 
-	res.Body.Close()
+	responseBody, err := io.ReadAll(stream)
+	if err != nil {
+		httpCallResult.Err = fmt.Errorf("reading the response: %w", err)
+		return httpCallResult
+	}
+	httpCallResult.Body = string(responseBody)
 
 	return httpCallResult
 }
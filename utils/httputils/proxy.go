@@ -0,0 +1,169 @@
+package httputils
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// headers of one connection, not passed on by proxies (RFC 7230 6.1)
+var hopHeaders = []string{
+	"Connection",
+	"Proxy-Connection",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+}
+
+// transports by timeouts, so connections are reused
+var callTransports sync.Map
+
+// context key of the address of the connection
+type peerAddrKey struct{}
+
+// --------------------------------------------------------
+// RemoveHopHeaders drops the hop-by-hop headers and the headers
+// listed in Connection
+// --------------------------------------------------------
+func RemoveHopHeaders(header http.Header) {
+	for _, value := range header.Values("Connection") {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				header.Del(name)
+			}
+		}
+	}
+	for _, name := range hopHeaders {
+		header.Del(name)
+	}
+}
+
+// --------------------------------------------------------
+// KeepPeerAddr keeps the address of the connection in the request
+// context, it has to run before middleware.RealIP replaces RemoteAddr
+// with the client of X-Forwarded-For or X-Real-IP
+// --------------------------------------------------------
+func KeepPeerAddr(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), peerAddrKey{}, r.RemoteAddr)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// --------------------------------------------------------
+// PeerAddr is the address of the connection kept by KeepPeerAddr,
+// RemoteAddr when it did not run
+// --------------------------------------------------------
+func PeerAddr(r *http.Request) string {
+	if addr, ok := r.Context().Value(peerAddrKey{}).(string); ok {
+		return addr
+	}
+	return r.RemoteAddr
+}
+
+// --------------------------------------------------------
+// ForwardedHeader is the header of a request passed on, with the
+// X-Forwarded-For, X-Forwarded-Host and X-Forwarded-Proto headers
+// --------------------------------------------------------
+func ForwardedHeader(r *http.Request) http.Header {
+	header := r.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+
+	// the peer, the client is already in X-Forwarded-For
+	peerAddr := PeerAddr(r)
+	clientIP, _, err := net.SplitHostPort(peerAddr)
+	if err != nil {
+		clientIP = peerAddr
+	}
+	if clientIP != "" {
+		if prior := strings.Join(header.Values("X-Forwarded-For"), ", "); prior != "" {
+			clientIP = prior + ", " + clientIP
+		}
+		header.Set("X-Forwarded-For", clientIP)
+	}
+
+	if header.Get("X-Forwarded-Host") == "" && r.Host != "" {
+		header.Set("X-Forwarded-Host", r.Host)
+	}
+
+	if header.Get("X-Forwarded-Proto") == "" {
+		proto := "http"
+		if r.TLS != nil {
+			proto = "https"
+		}
+		header.Set("X-Forwarded-Proto", proto)
+	}
+
+	return header
+}
+
+// --------------------------------------------------------
+//
+// --------------------------------------------------------
+func callTransport(connectTimeout time.Duration, readTimeout time.Duration) *http.Transport {
+	key := fmt.Sprintf("%d/%d", connectTimeout, readTimeout)
+	if transport, found := callTransports.Load(key); found {
+		return transport.(*http.Transport)
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   connectTimeout,
+		KeepAlive: 30 * time.Second,
+	}
+	transport := &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     true,
+		TLSHandshakeTimeout:   connectTimeout,
+		ResponseHeaderTimeout: readTimeout,
+		ExpectContinueTimeout: 1 * time.Second,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   10,
+		IdleConnTimeout:       90 * time.Second,
+	}
+
+	actual, _ := callTransports.LoadOrStore(key, transport)
+	return actual.(*http.Transport)
+}
+
+// --------------------------------------------------------
+// the call is cancelled when a read of the body takes longer than
+// the timeout; time between reads is not counted
+// --------------------------------------------------------
+type timeoutReader struct {
+	body    io.ReadCloser
+	timeout time.Duration
+	timer   *time.Timer
+	cancel  context.CancelFunc
+}
+
+func newTimeoutReader(body io.ReadCloser, timeout time.Duration, cancel context.CancelFunc) *timeoutReader {
+	timer := time.AfterFunc(timeout, cancel)
+	timer.Stop()
+	return &timeoutReader{body: body, timeout: timeout, timer: timer, cancel: cancel}
+}
+
+func (t *timeoutReader) Read(p []byte) (int, error) {
+	t.timer.Reset(t.timeout)
+	n, err := t.body.Read(p)
+	t.timer.Stop()
+	return n, err
+}
+
+func (t *timeoutReader) Close() error {
+	t.timer.Stop()
+	err := t.body.Close()
+	t.cancel()
+	return err
+}
@@ -0,0 +1,231 @@
+package httputils
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_RemoveHopHeaders(t *testing.T) {
+	header := http.Header{}
+	header.Set("Connection", "keep-alive, X-Private ,")
+	header.Add("Connection", "X-Other")
+	header.Set("Keep-Alive", "timeout=5")
+	header.Set("Transfer-Encoding", "chunked")
+	header.Set("Upgrade", "websocket")
+	header.Set("Proxy-Authorization", "Basic x")
+	header.Set("Te", "trailers")
+	header.Set("X-Private", "1")
+	header.Set("X-Other", "2")
+	header.Set("Content-Type", "application/json")
+	header.Set("Authorization", "Bearer t")
+
+	RemoveHopHeaders(header)
+
+	expected := http.Header{
+		"Content-Type":  {"application/json"},
+		"Authorization": {"Bearer t"},
+	}
+	if !reflect.DeepEqual(header, expected) {
+		t.Errorf("expected %v but got %v", expected, header)
+	}
+
+	RemoveHopHeaders(http.Header{})
+}
+
+func Test_ForwardedHeader(t *testing.T) {
+	tests := []struct {
+		name       string
+		remoteAddr string
+		host       string
+		tls        bool
+		header     http.Header
+		expected   map[string]string
+	}{
+		{"plain", "10.0.0.1:5000", "mock.local", false, nil,
+			map[string]string{"X-Forwarded-For": "10.0.0.1", "X-Forwarded-Host": "mock.local", "X-Forwarded-Proto": "http"}},
+		{"tls", "10.0.0.1:5000", "mock.local", true, nil,
+			map[string]string{"X-Forwarded-Proto": "https"}},
+		{"appends to the chain", "10.0.0.2:5000", "mock.local", false, http.Header{"X-Forwarded-For": {"1.1.1.1, 2.2.2.2"}},
+			map[string]string{"X-Forwarded-For": "1.1.1.1, 2.2.2.2, 10.0.0.2"}},
+		{"keeps host and proto", "10.0.0.1:5000", "mock.local", false, http.Header{"X-Forwarded-Host": {"api.com"}, "X-Forwarded-Proto": {"https"}},
+			map[string]string{"X-Forwarded-Host": "api.com", "X-Forwarded-Proto": "https"}},
+		{"address without port", "10.0.0.3", "", false, nil,
+			map[string]string{"X-Forwarded-For": "10.0.0.3", "X-Forwarded-Host": ""}},
+	}
+
+	for _, test := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.RemoteAddr = test.remoteAddr
+		r.Host = test.host
+		r.TLS = nil
+		if test.tls {
+			r.TLS = &tls.ConnectionState{}
+		}
+		for k, v := range test.header {
+			r.Header[k] = v
+		}
+
+		header := ForwardedHeader(r)
+		for k, v := range test.expected {
+			if header.Get(k) != v {
+				t.Errorf("%s: expected %s %q but got %q", test.name, k, v, header.Get(k))
+			}
+		}
+		if test.header == nil && r.Header.Get("X-Forwarded-For") != "" {
+			t.Errorf("%s: the request header was changed", test.name)
+		}
+	}
+}
+
+func Test_HttpCall(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/redirect":
+			http.Redirect(w, r, "/echo", http.StatusFound)
+		case "/slow":
+			select {
+			case <-time.After(2 * time.Second):
+			case <-r.Context().Done():
+			}
+		case "/stall":
+			w.Write([]byte("first"))
+			w.(http.Flusher).Flush()
+			select {
+			case <-time.After(2 * time.Second):
+			case <-r.Context().Done():
+			}
+		default:
+			body, _ := io.ReadAll(r.Body)
+			w.Header().Set("Connection", "X-Hop")
+			w.Header().Set("X-Hop", "1")
+			w.Header().Set("X-Method", r.Method)
+			w.Header().Set("X-Content-Type", r.Header.Get("Content-Type"))
+			w.Header().Set("X-Private", r.Header.Get("X-Private"))
+			w.Write(body)
+		}
+	}))
+	defer server.Close()
+
+	header := http.Header{}
+	header.Set("Connection", "X-Private")
+	header.Set("X-Private", "secret")
+	header.Set("Content-Length", "999")
+	body := []byte(`{"id": 1}`)
+
+	// the same body is sent on every call
+	for i := 0; i < 2; i++ {
+		result := HttpCall(http.MethodPost, server.URL+"/echo", header, body, CallOptions{})
+		if result.Err != nil {
+			t.Fatalf("unexpected error %s", result.Err.Error())
+		}
+		if result.StatusCode != 200 || result.Body != `{"id": 1}` {
+			t.Errorf("call %d: expected 200 %s but got %d %q", i, body, result.StatusCode, result.Body)
+		}
+		if result.Header.Get("X-Method") != "POST" || result.Header.Get("X-Content-Type") != "application/json" {
+			t.Errorf("call %d: unexpected request %v", i, result.Header)
+		}
+		if result.Header.Get("X-Private") != "" || result.Header.Get("X-Hop") != "" {
+			t.Errorf("call %d: hop headers passed on: %v", i, result.Header)
+		}
+	}
+	if header.Get("X-Private") != "secret" {
+		t.Errorf("the caller header was changed")
+	}
+
+	result := HttpCall(http.MethodGet, server.URL+"/redirect", nil, nil, CallOptions{})
+	if result.Err != nil || result.StatusCode != http.StatusFound || !strings.HasSuffix(result.Header.Get("Location"), "/echo") {
+		t.Errorf("expected the redirect to be returned but got %d %v %v", result.StatusCode, result.Header, result.Err)
+	}
+
+	start := time.Now()
+	result = HttpCall(http.MethodGet, server.URL+"/slow", nil, nil, CallOptions{ReadTimeout: 100 * time.Millisecond})
+	if result.Err == nil || time.Since(start) > time.Second {
+		t.Errorf("expected a read timeout but got %v after %s", result.Err, time.Since(start))
+	}
+
+	start = time.Now()
+	result = HttpCall(http.MethodGet, server.URL+"/stall", nil, nil, CallOptions{ReadTimeout: 100 * time.Millisecond})
+	if result.Err == nil || !strings.Contains(result.Err.Error(), "reading the response") || time.Since(start) > time.Second {
+		t.Errorf("expected a body read timeout but got %v after %s", result.Err, time.Since(start))
+	}
+
+	result = HttpCall(http.MethodPost, server.URL+"/echo", nil, []byte("streamed"), CallOptions{Stream: true})
+	if result.Err != nil || result.Stream == nil || result.Body != "" {
+		t.Fatalf("expected a stream but got %+v", result)
+	}
+	streamed, err := io.ReadAll(result.Stream)
+	result.Stream.Close()
+	if err != nil || string(streamed) != "streamed" {
+		t.Errorf("expected %q but got %q %v", "streamed", streamed, err)
+	}
+}
+
+// the call ends with the context of the incoming request
+func Test_HttpCall_Context(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/stall" {
+			w.Write([]byte("first"))
+			w.(http.Flusher).Flush()
+		}
+		select {
+		case <-time.After(2 * time.Second):
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	result := HttpCall(http.MethodGet, server.URL+"/slow", nil, nil, CallOptions{Context: ctx})
+	if result.Err == nil || !errors.Is(result.Err, context.DeadlineExceeded) || time.Since(start) > time.Second {
+		t.Errorf("expected the call to end with the context but got %v after %s", result.Err, time.Since(start))
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	result = HttpCall(http.MethodGet, server.URL+"/stall", nil, nil, CallOptions{Context: ctx, Stream: true})
+	if result.Err != nil || result.Stream == nil {
+		t.Fatalf("expected a stream but got %+v", result)
+	}
+	defer result.Stream.Close()
+
+	buf := make([]byte, 5)
+	if _, err := io.ReadFull(result.Stream, buf); err != nil || string(buf) != "first" {
+		t.Fatalf("expected first but got %q %v", buf, err)
+	}
+	cancel()
+	start = time.Now()
+	if _, err := io.ReadAll(result.Stream); err == nil || time.Since(start) > time.Second {
+		t.Errorf("expected the stream to end with the context but got %v after %s", err, time.Since(start))
+	}
+}
+
+func Test_HttpCall_ConnectErrors(t *testing.T) {
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closedURL := closed.URL
+	closed.Close()
+
+	result := HttpCall(http.MethodGet, closedURL, nil, nil, CallOptions{})
+	if result.Err == nil {
+		t.Errorf("closed port: expected an error")
+	}
+
+	// not routable: the dial times out, or fails at once without a network
+	start := time.Now()
+	result = HttpCall(http.MethodGet, "http://10.255.255.1:81/", nil, nil, CallOptions{ConnectTimeout: 100 * time.Millisecond})
+	if result.Err == nil || time.Since(start) > 2*time.Second {
+		t.Errorf("expected a connect timeout but got %v after %s", result.Err, time.Since(start))
+	}
+
+	if HttpCall(http.MethodGet, "http://a b", nil, nil, CallOptions{}).Err == nil {
+		t.Errorf("invalid url: expected an error")
+	}
+}
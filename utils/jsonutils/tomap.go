@@ -51,8 +51,8 @@ func setMapValues(parsedJson map[string]any, keys string) map[string]any {
 
 			tempMap := setMapValues(listMap, "")
 			tempList := make([]any, 0)
-			for _, tVal := range tempMap {
-				tempList = append(tempList, tVal)
+			for i := range newList {
+				tempList = append(tempList, tempMap[fmt.Sprintf("%s[%d]", keyChain, i)]) // keep the array order
 			}
 
 			parsedJson[key] = tempList
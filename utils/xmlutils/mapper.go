@@ -24,8 +24,6 @@ func XmlToMap(r io.Reader) (map[string]any) {
 	masterkey := "$"
 	for token, err := p.Token(); err == nil; token, err = p.Token() {
 
-		fmt.Println("------------------------", elementCountMap, "\n\n")
-
 		//fmt.Println("token", token)
 
 		switch t := token.(type) {
@@ -56,18 +54,12 @@ func XmlToMap(r io.Reader) (map[string]any) {
 
 			if strings.HasSuffix(key, element_name) {
 				key = strings.TrimSuffix(key, element_name)
-			} else {
-				fmt.Println(">>>>>>>>>>  error >>>", key, element_name)
 			}
 
 			if strings.HasSuffix(masterkey, element_name) {
 				masterkey = strings.TrimSuffix(masterkey, element_name_unindexed)
-			} else {
-				fmt.Println(">>>>>>>>>>  error >>>", key, element_name)
 			}
 
-			fmt.Println(">>>>>>>>>>  EndElement >>>", key, " :: ", element_name)
-
 		case xml.StartElement:
 			//fmt.Println("EndElement values::", t.Name.Local)
 			element_name := ""
@@ -92,11 +84,8 @@ func XmlToMap(r io.Reader) (map[string]any) {
 				elementCountMap[e] = 1
 			}
 
-			fmt.Println(">>>>>>>>>>  StartElement >>>", key, " :: ", element_name)
-
 			for _, a := range t.Attr {
 				attribute := key + "_*ATTR_" + a.Name.Space + "_" + a.Name.Local
-				fmt.Println(">>>>>>>>>>  StartElement attribute >>>", attribute)
 
 				xmlMap[attribute] = a.Value
 
@@ -0,0 +1,201 @@
+package xmlutils
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// XmlToLocalFlatMapAndPlaceholder is XmlToFlatMapAndPlaceholder for namespaced
+// XML like SOAP envelopes: keys are made of local names only
+// (soap:Envelope/soap:Body/m:GetQuote => Envelope.Body.GetQuote) so they do
+// not depend on the prefixes or namespace URIs a client uses, and the
+// placeholder keeps the prefixes and the xmlns declarations as they are.
+//
+// The first namespace of a key keeps the local name, an element of another
+// namespace with the same key gets its prefix (a:item, b:item => item, b:item).
+// Same for attributes of one element. Unprefixed elements that can not be
+// told apart are an error.
+func XmlToLocalFlatMapAndPlaceholder(xmlString string) (map[string]ValueDatatype, string, error) {
+	p := xml.NewDecoder(strings.NewReader(xmlString))
+
+	var buf bytes.Buffer
+
+	xmlMap := make(map[string]ValueDatatype)
+	elementCountMap := make(map[string]int)
+	alreadyUsed := make(map[string]bool)
+
+	// keys of the open elements
+	keys := make([]string, 0)
+
+	// xmlns declarations of the open elements, and the namespace of every key
+	scopes := make([]map[string]string, 0)
+	keyNamespaces := make(map[string]string)
+
+	for {
+		token, err := p.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, "", err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			scope := make(map[string]string)
+			for _, a := range t.Attr {
+				switch {
+				case a.Name.Space == "" && a.Name.Local == "xmlns":
+					scope[""] = a.Value
+				case a.Name.Space == "xmlns":
+					scope[a.Name.Local] = a.Value
+				}
+			}
+			scopes = append(scopes, scope)
+
+			parent := ""
+			if len(keys) > 0 {
+				parent = keys[len(keys)-1] + "."
+			}
+			key, err := namespacedKey(keyNamespaces, parent, t.Name, resolveNamespace(scopes, t.Name.Space))
+			if err != nil {
+				return nil, "", err
+			}
+
+			count := elementCountMap[key]
+			elementCountMap[key] = count + 1
+			if count > 0 {
+				key = fmt.Sprintf("%s[%d]", key, count)
+			}
+			keys = append(keys, key)
+
+			// unprefixed attributes keep their local name
+			for _, a := range t.Attr {
+				if a.Name.Space == "" && !isNamespaceDeclaration(a.Name) {
+					keyNamespaces[key+".*ATTR."+a.Name.Local] = ""
+				}
+			}
+
+			buf.WriteString("<" + qualifiedName(t.Name))
+			for _, a := range t.Attr {
+				if isNamespaceDeclaration(a.Name) {
+					buf.WriteString(" " + qualifiedName(a.Name) + "=\"")
+					xml.EscapeText(&buf, []byte(a.Value))
+					buf.WriteString("\"")
+					continue
+				}
+
+				// unprefixed attributes have no namespace
+				namespace := ""
+				if a.Name.Space != "" {
+					namespace = resolveNamespace(scopes, a.Name.Space)
+				}
+				attribute, err := namespacedKey(keyNamespaces, key+".*ATTR.", a.Name, namespace)
+				if err != nil {
+					return nil, "", err
+				}
+				xmlMap[attribute] = ValueDatatype{Value: strings.TrimSpace(a.Value), DataType: "XMLSTRING"}
+				buf.WriteString(fmt.Sprintf(" %s=\"&#34;{{%s}}&#34;\"", qualifiedName(a.Name), attribute))
+			}
+			buf.WriteString(">")
+
+		case xml.EndElement:
+			if len(keys) == 0 {
+				return nil, "", fmt.Errorf("unexpected end element %s", t.Name.Local)
+			}
+			keys = keys[:len(keys)-1]
+			scopes = scopes[:len(scopes)-1]
+			buf.WriteString("</" + qualifiedName(t.Name) + ">")
+
+		case xml.CharData:
+			value := strings.TrimSpace(string(t))
+			if value == "" || len(keys) == 0 {
+				// formatting between the elements is kept
+				buf.Write(t)
+				continue
+			}
+
+			key := keys[len(keys)-1]
+			xmlMap[key] = ValueDatatype{Value: value, DataType: "XMLSTRING"}
+			if !alreadyUsed[key] {
+				alreadyUsed[key] = true
+				buf.WriteString(fmt.Sprintf("&#34;{{%s}}&#34;", key))
+			}
+
+		case xml.ProcInst:
+			buf.WriteString("<?" + t.Target + " " + string(t.Inst) + "?>")
+
+		case xml.Comment:
+			buf.WriteString("<!--" + string(t) + "-->")
+
+		case xml.Directive:
+			buf.WriteString("<!" + string(t) + ">")
+		}
+	}
+
+	if len(keys) > 0 {
+		return nil, "", fmt.Errorf("unclosed element %s", keys[len(keys)-1])
+	}
+
+	return xmlMap, buf.String(), nil
+}
+
+// -----------------------------------------------------------------
+// parent + local name, parent + prefix:local name when the local name is
+// taken by another namespace
+// -----------------------------------------------------------------
+func namespacedKey(keyNamespaces map[string]string, parent string, name xml.Name, namespace string) (string, error) {
+	key := parent + name.Local
+	taken, found := keyNamespaces[key]
+	if !found || taken == namespace {
+		keyNamespaces[key] = namespace
+		return key, nil
+	}
+
+	if name.Space == "" {
+		return "", fmt.Errorf("%s of namespace %q and %q share the key %s, use a prefix", name.Local, taken, namespace, key)
+	}
+
+	key = parent + qualifiedName(name)
+	if taken, found = keyNamespaces[key]; found && taken != namespace {
+		return "", fmt.Errorf("%s of namespace %q and %q share the key %s", qualifiedName(name), taken, namespace, key)
+	}
+	keyNamespaces[key] = namespace
+	return key, nil
+}
+
+// -----------------------------------------------------------------
+// namespace URI of a prefix in the open elements, the prefix itself
+// when it is not declared
+// -----------------------------------------------------------------
+func resolveNamespace(scopes []map[string]string, prefix string) string {
+	if prefix == "xml" {
+		return "http://www.w3.org/XML/1998/namespace"
+	}
+	for i := len(scopes) - 1; i >= 0; i-- {
+		if namespace, found := scopes[i][prefix]; found {
+			return namespace
+		}
+	}
+	return prefix
+}
+
+// -----------------------------------------------------------------
+//
+// -----------------------------------------------------------------
+func qualifiedName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return name.Space + ":" + name.Local
+}
+
+// -----------------------------------------------------------------
+// xmlns="..." and xmlns:prefix="..."
+// -----------------------------------------------------------------
+func isNamespaceDeclaration(name xml.Name) bool {
+	return (name.Space == "" && name.Local == "xmlns") || name.Space == "xmlns"
+}
@@ -0,0 +1,118 @@
+package xmlutils
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_XmlToLocalFlatMapAndPlaceholder(t *testing.T) {
+	envelope := `<?xml version="1.0"?>
+<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/">
+<s:Body>
+<m:GetQuote xmlns:m="http://example.com/stockquote" m:lang="en" lang="fr">
+  <m:symbol>ACME</m:symbol>
+  <m:symbol>INIT</m:symbol>
+</m:GetQuote>
+</s:Body>
+</s:Envelope>`
+
+	flatMap, placeholder, err := XmlToLocalFlatMapAndPlaceholder(envelope)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+
+	expected := map[string]string{
+		"Envelope.Body.GetQuote.symbol":       "ACME",
+		"Envelope.Body.GetQuote.symbol[1]":    "INIT",
+		"Envelope.Body.GetQuote.*ATTR.lang":   "fr",
+		"Envelope.Body.GetQuote.*ATTR.m:lang": "en",
+	}
+	if len(flatMap) != len(expected) {
+		t.Errorf("expected %d keys but got %v", len(expected), flatMap)
+	}
+	for key, value := range expected {
+		if flatMap[key].Value != value {
+			t.Errorf("%s: expected %q but got %q", key, value, flatMap[key].Value)
+		}
+	}
+
+	for _, kept := range []string{`<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/">`, `xmlns:m="http://example.com/stockquote"`, `<m:symbol>&#34;{{Envelope.Body.GetQuote.symbol}}&#34;</m:symbol>`} {
+		if !strings.Contains(placeholder, kept) {
+			t.Errorf("expected %q in the placeholder %q", kept, placeholder)
+		}
+	}
+
+	// keys do not depend on the prefixes of the client
+	flatMap, _, err = XmlToLocalFlatMapAndPlaceholder(`<soapenv:Envelope xmlns:soapenv="http://schemas.xmlsoap.org/soap/envelope/"><soapenv:Body><GetQuote xmlns="http://example.com/stockquote"><symbol>ACME</symbol></GetQuote></soapenv:Body></soapenv:Envelope>`)
+	if err != nil || flatMap["Envelope.Body.GetQuote.symbol"].Value != "ACME" {
+		t.Errorf("expected the same key for another prefix, got %v %v", flatMap, err)
+	}
+}
+
+func Test_XmlToLocalFlatMapAndPlaceholder_Namespaces(t *testing.T) {
+	tests := []struct {
+		name     string
+		xml      string
+		expected map[string]string
+		errorX   string
+	}{
+		{
+			"same local name, other namespace",
+			`<r xmlns:a="urn:a" xmlns:b="urn:b"><a:id>1</a:id><b:id>2</b:id><a:id>3</a:id></r>`,
+			map[string]string{"r.id": "1", "r.b:id": "2", "r.id[1]": "3"},
+			"",
+		},
+		{
+			"prefix of another scope",
+			`<r><a:item xmlns:a="urn:a">1</a:item><a:item xmlns:a="urn:x">2</a:item></r>`,
+			map[string]string{"r.item": "1", "r.a:item": "2"},
+			"",
+		},
+		{
+			"same namespace, other prefix",
+			`<r xmlns:a="urn:a" xmlns:b="urn:a"><a:id>1</a:id><b:id>2</b:id></r>`,
+			map[string]string{"r.id": "1", "r.id[1]": "2"},
+			"",
+		},
+		{
+			"prefixed attribute first",
+			`<r xmlns:x="urn:x" x:type="a" type="b"/>`,
+			map[string]string{"r.*ATTR.type": "b", "r.*ATTR.x:type": "a"},
+			"",
+		},
+		{
+			"default namespaces",
+			`<r><id xmlns="urn:a">1</id><id xmlns="urn:b">2</id></r>`,
+			nil,
+			"share the key r.id",
+		},
+		{
+			"unclosed",
+			`<r><id>1</id>`,
+			nil,
+			"unclosed element r",
+		},
+	}
+
+	for _, test := range tests {
+		flatMap, _, err := XmlToLocalFlatMapAndPlaceholder(test.xml)
+		if test.errorX != "" {
+			if err == nil || !strings.Contains(err.Error(), test.errorX) {
+				t.Errorf("%s: expected error %q but got %v", test.name, test.errorX, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error %s", test.name, err.Error())
+			continue
+		}
+		if len(flatMap) != len(test.expected) {
+			t.Errorf("%s: expected %d keys but got %v", test.name, len(test.expected), flatMap)
+		}
+		for key, value := range test.expected {
+			if flatMap[key].Value != value {
+				t.Errorf("%s: %s: expected %q but got %q", test.name, key, value, flatMap[key].Value)
+			}
+		}
+	}
+}